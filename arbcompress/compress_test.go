@@ -5,6 +5,7 @@ package arbcompress
 
 import (
 	"bytes"
+	"errors"
 	"testing"
 
 	"github.com/offchainlabs/nitro/util/testhelpers"
@@ -32,6 +33,23 @@ func testCompressDecompress(t *testing.T, data []byte) {
 		t.Fatal(err)
 	}
 	testDecompress(t, compressedFast, data)
+
+	compressedZstd, err := CompressZstd(data, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decompressedZstd, err := DecompressZstd(compressedZstd, len(data)*2+64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(decompressedZstd, data) {
+		t.Fatal("zstd results differ ", decompressedZstd, " vs. ", data)
+	}
+	if len(data) > 0 {
+		if _, err := DecompressZstd(compressedZstd, len(data)-1); !errors.Is(err, ErrOutputWontFit) {
+			t.Fatal("expected zstd decompression into a too small buffer to fail with ErrOutputWontFit, got ", err)
+		}
+	}
 }
 
 func TestArbCompress(t *testing.T) {
@@ -72,6 +72,38 @@ func DecompressWithDictionary(input []byte, maxSize int, dictionary Dictionary)
 	return output, nil
 }
 
+// CompressZstd zstd compresses the input. Only the fastest zstd level is
+// implemented, which any non-zero level uses.
+func CompressZstd(input []byte, level uint32) ([]byte, error) {
+	maxSize := compressedBufferSizeFor(len(input))
+	output := make([]byte, maxSize)
+	outbuf := sliceToBuffer(output)
+	inbuf := sliceToBuffer(input)
+
+	status := C.zstd_compress(inbuf, outbuf, u32(level))
+	if status != C.BrotliStatus_Success {
+		return nil, fmt.Errorf("failed zstd compression: %d", status)
+	}
+	output = output[:*outbuf.len]
+	return output, nil
+}
+
+func DecompressZstd(input []byte, maxSize int) ([]byte, error) {
+	output := make([]byte, maxSize)
+	outbuf := sliceToBuffer(output)
+	inbuf := sliceToBuffer(input)
+
+	status := C.zstd_decompress(inbuf, outbuf)
+	if status == C.BrotliStatus_NeedsMoreOutput {
+		return nil, ErrOutputWontFit
+	}
+	if status != C.BrotliStatus_Success {
+		return nil, fmt.Errorf("failed zstd decompression: %d", status)
+	}
+	output = output[:*outbuf.len]
+	return output, nil
+}
+
 func sliceToBuffer(slice []byte) brotliBuffer {
 	count := usize(len(slice))
 	if count == 0 {
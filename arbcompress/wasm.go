@@ -25,6 +25,12 @@ func brotliCompress(inBuf unsafe.Pointer, inLen uint32, outBuf unsafe.Pointer, o
 //go:wasmimport arbcompress brotli_decompress
 func brotliDecompress(inBuf unsafe.Pointer, inLen uint32, outBuf unsafe.Pointer, outLen unsafe.Pointer, dictionary Dictionary) brotliStatus
 
+//go:wasmimport arbcompress zstd_compress
+func zstdCompress(inBuf unsafe.Pointer, inLen uint32, outBuf unsafe.Pointer, outLen unsafe.Pointer, level uint32) brotliStatus
+
+//go:wasmimport arbcompress zstd_decompress
+func zstdDecompress(inBuf unsafe.Pointer, inLen uint32, outBuf unsafe.Pointer, outLen unsafe.Pointer) brotliStatus
+
 func Compress(input []byte, level uint32, dictionary Dictionary) ([]byte, error) {
 	maxOutSize := compressedBufferSizeFor(len(input))
 	outBuf := make([]byte, maxOutSize)
@@ -61,3 +67,35 @@ func DecompressWithDictionary(input []byte, maxSize int, dictionary Dictionary)
 	}
 	return outBuf[:outLen], nil
 }
+
+// CompressZstd zstd compresses the input. Only the fastest zstd level is
+// implemented, which any non-zero level uses.
+func CompressZstd(input []byte, level uint32) ([]byte, error) {
+	maxOutSize := compressedBufferSizeFor(len(input))
+	outBuf := make([]byte, maxOutSize)
+	outLen := uint32(len(outBuf))
+	status := zstdCompress(
+		arbutil.SliceToUnsafePointer(input), uint32(len(input)),
+		arbutil.SliceToUnsafePointer(outBuf), unsafe.Pointer(&outLen),
+		level,
+	)
+	if status != brotliSuccess {
+		return nil, fmt.Errorf("failed zstd compression")
+	}
+	return outBuf[:outLen], nil
+}
+
+func DecompressZstd(input []byte, maxSize int) ([]byte, error) {
+	outBuf := make([]byte, maxSize)
+	outLen := uint32(len(outBuf))
+	status := zstdDecompress(
+		arbutil.SliceToUnsafePointer(input),
+		uint32(len(input)),
+		arbutil.SliceToUnsafePointer(outBuf),
+		unsafe.Pointer(&outLen),
+	)
+	if status != brotliSuccess {
+		return nil, fmt.Errorf("failed zstd decompression")
+	}
+	return outBuf[:outLen], nil
+}
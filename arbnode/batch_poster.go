@@ -10,6 +10,7 @@ import (
 	"encoding/hex"
 	"errors"
 	"fmt"
+	"io"
 	"math"
 	"math/big"
 	"strings"
@@ -17,6 +18,7 @@ import (
 	"time"
 
 	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
 	"github.com/spf13/pflag"
 
 	"github.com/ethereum/go-ethereum"
@@ -137,6 +139,7 @@ type BatchPoster struct {
 	batchReverted          atomic.Bool // indicates whether data poster batch was reverted
 	nextRevertCheckBlock   int64       // the last parent block scanned for reverting batches
 	postedFirstBatch       bool        // indicates if batch poster has posted the first batch
+	lastBatchSize          int         // size of the last batch, to estimate the cost of the next one
 	ethDAFallbackRemaining int         // when >0, use EthDA and decrement; when 0, use altDA
 	currentWriterIndex     int         // index of DA writer to use (reset to 0 after success)
 
@@ -186,7 +189,10 @@ type BatchPosterConfig struct {
 	CompressionLevel int `koanf:"compression-level" reload:"hot"`
 	// CompressionLevels defines adaptive compression based on backlog. Each entry specifies the
 	// compression level and recompression level to use when backlog >= the entry's backlog threshold.
-	CompressionLevels              CompressionLevelStepList    `koanf:"compression-levels" reload:"hot"`
+	CompressionLevels CompressionLevelStepList `koanf:"compression-levels" reload:"hot"`
+	// Compression is the batch compression algorithm, "brotli" or "zstd".
+	// zstd is only used once the chain is at ArbOS daprovider.ZstdMessageArbOSVersion.
+	Compression                    string                      `koanf:"compression" reload:"hot"`
 	AnyTrustRetentionPeriod        time.Duration               `koanf:"anytrust-retention-period" reload:"hot"`
 	GasRefunderAddress             string                      `koanf:"gas-refunder-address" reload:"hot"`
 	DataPoster                     dataposter.DataPosterConfig `koanf:"data-poster" reload:"hot"`
@@ -205,10 +211,11 @@ type BatchPosterConfig struct {
 	CheckBatchCorrectness          bool                        `koanf:"check-batch-correctness"`
 	// MaxEmptyBatchDelay defines how long the batch poster waits before submitting a batch
 	// that contains no new useful transactions (a “report-only” or “empty” batch). Set to 0 to disable it.
-	MaxEmptyBatchDelay         time.Duration `koanf:"max-empty-batch-delay"`
-	DelayBufferThresholdMargin uint64        `koanf:"delay-buffer-threshold-margin"`
-	DelayBufferAlwaysUpdatable bool          `koanf:"delay-buffer-always-updatable"`
-	ParentChainEip7623         string        `koanf:"parent-chain-eip7623"`
+	MaxEmptyBatchDelay         time.Duration     `koanf:"max-empty-batch-delay"`
+	DelayBufferThresholdMargin uint64            `koanf:"delay-buffer-threshold-margin"`
+	DelayBufferAlwaysUpdatable bool              `koanf:"delay-buffer-always-updatable"`
+	ParentChainEip7623         string            `koanf:"parent-chain-eip7623"`
+	CostAwareDA                CostAwareDAConfig `koanf:"cost-aware-da" reload:"hot"`
 
 	gasRefunder  common.Address
 	l1BlockBound l1BlockBound
@@ -249,11 +256,19 @@ func (c *BatchPosterConfig) Validate() error {
 		return err
 	}
 	c.CompressionLevels = resolved
+	if c.Compression != BatchCompressionBrotli && c.Compression != BatchCompressionZstd {
+		return fmt.Errorf("invalid batch compression \"%v\", must be \"%v\" or \"%v\"", c.Compression, BatchCompressionBrotli, BatchCompressionZstd)
+	}
 	return nil
 }
 
 type BatchPosterConfigFetcher func() *BatchPosterConfig
 
+const (
+	BatchCompressionBrotli = "brotli"
+	BatchCompressionZstd   = "zstd"
+)
+
 func DangerousBatchPosterConfigAddOptions(prefix string, f *pflag.FlagSet) {
 	f.Bool(prefix+".allow-posting-first-batch-when-sequencer-message-count-mismatch", DefaultBatchPosterConfig.Dangerous.AllowPostingFirstBatchWhenSequencerMessageCountMismatch, "allow posting the first batch even if sequence number doesn't match chain (useful after force-inclusion)")
 	f.Uint64(prefix+".fixed-gas-limit", DefaultBatchPosterConfig.Dangerous.FixedGasLimit, "use this gas limit for batch posting instead of estimating it")
@@ -275,6 +290,7 @@ func BatchPosterConfigAddOptions(prefix string, f *pflag.FlagSet) {
 		`JSON array of compression level steps. Format: [{"backlog":<int>,"level":<int>,"recompression-level":<int>},...]. `+
 			`First entry must have backlog:0. Both Level and recomp-level must be 0-11, weakly descending. `+
 			`Example: [{"backlog":0,"level":11,"recompression-level":11},{"backlog":21,"level":6,"recompression-level":11}]`)
+	f.String(prefix+".compression", DefaultBatchPosterConfig.Compression, "batch compression algorithm (\"brotli\", or \"zstd\" which is only used from ArbOS "+fmt.Sprint(daprovider.ZstdMessageArbOSVersion)+" on, and can't be read by message extraction)")
 	f.Duration(prefix+".anytrust-retention-period", DefaultBatchPosterConfig.AnyTrustRetentionPeriod, "In AnyTrust mode, the period which AnyTrust nodes are requested to retain the stored batches.")
 	f.String(prefix+".gas-refunder-address", DefaultBatchPosterConfig.GasRefunderAddress, "The gas refunder contract address (optional)")
 	f.Uint64(prefix+".extra-batch-gas", DefaultBatchPosterConfig.ExtraBatchGas, "use this much more gas than estimation says is necessary to post batches")
@@ -295,6 +311,7 @@ func BatchPosterConfigAddOptions(prefix string, f *pflag.FlagSet) {
 	dataposter.DataPosterConfigAddOptions(prefix+".data-poster", f, dataposter.DefaultDataPosterConfig, dataposter.DataPosterUsageBatchPoster)
 	genericconf.WalletConfigAddOptions(prefix+".parent-chain-wallet", f, DefaultBatchPosterConfig.ParentChainWallet.Pathname)
 	DangerousBatchPosterConfigAddOptions(prefix+".dangerous", f)
+	CostAwareDAConfigAddOptions(prefix+".cost-aware-da", f)
 }
 
 var DefaultBatchPosterConfig = BatchPosterConfig{
@@ -312,6 +329,7 @@ var DefaultBatchPosterConfig = BatchPosterConfig{
 	ErrorDelay:                     time.Second * 10,
 	MaxDelay:                       time.Hour,
 	WaitForMaxDelay:                false,
+	Compression:                    BatchCompressionBrotli,
 	AnyTrustRetentionPeriod:        daprovider.DefaultAnyTrustRetentionPeriod,
 	GasRefunderAddress:             "",
 	ExtraBatchGas:                  50_000,
@@ -330,6 +348,7 @@ var DefaultBatchPosterConfig = BatchPosterConfig{
 	DelayBufferThresholdMargin:     25, // 5 minutes considering 12-second blocks
 	DelayBufferAlwaysUpdatable:     true,
 	ParentChainEip7623:             "auto",
+	CostAwareDA:                    DefaultCostAwareDAConfig,
 }
 
 var DefaultBatchPosterL1WalletConfig = genericconf.WalletConfig{
@@ -352,6 +371,7 @@ var TestBatchPosterConfig = BatchPosterConfig{
 	WaitForMaxDelay:                    false,
 	CompressionLevel:                   0,
 	CompressionLevels:                  CompressionLevelStepList{{Backlog: 0, Level: 2, RecompressionLevel: 2}},
+	Compression:                        BatchCompressionBrotli,
 	AnyTrustRetentionPeriod:            daprovider.DefaultAnyTrustRetentionPeriod,
 	GasRefunderAddress:                 "",
 	ExtraBatchGas:                      10_000,
@@ -368,6 +388,7 @@ var TestBatchPosterConfig = BatchPosterConfig{
 	DelayBufferThresholdMargin:         0,
 	DelayBufferAlwaysUpdatable:         true,
 	ParentChainEip7623:                 "auto",
+	CostAwareDA:                        DefaultCostAwareDAConfig,
 }
 
 type BatchPosterOpts struct {
@@ -612,6 +633,10 @@ type txInfo struct {
 	Accesses  *types.AccessList `json:"accessList,omitempty"`
 }
 
+func (b *BatchPoster) DataPoster() *dataposter.DataPoster {
+	return b.dataPoster
+}
+
 func (b *BatchPoster) ParentChainIsUsingEIP7623(ctx context.Context, latestHeader *types.Header) (bool, error) {
 	// Before EIP-7623 tx.gasUsed is defined as:
 	// tx.gasUsed = (
@@ -917,9 +942,26 @@ func (b *BatchPoster) getBatchPosterPosition(ctx context.Context, blockNum *big.
 
 var errBatchAlreadyClosed = errors.New("batch segments already closed")
 
+// batchCompressedWriter is implemented by the brotli and zstd writers.
+type batchCompressedWriter interface {
+	io.Writer
+	Flush() error
+	Close() error
+}
+
+// newBatchCompressedWriter returns a zstd writer if useZstd is set, with the
+// zstd level closest to the brotli level, and a brotli writer otherwise.
+func newBatchCompressedWriter(buffer *bytes.Buffer, level int, useZstd bool) (batchCompressedWriter, error) {
+	if useZstd {
+		return zstd.NewWriter(buffer, zstd.WithEncoderLevel(zstd.EncoderLevelFromZstd(level)), zstd.WithEncoderConcurrency(1))
+	}
+	return brotli.NewWriterLevel(buffer, level), nil
+}
+
 type batchSegments struct {
 	compressedBuffer      *bytes.Buffer
-	compressedWriter      *brotli.Writer
+	compressedWriter      batchCompressedWriter
+	useZstd               bool
 	rawSegments           [][]byte
 	timestamp             uint64
 	blockNum              uint64
@@ -940,13 +982,14 @@ type buildingBatch struct {
 	msgCount           arbutil.MessageIndex
 	haveUsefulMessage  bool
 	use4844            bool
+	usingAltDA         bool
 	muxBackend         *simulatedMuxBackend
 	firstDelayedMsg    *arbostypes.MessageWithMetadata
 	firstNonDelayedMsg *arbostypes.MessageWithMetadata
 	firstUsefulMsg     *arbostypes.MessageWithMetadata
 }
 
-func (b *BatchPoster) newBatchSegments(ctx context.Context, firstDelayed uint64, use4844 bool, usingAltDA bool) (*batchSegments, error) {
+func (b *BatchPoster) newBatchSegments(ctx context.Context, firstDelayed uint64, use4844 bool, usingAltDA bool, useZstd bool) (*batchSegments, error) {
 	config := b.config()
 	var maxSize int
 
@@ -959,9 +1002,7 @@ func (b *BatchPoster) newBatchSegments(ctx context.Context, firstDelayed uint64,
 			if err != nil {
 				return nil, err
 			}
-			// Try to fill under half of the parent chain's max blobs.
-			// #nosec G115
-			maxSize = blobs.BlobEncodableData*(int(maxBlobGasPerBlock)/params.BlobTxBlobGasPerBlob)/2 - blobBatchEncodingOverhead
+			maxSize = default4844BatchSize(maxBlobGasPerBlock)
 		}
 	} else if usingAltDA {
 		// Query the currently selected DA writer to get its max batch size
@@ -987,12 +1028,23 @@ func (b *BatchPoster) newBatchSegments(ctx context.Context, firstDelayed uint64,
 		}
 		maxSize -= SequencerMessageHeaderSize
 	}
+	// #nosec G115
+	return newBatchSegmentsWithSizeLimit(config.CompressionLevels, useZstd, b.GetBacklogEstimate(), maxSize, firstDelayed, int(b.chainConfig.MaxUncompressedBatchSize()))
+}
+
+// default4844BatchSize is the 4844 batch size used when Max4844BatchSize isn't
+// set, which tries to fill under half of the parent chain's max blobs.
+func default4844BatchSize(maxBlobGasPerBlock uint64) int {
+	// #nosec G115
+	return blobs.BlobEncodableData*(int(maxBlobGasPerBlock)/params.BlobTxBlobGasPerBlob)/2 - blobBatchEncodingOverhead
+}
+
+func newBatchSegmentsWithSizeLimit(compressionLevels CompressionLevelStepList, useZstd bool, backlog uint64, maxSize int, firstDelayed uint64, maxUncompressedSize int) (*batchSegments, error) {
 	compressedBuffer := bytes.NewBuffer(make([]byte, 0, maxSize*2))
 	// Determine compression levels based on backlog using configured steps
-	compressionLevel := config.CompressionLevels[0].Level
-	recompressionLevel := config.CompressionLevels[0].RecompressionLevel
-	backlog := b.GetBacklogEstimate()
-	for _, step := range config.CompressionLevels {
+	compressionLevel := compressionLevels[0].Level
+	recompressionLevel := compressionLevels[0].RecompressionLevel
+	for _, step := range compressionLevels {
 		if backlog >= step.Backlog {
 			compressionLevel = step.Level
 			recompressionLevel = step.RecompressionLevel
@@ -1000,20 +1052,29 @@ func (b *BatchPoster) newBatchSegments(ctx context.Context, firstDelayed uint64,
 			break
 		}
 	}
+	compressedWriter, err := newBatchCompressedWriter(compressedBuffer, compressionLevel, useZstd)
+	if err != nil {
+		return nil, err
+	}
 	return &batchSegments{
 		compressedBuffer:    compressedBuffer,
-		compressedWriter:    brotli.NewWriterLevel(compressedBuffer, compressionLevel),
+		compressedWriter:    compressedWriter,
+		useZstd:             useZstd,
 		sizeLimit:           maxSize,
 		recompressionLevel:  recompressionLevel,
 		rawSegments:         make([][]byte, 0, 128),
 		delayedMsg:          firstDelayed,
-		maxUncompressedSize: int(b.chainConfig.MaxUncompressedBatchSize()), // #nosec G115
+		maxUncompressedSize: maxUncompressedSize,
 	}, nil
 }
 
 func (s *batchSegments) recompressAll() error {
 	s.compressedBuffer = bytes.NewBuffer(make([]byte, 0, s.sizeLimit*2))
-	s.compressedWriter = brotli.NewWriterLevel(s.compressedBuffer, s.recompressionLevel)
+	compressedWriter, err := newBatchCompressedWriter(s.compressedBuffer, s.recompressionLevel, s.useZstd)
+	if err != nil {
+		return err
+	}
+	s.compressedWriter = compressedWriter
 	s.newUncompressedSize = 0
 	s.totalUncompressedSize = 0
 	for _, segment := range s.rawSegments {
@@ -1205,7 +1266,11 @@ func (s *batchSegments) CloseAndGetBytes() ([]byte, error) {
 	compressedBytes := s.compressedBuffer.Bytes()
 	fullMsg := make([]byte, 1, len(compressedBytes)+1)
 
-	fullMsg[0] = daprovider.BrotliMessageHeaderByte
+	if s.useZstd {
+		fullMsg[0] = daprovider.ZstdMessageHeaderByte
+	} else {
+		fullMsg[0] = daprovider.BrotliMessageHeaderByte
+	}
 
 	fullMsg = append(fullMsg, compressedBytes...)
 	return fullMsg, nil
@@ -1436,9 +1501,12 @@ func (b *BatchPoster) MaybePostSequencerBatch(ctx context.Context) (bool, error)
 		}
 		config := b.config()
 		buildingForEthDA := len(b.dapWriters) == 0 || b.ethDAFallbackRemaining > 0
+		// With cost aware DA, the batch may go to EthDA even if AltDA is available
+		costAwareDA := config.CostAwareDA.Enable && !buildingForEthDA
 		// Determine if we should use 4844 blobs (only relevant when posting to EthDA)
 		var use4844 bool
-		if buildingForEthDA &&
+		var blobsAvailable bool
+		if (buildingForEthDA || costAwareDA) &&
 			config.Post4844Blobs &&
 			latestHeader.ExcessBlobGas != nil &&
 			latestHeader.BlobGasUsed != nil {
@@ -1447,6 +1515,7 @@ func (b *BatchPoster) MaybePostSequencerBatch(ctx context.Context) (bool, error)
 				return false, err
 			}
 			if arbOSVersion >= params.ArbosVersion_20 {
+				blobsAvailable = true
 				if config.IgnoreBlobPrice {
 					use4844 = true
 				} else {
@@ -1459,50 +1528,50 @@ func (b *BatchPoster) MaybePostSequencerBatch(ctx context.Context) (bool, error)
 					if backlog == 0 ||
 						b.non4844BatchCount == 0 ||
 						b.non4844BatchCount > 16 {
-						blobFeePerByte, err := b.parentChain.BlobFeePerByte(ctx, latestHeader)
+						blobFeePerByte, err := b.blobFeePerByte(ctx, latestHeader)
 						if err != nil {
 							return false, err
 						}
-						blobFeePerByte.Mul(blobFeePerByte, blobTxBlobGasPerBlob)
-						blobFeePerByte.Div(blobFeePerByte, usableBytesInBlob)
-
-						// STANDARD_TOKEN_COST = 4
-						// TOTAL_COST_FLOOR_PER_TOKEN = 10
-						//
-						// The following analysis is applied for transactions unrelated to contract creation.
-						//
-						// Before EIP-7623, gas used related to calldata is defined as
-						// STANDARD_TOKEN_COST * (zero_bytes_in_calldata + nonzero_bytes_in_calldata * 4).
-						// Considering the worst case scenario regarding gas used per calldata byte,
-						// in which calldata only has non-zero bytes, each calldata byte will consume STANDARD_TOKEN * 4, which is 16 gas.
-						//
-						// With EIP-7623, considering the worst case scenario regarding gas used per calldata byte,
-						// in which calldata is also composed only of non-zero bytes,
-						// and that (TOTAL_COST_FLOOR_PER_TOKEN * tokens_in_calldata > STANDARD_TOKEN_COST * tokens_in_calldata + execution_gas_used),
-						// each calldata byte will consume TOTAL_COST_FLOOR_PER_TOKEN * 4, which is 40 gas.
-						calldataFeePerByteMultiplier := uint64(16)
-						parentChainIsUsingEIP7623, err := b.ParentChainIsUsingEIP7623(ctx, latestHeader)
-						if err != nil {
-							log.Error("ParentChainIsUsingEIP7623 failed", "err", err)
-						} else if parentChainIsUsingEIP7623 {
-							calldataFeePerByteMultiplier = uint64(40)
-						}
-
-						calldataFeePerByte := arbmath.BigMulByUint(latestHeader.BaseFee, calldataFeePerByteMultiplier)
+						calldataFeePerByte := b.calldataFeePerByte(ctx, latestHeader)
 						use4844 = arbmath.BigLessThan(blobFeePerByte, calldataFeePerByte)
 					}
 				}
 			}
 		}
 
+		if costAwareDA {
+			estimates, err := b.estimateDACosts(ctx, config, latestHeader, blobsAvailable)
+			if err != nil {
+				return false, err
+			}
+			buildingForEthDA = !estimates.preferAltDA(use4844)
+			log.Info(
+				"Chose DA path by estimated cost",
+				"altDA", !buildingForEthDA,
+				"use4844", use4844 && buildingForEthDA,
+				"calldataCost", estimates.Calldata,
+				"blobsCost", estimates.Blobs,
+				"altDACost", estimates.AltDA,
+			)
+		}
+
 		if b.ethDAFallbackRemaining > 0 {
 			log.Info("Building batch for EthDA due to previous altDA failure", "use4844", use4844, "fallbackRemaining", b.ethDAFallbackRemaining)
 		}
 
+		var useZstd bool
+		if config.Compression == BatchCompressionZstd {
+			arbOSVersion, err := b.arbOSVersionGetter.ArbOSVersionForMessageIndex(arbutil.MessageIndex(arbmath.SaturatingUSub(uint64(batchPosition.MessageCount), 1))).Await(ctx)
+			if err != nil {
+				return false, err
+			}
+			useZstd = arbOSVersion >= daprovider.ZstdMessageArbOSVersion
+		}
+
 		// Only use 4844 batching when posting to EthDA
 		use4844 = use4844 && buildingForEthDA
 		usingAltDA := !buildingForEthDA
-		segments, err := b.newBatchSegments(ctx, batchPosition.DelayedMessageCount, use4844, usingAltDA)
+		segments, err := b.newBatchSegments(ctx, batchPosition.DelayedMessageCount, use4844, usingAltDA, useZstd)
 		if err != nil {
 			return false, err
 		}
@@ -1511,6 +1580,7 @@ func (b *BatchPoster) MaybePostSequencerBatch(ctx context.Context) (bool, error)
 			msgCount:      batchPosition.MessageCount,
 			startMsgCount: batchPosition.MessageCount,
 			use4844:       use4844,
+			usingAltDA:    usingAltDA,
 		}
 		if b.config().CheckBatchCorrectness {
 			b.building.muxBackend = &simulatedMuxBackend{
@@ -1750,8 +1820,9 @@ func (b *BatchPoster) MaybePostSequencerBatch(ctx context.Context) (bool, error)
 	}
 	var sequencerMsg []byte
 
-	// Try DA writers if not forced to EthDA
-	if len(b.dapWriters) > 0 && b.ethDAFallbackRemaining == 0 {
+	b.lastBatchSize = len(batchData)
+	// Try DA writers if this batch was built for AltDA
+	if b.building.usingAltDA {
 		if !b.redisLock.AttemptLock(ctx) {
 			return false, errAttemptLockFailed
 		}
@@ -1818,6 +1889,10 @@ func (b *BatchPoster) MaybePostSequencerBatch(ctx context.Context) (bool, error)
 		}
 
 		log.Debug("DA writer succeeded", "writerIndex", writerIndex, "duration", storeDuration)
+		if maxLatency := config.CostAwareDA.MaxAltDAStoreLatency; config.CostAwareDA.Enable && maxLatency > 0 && storeDuration > maxLatency {
+			log.Warn("DA writer store exceeded max latency, will post the next batches to EthDA", "writerIndex", writerIndex, "duration", storeDuration, "maxLatency", maxLatency, "fallbackBatches", config.EthDAFallbackBatchCount)
+			b.ethDAFallbackRemaining = config.EthDAFallbackBatchCount
+		}
 		batchPosterDASuccessCounter.Inc(1)
 		batchPosterDALastSuccessfulActionGauge.Update(time.Now().Unix())
 	} else {
@@ -1947,7 +2022,10 @@ func (b *BatchPoster) MaybePostSequencerBatch(ctx context.Context) (bool, error)
 		b.building.muxBackend.seqMsg = seqMsg
 		b.building.muxBackend.delayedInboxStart = batchPosition.DelayedMessageCount
 		b.building.muxBackend.SetPositionWithinMessage(0)
-		simMux := arbstate.NewInboxMultiplexer(b.building.muxBackend, batchPosition.DelayedMessageCount, dapReaders, daprovider.KeysetValidate, b.chainConfig)
+		arbOSVersion := func() (uint64, error) {
+			return b.arbOSVersionGetter.ArbOSVersionForMessageIndex(arbutil.MessageIndex(arbmath.SaturatingUSub(uint64(batchPosition.MessageCount), 1))).Await(ctx)
+		}
+		simMux := arbstate.NewInboxMultiplexer(b.building.muxBackend, batchPosition.DelayedMessageCount, dapReaders, daprovider.KeysetValidate, arbOSVersion, b.chainConfig)
 		log.Debug("Begin checking the correctness of batch against inbox multiplexer", "startMsgSeqNum", batchPosition.MessageCount, "endMsgSeqNum", b.building.msgCount-1)
 		for i := batchPosition.MessageCount; i < b.building.msgCount; i++ {
 			msg, err := simMux.Pop(ctx)
@@ -1984,6 +2062,7 @@ func (b *BatchPoster) MaybePostSequencerBatch(ctx context.Context) (bool, error)
 	}
 	b.postedFirstBatch = true
 	b.currentWriterIndex = 0 // Reset to first writer after successful batch
+	recordDAChoice(b.building.usingAltDA, b.building.use4844)
 	log.Info(
 		"BatchPoster: batch sent",
 		"sequenceNumber", batchPosition.NextSeqNum,
@@ -2054,7 +2133,7 @@ func (b *BatchPoster) MaybePostSequencerBatch(ctx context.Context) (bool, error)
 	}
 
 	// After successful EthDA batch post in fallback mode, decrement counter and potentially retry AltDA
-	if b.ethDAFallbackRemaining > 0 {
+	if b.ethDAFallbackRemaining > 0 && !b.building.usingAltDA {
 		b.ethDAFallbackRemaining--
 		if b.ethDAFallbackRemaining == 0 {
 			log.Info("EthDA fallback period complete, will retry AltDA")
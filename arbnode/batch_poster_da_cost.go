@@ -0,0 +1,182 @@
+// Copyright 2026, Offchain Labs, Inc.
+// For license information, see https://github.com/OffchainLabs/nitro/blob/master/LICENSE.md
+
+package arbnode
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/spf13/pflag"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethereum/go-ethereum/params"
+
+	"github.com/offchainlabs/nitro/daprovider"
+	"github.com/offchainlabs/nitro/util/arbmath"
+)
+
+var (
+	daCostCalldataGauge = metrics.NewRegisteredGauge("arb/batchposter/dacost/calldata/gwei", nil)
+	daCostBlobsGauge    = metrics.NewRegisteredGauge("arb/batchposter/dacost/blobs/gwei", nil)
+	daCostAltDAGauge    = metrics.NewRegisteredGauge("arb/batchposter/dacost/altda/gwei", nil)
+
+	daChoiceCalldataCounter = metrics.NewRegisteredCounter("arb/batchposter/dachoice/calldata", nil)
+	daChoiceBlobsCounter    = metrics.NewRegisteredCounter("arb/batchposter/dachoice/blobs", nil)
+	daChoiceAltDACounter    = metrics.NewRegisteredCounter("arb/batchposter/dachoice/altda", nil)
+)
+
+type CostAwareDAConfig struct {
+	Enable bool `koanf:"enable" reload:"hot"`
+	// AltDAFeePerByte prices storing data with DA writers which can't quote
+	// its cost with daprovider.StoreCostEstimator.
+	AltDAFeePerByte uint64 `koanf:"altda-fee-per-byte" reload:"hot"`
+	// AltDACertificateSize is the estimated size of the certificate posted as
+	// calldata for an AltDA batch.
+	AltDACertificateSize uint64 `koanf:"altda-certificate-size" reload:"hot"`
+	// MaxAltDAStoreLatency makes the batch poster post the next
+	// EthDAFallbackBatchCount batches to EthDA after a slower store.
+	MaxAltDAStoreLatency time.Duration `koanf:"max-altda-store-latency" reload:"hot"`
+}
+
+var DefaultCostAwareDAConfig = CostAwareDAConfig{
+	Enable:               false,
+	AltDAFeePerByte:      0,
+	AltDACertificateSize: 256,
+	MaxAltDAStoreLatency: 0,
+}
+
+func CostAwareDAConfigAddOptions(prefix string, f *pflag.FlagSet) {
+	f.Bool(prefix+".enable", DefaultCostAwareDAConfig.Enable, "when DA writers are configured, estimate the cost of posting each batch to AltDA, with blobs and with calldata, and post it the cheapest way")
+	f.Uint64(prefix+".altda-fee-per-byte", DefaultCostAwareDAConfig.AltDAFeePerByte, "fee in parent chain wei per byte of storing a batch with a DA writer which doesn't quote its own cost")
+	f.Uint64(prefix+".altda-certificate-size", DefaultCostAwareDAConfig.AltDACertificateSize, "estimated size of the certificate posted as calldata for an AltDA batch")
+	f.Duration(prefix+".max-altda-store-latency", DefaultCostAwareDAConfig.MaxAltDAStoreLatency, "if a DA writer takes longer than this to store a batch, post the next ethda-fallback-batch-count batches to EthDA (0 = disabled)")
+}
+
+// daCostEstimates are the estimated parent chain costs in wei of posting a
+// batch through each DA path.
+type daCostEstimates struct {
+	Calldata *big.Int
+	// Blobs is nil if blobs can't be posted.
+	Blobs *big.Int
+	// AltDA is nil if no DA writer can be used.
+	AltDA *big.Int
+}
+
+// preferAltDA returns whether posting to AltDA is cheaper than posting to the
+// parent chain with blobs if use4844, and with calldata otherwise.
+func (e *daCostEstimates) preferAltDA(use4844 bool) bool {
+	if e.AltDA == nil {
+		return false
+	}
+	ethDA := e.Calldata
+	if use4844 && e.Blobs != nil {
+		ethDA = e.Blobs
+	}
+	return arbmath.BigLessThan(e.AltDA, ethDA)
+}
+
+func (e *daCostEstimates) updateMetrics() {
+	update := func(gauge *metrics.Gauge, cost *big.Int) {
+		if cost == nil {
+			gauge.Update(0)
+			return
+		}
+		gauge.Update(arbmath.BigDivByUint(cost, params.GWei).Int64())
+	}
+	update(daCostCalldataGauge, e.Calldata)
+	update(daCostBlobsGauge, e.Blobs)
+	update(daCostAltDAGauge, e.AltDA)
+}
+
+// calldataFeePerByte is the parent chain fee of a byte of batch calldata.
+func (b *BatchPoster) calldataFeePerByte(ctx context.Context, latestHeader *types.Header) *big.Int {
+	// STANDARD_TOKEN_COST = 4
+	// TOTAL_COST_FLOOR_PER_TOKEN = 10
+	//
+	// The following analysis is applied for transactions unrelated to contract creation.
+	//
+	// Before EIP-7623, gas used related to calldata is defined as
+	// STANDARD_TOKEN_COST * (zero_bytes_in_calldata + nonzero_bytes_in_calldata * 4).
+	// Considering the worst case scenario regarding gas used per calldata byte,
+	// in which calldata only has non-zero bytes, each calldata byte will consume STANDARD_TOKEN * 4, which is 16 gas.
+	//
+	// With EIP-7623, considering the worst case scenario regarding gas used per calldata byte,
+	// in which calldata is also composed only of non-zero bytes,
+	// and that (TOTAL_COST_FLOOR_PER_TOKEN * tokens_in_calldata > STANDARD_TOKEN_COST * tokens_in_calldata + execution_gas_used),
+	// each calldata byte will consume TOTAL_COST_FLOOR_PER_TOKEN * 4, which is 40 gas.
+	calldataFeePerByteMultiplier := uint64(16)
+	parentChainIsUsingEIP7623, err := b.ParentChainIsUsingEIP7623(ctx, latestHeader)
+	if err != nil {
+		log.Error("ParentChainIsUsingEIP7623 failed", "err", err)
+	} else if parentChainIsUsingEIP7623 {
+		calldataFeePerByteMultiplier = uint64(40)
+	}
+	return arbmath.BigMulByUint(latestHeader.BaseFee, calldataFeePerByteMultiplier)
+}
+
+// blobFeePerByte is the parent chain fee of a byte of batch data in blobs.
+func (b *BatchPoster) blobFeePerByte(ctx context.Context, latestHeader *types.Header) (*big.Int, error) {
+	blobFeePerByte, err := b.parentChain.BlobFeePerByte(ctx, latestHeader)
+	if err != nil {
+		return nil, err
+	}
+	blobFeePerByte.Mul(blobFeePerByte, blobTxBlobGasPerBlob)
+	blobFeePerByte.Div(blobFeePerByte, usableBytesInBlob)
+	return blobFeePerByte, nil
+}
+
+// estimateDACosts estimates the cost of posting a batch of the size of the
+// last posted batch, or of MaxCalldataBatchSize before the first one, through
+// each DA path. Blobs are only estimated if blobsAvailable.
+func (b *BatchPoster) estimateDACosts(ctx context.Context, config *BatchPosterConfig, latestHeader *types.Header, blobsAvailable bool) (*daCostEstimates, error) {
+	size := b.lastBatchSize
+	if size == 0 {
+		size = config.MaxCalldataBatchSize
+	}
+	// #nosec G115
+	batchSize := uint64(size)
+	calldataFeePerByte := b.calldataFeePerByte(ctx, latestHeader)
+	estimates := &daCostEstimates{
+		Calldata: arbmath.BigMulByUint(calldataFeePerByte, batchSize),
+	}
+	if blobsAvailable {
+		blobFeePerByte, err := b.blobFeePerByte(ctx, latestHeader)
+		if err != nil {
+			return nil, err
+		}
+		estimates.Blobs = arbmath.BigMulByUint(blobFeePerByte, batchSize)
+	}
+	if len(b.dapWriters) > 0 && b.ethDAFallbackRemaining == 0 {
+		writer := b.dapWriters[b.currentWriterIndex]
+		var storeCost *big.Int
+		if estimator, ok := writer.(daprovider.StoreCostEstimator); ok {
+			var err error
+			storeCost, err = estimator.EstimateStoreCost(size).Await(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("estimating store cost of DA writer %d: %w", b.currentWriterIndex, err)
+			}
+		}
+		if storeCost == nil {
+			storeCost = new(big.Int).SetUint64(arbmath.SaturatingUMul(config.CostAwareDA.AltDAFeePerByte, batchSize))
+		}
+		certificateCost := arbmath.BigMulByUint(calldataFeePerByte, config.CostAwareDA.AltDACertificateSize)
+		estimates.AltDA = new(big.Int).Add(storeCost, certificateCost)
+	}
+	estimates.updateMetrics()
+	return estimates, nil
+}
+
+func recordDAChoice(usingAltDA bool, use4844 bool) {
+	if usingAltDA {
+		daChoiceAltDACounter.Inc(1)
+	} else if use4844 {
+		daChoiceBlobsCounter.Inc(1)
+	} else {
+		daChoiceCalldataCounter.Inc(1)
+	}
+}
@@ -0,0 +1,130 @@
+// Copyright 2026, Offchain Labs, Inc.
+// For license information, see https://github.com/OffchainLabs/nitro/blob/master/LICENSE.md
+
+package arbnode
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+
+	"github.com/offchainlabs/nitro/cmd/genericconf"
+	"github.com/offchainlabs/nitro/daprovider"
+	anytrustutil "github.com/offchainlabs/nitro/daprovider/anytrust/util"
+	"github.com/offchainlabs/nitro/daprovider/daclient"
+	"github.com/offchainlabs/nitro/daprovider/data_streaming"
+	"github.com/offchainlabs/nitro/daprovider/referenceda"
+	dapserver "github.com/offchainlabs/nitro/daprovider/server"
+	"github.com/offchainlabs/nitro/util/arbmath"
+	"github.com/offchainlabs/nitro/util/signature"
+	"github.com/offchainlabs/nitro/util/testhelpers"
+)
+
+func TestDACostEstimatesPreferAltDA(t *testing.T) {
+	tests := []struct {
+		name      string
+		estimates daCostEstimates
+		use4844   bool
+		want      bool
+	}{
+		{"no altda", daCostEstimates{Calldata: big.NewInt(100), Blobs: big.NewInt(10)}, true, false},
+		{"altda cheaper than calldata", daCostEstimates{Calldata: big.NewInt(100), AltDA: big.NewInt(50)}, false, true},
+		{"calldata cheaper than altda", daCostEstimates{Calldata: big.NewInt(100), AltDA: big.NewInt(150)}, false, false},
+		{"blobs cheaper than altda", daCostEstimates{Calldata: big.NewInt(100), Blobs: big.NewInt(10), AltDA: big.NewInt(50)}, true, false},
+		{"altda cheaper than blobs", daCostEstimates{Calldata: big.NewInt(100), Blobs: big.NewInt(60), AltDA: big.NewInt(50)}, true, true},
+		{"blobs not chosen", daCostEstimates{Calldata: big.NewInt(100), Blobs: big.NewInt(10), AltDA: big.NewInt(50)}, false, true},
+		{"equal cost stays on ethda", daCostEstimates{Calldata: big.NewInt(100), AltDA: big.NewInt(100)}, false, false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.estimates.preferAltDA(tc.use4844); got != tc.want {
+				t.Errorf("preferAltDA(%v) = %v, want %v", tc.use4844, got, tc.want)
+			}
+		})
+	}
+}
+
+// nonQuotingWriter hides the StoreCostEstimator implementation of the writer
+// it wraps.
+type nonQuotingWriter struct {
+	daprovider.Writer
+}
+
+func startDAProviderServer(t *testing.T, ctx context.Context, writer daprovider.Writer) *daclient.Client {
+	t.Helper()
+	serverConfig := dapserver.ServerConfig{
+		Addr:               "localhost",
+		Port:               0,
+		EnableDAWriter:     true,
+		ServerTimeouts:     genericconf.HTTPServerTimeoutConfig{},
+		RPCServerBodyLimit: data_streaming.TestHttpBodyLimit,
+		JWTSecret:          "",
+	}
+	storage := referenceda.GetInMemoryStorage()
+	reader := referenceda.NewReader(storage, nil, common.Address{})
+	validator := referenceda.NewValidator(storage, nil, common.Address{})
+	headerBytes := []byte{daprovider.DACertificateMessageHeaderFlag}
+	server, err := dapserver.NewServerWithDAPProvider(ctx, &serverConfig, reader, writer, validator, headerBytes, data_streaming.PayloadCommitmentVerifier())
+	testhelpers.RequireImpl(t, err)
+	t.Cleanup(func() { _ = server.Close() })
+	client, err := daclient.NewClient(ctx, daclient.TestClientConfig(server.Addr), data_streaming.PayloadCommiter())
+	testhelpers.RequireImpl(t, err)
+	return client
+}
+
+func TestEstimateDACostsWithDAWriters(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	privateKey, err := crypto.GenerateKey()
+	testhelpers.RequireImpl(t, err)
+	signer := signature.DataSignerFromPrivateKey(privateKey)
+	referenceWriter := referenceda.NewWriter(referenceda.GetInMemoryStorage(), signer, referenceda.DefaultConfig.MaxBatchSize)
+
+	const batchSize = 1000
+	const feePerByte = 1_000_000
+	const certificateSize = 100
+	config := TestBatchPosterConfig
+	config.CostAwareDA.Enable = true
+	config.CostAwareDA.AltDAFeePerByte = feePerByte
+	config.CostAwareDA.AltDACertificateSize = certificateSize
+	header := &types.Header{Number: big.NewInt(1), BaseFee: big.NewInt(params.GWei)}
+	calldataFeePerByte := arbmath.BigMulByUint(header.BaseFee, 16)
+	certificateCost := arbmath.BigMulByUint(calldataFeePerByte, certificateSize)
+
+	tests := []struct {
+		name      string
+		writer    daprovider.Writer
+		wantAltDA *big.Int
+	}{
+		{"anytrust", anytrustutil.NewWriter(nil, batchSize), certificateCost},
+		{"referenceda", referenceWriter, certificateCost},
+		{"referenceda over rpc", startDAProviderServer(t, ctx, referenceWriter), certificateCost},
+		{"non-quoting writer", nonQuotingWriter{referenceWriter}, new(big.Int).Add(big.NewInt(feePerByte*batchSize), certificateCost)},
+		{"non-quoting writer over rpc", startDAProviderServer(t, ctx, nonQuotingWriter{referenceWriter}), new(big.Int).Add(big.NewInt(feePerByte*batchSize), certificateCost)},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			b := &BatchPoster{
+				dapWriters:    []daprovider.Writer{tc.writer},
+				lastBatchSize: batchSize,
+			}
+			estimates, err := b.estimateDACosts(ctx, &config, header, false)
+			testhelpers.RequireImpl(t, err)
+			if want := arbmath.BigMulByUint(calldataFeePerByte, batchSize); estimates.Calldata.Cmp(want) != 0 {
+				t.Errorf("calldata cost %v, want %v", estimates.Calldata, want)
+			}
+			if estimates.Blobs != nil {
+				t.Errorf("blob cost %v estimated without blobs", estimates.Blobs)
+			}
+			if estimates.AltDA == nil || estimates.AltDA.Cmp(tc.wantAltDA) != 0 {
+				t.Errorf("altda cost %v, want %v", estimates.AltDA, tc.wantAltDA)
+			}
+		})
+	}
+}
@@ -0,0 +1,248 @@
+// Copyright 2026, Offchain Labs, Inc.
+// For license information, see https://github.com/OffchainLabs/nitro/blob/master/LICENSE.md
+
+package arbnode
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/params"
+
+	"github.com/offchainlabs/nitro/arbos/arbostypes"
+	"github.com/offchainlabs/nitro/arbos/l1pricing"
+	"github.com/offchainlabs/nitro/arbutil"
+	"github.com/offchainlabs/nitro/util/arbmath"
+	"github.com/offchainlabs/nitro/util/blobs"
+)
+
+// SimulationMessageSource provides the messages replayed by SimulateBatches,
+// such as a TransactionStreamer or a node database.
+type SimulationMessageSource interface {
+	GetMessage(msgIdx arbutil.MessageIndex) (*arbostypes.MessageWithMetadata, error)
+}
+
+type BatchSimulationOpts struct {
+	// Config is the validated batch poster config to simulate. Post4844Blobs
+	// selects blob batches instead of calldata batches, and Compression the
+	// compression regardless of the ArbOS version.
+	Config   *BatchPosterConfig
+	Messages SimulationMessageSource
+	// Start and End are the range of messages to put into batches, End
+	// excluded.
+	Start arbutil.MessageIndex
+	End   arbutil.MessageIndex
+	// Backlog is the batch backlog selecting the compression levels.
+	Backlog                  uint64
+	MaxUncompressedBatchSize uint64
+	// MaxBlobGasPerBlock sizes the 4844 batches if Max4844BatchSize isn't set.
+	MaxBlobGasPerBlock uint64
+	// ParentChainBaseFee and ParentChainBlobBaseFee price the batches.
+	ParentChainBaseFee     *big.Int
+	ParentChainBlobBaseFee *big.Int
+	ParentChainEIP7623     bool
+}
+
+// SimulatedBatch is a batch built by SimulateBatches.
+type SimulatedBatch struct {
+	FirstMessage     arbutil.MessageIndex `json:"firstMessage"`
+	MessageCount     uint64               `json:"messageCount"`
+	DelayedMessages  uint64               `json:"delayedMessages"`
+	UncompressedSize int                  `json:"uncompressedSize"`
+	Size             int                  `json:"size"`
+	Blobs            int                  `json:"blobs,omitempty"`
+	Gas              uint64               `json:"gas"`
+	BlobGas          uint64               `json:"blobGas,omitempty"`
+	EstimatedCost    *big.Int             `json:"estimatedCost"`
+}
+
+type BatchSimulationResult struct {
+	Batches               []SimulatedBatch `json:"batches,omitempty"`
+	BatchCount            int              `json:"batchCount"`
+	MessageCount          uint64           `json:"messageCount"`
+	TotalUncompressedSize int              `json:"totalUncompressedSize"`
+	TotalSize             int              `json:"totalSize"`
+	TotalBlobs            int              `json:"totalBlobs,omitempty"`
+	TotalGas              uint64           `json:"totalGas"`
+	TotalBlobGas          uint64           `json:"totalBlobGas,omitempty"`
+	TotalEstimatedCost    *big.Int         `json:"totalEstimatedCost"`
+}
+
+// sequencerBatchCallGas estimates the gas addSequencerL2BatchFromOrigin uses
+// besides the intrinsic and calldata gas, for storing the batch accumulator
+// and emitting the batch events. It's the per-batch gas cost ArbOS charges
+// for posting a batch, less the intrinsic gas.
+const sequencerBatchCallGas = l1pricing.InitialPerBatchGasCostV12 - params.TxGas
+
+// batchCalldataGas estimates the gas of posting data as calldata, counting
+// 4 gas per token or the EIP-7623 floor of 10 gas per token, where zero bytes
+// are one token and non-zero bytes four.
+func batchCalldataGas(data []byte, eip7623 bool) uint64 {
+	// #nosec G115
+	zeros := uint64(bytes.Count(data, []byte{0}))
+	// #nosec G115
+	tokens := zeros + (uint64(len(data))-zeros)*4
+	if eip7623 {
+		return tokens * 10
+	}
+	return tokens * 4
+}
+
+func (o *BatchSimulationOpts) maxBatchSize() (int, error) {
+	if o.Config.Post4844Blobs {
+		if o.Config.Max4844BatchSize != 0 {
+			return o.Config.Max4844BatchSize, nil
+		}
+		if o.MaxBlobGasPerBlock < params.BlobTxBlobGasPerBlob {
+			return 0, fmt.Errorf("max blob gas per block too small to simulate 4844 batches: %d", o.MaxBlobGasPerBlock)
+		}
+		return default4844BatchSize(o.MaxBlobGasPerBlock), nil
+	}
+	if o.Config.MaxCalldataBatchSize <= SequencerMessageHeaderSize {
+		return 0, fmt.Errorf("maximum calldata batch size too small: %d", o.Config.MaxCalldataBatchSize)
+	}
+	return o.Config.MaxCalldataBatchSize - SequencerMessageHeaderSize, nil
+}
+
+// simulatedBatch tracks the batch being built by SimulateBatches.
+type simulatedBatch struct {
+	segments             *batchSegments
+	start                arbutil.MessageIndex
+	firstDelayed         uint64
+	haveUsefulMessage    bool
+	firstUsefulTimestamp uint64
+}
+
+// SimulateBatches replays a range of messages through the batch building of
+// the BatchPoster, and reports the batches it would post with their size and
+// estimated parent chain cost. Nothing is posted.
+// A batch is closed when it's full, or before the first message which
+// arrived MaxDelay after its first useful message, as if the batch poster
+// always kept up with the chain. Parent chain bounds and the delay buffer
+// aren't simulated.
+func SimulateBatches(ctx context.Context, opts *BatchSimulationOpts) (*BatchSimulationResult, error) {
+	if opts.Start >= opts.End {
+		return nil, fmt.Errorf("empty message range [%d, %d)", opts.Start, opts.End)
+	}
+	config := opts.Config
+	maxSize, err := opts.maxBatchSize()
+	if err != nil {
+		return nil, err
+	}
+	// #nosec G115
+	maxUncompressedSize := int(opts.MaxUncompressedBatchSize)
+	var delayedRead uint64
+	if opts.Start > 0 {
+		prevMsg, err := opts.Messages.GetMessage(opts.Start - 1)
+		if err != nil {
+			return nil, fmt.Errorf("getting message %d: %w", opts.Start-1, err)
+		}
+		delayedRead = prevMsg.DelayedMessagesRead
+	}
+	// #nosec G115
+	maxDelaySeconds := uint64(config.MaxDelay / time.Second)
+
+	result := &BatchSimulationResult{
+		TotalEstimatedCost: big.NewInt(0),
+	}
+	var building *simulatedBatch
+	closeBatch := func(end arbutil.MessageIndex) error {
+		batch := SimulatedBatch{
+			FirstMessage:    building.start,
+			MessageCount:    uint64(end - building.start),
+			DelayedMessages: delayedRead - building.firstDelayed,
+		}
+		segments := building.segments
+		building = nil
+		data, err := segments.CloseAndGetBytes()
+		if err != nil {
+			return err
+		}
+		if data == nil {
+			return nil
+		}
+		batch.UncompressedSize = segments.totalUncompressedSize
+		batch.Size = len(data)
+		// ExtraBatchGas only pads the gas limit, so it isn't part of the cost
+		batch.Gas = params.TxGas + sequencerBatchCallGas
+		if config.Post4844Blobs {
+			kzgBlobs, err := blobs.EncodeBlobs(data)
+			if err != nil {
+				return fmt.Errorf("encoding batch into blobs: %w", err)
+			}
+			batch.Blobs = len(kzgBlobs)
+			// #nosec G115
+			batch.BlobGas = uint64(len(kzgBlobs)) * params.BlobTxBlobGasPerBlob
+		} else {
+			batch.Gas += batchCalldataGas(data, opts.ParentChainEIP7623)
+		}
+		batch.EstimatedCost = arbmath.BigMulByUint(opts.ParentChainBaseFee, batch.Gas)
+		batch.EstimatedCost.Add(batch.EstimatedCost, arbmath.BigMulByUint(opts.ParentChainBlobBaseFee, batch.BlobGas))
+
+		result.Batches = append(result.Batches, batch)
+		result.BatchCount++
+		result.MessageCount += batch.MessageCount
+		result.TotalUncompressedSize += batch.UncompressedSize
+		result.TotalSize += batch.Size
+		result.TotalBlobs += batch.Blobs
+		result.TotalGas += batch.Gas
+		result.TotalBlobGas += batch.BlobGas
+		result.TotalEstimatedCost.Add(result.TotalEstimatedCost, batch.EstimatedCost)
+		return nil
+	}
+
+	for pos := opts.Start; pos < opts.End; {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		msg, err := opts.Messages.GetMessage(pos)
+		if err != nil {
+			return nil, fmt.Errorf("getting message %d: %w", pos, err)
+		}
+		if building == nil {
+			segments, err := newBatchSegmentsWithSizeLimit(config.CompressionLevels, config.Compression == BatchCompressionZstd, opts.Backlog, maxSize, delayedRead, maxUncompressedSize)
+			if err != nil {
+				return nil, err
+			}
+			building = &simulatedBatch{
+				segments:     segments,
+				start:        pos,
+				firstDelayed: delayedRead,
+			}
+		} else if building.haveUsefulMessage && config.MaxDelay > 0 &&
+			msg.Message.Header.Timestamp >= arbmath.SaturatingUAdd(building.firstUsefulTimestamp, maxDelaySeconds) {
+			if err := closeBatch(pos); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		success, err := building.segments.AddMessage(msg)
+		if err != nil {
+			return nil, fmt.Errorf("adding message %d to batch: %w", pos, err)
+		}
+		if !success {
+			if pos == building.start {
+				return nil, fmt.Errorf("message %d doesn't fit into an empty batch", pos)
+			}
+			if err := closeBatch(pos); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if !building.haveUsefulMessage && msg.Message.Header.Kind != arbostypes.L1MessageType_BatchPostingReport {
+			building.haveUsefulMessage = true
+			building.firstUsefulTimestamp = msg.Message.Header.Timestamp
+		}
+		delayedRead = msg.DelayedMessagesRead
+		pos++
+	}
+	if building != nil {
+		if err := closeBatch(opts.End); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,180 @@
+// Copyright 2026, Offchain Labs, Inc.
+// For license information, see https://github.com/OffchainLabs/nitro/blob/master/LICENSE.md
+
+package arbnode
+
+import (
+	"context"
+	"math/big"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/params"
+
+	"github.com/offchainlabs/nitro/arbos/arbostypes"
+	"github.com/offchainlabs/nitro/arbstate"
+	"github.com/offchainlabs/nitro/arbutil"
+	"github.com/offchainlabs/nitro/daprovider"
+	"github.com/offchainlabs/nitro/util/testhelpers"
+)
+
+type sliceMessageSource []*arbostypes.MessageWithMetadata
+
+func (s sliceMessageSource) GetMessage(msgIdx arbutil.MessageIndex) (*arbostypes.MessageWithMetadata, error) {
+	return s[msgIdx], nil
+}
+
+func simulationMessages(count int, l2MsgSize uint64) sliceMessageSource {
+	var messages sliceMessageSource
+	for i := 0; i < count; i++ {
+		messages = append(messages, &arbostypes.MessageWithMetadata{
+			Message: &arbostypes.L1IncomingMessage{
+				Header: &arbostypes.L1IncomingMessageHeader{
+					Kind: arbostypes.L1MessageType_L2Message,
+					// #nosec G115
+					Timestamp:   uint64(i),
+					BlockNumber: 1,
+				},
+				L2msg: testhelpers.RandomSlice(l2MsgSize),
+			},
+		})
+	}
+	return messages
+}
+
+func simulationOpts(config *BatchPosterConfig, messages sliceMessageSource) *BatchSimulationOpts {
+	return &BatchSimulationOpts{
+		Config:                   config,
+		Messages:                 messages,
+		Start:                    0,
+		End:                      arbutil.MessageIndex(len(messages)),
+		MaxUncompressedBatchSize: params.DefaultMaxUncompressedBatchSize,
+		MaxBlobGasPerBlock:       6 * params.BlobTxBlobGasPerBlob,
+		ParentChainBaseFee:       big.NewInt(params.GWei),
+		ParentChainBlobBaseFee:   big.NewInt(1),
+		ParentChainEIP7623:       true,
+	}
+}
+
+func checkSimulatedBatches(t *testing.T, result *BatchSimulationResult, messageCount int) {
+	t.Helper()
+	var next arbutil.MessageIndex
+	for i, batch := range result.Batches {
+		if batch.FirstMessage != next || batch.MessageCount == 0 {
+			t.Fatalf("batch %d covers %d messages from %d, want messages from %d", i, batch.MessageCount, batch.FirstMessage, next)
+		}
+		next += arbutil.MessageIndex(batch.MessageCount)
+	}
+	// #nosec G115
+	if next != arbutil.MessageIndex(messageCount) || result.MessageCount != uint64(messageCount) || result.BatchCount != len(result.Batches) {
+		t.Fatalf("batches cover %d messages, result reports %d messages in %d batches, want %d messages", next, result.MessageCount, result.BatchCount, messageCount)
+	}
+}
+
+func TestSimulateBatchesSizeLimit(t *testing.T) {
+	config := TestBatchPosterConfig
+	config.MaxCalldataBatchSize = 3000 + SequencerMessageHeaderSize
+	config.MaxDelay = 0
+	messages := simulationMessages(10, 1000)
+	result, err := SimulateBatches(context.Background(), simulationOpts(&config, messages))
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkSimulatedBatches(t, result, len(messages))
+	// Random data doesn't compress, so it takes several batches
+	if result.BatchCount < 3 {
+		t.Errorf("got %d batches, want at least 3", result.BatchCount)
+	}
+	for i, batch := range result.Batches {
+		if batch.Size > config.MaxCalldataBatchSize {
+			t.Errorf("batch %d has size %d over the limit %d", i, batch.Size, config.MaxCalldataBatchSize)
+		}
+		if batch.Blobs != 0 || batch.BlobGas != 0 {
+			t.Errorf("calldata batch %d uses blobs", i)
+		}
+		// #nosec G115
+		if batch.Gas <= params.TxGas+sequencerBatchCallGas+uint64(batch.Size)*10 {
+			t.Errorf("batch %d gas %d doesn't account for its calldata", i, batch.Gas)
+		}
+		if batch.EstimatedCost.Cmp(new(big.Int).SetUint64(batch.Gas*params.GWei)) != 0 {
+			t.Errorf("batch %d estimated cost %v, want %d gas at 1 gwei", i, batch.EstimatedCost, batch.Gas)
+		}
+	}
+}
+
+func TestSimulateBatchesMaxDelay(t *testing.T) {
+	config := TestBatchPosterConfig
+	config.MaxDelay = 3 * time.Second
+	config.Post4844Blobs = true
+	messages := simulationMessages(10, 100)
+	// The fifth message reads a delayed message
+	for _, msg := range messages[4:] {
+		msg.DelayedMessagesRead = 1
+	}
+	result, err := SimulateBatches(context.Background(), simulationOpts(&config, messages))
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkSimulatedBatches(t, result, len(messages))
+	wantMessages := []uint64{3, 3, 3, 1}
+	wantDelayed := []uint64{0, 1, 0, 0}
+	if len(result.Batches) != len(wantMessages) {
+		t.Fatalf("got %d batches, want %d: %+v", len(result.Batches), len(wantMessages), result.Batches)
+	}
+	for i, batch := range result.Batches {
+		if batch.MessageCount != wantMessages[i] || batch.DelayedMessages != wantDelayed[i] {
+			t.Errorf("batch %d has %d messages and %d delayed messages, want %d and %d", i, batch.MessageCount, batch.DelayedMessages, wantMessages[i], wantDelayed[i])
+		}
+		if batch.Blobs != 1 || batch.BlobGas != params.BlobTxBlobGasPerBlob {
+			t.Errorf("batch %d uses %d blobs and %d blob gas, want one blob", i, batch.Blobs, batch.BlobGas)
+		}
+		if batch.Gas != params.TxGas+sequencerBatchCallGas {
+			t.Errorf("blob batch %d gas %d, want the fixed gas of a batch posting call", i, batch.Gas)
+		}
+	}
+}
+
+func TestBatchSegmentsZstd(t *testing.T) {
+	messages := simulationMessages(5, 100)
+	for _, msg := range messages {
+		msg.Message.L2msg = append(msg.Message.L2msg, make([]byte, 1000)...)
+	}
+	compress := func(useZstd bool) []byte {
+		t.Helper()
+		segments, err := newBatchSegmentsWithSizeLimit(TestBatchPosterConfig.CompressionLevels, useZstd, 0, 100000, 0, params.DefaultMaxUncompressedBatchSize)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i, msg := range messages {
+			success, err := segments.AddMessage(msg)
+			if err != nil || !success {
+				t.Fatalf("adding message %d: success %v err %v", i, success, err)
+			}
+		}
+		data, err := segments.CloseAndGetBytes()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return data
+	}
+	brotliBatch := compress(false)
+	zstdBatch := compress(true)
+	if brotliBatch[0] != daprovider.BrotliMessageHeaderByte || zstdBatch[0] != daprovider.ZstdMessageHeaderByte {
+		t.Fatalf("got header bytes 0x%02x and 0x%02x", brotliBatch[0], zstdBatch[0])
+	}
+	parse := func(batch []byte) [][]byte {
+		t.Helper()
+		msg, err := arbstate.ParseSequencerMessage(context.Background(), 0, common.Hash{}, append(make([]byte, 40), batch...), nil, daprovider.KeysetValidate, arbstate.FixedArbOSVersion(daprovider.ZstdMessageArbOSVersion), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return msg.Segments
+	}
+	brotliSegments := parse(brotliBatch)
+	zstdSegments := parse(zstdBatch)
+	if len(zstdSegments) == 0 || !reflect.DeepEqual(brotliSegments, zstdSegments) {
+		t.Fatalf("zstd batch has %d segments, brotli batch %d, or they differ", len(zstdSegments), len(brotliSegments))
+	}
+}
@@ -0,0 +1,355 @@
+// Copyright 2026, Offchain Labs, Inc.
+// For license information, see https://github.com/OffchainLabs/nitro/blob/master/LICENSE.md
+
+package dataposter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/params"
+
+	"github.com/offchainlabs/nitro/arbnode/dataposter/state"
+	"github.com/offchainlabs/nitro/arbnode/dataposter/storage"
+)
+
+// AdminNamespace is the RPC namespace of the AdminAPI, which should not be
+// exposed publicly.
+const AdminNamespace = "dataposter"
+
+// defaultQueueContentsLimit is the number of queued transactions listed by
+// the AdminAPI unless specified otherwise.
+const defaultQueueContentsLimit = 1000
+
+const (
+	// QueuedTxPending means that the nonce of the transaction wasn't used on
+	// chain yet.
+	QueuedTxPending = "pending"
+	// QueuedTxSucceeded means that the transaction, or one of the versions it
+	// replaced, was included on chain and succeeded.
+	QueuedTxSucceeded = "succeeded"
+	// QueuedTxFailed means that the transaction, or one of the versions it
+	// replaced, was included on chain and reverted.
+	QueuedTxFailed = "failed"
+	// QueuedTxNonceUsed means that the nonce of the transaction was used on
+	// chain by a version of the transaction which isn't known anymore, for
+	// instance one replaced before the node restarted.
+	QueuedTxNonceUsed = "nonce-used"
+)
+
+type ReplacementInfo struct {
+	Hash          common.Hash  `json:"hash"`
+	GasFeeCap     *hexutil.Big `json:"gasFeeCap"`
+	GasTipCap     *hexutil.Big `json:"gasTipCap"`
+	BlobGasFeeCap *hexutil.Big `json:"blobGasFeeCap,omitempty"`
+	ReplacedAt    time.Time    `json:"replacedAt"`
+}
+
+// QueuedTransactionInfo describes a transaction of the DataPoster's queue.
+type QueuedTransactionInfo struct {
+	Nonce            uint64       `json:"nonce"`
+	Hash             common.Hash  `json:"hash"`
+	Type             uint8        `json:"type"`
+	Gas              uint64       `json:"gas"`
+	GasFeeCap        *hexutil.Big `json:"gasFeeCap"`
+	GasTipCap        *hexutil.Big `json:"gasTipCap"`
+	BlobGasFeeCap    *hexutil.Big `json:"blobGasFeeCap,omitempty"`
+	NumBlobs         int          `json:"numBlobs,omitempty"`
+	Sent             bool         `json:"sent"`
+	Created          time.Time    `json:"created"`
+	NextReplacement  time.Time    `json:"nextReplacement"`
+	CumulativeWeight uint64       `json:"cumulativeWeight"`
+	// Replacements are the previous versions of the transaction replaced
+	// since the node started, oldest first.
+	Replacements []ReplacementInfo `json:"replacements,omitempty"`
+	Status       string            `json:"status"`
+	// IncludedHash and BlockNumber identify the version of the transaction
+	// included on chain, if known.
+	IncludedHash *common.Hash `json:"includedHash,omitempty"`
+	BlockNumber  *uint64      `json:"blockNumber,omitempty"`
+}
+
+// QueueContents lists at most maxResults transactions of the queue, with the
+// status of their nonce on chain.
+func (p *DataPoster) QueueContents(ctx context.Context, maxResults uint64) ([]QueuedTransactionInfo, error) {
+	lockedState := p.internalState.Lock()
+	contents, err := lockedState.Queue.FetchContents(ctx, 0, maxResults)
+	replacements := make(map[uint64][]ReplacementInfo)
+	for _, tx := range contents {
+		for _, replacement := range lockedState.Replacements[tx.FullTx.Nonce()] {
+			replacements[tx.FullTx.Nonce()] = append(replacements[tx.FullTx.Nonce()], ReplacementInfo{
+				Hash:          replacement.Hash,
+				GasFeeCap:     (*hexutil.Big)(replacement.GasFeeCap),
+				GasTipCap:     (*hexutil.Big)(replacement.GasTipCap),
+				BlobGasFeeCap: (*hexutil.Big)(replacement.BlobGasFeeCap),
+				ReplacedAt:    replacement.ReplacedAt,
+			})
+		}
+	}
+	p.internalState.Unlock()
+	if err != nil {
+		return nil, fmt.Errorf("fetching queue contents: %w", err)
+	}
+	unconfirmedNonce, err := p.client.NonceAt(ctx, p.Sender(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("getting nonce of the data poster sender: %w", err)
+	}
+
+	infos := make([]QueuedTransactionInfo, 0, len(contents))
+	for _, queued := range contents {
+		tx := queued.FullTx
+		info := QueuedTransactionInfo{
+			Nonce:            tx.Nonce(),
+			Hash:             tx.Hash(),
+			Type:             tx.Type(),
+			Gas:              tx.Gas(),
+			GasFeeCap:        (*hexutil.Big)(tx.GasFeeCap()),
+			GasTipCap:        (*hexutil.Big)(tx.GasTipCap()),
+			BlobGasFeeCap:    (*hexutil.Big)(tx.BlobGasFeeCap()),
+			NumBlobs:         len(tx.BlobHashes()),
+			Sent:             queued.Sent,
+			Created:          queued.Created,
+			NextReplacement:  queued.NextReplacement,
+			CumulativeWeight: queued.CumulativeWeight(),
+			Replacements:     replacements[tx.Nonce()],
+			Status:           QueuedTxPending,
+		}
+		if tx.Nonce() < unconfirmedNonce {
+			info.Status = QueuedTxNonceUsed
+			// The most recent version is the most likely to be included
+			hashes := []common.Hash{tx.Hash()}
+			for _, replacement := range slices.Backward(info.Replacements) {
+				hashes = append(hashes, replacement.Hash)
+			}
+			for _, hash := range hashes {
+				receipt, err := p.client.TransactionReceipt(ctx, hash)
+				if errors.Is(err, ethereum.NotFound) {
+					continue
+				}
+				if err != nil {
+					return nil, fmt.Errorf("getting receipt of transaction %v: %w", hash, err)
+				}
+				info.Status = QueuedTxFailed
+				if receipt.Status == types.ReceiptStatusSuccessful {
+					info.Status = QueuedTxSucceeded
+				}
+				blockNumber := receipt.BlockNumber.Uint64()
+				info.IncludedHash = &receipt.TxHash
+				info.BlockNumber = &blockNumber
+				break
+			}
+		}
+		infos = append(infos, info)
+	}
+	return infos, nil
+}
+
+func getQueuedTransaction(ctx context.Context, queue state.QueueStorage, nonce uint64) (*storage.QueuedTransaction, error) {
+	queued, err := queue.Get(ctx, nonce)
+	if err != nil {
+		return nil, err
+	}
+	if queued == nil {
+		return nil, fmt.Errorf("no queued transaction with nonce %d", nonce)
+	}
+	return queued, nil
+}
+
+// BumpFees immediately replaces by fee the queued transaction with the nonce,
+// raising its fee caps by at least the minimum replacement increase.
+func (p *DataPoster) BumpFees(ctx context.Context, nonce uint64) (*types.Transaction, error) {
+	lockedState := p.internalState.Lock()
+	defer p.internalState.Unlock()
+	prevTx, err := getQueuedTransaction(ctx, lockedState.Queue, nonce)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.updateBalance(ctx, lockedState); err != nil {
+		return nil, fmt.Errorf("failed to update data poster balance: %w", err)
+	}
+	if err := p.replaceTx(ctx, lockedState, prevTx, 0, true); err != nil {
+		return nil, err
+	}
+	newTx, err := getQueuedTransaction(ctx, lockedState.Queue, nonce)
+	if err != nil {
+		return nil, err
+	}
+	log.Warn("DataPoster fees bumped by admin", "nonce", nonce, "prevHash", prevTx.FullTx.Hash(), "hash", newTx.FullTx.Hash())
+	return newTx.FullTx, nil
+}
+
+// CancelNonce replaces the queued transaction with the nonce by a self
+// transfer of no value. The metadata of the queued transaction is kept, so
+// cancelling a transaction which later transactions depend on, such as a
+// batch, makes them fail.
+func (p *DataPoster) CancelNonce(ctx context.Context, nonce uint64) (*types.Transaction, error) {
+	lockedState := p.internalState.Lock()
+	defer p.internalState.Unlock()
+	prevTx, err := getQueuedTransaction(ctx, lockedState.Queue, nonce)
+	if err != nil {
+		return nil, err
+	}
+	if len(prevTx.FullTx.BlobHashes()) > 0 {
+		// Parent chain mempools don't allow replacing a blob transaction by a regular one
+		return nil, errors.New("cancelling blob transactions isn't supported, bump their fees instead")
+	}
+	if err := p.updateBalance(ctx, lockedState); err != nil {
+		return nil, fmt.Errorf("failed to update data poster balance: %w", err)
+	}
+	latestHeader, err := p.headerReader.LastHeader(ctx)
+	if err != nil {
+		return nil, err
+	}
+	feeCap, tipCap, _, err := p.feeAndTipCaps(ctx, lockedState, nonce, params.TxGas, 0, prevTx.FullTx, prevTx.Created, 0, latestHeader)
+	if err != nil {
+		return nil, err
+	}
+	feeCap, tipCap, _ = minReplacementFeeCaps(prevTx.FullTx, minNonBlobRbfIncrease, feeCap, tipCap, nil)
+
+	sender := p.Sender()
+	data := types.DynamicFeeTx{
+		ChainID:   p.parentChain.ChainID,
+		Nonce:     nonce,
+		GasTipCap: tipCap,
+		GasFeeCap: feeCap,
+		Gas:       params.TxGas,
+		To:        &sender,
+		Value:     common.Big0,
+	}
+	fullTx, err := p.signer(ctx, sender, types.NewTx(&data))
+	if err != nil {
+		return nil, fmt.Errorf("signing transaction: %w", err)
+	}
+	recordReplacement(lockedState, prevTx.FullTx)
+	newTx := *prevTx
+	newTx.FullTx = fullTx
+	newTx.DeprecatedData = data
+	newTx.Sent = false
+	log.Warn("DataPoster nonce cancelled by admin", "nonce", nonce, "prevHash", prevTx.FullTx.Hash(), "hash", fullTx.Hash())
+	return fullTx, p.sendTx(ctx, lockedState, prevTx, &newTx)
+}
+
+// DropConfirmed removes the queued transactions whose nonce was already used
+// on chain, except for the most recent one which is kept as a reference for
+// the next nonce. Unlike the regular pruning, it doesn't wait for finality.
+func (p *DataPoster) DropConfirmed(ctx context.Context) (int, error) {
+	lockedState := p.internalState.Lock()
+	defer p.internalState.Unlock()
+	unconfirmedNonce, err := p.client.NonceAt(ctx, p.Sender(), nil)
+	if err != nil {
+		return 0, fmt.Errorf("getting nonce of the data poster sender: %w", err)
+	}
+	if unconfirmedNonce == 0 {
+		return 0, nil
+	}
+	lengthBefore, err := lockedState.Queue.Length(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if err := lockedState.Queue.Prune(ctx, unconfirmedNonce-1); err != nil {
+		return 0, err
+	}
+	lengthAfter, err := lockedState.Queue.Length(ctx)
+	if err != nil {
+		return 0, err
+	}
+	for nonce := range lockedState.Replacements {
+		if nonce < unconfirmedNonce-1 {
+			delete(lockedState.Replacements, nonce)
+		}
+	}
+	for nonce := range lockedState.ErrorCount {
+		if nonce < unconfirmedNonce-1 {
+			delete(lockedState.ErrorCount, nonce)
+		}
+	}
+	dropped := lengthBefore - lengthAfter
+	log.Warn("DataPoster confirmed transactions dropped by admin", "dropped", dropped, "unconfirmedNonce", unconfirmedNonce)
+	return dropped, nil
+}
+
+// AdminAPI inspects the queues of the node's DataPosters, and allows manual
+// intervention on stuck transactions.
+type AdminAPI struct {
+	posters map[string]*DataPoster
+}
+
+// NewAdminAPI creates an AdminAPI for the DataPosters by name, such as
+// "batch-poster" and "staker".
+func NewAdminAPI(posters map[string]*DataPoster) *AdminAPI {
+	return &AdminAPI{posters: posters}
+}
+
+func (a *AdminAPI) poster(name string) (*DataPoster, error) {
+	poster, ok := a.posters[name]
+	if !ok {
+		names := make([]string, 0, len(a.posters))
+		for name := range a.posters {
+			names = append(names, name)
+		}
+		slices.Sort(names)
+		return nil, fmt.Errorf("unknown data poster %q, available data posters: %v", name, names)
+	}
+	return poster, nil
+}
+
+// Posters returns the sender of each DataPoster by name.
+func (a *AdminAPI) Posters(ctx context.Context) map[string]common.Address {
+	senders := make(map[string]common.Address, len(a.posters))
+	for name, poster := range a.posters {
+		senders[name] = poster.Sender()
+	}
+	return senders
+}
+
+func (a *AdminAPI) Queue(ctx context.Context, poster string, maxResults *uint64) ([]QueuedTransactionInfo, error) {
+	p, err := a.poster(poster)
+	if err != nil {
+		return nil, err
+	}
+	limit := uint64(defaultQueueContentsLimit)
+	if maxResults != nil {
+		limit = *maxResults
+	}
+	return p.QueueContents(ctx, limit)
+}
+
+func (a *AdminAPI) BumpFees(ctx context.Context, poster string, nonce uint64) (common.Hash, error) {
+	p, err := a.poster(poster)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	tx, err := p.BumpFees(ctx, nonce)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return tx.Hash(), nil
+}
+
+func (a *AdminAPI) CancelNonce(ctx context.Context, poster string, nonce uint64) (common.Hash, error) {
+	p, err := a.poster(poster)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	tx, err := p.CancelNonce(ctx, nonce)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return tx.Hash(), nil
+}
+
+func (a *AdminAPI) DropConfirmed(ctx context.Context, poster string) (int, error) {
+	p, err := a.poster(poster)
+	if err != nil {
+		return 0, err
+	}
+	return p.DropConfirmed(ctx)
+}
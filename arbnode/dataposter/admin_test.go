@@ -0,0 +1,95 @@
+// Copyright 2026, Offchain Labs, Inc.
+// For license information, see https://github.com/OffchainLabs/nitro/blob/master/LICENSE.md
+
+package dataposter
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+
+	"github.com/offchainlabs/nitro/arbnode/dataposter/state"
+	"github.com/offchainlabs/nitro/arbnode/dataposter/storage"
+)
+
+func TestAdminQueueContentsAndDropConfirmed(t *testing.T) {
+	ctx := context.Background()
+	queue := newSliceStorage(func() storage.EncoderDecoderInterface { return &storage.EncoderDecoder{} })
+	for i := 3; i <= 6; i++ {
+		// #nosec G115
+		if err := queue.Put(ctx, uint64(i), nil, valueOf(t, i)); err != nil {
+			t.Fatalf("Put(%d) unexpected error: %v", i, err)
+		}
+	}
+	p := &DataPoster{
+		internalState: state.NewInternalState(queue),
+		client: ethclient.NewClient(&stubL1ClientInner{
+			senderNonce: 5,
+		}),
+		auth: &bind.TransactOpts{
+			From: common.Address{},
+		},
+	}
+	replacedAt := time.Now()
+	lockedState := p.internalState.Lock()
+	lockedState.Replacements[6] = []state.Replacement{{
+		Hash:       common.HexToHash("0x06"),
+		GasFeeCap:  big.NewInt(1),
+		GasTipCap:  big.NewInt(1),
+		ReplacedAt: replacedAt,
+	}}
+	lockedState.ErrorCount[3] = 1
+	p.internalState.Unlock()
+
+	api := NewAdminAPI(map[string]*DataPoster{"batch-poster": p})
+	if _, err := api.Queue(ctx, "staker", nil); err == nil {
+		t.Fatal("Queue() of an unknown data poster succeeded")
+	}
+	infos, err := api.Queue(ctx, "batch-poster", nil)
+	if err != nil {
+		t.Fatalf("Queue() unexpected error: %v", err)
+	}
+	if len(infos) != 4 {
+		t.Fatalf("got %d queued transactions, want 4: %+v", len(infos), infos)
+	}
+	// The stub parent chain client has no receipts
+	wantStatuses := []string{QueuedTxNonceUsed, QueuedTxNonceUsed, QueuedTxPending, QueuedTxPending}
+	for i, info := range infos {
+		// #nosec G115
+		if info.Nonce != uint64(i+3) || info.Status != wantStatuses[i] {
+			t.Errorf("queued transaction %d has nonce %d status %q, want nonce %d status %q", i, info.Nonce, info.Status, i+3, wantStatuses[i])
+		}
+	}
+	if len(infos[3].Replacements) != 1 || infos[3].Replacements[0].Hash != common.HexToHash("0x06") {
+		t.Errorf("unexpected replacements of nonce 6: %+v", infos[3].Replacements)
+	}
+
+	dropped, err := api.DropConfirmed(ctx, "batch-poster")
+	if err != nil {
+		t.Fatalf("DropConfirmed() unexpected error: %v", err)
+	}
+	// The transaction with the last used nonce is kept
+	if dropped != 1 {
+		t.Errorf("DropConfirmed() dropped %d transactions, want 1", dropped)
+	}
+	length, err := queue.Length(ctx)
+	if err != nil {
+		t.Fatalf("Length() unexpected error: %v", err)
+	}
+	if length != 3 {
+		t.Errorf("got queue length %d after dropping confirmed transactions, want 3", length)
+	}
+	lockedState = p.internalState.Lock()
+	defer p.internalState.Unlock()
+	if _, ok := lockedState.ErrorCount[3]; ok {
+		t.Error("error count of a dropped transaction was kept")
+	}
+	if len(lockedState.Replacements[6]) != 1 {
+		t.Error("replacements of a pending transaction were dropped")
+	}
+}
@@ -87,6 +87,8 @@ type DataPoster struct {
 	internalState *state.InternalState
 
 	maxFeeCapExpression *govaluate.EvaluableExpression
+	feeStrategy         FeeStrategy
+	feeHistoryStrategy  *FeeHistoryFeeStrategy
 }
 
 // signerFn is a signer function callback when a contract requires a method to
@@ -104,6 +106,10 @@ type DataPosterOpts struct {
 	ExtraBacklog      func() uint64
 	RedisKey          string // Redis storage key
 	ParentChain       *parent.ParentChain
+	// FeeStrategy overrides the fee-strategy config if set.
+	FeeStrategy FeeStrategy
+	// ExternalSigner overrides the external-signer config if set.
+	ExternalSigner *ExternalSignerCfg
 }
 
 func NewDataPoster(ctx context.Context, opts *DataPosterOpts) (*DataPoster, error) {
@@ -161,7 +167,9 @@ func NewDataPoster(ctx context.Context, opts *DataPosterOpts) (*DataPoster, erro
 		maxFeeCapExpression: expression,
 		extraBacklog:        opts.ExtraBacklog,
 		parentChain:         opts.ParentChain,
+		feeStrategy:         opts.FeeStrategy,
 	}
+	dp.feeHistoryStrategy = NewFeeHistoryFeeStrategy(dp.client, func() *FeeHistoryStrategyConfig { return &opts.Config().FeeHistory })
 	var overflow bool
 	dp.parentChainID256, overflow = uint256.FromBig(opts.ParentChain.ChainID)
 	if overflow {
@@ -170,8 +178,12 @@ func NewDataPoster(ctx context.Context, opts *DataPosterOpts) (*DataPoster, erro
 	if dp.extraBacklog == nil {
 		dp.extraBacklog = func() uint64 { return 0 }
 	}
-	if cfg.ExternalSigner.URL != "" {
-		signer, sender, err := externalSigner(ctx, &cfg.ExternalSigner)
+	externalSignerCfg := &cfg.ExternalSigner
+	if opts.ExternalSigner != nil {
+		externalSignerCfg = opts.ExternalSigner
+	}
+	if externalSignerCfg.URL != "" {
+		signer, sender, err := externalSigner(ctx, externalSignerCfg)
 		if err != nil {
 			return nil, err
 		}
@@ -532,6 +544,25 @@ func (p *DataPoster) evalMaxFeeCapExpr(backlogOfBatches uint64, elapsed time.Dur
 	return resultBig, nil
 }
 
+// currentFeeStrategy returns the FeeStrategy the DataPoster was created with,
+// or otherwise the one selected by the fee-strategy config.
+func (p *DataPoster) currentFeeStrategy() (FeeStrategy, error) {
+	if p.feeStrategy != nil {
+		return p.feeStrategy, nil
+	}
+	switch p.config().FeeStrategy {
+	case "", FeeStrategyFormula:
+		return &formulaFeeStrategy{client: p.client, evalMaxFeeCap: p.evalMaxFeeCapExpr}, nil
+	case FeeStrategyFeeHistory:
+		if p.feeHistoryStrategy == nil {
+			return nil, errors.New("fee history strategy not initialized")
+		}
+		return p.feeHistoryStrategy, nil
+	default:
+		return nil, fmt.Errorf("unknown data poster fee strategy %q", p.config().FeeStrategy)
+	}
+}
+
 var big4 = big.NewInt(4)
 
 // The dataPosterBacklog argument should *not* include extraBacklog (it's added in this function)
@@ -566,10 +597,26 @@ func (p *DataPoster) feeAndTipCaps(ctx context.Context, s *state.LockedInternalS
 	// #nosec G115
 	latestSoftConfirmedNonceGauge.Update(int64(softConfNonce))
 
-	suggestedTip, err := p.client.SuggestGasTipCap(ctx)
+	strategy, err := p.currentFeeStrategy()
 	if err != nil {
 		return nil, nil, nil, err
 	}
+	elapsed := time.Since(dataCreatedAt)
+	bid, err := strategy.Bid(ctx, &FeeBidRequest{
+		Config:         config,
+		LatestHeader:   latestHeader,
+		CurrentBlobFee: currentBlobFee,
+		Nonce:          nonce,
+		GasLimit:       gasLimit,
+		NumBlobs:       numBlobs,
+		Backlog:        dataPosterBacklog,
+		Elapsed:        elapsed,
+		LastTx:         lastTx,
+	})
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	suggestedTip := bid.TipCap
 	minTipCapGwei, maxTipCapGwei, minRbfIncrease := config.MinTipCapGwei, config.MaxTipCapGwei, minNonBlobRbfIncrease
 	if numBlobs > 0 {
 		minTipCapGwei, maxTipCapGwei, minRbfIncrease = config.MinBlobTxTipCapGwei, config.MaxBlobTxTipCapGwei, minBlobRbfIncrease
@@ -578,15 +625,9 @@ func (p *DataPoster) feeAndTipCaps(ctx context.Context, s *state.LockedInternalS
 	newTipCap = arbmath.BigMax(newTipCap, floatmath.FloatToBig(minTipCapGwei*params.GWei))
 	newTipCap = arbmath.BigMin(newTipCap, floatmath.FloatToBig(maxTipCapGwei*params.GWei))
 
-	// Compute the max fee with normalized gas so that blob txs aren't priced differently.
+	// The strategy bids a max fee with normalized gas so that blob txs aren't priced differently.
 	// Later, split the total cost bid into blob and non-blob fee caps.
-	elapsed := time.Since(dataCreatedAt)
-	maxNormalizedFeeCap, err := p.evalMaxFeeCapExpr(dataPosterBacklog, elapsed)
-	if err != nil {
-		return nil, nil, nil, err
-	}
-	normalizedGas := gasLimit + numBlobs*blobs.BlobEncodableData*params.TxDataNonZeroGasEIP2028
-	targetMaxCost := arbmath.BigMulByUint(maxNormalizedFeeCap, normalizedGas)
+	targetMaxCost := arbmath.BigMulByUint(bid.MaxNormalizedFeeCap, normalizedGas(gasLimit, numBlobs))
 
 	maxMempoolWeight := arbmath.MinInt(config.MaxMempoolWeight, config.MaxMempoolTransactions)
 
@@ -1016,7 +1057,32 @@ func updateGasCaps(tx *types.Transaction, newFeeCap, newTipCap, newBlobFeeCap *b
 	return types.NewTx(data), nil
 }
 
-func (p *DataPoster) replaceTx(ctx context.Context, s *state.LockedInternalState, prevTx *storage.QueuedTransaction, backlogWeight uint64) error {
+// minReplacementFeeCaps raises the fee caps to at least the minimum increase
+// over the fee caps of the transaction being replaced.
+func minReplacementFeeCaps(prevTx *types.Transaction, minRbfIncrease arbmath.Bips, feeCap, tipCap, blobFeeCap *big.Int) (*big.Int, *big.Int, *big.Int) {
+	feeCap = arbmath.BigMax(feeCap, arbmath.BigMulByBips(prevTx.GasFeeCap(), minRbfIncrease))
+	tipCap = arbmath.BigMax(tipCap, arbmath.BigMulByBips(prevTx.GasTipCap(), minRbfIncrease))
+	if prevTx.BlobGasFeeCap() != nil {
+		blobFeeCap = arbmath.BigMax(blobFeeCap, arbmath.BigMulByBips(prevTx.BlobGasFeeCap(), minRbfIncrease))
+	}
+	return feeCap, tipCap, blobFeeCap
+}
+
+// recordReplacement remembers a version of a queued transaction which is
+// being replaced, to be reported by QueueContents.
+func recordReplacement(s *state.LockedInternalState, tx *types.Transaction) {
+	s.Replacements[tx.Nonce()] = append(s.Replacements[tx.Nonce()], state.Replacement{
+		Hash:          tx.Hash(),
+		GasFeeCap:     tx.GasFeeCap(),
+		GasTipCap:     tx.GasTipCap(),
+		BlobGasFeeCap: tx.BlobGasFeeCap(),
+		ReplacedAt:    time.Now(),
+	})
+}
+
+// replaceTx replaces by fee the transaction if the recommended fee caps have
+// increased enough, or in any case if force is set.
+func (p *DataPoster) replaceTx(ctx context.Context, s *state.LockedInternalState, prevTx *storage.QueuedTransaction, backlogWeight uint64, force bool) error {
 	latestHeader, err := p.headerReader.LastHeader(ctx)
 	if err != nil {
 		return err
@@ -1032,8 +1098,12 @@ func (p *DataPoster) replaceTx(ctx context.Context, s *state.LockedInternalState
 		minRbfIncrease = minBlobRbfIncrease
 	}
 
+	if force {
+		newFeeCap, newTipCap, newBlobFeeCap = minReplacementFeeCaps(prevTx.FullTx, minRbfIncrease, newFeeCap, newTipCap, newBlobFeeCap)
+	}
+
 	newTx := *prevTx
-	if (prevTx.FullTx.GasFeeCap().Sign() > 0 && arbmath.BigDivToBips(newFeeCap, prevTx.FullTx.GasFeeCap()) < minRbfIncrease) ||
+	if !force && (prevTx.FullTx.GasFeeCap().Sign() > 0 && arbmath.BigDivToBips(newFeeCap, prevTx.FullTx.GasFeeCap()) < minRbfIncrease) ||
 		(prevTx.FullTx.BlobGasFeeCap() != nil && prevTx.FullTx.BlobGasFeeCap().Sign() > 0 && arbmath.BigDivToBips(newBlobFeeCap, prevTx.FullTx.BlobGasFeeCap()) < minRbfIncrease) {
 		log.Debug(
 			"no need to replace by fee transaction",
@@ -1073,6 +1143,7 @@ func (p *DataPoster) replaceTx(ctx context.Context, s *state.LockedInternalState
 	if err != nil {
 		return err
 	}
+	recordReplacement(s, prevTx.FullTx)
 
 	return p.sendTx(ctx, s, prevTx, &newTx)
 }
@@ -1115,6 +1186,11 @@ func (p *DataPoster) updateNonce(ctx context.Context, s *state.LockedInternalSta
 			delete(s.ErrorCount, x)
 		}
 	}
+	if len(s.Replacements) > 0 {
+		for x := s.Nonce; x < nonce; x++ {
+			delete(s.Replacements, x)
+		}
+	}
 	// We don't prune the most recent transaction in order to ensure that the data poster
 	// always has a reference point in its queue of the latest transaction nonce and metadata.
 	// nonce > 0 is implied by nonce > p.nonce, so this won't underflow.
@@ -1236,7 +1312,7 @@ func (p *DataPoster) Start(ctxIn context.Context) {
 			if now.After(tx.NextReplacement) {
 				weightBacklog := arbmath.SaturatingUSub(latestCumulativeWeight, tx.CumulativeWeight())
 				nonceBacklog := arbmath.SaturatingUSub(latestNonce, tx.FullTx.Nonce())
-				err := p.replaceTx(ctx, lockedState, tx, arbmath.MaxInt(nonceBacklog, weightBacklog))
+				err := p.replaceTx(ctx, lockedState, tx, arbmath.MaxInt(nonceBacklog, weightBacklog), false)
 				p.maybeLogError(err, lockedState, tx, "failed to replace-by-fee transaction")
 			} else {
 				err := p.sendTx(ctx, lockedState, tx, tx)
@@ -1275,33 +1351,47 @@ type DataPosterConfig struct {
 	BlobTxReplacementTimes []time.Duration            `koanf:"blob-tx-replacement-times"`
 	// This is forcibly disabled if the parent chain is an Arbitrum chain,
 	// so you should probably use DataPoster's waitForL1Finality method instead of reading this field directly.
-	WaitForL1Finality      bool              `koanf:"wait-for-l1-finality" reload:"hot"`
-	MaxMempoolTransactions uint64            `koanf:"max-mempool-transactions" reload:"hot"`
-	MaxMempoolWeight       uint64            `koanf:"max-mempool-weight" reload:"hot"`
-	MaxQueuedTransactions  int               `koanf:"max-queued-transactions" reload:"hot"`
-	TargetPriceGwei        float64           `koanf:"target-price-gwei" reload:"hot"`
-	UrgencyGwei            float64           `koanf:"urgency-gwei" reload:"hot"`
-	MinTipCapGwei          float64           `koanf:"min-tip-cap-gwei" reload:"hot"`
-	MinBlobTxTipCapGwei    float64           `koanf:"min-blob-tx-tip-cap-gwei" reload:"hot"`
-	MaxTipCapGwei          float64           `koanf:"max-tip-cap-gwei" reload:"hot"`
-	MaxBlobTxTipCapGwei    float64           `koanf:"max-blob-tx-tip-cap-gwei" reload:"hot"`
-	MaxFeeBidMultipleBips  arbmath.UBips     `koanf:"max-fee-bid-multiple-bips" reload:"hot"`
-	NonceRbfSoftConfs      uint64            `koanf:"nonce-rbf-soft-confs" reload:"hot"`
-	Post4844Blobs          bool              `koanf:"post-4844-blobs" reload:"hot"`
-	AllocateMempoolBalance bool              `koanf:"allocate-mempool-balance" reload:"hot"`
-	UseDBStorage           bool              `koanf:"use-db-storage"`
-	UseNoOpStorage         bool              `koanf:"use-noop-storage"`
-	LegacyStorageEncoding  bool              `koanf:"legacy-storage-encoding" reload:"hot"`
-	Dangerous              DangerousConfig   `koanf:"dangerous"`
-	ExternalSigner         ExternalSignerCfg `koanf:"external-signer"`
-	MaxFeeCapFormula       string            `koanf:"max-fee-cap-formula" reload:"hot"`
-	ElapsedTimeBase        time.Duration     `koanf:"elapsed-time-base" reload:"hot"`
-	ElapsedTimeImportance  float64           `koanf:"elapsed-time-importance" reload:"hot"`
+	WaitForL1Finality      bool                     `koanf:"wait-for-l1-finality" reload:"hot"`
+	MaxMempoolTransactions uint64                   `koanf:"max-mempool-transactions" reload:"hot"`
+	MaxMempoolWeight       uint64                   `koanf:"max-mempool-weight" reload:"hot"`
+	MaxQueuedTransactions  int                      `koanf:"max-queued-transactions" reload:"hot"`
+	TargetPriceGwei        float64                  `koanf:"target-price-gwei" reload:"hot"`
+	UrgencyGwei            float64                  `koanf:"urgency-gwei" reload:"hot"`
+	MinTipCapGwei          float64                  `koanf:"min-tip-cap-gwei" reload:"hot"`
+	MinBlobTxTipCapGwei    float64                  `koanf:"min-blob-tx-tip-cap-gwei" reload:"hot"`
+	MaxTipCapGwei          float64                  `koanf:"max-tip-cap-gwei" reload:"hot"`
+	MaxBlobTxTipCapGwei    float64                  `koanf:"max-blob-tx-tip-cap-gwei" reload:"hot"`
+	MaxFeeBidMultipleBips  arbmath.UBips            `koanf:"max-fee-bid-multiple-bips" reload:"hot"`
+	NonceRbfSoftConfs      uint64                   `koanf:"nonce-rbf-soft-confs" reload:"hot"`
+	Post4844Blobs          bool                     `koanf:"post-4844-blobs" reload:"hot"`
+	AllocateMempoolBalance bool                     `koanf:"allocate-mempool-balance" reload:"hot"`
+	UseDBStorage           bool                     `koanf:"use-db-storage"`
+	UseNoOpStorage         bool                     `koanf:"use-noop-storage"`
+	LegacyStorageEncoding  bool                     `koanf:"legacy-storage-encoding" reload:"hot"`
+	Dangerous              DangerousConfig          `koanf:"dangerous"`
+	ExternalSigner         ExternalSignerCfg        `koanf:"external-signer"`
+	PoolSigners            PoolSignersConfig        `koanf:"pool-signers"`
+	MaxFeeCapFormula       string                   `koanf:"max-fee-cap-formula" reload:"hot"`
+	ElapsedTimeBase        time.Duration            `koanf:"elapsed-time-base" reload:"hot"`
+	ElapsedTimeImportance  float64                  `koanf:"elapsed-time-importance" reload:"hot"`
+	FeeStrategy            string                   `koanf:"fee-strategy" reload:"hot"`
+	FeeHistory             FeeHistoryStrategyConfig `koanf:"fee-history" reload:"hot"`
 	// When set, dataposter will not post new batches, but will keep running to
 	// get existing batches confirmed.
 	DisableNewTx bool `koanf:"disable-new-tx" reload:"hot"`
 }
 
+// PoolSignersConfig are the signers of a DataPosterPool, posting the
+// transactions which don't need to come from the data poster's sender.
+type PoolSignersConfig struct {
+	PrivateKeys             []string `koanf:"private-keys"`
+	ExternalSignerAddresses []string `koanf:"external-signer-addresses"`
+}
+
+func (c *PoolSignersConfig) Enabled() bool {
+	return len(c.PrivateKeys)+len(c.ExternalSignerAddresses) > 0
+}
+
 type ExternalSignerCfg struct {
 	// URL of the external signer rpc server, if set this overrides transaction
 	// options and uses external signer
@@ -1352,6 +1442,15 @@ func (c *DataPosterConfig) Validate() error {
 	if c.Post4844Blobs && len(c.BlobTxReplacementTimes) == 0 {
 		return fmt.Errorf("blob-tx-replacement-times must have at least one value when post-4844-blobs is enabled")
 	}
+	switch c.FeeStrategy {
+	case "", FeeStrategyFormula:
+	case FeeStrategyFeeHistory:
+		if err := c.FeeHistory.Validate(); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("invalid fee-strategy %q, expected %q or %q", c.FeeStrategy, FeeStrategyFormula, FeeStrategyFeeHistory)
+	}
 	return nil
 }
 
@@ -1395,10 +1494,13 @@ func DataPosterConfigAddOptions(prefix string, f *pflag.FlagSet, defaultDataPost
 		"Currently available variables to construct the formula are BacklogOfBatches, UrgencyGWei, ElapsedTime, ElapsedTimeBase, ElapsedTimeImportance, and TargetPriceGWei")
 	f.Duration(prefix+".elapsed-time-base", defaultDataPosterConfig.ElapsedTimeBase, "unit to measure the time elapsed since creation of transaction used for maximum fee cap calculation")
 	f.Float64(prefix+".elapsed-time-importance", defaultDataPosterConfig.ElapsedTimeImportance, "weight given to the units of time elapsed used for maximum fee cap calculation")
+	f.String(prefix+".fee-strategy", defaultDataPosterConfig.FeeStrategy, "strategy to bid the fees of transactions with: \""+FeeStrategyFormula+"\" bids the max-fee-cap-formula and the suggested tip, \""+FeeStrategyFeeHistory+"\" bids percentiles of the recent parent chain fees")
+	FeeHistoryStrategyConfigAddOptions(prefix+".fee-history", f, defaultDataPosterConfig.FeeHistory)
 
 	signature.SimpleHmacConfigAddOptions(prefix+".redis-signer", f)
 	addDangerousOptions(prefix+".dangerous", f)
 	addExternalSignerOptions(prefix+".external-signer", f)
+	addPoolSignersOptions(prefix+".pool-signers", f)
 	f.Bool(prefix+".disable-new-tx", defaultDataPosterConfig.DisableNewTx, "disable posting new transactions, data poster will still keep confirming existing batches")
 
 	includeBlobTuning := usageContext != DataPosterUsageStaker
@@ -1426,6 +1528,11 @@ func addExternalSignerOptions(prefix string, f *pflag.FlagSet) {
 	f.Bool(prefix+".insecure-skip-verify", DefaultDataPosterConfig.ExternalSigner.InsecureSkipVerify, "skip TLS certificate verification")
 }
 
+func addPoolSignersOptions(prefix string, f *pflag.FlagSet) {
+	f.StringSlice(prefix+".private-keys", DefaultDataPosterConfig.PoolSigners.PrivateKeys, "hex encoded private keys of a pool of senders for the transactions which don't need to come from the main sender, e.g. challenge timeouts of the staker")
+	f.StringSlice(prefix+".external-signer-addresses", DefaultDataPosterConfig.PoolSigners.ExternalSignerAddresses, "addresses of a pool of senders signing with the external signer, for the transactions which don't need to come from the main sender")
+}
+
 var DefaultDataPosterConfig = DataPosterConfig{
 	ReplacementTimes:       []time.Duration{5 * time.Minute, 10 * time.Minute, 20 * time.Minute, 30 * time.Minute, time.Hour, 2 * time.Hour, 4 * time.Hour, 6 * time.Hour, 8 * time.Hour, 12 * time.Hour, 16 * time.Hour, 18 * time.Hour, 20 * time.Hour, 22 * time.Hour},
 	BlobTxReplacementTimes: []time.Duration{5 * time.Minute, 10 * time.Minute, 30 * time.Minute, time.Hour, 4 * time.Hour, 8 * time.Hour, 16 * time.Hour, 22 * time.Hour},
@@ -1447,9 +1554,12 @@ var DefaultDataPosterConfig = DataPosterConfig{
 	LegacyStorageEncoding:  false,
 	Dangerous:              DangerousConfig{ClearDBStorage: false},
 	ExternalSigner:         ExternalSignerCfg{Method: "eth_signTransaction", InsecureSkipVerify: false},
+	PoolSigners:            PoolSignersConfig{},
 	MaxFeeCapFormula:       "((BacklogOfBatches * UrgencyGWei) ** 2) + ((ElapsedTime/ElapsedTimeBase) ** 2) * ElapsedTimeImportance + TargetPriceGWei",
 	ElapsedTimeBase:        10 * time.Minute,
 	ElapsedTimeImportance:  10,
+	FeeStrategy:            FeeStrategyFormula,
+	FeeHistory:             DefaultFeeHistoryStrategyConfig,
 	DisableNewTx:           false,
 }
 
@@ -1486,9 +1596,12 @@ var TestDataPosterConfig = DataPosterConfig{
 	UseNoOpStorage:         false,
 	LegacyStorageEncoding:  false,
 	ExternalSigner:         ExternalSignerCfg{Method: "eth_signTransaction", InsecureSkipVerify: true},
+	PoolSigners:            PoolSignersConfig{},
 	MaxFeeCapFormula:       "((BacklogOfBatches * UrgencyGWei) ** 2) + ((ElapsedTime/ElapsedTimeBase) ** 2) * ElapsedTimeImportance + TargetPriceGWei",
 	ElapsedTimeBase:        10 * time.Minute,
 	ElapsedTimeImportance:  10,
+	FeeStrategy:            FeeStrategyFormula,
+	FeeHistory:             DefaultFeeHistoryStrategyConfig,
 	DisableNewTx:           false,
 }
 
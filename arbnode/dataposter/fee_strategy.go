@@ -0,0 +1,245 @@
+// Copyright 2026, Offchain Labs, Inc.
+// For license information, see https://github.com/OffchainLabs/nitro/blob/master/LICENSE.md
+
+package dataposter
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/spf13/pflag"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+
+	"github.com/offchainlabs/nitro/util/arbmath"
+	"github.com/offchainlabs/nitro/util/blobs"
+)
+
+const (
+	// FeeStrategyFormula bids using MaxFeeCapFormula and the tip suggested by
+	// the parent chain node.
+	FeeStrategyFormula = "formula"
+	// FeeStrategyFeeHistory bids using percentiles of the recent parent chain
+	// base fees and tips.
+	FeeStrategyFeeHistory = "fee-history"
+)
+
+// FeeBidRequest describes the transaction the DataPoster needs to bid for.
+type FeeBidRequest struct {
+	Config       *DataPosterConfig
+	LatestHeader *types.Header
+	// CurrentBlobFee is the current blob base fee, or zero if the
+	// transaction doesn't carry blobs.
+	CurrentBlobFee *big.Int
+	Nonce          uint64
+	GasLimit       uint64
+	NumBlobs       uint64
+	// Backlog includes the extra backlog reported by the DataPoster's user.
+	Backlog uint64
+	// Elapsed is the time since the data of the transaction was created.
+	Elapsed time.Duration
+	// LastTx is the transaction being replaced, or nil for an initial bid.
+	LastTx *types.Transaction
+}
+
+// FeeBid is the bid of a FeeStrategy. The DataPoster splits it into the fee
+// caps of the transaction, and applies the balance, replace-by-fee and
+// configured fee limits on top of it.
+type FeeBid struct {
+	// MaxNormalizedFeeCap is the maximum fee per normalized gas, where blob gas
+	// is normalized to the calldata gas it would take to post the same data.
+	MaxNormalizedFeeCap *big.Int
+	// TipCap is clamped to the configured minimum and maximum tip caps.
+	TipCap *big.Int
+}
+
+// FeeStrategy decides how much the DataPoster bids for the initial and the
+// replacement transactions it posts.
+type FeeStrategy interface {
+	Bid(ctx context.Context, req *FeeBidRequest) (*FeeBid, error)
+}
+
+// normalizedGas returns the gas of a transaction with its blobs normalized to
+// the calldata gas it would take to post the same data, so that blob
+// transactions aren't priced differently.
+func normalizedGas(gasLimit uint64, numBlobs uint64) uint64 {
+	return gasLimit + numBlobs*blobs.BlobEncodableData*params.TxDataNonZeroGasEIP2028
+}
+
+type tipSuggester interface {
+	SuggestGasTipCap(ctx context.Context) (*big.Int, error)
+}
+
+// formulaFeeStrategy is the default FeeStrategy. It bids the result of
+// MaxFeeCapFormula and the tip suggested by the parent chain node.
+type formulaFeeStrategy struct {
+	client        tipSuggester
+	evalMaxFeeCap func(backlogOfBatches uint64, elapsed time.Duration) (*big.Int, error)
+}
+
+func (s *formulaFeeStrategy) Bid(ctx context.Context, req *FeeBidRequest) (*FeeBid, error) {
+	suggestedTip, err := s.client.SuggestGasTipCap(ctx)
+	if err != nil {
+		return nil, err
+	}
+	maxNormalizedFeeCap, err := s.evalMaxFeeCap(req.Backlog, req.Elapsed)
+	if err != nil {
+		return nil, err
+	}
+	return &FeeBid{MaxNormalizedFeeCap: maxNormalizedFeeCap, TipCap: suggestedTip}, nil
+}
+
+type FeeHistoryStrategyConfig struct {
+	Blocks              uint64        `koanf:"blocks" reload:"hot"`
+	TipPercentile       float64       `koanf:"tip-percentile" reload:"hot"`
+	BaseFeePercentile   float64       `koanf:"base-fee-percentile" reload:"hot"`
+	BaseFeeMultipleBips arbmath.UBips `koanf:"base-fee-multiple-bips" reload:"hot"`
+	EscalationBips      arbmath.UBips `koanf:"escalation-bips" reload:"hot"`
+}
+
+var DefaultFeeHistoryStrategyConfig = FeeHistoryStrategyConfig{
+	Blocks:              20,
+	TipPercentile:       50,
+	BaseFeePercentile:   90,
+	BaseFeeMultipleBips: arbmath.OneInUBips * 3 / 2,
+	EscalationBips:      arbmath.OneInUBips / 4,
+}
+
+func FeeHistoryStrategyConfigAddOptions(prefix string, f *pflag.FlagSet, defaultConfig FeeHistoryStrategyConfig) {
+	f.Uint64(prefix+".blocks", defaultConfig.Blocks, "number of recent parent chain blocks to take the fee history of")
+	f.Float64(prefix+".tip-percentile", defaultConfig.TipPercentile, "percentile of the tips paid in each recent block to bid as tip, the median across the blocks is used")
+	f.Float64(prefix+".base-fee-percentile", defaultConfig.BaseFeePercentile, "percentile of the recent base fees to bid for, if higher than the current base fee")
+	f.Uint64(prefix+".base-fee-multiple-bips", uint64(defaultConfig.BaseFeeMultipleBips), "multiple of the base fee percentile (and of the current blob base fee) to bid as fee cap")
+	f.Uint64(prefix+".escalation-bips", uint64(defaultConfig.EscalationBips), "bips added to the base fee multiple for each elapsed-time-base the data waited to be posted and for each batch of backlog")
+}
+
+func (c *FeeHistoryStrategyConfig) Validate() error {
+	// Parent chain nodes cap the fee history to 1024 blocks
+	if c.Blocks == 0 || c.Blocks > 1024 {
+		return fmt.Errorf("fee-history.blocks must be between 1 and 1024, got %d", c.Blocks)
+	}
+	if c.TipPercentile < 0 || c.TipPercentile > 100 {
+		return fmt.Errorf("fee-history.tip-percentile must be between 0 and 100, got %v", c.TipPercentile)
+	}
+	if c.BaseFeePercentile < 0 || c.BaseFeePercentile > 100 {
+		return fmt.Errorf("fee-history.base-fee-percentile must be between 0 and 100, got %v", c.BaseFeePercentile)
+	}
+	if c.BaseFeeMultipleBips < arbmath.OneInUBips {
+		return fmt.Errorf("fee-history.base-fee-multiple-bips must be at least %d, got %d", arbmath.OneInUBips, c.BaseFeeMultipleBips)
+	}
+	return nil
+}
+
+type feeHistoryReader interface {
+	FeeHistory(ctx context.Context, blockCount uint64, lastBlock *big.Int, rewardPercentiles []float64) (*ethereum.FeeHistory, error)
+}
+
+type feeHistoryPercentiles struct {
+	blockHash common.Hash
+	config    FeeHistoryStrategyConfig
+	tip       *big.Int
+	baseFee   *big.Int
+}
+
+// FeeHistoryFeeStrategy bids a percentile of the recent parent chain base
+// fees and tips, instead of an absolute target price, with a multiple which
+// escalates with the time the data waited and the backlog.
+type FeeHistoryFeeStrategy struct {
+	client feeHistoryReader
+	config func() *FeeHistoryStrategyConfig
+
+	mutex  sync.Mutex
+	cached *feeHistoryPercentiles
+}
+
+func NewFeeHistoryFeeStrategy(client feeHistoryReader, config func() *FeeHistoryStrategyConfig) *FeeHistoryFeeStrategy {
+	return &FeeHistoryFeeStrategy{
+		client: client,
+		config: config,
+	}
+}
+
+// percentile returns the p-th percentile of the values, rounded down to the
+// closest value.
+func percentile(values []*big.Int, p float64) *big.Int {
+	if len(values) == 0 {
+		return nil
+	}
+	sorted := slices.Clone(values)
+	slices.SortFunc(sorted, func(a, b *big.Int) int { return a.Cmp(b) })
+	index := int(p / 100 * float64(len(sorted)-1))
+	return sorted[index]
+}
+
+// percentiles fetches the fee history up to the latest header, reusing the
+// last result while the latest header and the config don't change.
+func (s *FeeHistoryFeeStrategy) percentiles(ctx context.Context, latestHeader *types.Header) (*feeHistoryPercentiles, error) {
+	config := *s.config()
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.cached != nil && s.cached.blockHash == latestHeader.Hash() && s.cached.config == config {
+		return s.cached, nil
+	}
+	history, err := s.client.FeeHistory(ctx, config.Blocks, latestHeader.Number, []float64{config.TipPercentile})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get parent chain fee history: %w", err)
+	}
+	var tips []*big.Int
+	for _, rewards := range history.Reward {
+		// Empty blocks report a zero reward, which says nothing about the tip needed
+		if len(rewards) > 0 && rewards[0] != nil && rewards[0].Sign() > 0 {
+			tips = append(tips, rewards[0])
+		}
+	}
+	var baseFees []*big.Int
+	for _, baseFee := range history.BaseFee {
+		if baseFee != nil {
+			baseFees = append(baseFees, baseFee)
+		}
+	}
+	result := &feeHistoryPercentiles{
+		blockHash: latestHeader.Hash(),
+		config:    config,
+		tip:       percentile(tips, 50),
+		baseFee:   percentile(baseFees, config.BaseFeePercentile),
+	}
+	if result.tip == nil {
+		result.tip = big.NewInt(0)
+	}
+	s.cached = result
+	return result, nil
+}
+
+func (s *FeeHistoryFeeStrategy) Bid(ctx context.Context, req *FeeBidRequest) (*FeeBid, error) {
+	percentiles, err := s.percentiles(ctx, req.LatestHeader)
+	if err != nil {
+		return nil, err
+	}
+	config := &percentiles.config
+	baseFee := req.LatestHeader.BaseFee
+	if percentiles.baseFee != nil {
+		baseFee = arbmath.BigMax(baseFee, percentiles.baseFee)
+	}
+
+	escalations := float64(req.Backlog)
+	if req.Config.ElapsedTimeBase > 0 {
+		escalations += float64(req.Elapsed) / float64(req.Config.ElapsedTimeBase)
+	}
+	multipleBips := arbmath.SaturatingUAdd(config.BaseFeeMultipleBips, arbmath.UBips(float64(config.EscalationBips)*escalations))
+
+	baseFeeCap := arbmath.BigAdd(arbmath.BigMulByUBips(baseFee, multipleBips), percentiles.tip)
+	blobFeeCap := arbmath.BigMulByUBips(req.CurrentBlobFee, multipleBips)
+	maxCost := arbmath.BigMulByUint(baseFeeCap, req.GasLimit)
+	maxCost.Add(maxCost, arbmath.BigMulByUint(blobFeeCap, params.BlobTxBlobGasPerBlob*req.NumBlobs))
+	return &FeeBid{
+		MaxNormalizedFeeCap: arbmath.BigDivByUint(maxCost, normalizedGas(req.GasLimit, req.NumBlobs)),
+		TipCap:              new(big.Int).Set(percentiles.tip),
+	}, nil
+}
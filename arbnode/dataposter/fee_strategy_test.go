@@ -0,0 +1,108 @@
+// Copyright 2026, Offchain Labs, Inc.
+// For license information, see https://github.com/OffchainLabs/nitro/blob/master/LICENSE.md
+
+package dataposter
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+
+	"github.com/offchainlabs/nitro/util/arbmath"
+)
+
+type stubFeeHistoryReader struct {
+	history *ethereum.FeeHistory
+	calls   int
+}
+
+func (r *stubFeeHistoryReader) FeeHistory(ctx context.Context, blockCount uint64, lastBlock *big.Int, rewardPercentiles []float64) (*ethereum.FeeHistory, error) {
+	r.calls++
+	return r.history, nil
+}
+
+func gwei(n int64) *big.Int {
+	return big.NewInt(n * params.GWei)
+}
+
+func TestFeeHistoryFeeStrategy(t *testing.T) {
+	reader := &stubFeeHistoryReader{
+		history: &ethereum.FeeHistory{
+			BaseFee: []*big.Int{gwei(10), gwei(20), gwei(50), gwei(40), gwei(30)},
+			// The empty block's zero reward is ignored
+			Reward: [][]*big.Int{{gwei(1)}, {gwei(0)}, {gwei(3)}, {gwei(2)}},
+		},
+	}
+	strategyConfig := DefaultFeeHistoryStrategyConfig
+	strategyConfig.BaseFeePercentile = 50
+	strategy := NewFeeHistoryFeeStrategy(reader, func() *FeeHistoryStrategyConfig { return &strategyConfig })
+	config := DefaultDataPosterConfig
+	header := &types.Header{Number: big.NewInt(100), BaseFee: gwei(25)}
+	req := &FeeBidRequest{
+		Config:         &config,
+		LatestHeader:   header,
+		CurrentBlobFee: big.NewInt(0),
+		GasLimit:       100_000,
+	}
+
+	bid, err := strategy.Bid(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Median tip of the non-empty blocks
+	if !arbmath.BigEquals(bid.TipCap, gwei(2)) {
+		t.Errorf("got tip cap %v, want %v", bid.TipCap, gwei(2))
+	}
+	// 1.5 times the median base fee of 30 gwei, plus the tip
+	if !arbmath.BigEquals(bid.MaxNormalizedFeeCap, gwei(47)) {
+		t.Errorf("got max normalized fee cap %v, want %v", bid.MaxNormalizedFeeCap, gwei(47))
+	}
+
+	// The multiple escalates by 0.25 per batch of backlog
+	req.Backlog = 2
+	bid, err = strategy.Bid(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !arbmath.BigEquals(bid.MaxNormalizedFeeCap, gwei(62)) {
+		t.Errorf("got escalated max normalized fee cap %v, want %v", bid.MaxNormalizedFeeCap, gwei(62))
+	}
+	if reader.calls != 1 {
+		t.Errorf("fee history fetched %d times for the same block, want 1", reader.calls)
+	}
+
+	// The current base fee is bid for if it's above the recent percentile
+	req.Backlog = 0
+	req.LatestHeader = &types.Header{Number: big.NewInt(101), BaseFee: gwei(100)}
+	bid, err = strategy.Bid(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !arbmath.BigEquals(bid.MaxNormalizedFeeCap, gwei(152)) {
+		t.Errorf("got max normalized fee cap %v, want %v", bid.MaxNormalizedFeeCap, gwei(152))
+	}
+	if reader.calls != 2 {
+		t.Errorf("fee history fetched %d times for two blocks, want 2", reader.calls)
+	}
+}
+
+func TestFeeStrategyConfigValidation(t *testing.T) {
+	config := DefaultDataPosterConfig
+	config.FeeStrategy = FeeStrategyFeeHistory
+	if err := config.Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	config.FeeHistory.TipPercentile = 101
+	if err := config.Validate(); err == nil {
+		t.Error("expected an error for a tip percentile above 100")
+	}
+	config = DefaultDataPosterConfig
+	config.FeeStrategy = "cheapest"
+	if err := config.Validate(); err == nil {
+		t.Error("expected an error for an unknown fee strategy")
+	}
+}
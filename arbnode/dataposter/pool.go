@@ -0,0 +1,216 @@
+// Copyright 2026, Offchain Labs, Inc.
+// For license information, see https://github.com/OffchainLabs/nitro/blob/master/LICENSE.md
+
+package dataposter
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/offchainlabs/nitro/util/arbmath"
+)
+
+type DataPosterPoolOpts struct {
+	// DataPosterOpts are shared by the DataPosters of the pool, except for
+	// Auth and ExternalSigner. Each sender gets its own queue storage under
+	// the Database, which must not be used by another DataPoster, and under
+	// the RedisKey prefixed by the sender.
+	DataPosterOpts
+	// Auths are the local signers of the pool.
+	Auths []*bind.TransactOpts
+	// ExternalSigners are the external signers of the pool.
+	ExternalSigners []ExternalSignerCfg
+}
+
+// DataPosterPool spreads independent transactions across a pool of sender
+// accounts, each with its own DataPoster, nonce and queue storage. Posting
+// throughput isn't limited to a single nonce lane, and a stuck transaction
+// only delays the transactions behind it from the same sender.
+// It must only be used for transactions which don't need to be posted in
+// order or from a particular address.
+type DataPosterPool struct {
+	posters []*DataPoster
+
+	mutex sync.Mutex
+	next  int
+}
+
+func NewDataPosterPool(ctx context.Context, opts *DataPosterPoolOpts) (*DataPosterPool, error) {
+	if len(opts.Auths)+len(opts.ExternalSigners) == 0 {
+		return nil, errors.New("data poster pool needs at least one signer")
+	}
+	pool := &DataPosterPool{}
+	senders := make(map[common.Address]struct{})
+	newPoster := func(sender common.Address, auth *bind.TransactOpts, externalSigner *ExternalSignerCfg) error {
+		if _, ok := senders[sender]; ok {
+			return fmt.Errorf("duplicate data poster pool sender %v", sender)
+		}
+		senders[sender] = struct{}{}
+		posterOpts := opts.DataPosterOpts
+		posterOpts.Auth = auth
+		posterOpts.ExternalSigner = externalSigner
+		if opts.Database != nil {
+			// Senders have the same length so that no queue is a prefix of another
+			posterOpts.Database = rawdb.NewTable(opts.Database, string(sender.Bytes()))
+		}
+		posterOpts.RedisKey = sender.Hex() + "." + opts.RedisKey
+		poster, err := NewDataPoster(ctx, &posterOpts)
+		if err != nil {
+			return fmt.Errorf("creating data poster for sender %v: %w", sender, err)
+		}
+		pool.posters = append(pool.posters, poster)
+		return nil
+	}
+	for _, auth := range opts.Auths {
+		if err := newPoster(auth.From, auth, nil); err != nil {
+			return nil, err
+		}
+	}
+	for i := range opts.ExternalSigners {
+		externalSigner := &opts.ExternalSigners[i]
+		if externalSigner.URL == "" || !common.IsHexAddress(externalSigner.Address) {
+			return nil, fmt.Errorf("data poster pool external signer %d needs an url and an address", i)
+		}
+		if err := newPoster(common.HexToAddress(externalSigner.Address), nil, externalSigner); err != nil {
+			return nil, err
+		}
+	}
+	return pool, nil
+}
+
+// NewDataPosterPoolFromConfig creates a pool of the pool-signers of the
+// config returned by opts.Config, or returns nil if there are none. External
+// signers of the pool share the external-signer config except for the
+// address.
+func NewDataPosterPoolFromConfig(ctx context.Context, opts *DataPosterOpts) (*DataPosterPool, error) {
+	cfg := opts.Config()
+	if !cfg.PoolSigners.Enabled() {
+		return nil, nil
+	}
+	poolOpts := &DataPosterPoolOpts{DataPosterOpts: *opts}
+	for i, key := range cfg.PoolSigners.PrivateKeys {
+		privateKey, err := crypto.HexToECDSA(strings.TrimPrefix(key, "0x"))
+		if err != nil {
+			return nil, fmt.Errorf("data poster pool private key %d: %w", i, err)
+		}
+		auth, err := bind.NewKeyedTransactorWithChainID(privateKey, opts.ParentChain.ChainID)
+		if err != nil {
+			return nil, err
+		}
+		poolOpts.Auths = append(poolOpts.Auths, auth)
+	}
+	for _, address := range cfg.PoolSigners.ExternalSignerAddresses {
+		externalSigner := cfg.ExternalSigner
+		externalSigner.Address = address
+		poolOpts.ExternalSigners = append(poolOpts.ExternalSigners, externalSigner)
+	}
+	return NewDataPosterPool(ctx, poolOpts)
+}
+
+func (p *DataPosterPool) Start(ctx context.Context) {
+	for _, poster := range p.posters {
+		poster.Start(ctx)
+	}
+}
+
+func (p *DataPosterPool) StopOnly() {
+	for _, poster := range p.posters {
+		poster.StopOnly()
+	}
+}
+
+func (p *DataPosterPool) StopAndWait() {
+	for _, poster := range p.posters {
+		poster.StopAndWait()
+	}
+}
+
+// Posters returns the DataPosters of the pool, one per sender.
+func (p *DataPosterPool) Posters() []*DataPoster {
+	return p.posters
+}
+
+func (p *DataPosterPool) Senders() []common.Address {
+	senders := make([]common.Address, 0, len(p.posters))
+	for _, poster := range p.posters {
+		senders = append(senders, poster.Sender())
+	}
+	return senders
+}
+
+// unconfirmedTransactions returns the number of queued transactions which
+// aren't in a block yet.
+func (p *DataPoster) unconfirmedTransactions(ctx context.Context) (uint64, error) {
+	lockedState := p.internalState.Lock()
+	lastQueueItem, err := lockedState.Queue.FetchLast(ctx)
+	p.internalState.Unlock()
+	if err != nil {
+		return 0, err
+	}
+	if lastQueueItem == nil {
+		return 0, nil
+	}
+	unconfirmedNonce, err := p.client.NonceAt(ctx, p.Sender(), nil)
+	if err != nil {
+		return 0, err
+	}
+	return arbmath.SaturatingUSub(lastQueueItem.FullTx.Nonce()+1, unconfirmedNonce), nil
+}
+
+// poolPostOrder orders the senders with the fewest unconfirmed transactions
+// first, breaking ties round-robin starting from next.
+func poolPostOrder(unconfirmed []uint64, next int) []int {
+	order := make([]int, len(unconfirmed))
+	for i := range order {
+		order[i] = (next + i) % len(unconfirmed)
+	}
+	slices.SortStableFunc(order, func(a, b int) int {
+		return cmp.Compare(unconfirmed[a], unconfirmed[b])
+	})
+	return order
+}
+
+// PostSimpleTransaction posts the transaction from the sender with the fewest
+// unconfirmed transactions, falling back to the next ones if its mempool is
+// full.
+func (p *DataPosterPool) PostSimpleTransaction(ctx context.Context, to common.Address, calldata []byte, gasLimit uint64, value *big.Int) (*types.Transaction, error) {
+	unconfirmed := make([]uint64, len(p.posters))
+	for i, poster := range p.posters {
+		count, err := poster.unconfirmedTransactions(ctx)
+		if err != nil {
+			log.Warn("failed to get unconfirmed transactions of data poster pool sender", "sender", poster.Sender(), "err", err)
+			count = ^uint64(0)
+		}
+		unconfirmed[i] = count
+	}
+	p.mutex.Lock()
+	order := poolPostOrder(unconfirmed, p.next)
+	p.next = (p.next + 1) % len(p.posters)
+	p.mutex.Unlock()
+
+	var errs []error
+	for _, i := range order {
+		tx, err := p.posters[i].PostSimpleTransaction(ctx, to, calldata, gasLimit, value)
+		if err == nil {
+			return tx, nil
+		}
+		if !errors.Is(err, ErrExceedsMaxMempoolSize) {
+			return nil, err
+		}
+		errs = append(errs, fmt.Errorf("sender %v: %w", p.posters[i].Sender(), err))
+	}
+	return nil, errors.Join(errs...)
+}
@@ -0,0 +1,29 @@
+// Copyright 2026, Offchain Labs, Inc.
+// For license information, see https://github.com/OffchainLabs/nitro/blob/master/LICENSE.md
+
+package dataposter
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestPoolPostOrder(t *testing.T) {
+	for _, tc := range []struct {
+		unconfirmed []uint64
+		next        int
+		want        []int
+	}{
+		{[]uint64{0, 0, 0}, 0, []int{0, 1, 2}},
+		// Ties are broken round-robin
+		{[]uint64{0, 0, 0}, 2, []int{2, 0, 1}},
+		// A sender with a stuck transaction goes last
+		{[]uint64{5, 1, 1}, 0, []int{1, 2, 0}},
+		{[]uint64{5, 1, 1}, 2, []int{2, 1, 0}},
+		{[]uint64{2, ^uint64(0), 0}, 1, []int{2, 0, 1}},
+	} {
+		if got := poolPostOrder(tc.unconfirmed, tc.next); !slices.Equal(got, tc.want) {
+			t.Errorf("poolPostOrder(%v, %d) = %v, want %v", tc.unconfirmed, tc.next, got, tc.want)
+		}
+	}
+}
@@ -6,6 +6,9 @@ import (
 	"context"
 	"math/big"
 	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
 
 	"github.com/offchainlabs/nitro/arbnode/dataposter/storage"
 )
@@ -20,11 +23,12 @@ func NewInternalState(queue QueueStorage) *InternalState {
 	return &InternalState{
 		mutex: sync.Mutex{},
 		lockedState: LockedInternalState{
-			LastBlock:  big.NewInt(0),
-			Balance:    big.NewInt(0),
-			Nonce:      0,
-			Queue:      queue,
-			ErrorCount: make(map[uint64]int),
+			LastBlock:    big.NewInt(0),
+			Balance:      big.NewInt(0),
+			Nonce:        0,
+			Queue:        queue,
+			ErrorCount:   make(map[uint64]int),
+			Replacements: make(map[uint64][]Replacement),
 		},
 	}
 }
@@ -45,6 +49,18 @@ type LockedInternalState struct {
 	Nonce      uint64
 	Queue      QueueStorage
 	ErrorCount map[uint64]int // number of consecutive intermittent errors rbf-ing or sending, per nonce
+	// Replacements are the previous versions of the queued transactions replaced since startup, per nonce.
+	Replacements map[uint64][]Replacement
+}
+
+// Replacement describes a version of a queued transaction which was replaced
+// by fee or cancelled.
+type Replacement struct {
+	Hash          common.Hash
+	GasFeeCap     *big.Int
+	GasTipCap     *big.Int
+	BlobGasFeeCap *big.Int
+	ReplacedAt    time.Time
 }
 
 // QueueStorage implements queue-alike storage that can
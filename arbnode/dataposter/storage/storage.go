@@ -22,6 +22,7 @@ var (
 	BlockValidatorPrefix string = "v" // the prefix for all block validator keys
 	StakerPrefix         string = "S" // the prefix for all staker keys
 	BatchPosterPrefix    string = "b" // the prefix for all batch poster keys
+	StakerPoolPrefix     string = "P" // the prefix for all keys of the staker's data poster pool
 	// TODO(anodar): move everything else from schema.go file to here once
 	// execution split is complete.
 )
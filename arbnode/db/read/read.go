@@ -10,6 +10,8 @@ import (
 
 	"github.com/offchainlabs/nitro/arbnode/db/schema"
 	"github.com/offchainlabs/nitro/arbnode/mel"
+	"github.com/offchainlabs/nitro/arbos/arbostypes"
+	"github.com/offchainlabs/nitro/arbutil"
 )
 
 // MELSequencerBatchCount returns the batch count corresponding to the head MEL state
@@ -40,6 +42,21 @@ func BatchMetadata(db ethdb.KeyValueStore, seqNum uint64) (mel.BatchMetadata, er
 	return Value[mel.BatchMetadata](db, Key(schema.SequencerBatchMetaPrefix, seqNum))
 }
 
+// MessageCount returns the number of messages stored by the transaction streamer
+func MessageCount(db ethdb.KeyValueStore) (arbutil.MessageIndex, error) {
+	count, err := Value[uint64](db, schema.MessageCountKey)
+	return arbutil.MessageIndex(count), err
+}
+
+// Message returns the message stored by the transaction streamer at the given index
+func Message(db ethdb.KeyValueStore, msgIdx arbutil.MessageIndex) (*arbostypes.MessageWithMetadata, error) {
+	message, err := Value[arbostypes.MessageWithMetadata](db, Key(schema.MessagePrefix, uint64(msgIdx)))
+	if err != nil {
+		return nil, err
+	}
+	return &message, nil
+}
+
 // Key returns appropriate database key for a given prefix and position, prefix generally picked
 // from the db schema available at arbnode/db/schema/schema.go
 func Key(prefix []byte, pos uint64) []byte {
@@ -26,6 +26,7 @@ var (
 	DbSchemaVersion             []byte = []byte("_schemaVersion")               // contains a uint64 representing the database schema version
 	HeadMelStateBlockNumKey     []byte = []byte("_headMelStateBlockNum")        // contains the latest computed MEL state's parent chain block number
 	InitialMelStateBlockNumKey  []byte = []byte("_initialMelStateBlockNum")     // contains the initial MEL state's parent chain block number (legacy/MEL boundary)
+	FeedSignerRotationsKey      []byte = []byte("_feedSignerRotations")         // contains the RLP encoded list of feed signer rotations
 )
 
 const CurrentDbSchemaVersion uint64 = 2
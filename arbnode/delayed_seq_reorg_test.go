@@ -21,7 +21,7 @@ func TestSequencerReorgFromDelayed(t *testing.T) {
 	defer cancel()
 
 	exec, streamer, db, _ := NewTransactionStreamerForTest(t, ctx, common.Address{})
-	tracker, err := NewInboxTracker(db, streamer, nil)
+	tracker, err := NewInboxTracker(db, streamer, nil, func() *InboxReaderConfig { return &TestInboxReaderConfig })
 	Require(t, err)
 
 	err = streamer.Start(ctx)
@@ -222,7 +222,7 @@ func TestSequencerReorgFromLastDelayedMsg(t *testing.T) {
 	defer cancel()
 
 	exec, streamer, db, _ := NewTransactionStreamerForTest(t, ctx, common.Address{})
-	tracker, err := NewInboxTracker(db, streamer, nil)
+	tracker, err := NewInboxTracker(db, streamer, nil, func() *InboxReaderConfig { return &TestInboxReaderConfig })
 	Require(t, err)
 
 	err = streamer.Start(ctx)
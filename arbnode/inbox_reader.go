@@ -36,6 +36,9 @@ type InboxReaderConfig struct {
 	TargetMessagesRead  uint64        `koanf:"target-messages-read" reload:"hot"`
 	MaxBlocksToRead     uint64        `koanf:"max-blocks-to-read" reload:"hot"`
 	ReadMode            string        `koanf:"read-mode" reload:"hot"`
+	// ExecutionWaitTimeout bounds waiting for the messages before a batch to
+	// be executed, when the batch is decoded according to the ArbOS version.
+	ExecutionWaitTimeout time.Duration `koanf:"execution-wait-timeout" reload:"hot"`
 }
 
 type InboxReaderConfigFetcher func() *InboxReaderConfig
@@ -48,6 +51,9 @@ func (c *InboxReaderConfig) Validate() error {
 	if c.ReadMode != "latest" && c.ReadMode != "safe" && c.ReadMode != "finalized" {
 		return fmt.Errorf("inbox reader read-mode is invalid, want: latest or safe or finalized, got: %s", c.ReadMode)
 	}
+	if c.ExecutionWaitTimeout <= 0 {
+		return errors.New("inbox reader execution-wait-timeout must be positive")
+	}
 	return nil
 }
 
@@ -59,26 +65,29 @@ func InboxReaderConfigAddOptions(prefix string, f *pflag.FlagSet) {
 	f.Uint64(prefix+".target-messages-read", DefaultInboxReaderConfig.TargetMessagesRead, "if adjust-blocks-to-read is enabled, the target number of messages to read at once")
 	f.Uint64(prefix+".max-blocks-to-read", DefaultInboxReaderConfig.MaxBlocksToRead, "if adjust-blocks-to-read is enabled, the maximum number of blocks to read at once")
 	f.String(prefix+".read-mode", DefaultInboxReaderConfig.ReadMode, "mode to only read latest or safe or finalized L1 blocks. Enabling safe or finalized disables feed input and output. Defaults to latest. Takes string input, valid strings- latest, safe, finalized")
+	f.Duration(prefix+".execution-wait-timeout", DefaultInboxReaderConfig.ExecutionWaitTimeout, "the maximum time to wait for the messages before a sequencer batch to be executed, if the batch's decoding depends on the ArbOS version after them")
 }
 
 var DefaultInboxReaderConfig = InboxReaderConfig{
-	DelayBlocks:         0,
-	CheckDelay:          time.Minute,
-	MinBlocksToRead:     1,
-	DefaultBlocksToRead: 100,
-	TargetMessagesRead:  500,
-	MaxBlocksToRead:     2000,
-	ReadMode:            "latest",
+	DelayBlocks:          0,
+	CheckDelay:           time.Minute,
+	MinBlocksToRead:      1,
+	DefaultBlocksToRead:  100,
+	TargetMessagesRead:   500,
+	MaxBlocksToRead:      2000,
+	ReadMode:             "latest",
+	ExecutionWaitTimeout: time.Minute,
 }
 
 var TestInboxReaderConfig = InboxReaderConfig{
-	DelayBlocks:         0,
-	CheckDelay:          time.Millisecond * 10,
-	MinBlocksToRead:     1,
-	DefaultBlocksToRead: 100,
-	TargetMessagesRead:  500,
-	MaxBlocksToRead:     2000,
-	ReadMode:            "latest",
+	DelayBlocks:          0,
+	CheckDelay:           time.Millisecond * 10,
+	MinBlocksToRead:      1,
+	DefaultBlocksToRead:  100,
+	TargetMessagesRead:   500,
+	MaxBlocksToRead:      2000,
+	ReadMode:             "latest",
+	ExecutionWaitTimeout: time.Second * 10,
 }
 
 type InboxReader struct {
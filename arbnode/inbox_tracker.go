@@ -40,16 +40,23 @@ type InboxTracker struct {
 	mutex      sync.Mutex
 	validator  *staker.BlockValidator
 	dapReaders *daprovider.DAProviderRegistry
+	config     InboxReaderConfigFetcher
 
 	batchMetaMutex sync.Mutex
 	batchMeta      *containers.LruCache[uint64, mel.BatchMetadata]
+
+	// zstdFromMessage is a message after which the chain is known to be at
+	// daprovider.ZstdMessageArbOSVersion or later, if set. It's protected by
+	// mutex.
+	zstdFromMessage *arbutil.MessageIndex
 }
 
-func NewInboxTracker(db ethdb.Database, txStreamer *TransactionStreamer, dapReaders *daprovider.DAProviderRegistry) (*InboxTracker, error) {
+func NewInboxTracker(db ethdb.Database, txStreamer *TransactionStreamer, dapReaders *daprovider.DAProviderRegistry, config InboxReaderConfigFetcher) (*InboxTracker, error) {
 	tracker := &InboxTracker{
 		db:         db,
 		txStreamer: txStreamer,
 		dapReaders: dapReaders,
+		config:     config,
 		batchMeta:  containers.NewLruCache[uint64, mel.BatchMetadata](1000),
 	}
 	return tracker, nil
@@ -596,12 +603,93 @@ func (b *multiplexerBackend) ReadDelayedInbox(seqNum uint64) (*arbostypes.L1Inco
 
 var delayedMessagesMismatch = errors.New("sequencer batch delayed messages missing or different")
 
+// errArbOSVersionPending is returned for the ArbOS version before a batch
+// whose previous messages are still being added or executed.
+var errArbOSVersionPending = errors.New("ArbOS version before sequencer batch not known yet")
+
+// messageNotExecutedError is returned when the first batch can't be parsed
+// until the message before it is executed.
+type messageNotExecutedError struct {
+	msgIdx arbutil.MessageIndex
+	err    error
+}
+
+func (e *messageNotExecutedError) Error() string {
+	return fmt.Sprintf("message %d before sequencer batch not executed yet: %v", e.msgIdx, e.err)
+}
+
+func (e *messageNotExecutedError) Unwrap() error {
+	return e.err
+}
+
+// arbOSVersionBefore returns the ArbOS version after the message before
+// msgIdx, failing if that message hasn't been executed yet. It must be called
+// with the mutex held.
+func (t *InboxTracker) arbOSVersionBefore(ctx context.Context, msgIdx arbutil.MessageIndex) (uint64, error) {
+	if msgIdx == 0 {
+		return 0, nil
+	}
+	// ArbOS versions only increase
+	if t.zstdFromMessage != nil && *t.zstdFromMessage < msgIdx {
+		return daprovider.ZstdMessageArbOSVersion, nil
+	}
+	version, err := t.txStreamer.exec.ArbOSVersionForMessageIndex(msgIdx - 1).Await(ctx)
+	if err != nil {
+		return 0, &messageNotExecutedError{msgIdx: msgIdx - 1, err: err}
+	}
+	if version >= daprovider.ZstdMessageArbOSVersion {
+		known := msgIdx - 1
+		t.zstdFromMessage = &known
+	}
+	return version, nil
+}
+
+// waitForExecution waits up to the configured timeout for msgIdx to be
+// executed. It must be called without the mutex held, so that readers of the
+// tracker aren't blocked meanwhile.
+func (t *InboxTracker) waitForExecution(ctx context.Context, msgIdx arbutil.MessageIndex) error {
+	ctx, cancel := context.WithTimeout(ctx, t.config().ExecutionWaitTimeout)
+	defer cancel()
+	for {
+		_, err := t.txStreamer.exec.ArbOSVersionForMessageIndex(msgIdx).Await(ctx)
+		if err == nil {
+			return nil
+		}
+		log.Debug("waiting for execution of the message before a sequencer batch", "msgIdx", msgIdx, "err", err)
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("timed out waiting for execution of message %d before a sequencer batch: %w", msgIdx, err)
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+}
+
+// AddSequencerBatches adds the batches and their messages. Batches whose
+// parsing depends on the ArbOS version before them are added once the
+// messages of the batches before them have been executed.
 func (t *InboxTracker) AddSequencerBatches(ctx context.Context, client *ethclient.Client, batches []*mel.SequencerInboxBatch) error {
+	for len(batches) > 0 {
+		added, err := t.addSequencerBatches(ctx, client, batches)
+		var notExecuted *messageNotExecutedError
+		if errors.As(err, &notExecuted) {
+			// The batches are parsed again after waiting, as the messages
+			// before them may have been reorged meanwhile.
+			if err := t.waitForExecution(ctx, notExecuted.msgIdx); err != nil {
+				return err
+			}
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		batches = batches[added:]
+	}
+	return nil
+}
+
+func (t *InboxTracker) addSequencerBatches(ctx context.Context, client *ethclient.Client, batches []*mel.SequencerInboxBatch) (int, error) {
 	var nextAcc common.Hash
 	var prevbatchmeta mel.BatchMetadata
-	if len(batches) == 0 {
-		return nil
-	}
 	t.mutex.Lock()
 	defer t.mutex.Unlock()
 
@@ -613,31 +701,35 @@ func (t *InboxTracker) AddSequencerBatches(ctx context.Context, client *ethclien
 		prevbatchmeta, err = t.GetBatchMetadata(pos - 1)
 		nextAcc = prevbatchmeta.Accumulator
 		if errors.Is(err, AccumulatorNotFoundErr) {
-			return errors.New("missing previous sequencer batch")
+			return 0, errors.New("missing previous sequencer batch")
 		} else if err != nil {
-			return err
+			return 0, err
 		}
 	}
+	// The messages from prevbatchmeta on are replaced
+	if t.zstdFromMessage != nil && *t.zstdFromMessage >= prevbatchmeta.MessageCount {
+		t.zstdFromMessage = nil
+	}
 
 	dbBatch := t.db.NewBatch()
 	err := deleteStartingAt(t.db, dbBatch, schema.DelayedSequencedPrefix, uint64ToKey(prevbatchmeta.DelayedMessageCount+1))
 	if err != nil {
-		return err
+		return 0, err
 	}
 
 	for _, batch := range batches {
 		if batch.SequenceNumber != pos {
-			return fmt.Errorf("unexpected batch sequence number %v expected %v", batch.SequenceNumber, pos)
+			return 0, fmt.Errorf("unexpected batch sequence number %v expected %v", batch.SequenceNumber, pos)
 		}
 		if nextAcc != batch.BeforeInboxAcc {
-			return fmt.Errorf("previous batch accumulator %v mismatch expected %v", batch.BeforeInboxAcc, nextAcc)
+			return 0, fmt.Errorf("previous batch accumulator %v mismatch expected %v", batch.BeforeInboxAcc, nextAcc)
 		}
 
 		if batch.AfterDelayedCount > 0 {
 			haveDelayedAcc, err := t.GetDelayedAcc(batch.AfterDelayedCount - 1)
 			notFound := errors.Is(err, AccumulatorNotFoundErr)
 			if err != nil && !notFound {
-				return err
+				return 0, err
 			}
 			if notFound || haveDelayedAcc != batch.AfterDelayedAcc {
 				log.Debug(
@@ -648,7 +740,7 @@ func (t *InboxTracker) AddSequencerBatches(ctx context.Context, client *ethclien
 					"batchDelayedAcc", batch.AfterDelayedAcc,
 				)
 				// We somehow missed a delayed message reorg; go back and look for it
-				return delayedMessagesMismatch
+				return 0, delayedMessagesMismatch
 			}
 		}
 
@@ -665,23 +757,46 @@ func (t *InboxTracker) AddSequencerBatches(ctx context.Context, client *ethclien
 		ctx:    ctx,
 		client: client,
 	}
+	currentPos := prevbatchmeta.MessageCount + 1
+	var notExecuted *messageNotExecutedError
+	arbOSVersion := func() (uint64, error) {
+		// The batch being parsed starts at message currentPos-1
+		if currentPos-1 > prevbatchmeta.MessageCount {
+			return 0, errArbOSVersionPending
+		}
+		version, err := t.arbOSVersionBefore(ctx, currentPos-1)
+		if errors.As(err, &notExecuted) {
+			return 0, errArbOSVersionPending
+		}
+		return version, err
+	}
 	multiplexer := arbstate.NewInboxMultiplexer(
 		backend,
 		prevbatchmeta.DelayedMessageCount,
 		t.dapReaders,
 		daprovider.KeysetValidate,
+		arbOSVersion,
 		t.txStreamer.chainConfig,
 	)
 	batchMessageCounts := make(map[uint64]arbutil.MessageIndex)
-	currentPos := prevbatchmeta.MessageCount + 1
 	for {
 		if len(backend.batches) == 0 {
 			break
 		}
 		batchSeqNum := backend.batches[0].SequenceNumber
 		msg, err := multiplexer.Pop(ctx)
+		if errors.Is(err, errArbOSVersionPending) {
+			// Add the batches before it first
+			batches = batches[:len(batches)-len(backend.batches)]
+			if len(batches) == 0 && notExecuted != nil {
+				return 0, notExecuted
+			}
+			// #nosec G115
+			pos = startPos + uint64(len(batches))
+			break
+		}
 		if err != nil {
-			return err
+			return 0, err
 		}
 		messages = append(messages, *msg)
 		batchMessageCounts[batchSeqNum] = currentPos
@@ -700,24 +815,24 @@ func (t *InboxTracker) AddSequencerBatches(ctx context.Context, client *ethclien
 		batchMetas[batch.SequenceNumber] = meta
 		metaBytes, err := rlp.EncodeToBytes(meta)
 		if err != nil {
-			return err
+			return 0, err
 		}
 		err = dbBatch.Put(dbKey(schema.SequencerBatchMetaPrefix, batch.SequenceNumber), metaBytes)
 		if err != nil {
-			return err
+			return 0, err
 		}
 
 		seqNumData, err := rlp.EncodeToBytes(batch.SequenceNumber)
 		if err != nil {
-			return err
+			return 0, err
 		}
 		if batch.AfterDelayedCount < lastBatchMeta.DelayedMessageCount {
-			return errors.New("batch delayed message count went backwards")
+			return 0, errors.New("batch delayed message count went backwards")
 		}
 		if batch.AfterDelayedCount > lastBatchMeta.DelayedMessageCount {
 			err = dbBatch.Put(dbKey(schema.DelayedSequencedPrefix, batch.AfterDelayedCount), seqNumData)
 			if err != nil {
-				return err
+				return 0, err
 			}
 		}
 		lastBatchMeta = meta
@@ -725,15 +840,15 @@ func (t *InboxTracker) AddSequencerBatches(ctx context.Context, client *ethclien
 
 	err = t.deleteBatchMetadataStartingAt(dbBatch, pos)
 	if err != nil {
-		return err
+		return 0, err
 	}
 	countData, err := rlp.EncodeToBytes(pos)
 	if err != nil {
-		return err
+		return 0, err
 	}
 	err = dbBatch.Put(schema.SequencerBatchCountKey, countData)
 	if err != nil {
-		return err
+		return 0, err
 	}
 
 	newMessageCount := prevbatchmeta.MessageCount + arbutil.MessageIndex(len(messages))
@@ -765,7 +880,7 @@ func (t *InboxTracker) AddSequencerBatches(ctx context.Context, client *ethclien
 	// This also writes the batch
 	err = t.txStreamer.AddMessagesAndEndBatch(prevbatchmeta.MessageCount, true, messages, nil, dbBatch)
 	if err != nil {
-		return err
+		return 0, err
 	}
 
 	// Update the batchMeta cache immediately after writing the batch
@@ -778,10 +893,10 @@ func (t *InboxTracker) AddSequencerBatches(ctx context.Context, client *ethclien
 	if t.txStreamer.broadcastServer != nil && pos > 1 {
 		prevprevbatchmeta, err := t.GetBatchMetadata(pos - 2)
 		if errors.Is(err, AccumulatorNotFoundErr) {
-			return errors.New("missing previous previous sequencer batch")
+			return 0, errors.New("missing previous previous sequencer batch")
 		}
 		if err != nil {
-			return err
+			return 0, err
 		}
 		if prevprevbatchmeta.MessageCount > 0 {
 			// Confirm messages from batch before last batch
@@ -789,7 +904,7 @@ func (t *InboxTracker) AddSequencerBatches(ctx context.Context, client *ethclien
 		}
 	}
 
-	return nil
+	return len(batches), nil
 }
 
 func (t *InboxTracker) ReorgDelayedTo(count uint64) error {
@@ -61,6 +61,7 @@ func ExtractMessages(
 	delayedMsgDatabase DelayedMessageDatabase,
 	txFetcher TransactionFetcher,
 	logsFetcher LogsFetcher,
+	arbOSVersion arbstate.ArbOSVersionFetcher,
 	chainConfig *params.ChainConfig,
 ) (*mel.State, []*arbostypes.MessageWithMetadata, []*mel.DelayedInboxMessage, []*mel.BatchMetadata, error) {
 	return extractMessagesImpl(
@@ -68,6 +69,7 @@ func ExtractMessages(
 		inputState,
 		parentChainHeader,
 		chainConfig,
+		arbOSVersion,
 		dapReaders,
 		delayedMsgDatabase,
 		txFetcher,
@@ -90,6 +92,7 @@ func extractMessagesImpl(
 	inputState *mel.State,
 	parentChainHeader *types.Header,
 	chainConfig *params.ChainConfig,
+	arbOSVersion arbstate.ArbOSVersionFetcher,
 	dapReaders arbstate.DapReaderSource,
 	delayedMsgDatabase DelayedMessageDatabase,
 	txFetcher TransactionFetcher,
@@ -233,6 +236,7 @@ func extractMessagesImpl(
 			serialized,
 			dapReaders,
 			daprovider.KeysetValidate,
+			arbOSVersion,
 			chainConfig,
 		)
 		if err != nil {
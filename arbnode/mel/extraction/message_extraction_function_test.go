@@ -183,6 +183,7 @@ func TestExtractMessages(t *testing.T) {
 					nil,
 					txFetcher,
 					blockLogsFetcher,
+					arbstate.FixedArbOSVersion(0),
 					chaininfo.ArbitrumDevTestChainConfig(),
 				)
 			} else {
@@ -192,6 +193,7 @@ func TestExtractMessages(t *testing.T) {
 					melState,
 					header,
 					chaininfo.ArbitrumDevTestChainConfig(),
+					arbstate.FixedArbOSVersion(0),
 					nil,
 					nil,
 					txFetcher,
@@ -350,6 +352,7 @@ func successfulParseSequencerMsg(
 	data []byte,
 	dapReaders arbstate.DapReaderSource,
 	keysetValidationMode daprovider.KeysetValidationMode,
+	arbOSVersion arbstate.ArbOSVersionFetcher,
 	chainConfig *params.ChainConfig,
 ) (*arbstate.SequencerMessage, error) {
 	return nil, nil
@@ -362,6 +365,7 @@ func failingParseSequencerMsg(
 	data []byte,
 	dapReaders arbstate.DapReaderSource,
 	keysetValidationMode daprovider.KeysetValidationMode,
+	arbOSVersion arbstate.ArbOSVersionFetcher,
 	chainConfig *params.ChainConfig,
 ) (*arbstate.SequencerMessage, error) {
 	return nil, errors.New("failed to parse sequencer message")
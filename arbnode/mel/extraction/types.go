@@ -65,6 +65,7 @@ type sequencerMessageParserFunc func(
 	data []byte,
 	dapReaders arbstate.DapReaderSource,
 	keysetValidationMode daprovider.KeysetValidationMode,
+	arbOSVersion arbstate.ArbOSVersionFetcher,
 	chainConfig *params.ChainConfig,
 ) (*arbstate.SequencerMessage, error)
 
@@ -32,6 +32,15 @@ func (f *txByLogFetcher) TransactionByLog(ctx context.Context, log *types.Log) (
 	return tx, err
 }
 
+// arbOSVersionUnknown is the ArbOS version fetcher of message extraction.
+// Message extraction runs ahead of execution, so it doesn't know the ArbOS
+// version before a batch, and can't read the batch formats depending on it.
+// Posting such batches is rejected by the config of nodes running message
+// extraction, so chains using it must not have zstd batches posted.
+func arbOSVersionUnknown() (uint64, error) {
+	return 0, errors.New("message extraction doesn't support batch formats depending on the ArbOS version yet")
+}
+
 func (m *MessageExtractor) processNextBlock(ctx context.Context, current *fsm.CurrentState[action, FSMState]) (time.Duration, error) {
 	// Process the next block in the parent chain and extracts messages.
 	processAction, ok := current.SourceEvent.(processNextBlock)
@@ -97,6 +106,7 @@ func (m *MessageExtractor) processNextBlock(ctx context.Context, current *fsm.Cu
 		m.melDB,
 		&txByLogFetcher{m.parentChainReader},
 		m.logsAndHeadersPreFetcher,
+		arbOSVersionUnknown,
 		m.chainConfig,
 	)
 	if err != nil {
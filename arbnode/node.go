@@ -123,6 +123,9 @@ func (c *Config) Validate() error {
 	if err := c.BatchPoster.Validate(); err != nil {
 		return err
 	}
+	if c.MessageExtraction.Enable && c.BatchPoster.Compression == BatchCompressionZstd {
+		return errors.New("zstd batch compression isn't supported with message extraction, which can't decode zstd batches")
+	}
 	if err := c.Feed.Validate(); err != nil {
 		return err
 	}
@@ -455,6 +458,36 @@ func StakerDataposter(
 		})
 }
 
+// StakerDataposterPool creates the data poster pool of the staker's
+// pool-signers, or returns nil if none are configured.
+func StakerDataposterPool(
+	ctx context.Context, db ethdb.Database, l1Reader *headerreader.HeaderReader,
+	cfgFetcher ConfigFetcher, parentChain *parent.ParentChain,
+) (*dataposter.DataPosterPool, error) {
+	cfg := cfgFetcher.Get()
+	if !cfg.Staker.DataPoster.PoolSigners.Enabled() {
+		return nil, nil
+	}
+	redisC, err := redisutil.RedisClientFromURL(cfg.Staker.RedisUrl)
+	if err != nil {
+		return nil, fmt.Errorf("creating redis client from url: %w", err)
+	}
+	return dataposter.NewDataPosterPoolFromConfig(ctx,
+		&dataposter.DataPosterOpts{
+			Database:     db,
+			HeaderReader: l1Reader,
+			RedisClient:  redisC,
+			Config: func() *dataposter.DataPosterConfig {
+				return &cfgFetcher.Get().Staker.DataPoster
+			},
+			MetadataRetriever: func(ctx context.Context, blockNum *big.Int) ([]byte, error) {
+				return nil, nil
+			},
+			RedisKey:    "staker-data-poster-pool.queue",
+			ParentChain: parentChain,
+		})
+}
+
 func getSyncMonitor(configFetcher ConfigFetcher) *SyncMonitor {
 	syncConfigFetcher := func() *SyncMonitorConfig {
 		return &configFetcher.Get().SyncMonitor
@@ -484,6 +517,7 @@ func getBroadcastServer(
 	config *Config,
 	configFetcher ConfigFetcher,
 	dataSigner signature.DataSignerFunc,
+	consensusDB ethdb.Database,
 	l2ChainId uint64,
 	fatalErrChan chan error,
 ) (*broadcaster.Broadcaster, error) {
@@ -497,6 +531,11 @@ func getBroadcastServer(
 			maybeDataSigner = dataSigner
 		}
 		broadcastServer = broadcaster.NewBroadcaster(func() *wsbroadcastserver.BroadcasterConfig { return &configFetcher.Get().Feed.Output }, l2ChainId, fatalErrChan, maybeDataSigner)
+		if maybeDataSigner != nil {
+			if err := broadcastServer.SetupSignerRotation(consensusDB, &config.Feed.Output.SignerRotation); err != nil {
+				return nil, err
+			}
+		}
 	}
 	return broadcastServer, nil
 }
@@ -759,12 +798,13 @@ func getInboxTrackerAndReader(
 		log.Info("Inbox reader and tracker disabled")
 		return nil, nil, nil
 	}
-	inboxTracker, err := NewInboxTracker(consensusDB, txStreamer, dapReaders)
+	inboxReaderConfig := func() *InboxReaderConfig { return &configFetcher.Get().InboxReader }
+	inboxTracker, err := NewInboxTracker(consensusDB, txStreamer, dapReaders, inboxReaderConfig)
 	if err != nil {
 		return nil, nil, err
 	}
 	firstMessageBlock := new(big.Int).SetUint64(deployInfo.DeployedAt)
-	inboxReader, err := NewInboxReader(inboxTracker, l1client, l1Reader, firstMessageBlock, delayedBridge, sequencerInbox, func() *InboxReaderConfig { return &configFetcher.Get().InboxReader })
+	inboxReader, err := NewInboxReader(inboxTracker, l1client, l1Reader, firstMessageBlock, delayedBridge, sequencerInbox, inboxReaderConfig)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -1066,10 +1106,20 @@ func getStaker(
 				if len(config.Staker.ContractWalletAddress) > 0 {
 					return nil, nil, common.Address{}, errors.New("validator contract wallet specified but flag to use a smart contract wallet was not specified")
 				}
-				wallet, err = validatorwallet.NewEOA(dp, l1client, getExtraGas)
+				var eoa *validatorwallet.EOA
+				eoa, err = validatorwallet.NewEOA(dp, l1client, getExtraGas)
+				if err != nil {
+					return nil, nil, common.Address{}, err
+				}
+				var pool *dataposter.DataPosterPool
+				pool, err = StakerDataposterPool(ctx, rawdb.NewTable(consensusDB, storage.StakerPoolPrefix), l1Reader, configFetcher, parentChain)
 				if err != nil {
 					return nil, nil, common.Address{}, err
 				}
+				if pool != nil {
+					eoa.SetDataPosterPool(pool)
+				}
+				wallet = eoa
 			}
 		}
 
@@ -1131,6 +1181,10 @@ func getTransactionStreamer(
 	if err != nil {
 		return nil, err
 	}
+	if broadcastServer != nil {
+		// Allows feed clients to replay messages which are no longer in the backlog
+		broadcastServer.SetFeedMessageSource(txStreamer)
+	}
 	return txStreamer, nil
 }
 
@@ -1378,7 +1432,7 @@ func createNodeImpl(
 		return nil, err
 	}
 
-	broadcastServer, err := getBroadcastServer(config, configFetcher, dataSigner, l2Config.ChainID.Uint64(), fatalErrChan)
+	broadcastServer, err := getBroadcastServer(config, configFetcher, dataSigner, consensusDB, l2Config.ChainID.Uint64(), fatalErrChan)
 	if err != nil {
 		return nil, err
 	}
@@ -1580,6 +1634,31 @@ func registerAPIs(currentNode *Node, stack *node.Node, genesisBlockNum uint64) {
 			Public:    false,
 		})
 	}
+	if currentNode.BroadcastServer != nil {
+		apis = append(apis, rpc.API{
+			Namespace: broadcaster.FeedAdminNamespace,
+			Version:   "1.0",
+			Service:   broadcaster.NewFeedAdminAPI(currentNode.BroadcastServer),
+			Public:    false,
+		})
+	}
+	dataPosters := make(map[string]*dataposter.DataPoster)
+	if currentNode.BatchPoster != nil {
+		dataPosters["batch-poster"] = currentNode.BatchPoster.DataPoster()
+	}
+	if currentNode.Staker != nil {
+		if dp := currentNode.Staker.DataPoster(); dp != nil {
+			dataPosters["staker"] = dp
+		}
+	}
+	if len(dataPosters) > 0 {
+		apis = append(apis, rpc.API{
+			Namespace: dataposter.AdminNamespace,
+			Version:   "1.0",
+			Service:   dataposter.NewAdminAPI(dataPosters),
+			Public:    false,
+		})
+	}
 	config := currentNode.configFetcher.Get()
 	if config.RPCServer.Enable {
 		apis = append(apis, rpc.API{
@@ -1199,34 +1199,66 @@ func (s *TransactionStreamer) PopulateFeedBacklog(ctx context.Context) error {
 	}
 	feedMessages := make([]*message.BroadcastFeedMessage, 0, arbmath.SaturatingUSub(messageCount, startMessage))
 	for seqNum := startMessage; seqNum < messageCount; seqNum++ {
-		message, err := s.GetMessage(seqNum)
+		feedMessage, err := s.feedMessage(seqNum)
 		if err != nil {
-			return fmt.Errorf("error getting message %v: %w", seqNum, err)
-		}
-
-		msgResult, err := s.ResultAtMessageIndex(seqNum)
-		var blockHash *common.Hash
-		if err == nil {
-			blockHash = &msgResult.BlockHash
-		}
-
-		blockMetadata, err := s.BlockMetadataAtMessageIndex(seqNum)
-		if err != nil {
-			log.Warn("Error getting blockMetadata byte array from tx streamer", "err", err)
+			return err
 		}
+		feedMessages = append(feedMessages, feedMessage)
+	}
+	return s.broadcastServer.PopulateFeedBacklog(feedMessages)
+}
 
-		messageWithInfo := arbostypes.MessageWithMetadataAndBlockInfo{
-			MessageWithMeta: *message,
-			BlockHash:       blockHash,
-			BlockMetadata:   blockMetadata,
-		}
-		feedMessage, err := s.broadcastServer.NewBroadcastFeedMessage(messageWithInfo, seqNum)
+// FeedMessages returns the signed feed messages from start to end, so that
+// the broadcaster can replay messages which are no longer in its backlog.
+func (s *TransactionStreamer) FeedMessages(start, end arbutil.MessageIndex) ([]*message.BroadcastFeedMessage, error) {
+	if s.broadcastServer == nil {
+		return nil, errors.New("transaction streamer has no broadcast server")
+	}
+	messageCount, err := s.GetMessageCount()
+	if err != nil {
+		return nil, fmt.Errorf("error getting tx streamer message count: %w", err)
+	}
+	if end >= messageCount || start > end {
+		return nil, fmt.Errorf("requested messages %d to %d but message count is %d", start, end, messageCount)
+	}
+	feedMessages := make([]*message.BroadcastFeedMessage, 0, end-start+1)
+	for seqNum := start; seqNum <= end; seqNum++ {
+		feedMessage, err := s.feedMessage(seqNum)
 		if err != nil {
-			return fmt.Errorf("error creating broadcast feed message %v: %w", seqNum, err)
+			return nil, err
 		}
 		feedMessages = append(feedMessages, feedMessage)
 	}
-	return s.broadcastServer.PopulateFeedBacklog(feedMessages)
+	return feedMessages, nil
+}
+
+func (s *TransactionStreamer) feedMessage(seqNum arbutil.MessageIndex) (*message.BroadcastFeedMessage, error) {
+	message, err := s.GetMessage(seqNum)
+	if err != nil {
+		return nil, fmt.Errorf("error getting message %v: %w", seqNum, err)
+	}
+
+	msgResult, err := s.ResultAtMessageIndex(seqNum)
+	var blockHash *common.Hash
+	if err == nil {
+		blockHash = &msgResult.BlockHash
+	}
+
+	blockMetadata, err := s.BlockMetadataAtMessageIndex(seqNum)
+	if err != nil {
+		log.Warn("Error getting blockMetadata byte array from tx streamer", "err", err)
+	}
+
+	messageWithInfo := arbostypes.MessageWithMetadataAndBlockInfo{
+		MessageWithMeta: *message,
+		BlockHash:       blockHash,
+		BlockMetadata:   blockMetadata,
+	}
+	feedMessage, err := s.broadcastServer.NewBroadcastFeedMessage(messageWithInfo, seqNum)
+	if err != nil {
+		return nil, fmt.Errorf("error creating broadcast feed message %v: %w", seqNum, err)
+	}
+	return feedMessage, nil
 }
 
 func (s *TransactionStreamer) writeMessage(msgIdx arbutil.MessageIndex, msg arbostypes.MessageWithMetadataAndBlockInfo, batch ethdb.Batch) error {
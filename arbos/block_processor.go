@@ -51,6 +51,10 @@ func (e *ErrFilteredCascadingRedeem) Error() string {
 	return fmt.Sprintf("cascading redeem filtered (originating tx: %s)", e.OriginatingTxHash.Hex())
 }
 
+// ErrBundleTxReverted is returned via BundleFailed when a tx of a bundle
+// executed but reverted, which drops the whole bundle.
+var ErrBundleTxReverted = errors.New("bundle transaction reverted")
+
 // A helper struct that implements String() by marshalling to JSON.
 // This is useful for logging because it's lazy, so if the log level is too high to print the transaction,
 // it doesn't waste compute marshalling the transaction when the result wouldn't be used.
@@ -69,8 +73,8 @@ func (p printTxAsJson) String() string {
 // blockBuildState holds all mutable state that accumulates during the tx
 // processing loop in ProduceBlockAdvanced. Grouping it here ensures that
 // the group-checkpoint and rollback logic stays in sync with the state it
-// manages. If you add a field, check whether saveGroupCheckpoint and
-// rollbackToGroupCheckpoint need updating.
+// manages. If you add a field, check whether newCheckpoint and
+// restoreCheckpoint need updating.
 //
 // lint:require-exhaustive-initialization
 type blockBuildState struct {
@@ -83,6 +87,8 @@ type blockBuildState struct {
 	receipts             types.Receipts
 	redeems              types.Transactions
 	activeGroupCP        *groupCheckpoint
+	activeBundleCP       *groupCheckpoint
+	bundleTxsLeft        int
 }
 
 // lint:require-exhaustive-initialization
@@ -98,16 +104,9 @@ type groupCheckpoint struct {
 	userTxHash           common.Hash
 }
 
-// saveGroupCheckpoint snapshots the loop state so the entire tx group can be
-// rolled back if a descendant redeem is filtered. header is passed separately
-// because only GasUsed is checkpointed; the rest of the header is immutable
-// during the loop.
-func (s *blockBuildState) saveGroupCheckpoint(header *types.Header, snap int, userTxHash common.Hash) error {
-	if len(s.redeems) != 0 {
-		return errors.New("saveGroupCheckpoint called with pending redeems")
-	}
-	s.activeGroupCP = &groupCheckpoint{
-		backup:               s.statedb.Copy(),
+func (s *blockBuildState) newCheckpoint(header *types.Header, backup *state.StateDB, snap int, userTxHash common.Hash) *groupCheckpoint {
+	return &groupCheckpoint{
+		backup:               backup,
 		snap:                 snap,
 		headerGasUsed:        header.GasUsed,
 		blockGasLeft:         s.blockGasLeft,
@@ -117,14 +116,36 @@ func (s *blockBuildState) saveGroupCheckpoint(header *types.Header, snap int, us
 		receiptsLen:          len(s.receipts),
 		userTxHash:           userTxHash,
 	}
+}
+
+// saveGroupCheckpoint snapshots the loop state so the entire tx group can be
+// rolled back if a descendant redeem is filtered. header is passed separately
+// because only GasUsed is checkpointed; the rest of the header is immutable
+// during the loop.
+func (s *blockBuildState) saveGroupCheckpoint(header *types.Header, snap int, userTxHash common.Hash) error {
+	if len(s.redeems) != 0 {
+		return errors.New("saveGroupCheckpoint called with pending redeems")
+	}
+	s.activeGroupCP = s.newCheckpoint(header, s.statedb.Copy(), snap, userTxHash)
 	return nil
 }
 
-// rollbackToGroupCheckpoint restores loop state to the saved checkpoint,
-// undoing the user tx and all its redeems. header is needed to restore
-// GasUsed, which lives outside blockBuildState.
-func (s *blockBuildState) rollbackToGroupCheckpoint(header *types.Header) error {
-	cp := s.activeGroupCP
+// saveBundleCheckpoint snapshots the loop state before the first tx of a
+// bundle, so the entire bundle (its user txs and all their redeems) can be
+// rolled back if any of its txs fails.
+func (s *blockBuildState) saveBundleCheckpoint(header *types.Header, firstTxHash common.Hash, size int) error {
+	if len(s.redeems) != 0 || s.activeGroupCP != nil {
+		return errors.New("saveBundleCheckpoint called with pending redeems or an active group")
+	}
+	backup := s.statedb.Copy()
+	s.activeBundleCP = s.newCheckpoint(header, backup, backup.Snapshot(), firstTxHash)
+	s.bundleTxsLeft = size - 1
+	return nil
+}
+
+// restoreCheckpoint restores loop state to the checkpoint. header is needed to
+// restore GasUsed, which lives outside blockBuildState.
+func (s *blockBuildState) restoreCheckpoint(header *types.Header, cp *groupCheckpoint) error {
 	cp.backup.RevertToSnapshot(cp.snap)
 	s.statedb = cp.backup
 	header.GasUsed = cp.headerGasUsed
@@ -136,10 +157,28 @@ func (s *blockBuildState) rollbackToGroupCheckpoint(header *types.Header) error
 	s.receipts = s.receipts[:cp.receiptsLen]
 	var err error
 	s.arbState, err = arbosState.OpenSystemArbosState(s.statedb, nil, true)
-	if err != nil {
+	return err
+}
+
+// rollbackToGroupCheckpoint restores loop state to the saved checkpoint,
+// undoing the user tx and all its redeems.
+func (s *blockBuildState) rollbackToGroupCheckpoint(header *types.Header) error {
+	if err := s.restoreCheckpoint(header, s.activeGroupCP); err != nil {
+		return err
+	}
+	s.activeGroupCP = nil
+	return nil
+}
+
+// rollbackToBundleCheckpoint restores loop state to before the first tx of
+// the active bundle, undoing all of its txs and their redeems.
+func (s *blockBuildState) rollbackToBundleCheckpoint(header *types.Header) error {
+	if err := s.restoreCheckpoint(header, s.activeBundleCP); err != nil {
 		return err
 	}
 	s.activeGroupCP = nil
+	s.activeBundleCP = nil
+	s.bundleTxsLeft = 0
 	return nil
 }
 
@@ -225,6 +264,20 @@ type SequencingHooks interface {
 	TxFailed(error)
 }
 
+// BundleSequencingHooks is optionally implemented by SequencingHooks which
+// support group rollback, to sequence bundles of user txs that must be
+// included contiguously and atomically.
+type BundleSequencingHooks interface {
+	// BundleSize returns the number of user txs of the bundle started by the
+	// last tx from NextTxToSequence, or 0 if that tx doesn't start a bundle.
+	BundleSize() int
+	// BundleFailed records an error for all txs of the bundle which the last
+	// user tx from NextTxToSequence belongs to, after the block was rolled
+	// back to before the bundle. The txs of the bundle which were not
+	// sequenced yet must be skipped.
+	BundleFailed(*types.Header, error)
+}
+
 type NoopSequencingHooks struct {
 	txs               types.Transactions
 	scheduledTxsCount int
@@ -359,7 +412,11 @@ func ProduceBlockAdvanced(
 		receipts:             nil,
 		redeems:              nil,
 		activeGroupCP:        nil,
+		activeBundleCP:       nil,
+		bundleTxsLeft:        0,
 	}
+	bundleHooks, supportsBundles := sequencingHooks.(BundleSequencingHooks)
+	supportsBundles = supportsBundles && sequencingHooks.SupportsGroupRollback()
 
 	for {
 		// repeatedly process the next tx, doing redeems created along the way in FIFO order
@@ -395,11 +452,27 @@ func ProduceBlockAdvanced(
 				return nil, nil, nil, fmt.Errorf("error fetching next transaction to sequence, userTxsProcessed: %d, err: %w", buildState.userTxsProcessed, err)
 			}
 			if tx == nil {
+				if buildState.bundleTxsLeft > 0 {
+					return nil, nil, nil, fmt.Errorf("sequencing hooks ended with %d txs of a bundle left", buildState.bundleTxsLeft)
+				}
 				break
 			}
 			if tx.Type() != types.ArbitrumInternalTxType {
 				isUserTx = true
 				options = conditionalOptions
+				if buildState.bundleTxsLeft > 0 {
+					buildState.bundleTxsLeft--
+				} else {
+					// Previous bundle (if any) completed successfully
+					buildState.activeBundleCP = nil
+					if supportsBundles {
+						if size := bundleHooks.BundleSize(); size > 0 {
+							if err := buildState.saveBundleCheckpoint(header, tx.Hash(), size); err != nil {
+								return nil, nil, nil, err
+							}
+						}
+					}
+				}
 			}
 		}
 
@@ -518,14 +591,30 @@ func ProduceBlockAdvanced(
 			// If a redeem was rejected by the address filter and we have an
 			// active group checkpoint, roll back the entire group (user tx + all
 			// redeems) to the pre-group state.
+			// A bundle containing the group is rolled back entirely.
 			if !isUserTx && buildState.activeGroupCP != nil && errors.Is(err, state.ErrArbTxFilter) {
 				userTxHash := buildState.activeGroupCP.userTxHash
+				if buildState.activeBundleCP != nil {
+					if err := buildState.rollbackToBundleCheckpoint(header); err != nil {
+						return nil, nil, nil, err
+					}
+					bundleHooks.BundleFailed(header, &ErrFilteredCascadingRedeem{OriginatingTxHash: userTxHash})
+					continue
+				}
 				if err := buildState.rollbackToGroupCheckpoint(header); err != nil {
 					return nil, nil, nil, err
 				}
 				sequencingHooks.TxFailed(&ErrFilteredCascadingRedeem{OriginatingTxHash: userTxHash})
 				continue
 			}
+			// If a tx of a bundle failed, roll back the entire bundle.
+			if isUserTx && buildState.activeBundleCP != nil {
+				if err := buildState.rollbackToBundleCheckpoint(header); err != nil {
+					return nil, nil, nil, err
+				}
+				bundleHooks.BundleFailed(header, err)
+				continue
+			}
 			if isUserTx {
 				sequencingHooks.TxFailed(err)
 			}
@@ -546,6 +635,14 @@ func ProduceBlockAdvanced(
 			continue
 		}
 
+		if isUserTx && buildState.activeBundleCP != nil && receipt.Status != types.ReceiptStatusSuccessful {
+			if err := buildState.rollbackToBundleCheckpoint(header); err != nil {
+				return nil, nil, nil, err
+			}
+			bundleHooks.BundleFailed(header, fmt.Errorf("%w: tx %v: %w", ErrBundleTxReverted, tx.Hash(), result.Err))
+			continue
+		}
+
 		if tx.Type() == types.ArbitrumInternalTxType {
 			// ArbOS might have upgraded to a new version, so we need to refresh our state
 			buildState.arbState, err = arbosState.OpenSystemArbosState(buildState.statedb, nil, true)
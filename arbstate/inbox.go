@@ -14,6 +14,7 @@ import (
 	"strings"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/params"
 	"github.com/ethereum/go-ethereum/rlp"
@@ -68,6 +69,45 @@ func NewBlobReaderOverride(base DapReaderSource, blobReader daprovider.Reader) D
 	return &blobReaderOverride{base: base, blobReader: blobReader}
 }
 
+// ArbOSVersionFetcher returns the ArbOS version of the chain before the first
+// message of the sequencer batch being parsed. It's only called for batch
+// formats which depend on the ArbOS version, as it may have to wait for the
+// previous batches to be executed.
+type ArbOSVersionFetcher func() (uint64, error)
+
+// FixedArbOSVersion returns an ArbOSVersionFetcher always returning version.
+func FixedArbOSVersion(version uint64) ArbOSVersionFetcher {
+	return func() (uint64, error) {
+		return version, nil
+	}
+}
+
+// ArbOSVersionBeforeBatch returns the ArbOS version before the first message of
+// a batch, from the header of the block before the message being produced and
+// the number of messages of the batch before it. Headers are only followed
+// back while their version may change how the batch is decoded: as ArbOS
+// versions only increase, a version which is lower than
+// daprovider.ZstdMessageArbOSVersion may be returned instead of an earlier one.
+func ArbOSVersionBeforeBatch(lastHeader *types.Header, posInBatch uint64, genesisBlockNum uint64, headerByHash func(common.Hash) (*types.Header, error)) (uint64, error) {
+	header := lastHeader
+	for remaining := posInBatch; header != nil; remaining-- {
+		version := types.DeserializeHeaderExtraInformation(header).ArbOSFormatVersion
+		if remaining == 0 || version < daprovider.ZstdMessageArbOSVersion {
+			return version, nil
+		}
+		if header.Number.Uint64() <= genesisBlockNum {
+			// The batch starts with the first message
+			return 0, nil
+		}
+		var err error
+		header, err = headerByHash(header.ParentHash)
+		if err != nil {
+			return 0, err
+		}
+	}
+	return 0, nil
+}
+
 // lint:require-exhaustive-initialization
 type SequencerMessage struct {
 	MinTimestamp         uint64
@@ -80,7 +120,7 @@ type SequencerMessage struct {
 
 const MaxSegmentsPerSequencerMessage = 100 * 1024
 
-func ParseSequencerMessage(ctx context.Context, batchNum uint64, batchBlockHash common.Hash, data []byte, dapReaders DapReaderSource, keysetValidationMode daprovider.KeysetValidationMode, chainConfig *params.ChainConfig) (*SequencerMessage, error) {
+func ParseSequencerMessage(ctx context.Context, batchNum uint64, batchBlockHash common.Hash, data []byte, dapReaders DapReaderSource, keysetValidationMode daprovider.KeysetValidationMode, arbOSVersion ArbOSVersionFetcher, chainConfig *params.ChainConfig) (*SequencerMessage, error) {
 	if len(data) < 40 {
 		return nil, errors.New("sequencer message missing L1 header")
 	}
@@ -170,9 +210,27 @@ func ParseSequencerMessage(ctx context.Context, batchNum uint64, batchBlockHash
 		payload = pl
 	}
 
-	// Stage 3: Decompress the brotli payload and fill the parsedMsg.segments list.
-	if len(payload) > 0 && daprovider.IsBrotliMessageHeaderByte(payload[0]) {
-		decompressed, err := arbcompress.Decompress(payload[1:], int(uncompressedBatchSizeLimit)) // #nosec G115
+	// Zstd batches are an unknown format, and so empty, before the ArbOS version introducing them.
+	if len(payload) > 0 && daprovider.IsZstdMessageHeaderByte(payload[0]) {
+		version, err := arbOSVersion()
+		if err != nil {
+			return nil, fmt.Errorf("getting ArbOS version before batch %d: %w", batchNum, err)
+		}
+		if version < daprovider.ZstdMessageArbOSVersion {
+			log.Warn("zstd-compressed sequencer message before ArbOS version supporting it", "batch", batchNum, "arbosVersion", version)
+			return parsedMsg, nil
+		}
+	}
+
+	// Stage 3: Decompress the brotli or zstd payload and fill the parsedMsg.segments list.
+	if len(payload) > 0 && (daprovider.IsBrotliMessageHeaderByte(payload[0]) || daprovider.IsZstdMessageHeaderByte(payload[0])) {
+		var decompressed []byte
+		var err error
+		if daprovider.IsZstdMessageHeaderByte(payload[0]) {
+			decompressed, err = arbcompress.DecompressZstd(payload[1:], int(uncompressedBatchSizeLimit)) // #nosec G115
+		} else {
+			decompressed, err = arbcompress.Decompress(payload[1:], int(uncompressedBatchSizeLimit)) // #nosec G115
+		}
 		if err == nil {
 			reader := bytes.NewReader(decompressed)
 			stream := rlp.NewStream(reader, 0)
@@ -213,6 +271,7 @@ type inboxMultiplexer struct {
 	delayedMessagesRead       uint64
 	chainConfig               *params.ChainConfig
 	dapReaders                DapReaderSource
+	arbOSVersion              ArbOSVersionFetcher
 	cachedSequencerMessage    *SequencerMessage
 	cachedSequencerMessageNum uint64
 	cachedSegmentNum          uint64
@@ -226,12 +285,13 @@ type inboxMultiplexer struct {
 	keysetValidationMode daprovider.KeysetValidationMode
 }
 
-func NewInboxMultiplexer(backend InboxBackend, delayedMessagesRead uint64, dapReaders DapReaderSource, keysetValidationMode daprovider.KeysetValidationMode, chainConfig *params.ChainConfig) arbostypes.InboxMultiplexer {
+func NewInboxMultiplexer(backend InboxBackend, delayedMessagesRead uint64, dapReaders DapReaderSource, keysetValidationMode daprovider.KeysetValidationMode, arbOSVersion ArbOSVersionFetcher, chainConfig *params.ChainConfig) arbostypes.InboxMultiplexer {
 	return &inboxMultiplexer{
 		backend:                   backend,
 		delayedMessagesRead:       delayedMessagesRead,
 		chainConfig:               chainConfig,
 		dapReaders:                dapReaders,
+		arbOSVersion:              arbOSVersion,
 		cachedSequencerMessage:    nil,
 		cachedSequencerMessageNum: 0,
 		cachedSegmentNum:          0,
@@ -260,7 +320,7 @@ func (r *inboxMultiplexer) Pop(ctx context.Context) (*arbostypes.MessageWithMeta
 		r.cachedSequencerMessageNum = r.backend.GetSequencerInboxPosition()
 
 		var err error
-		r.cachedSequencerMessage, err = ParseSequencerMessage(ctx, r.cachedSequencerMessageNum, batchBlockHash, bytes, r.dapReaders, r.keysetValidationMode, r.chainConfig)
+		r.cachedSequencerMessage, err = ParseSequencerMessage(ctx, r.cachedSequencerMessageNum, batchBlockHash, bytes, r.dapReaders, r.keysetValidationMode, r.arbOSVersion, r.chainConfig)
 		if err != nil {
 			return nil, err
 		}
@@ -76,6 +76,7 @@ func FuzzInboxMultiplexer(f *testing.F) {
 			0,
 			nil,
 			daprovider.KeysetValidate,
+			FixedArbOSVersion(daprovider.ZstdMessageArbOSVersion),
 			chaininfo.ArbitrumDevTestChainConfig(),
 		)
 		_, err := multiplexer.Pop(context.TODO())
@@ -4,12 +4,19 @@
 package arbstate
 
 import (
+	"bytes"
 	"context"
 	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
 	"testing"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rlp"
 
+	"github.com/offchainlabs/nitro/arbcompress"
 	"github.com/offchainlabs/nitro/daprovider"
 )
 
@@ -40,7 +47,7 @@ func TestParseSequencerMessage_ShortAnyTrustNoReader(t *testing.T) {
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
 			data := buildSequencerMsg(tc.payload)
-			msg, err := ParseSequencerMessage(ctx, 0, common.Hash{}, data, registry, daprovider.KeysetValidate, nil)
+			msg, err := ParseSequencerMessage(ctx, 0, common.Hash{}, data, registry, daprovider.KeysetValidate, FixedArbOSVersion(daprovider.ZstdMessageArbOSVersion), nil)
 			if err != nil {
 				t.Fatalf("expected no error for short AnyTrust message, got: %v", err)
 			}
@@ -69,7 +76,7 @@ func TestParseSequencerMessage_LongAnyTrustNoReaderErrors(t *testing.T) {
 			payload := append([]byte{tc.header}, make([]byte, 32)...)
 			data := buildSequencerMsg(payload)
 
-			_, err := ParseSequencerMessage(ctx, 0, common.Hash{}, data, registry, daprovider.KeysetValidate, nil)
+			_, err := ParseSequencerMessage(ctx, 0, common.Hash{}, data, registry, daprovider.KeysetValidate, FixedArbOSVersion(daprovider.ZstdMessageArbOSVersion), nil)
 			if err == nil {
 				t.Fatal("expected error for AnyTrust message with no reader configured, got nil")
 			}
@@ -87,7 +94,7 @@ func TestParseSequencerMessage_DACertWithFallbackReader(t *testing.T) {
 	payload := append([]byte{daprovider.DACertificateMessageHeaderFlag}, make([]byte, 64)...)
 	data := buildSequencerMsg(payload)
 
-	msg, err := ParseSequencerMessage(ctx, 0, common.Hash{}, data, registry, daprovider.KeysetValidate, nil)
+	msg, err := ParseSequencerMessage(ctx, 0, common.Hash{}, data, registry, daprovider.KeysetValidate, FixedArbOSVersion(daprovider.ZstdMessageArbOSVersion), nil)
 	if err != nil {
 		t.Fatalf("expected no error with fallback DACert reader, got: %v", err)
 	}
@@ -103,7 +110,7 @@ func TestParseSequencerMessage_DACertNoReaderErrors(t *testing.T) {
 	payload := append([]byte{daprovider.DACertificateMessageHeaderFlag}, make([]byte, 64)...)
 	data := buildSequencerMsg(payload)
 
-	_, err := ParseSequencerMessage(ctx, 0, common.Hash{}, data, registry, daprovider.KeysetValidate, nil)
+	_, err := ParseSequencerMessage(ctx, 0, common.Hash{}, data, registry, daprovider.KeysetValidate, FixedArbOSVersion(daprovider.ZstdMessageArbOSVersion), nil)
 	if err == nil {
 		t.Fatal("expected error for DACert message with no reader configured, got nil")
 	}
@@ -112,7 +119,7 @@ func TestParseSequencerMessage_DACertNoReaderErrors(t *testing.T) {
 func TestParseSequencerMessage_MinimalHeader(t *testing.T) {
 	ctx := context.Background()
 	// Ensure messages shorter than 40 bytes return an error
-	_, err := ParseSequencerMessage(ctx, 0, common.Hash{}, make([]byte, 39), nil, daprovider.KeysetValidate, nil)
+	_, err := ParseSequencerMessage(ctx, 0, common.Hash{}, make([]byte, 39), nil, daprovider.KeysetValidate, FixedArbOSVersion(daprovider.ZstdMessageArbOSVersion), nil)
 	if err == nil {
 		t.Fatal("expected error for message shorter than 40 bytes")
 	}
@@ -122,7 +129,7 @@ func TestParseSequencerMessage_EmptyPayload(t *testing.T) {
 	ctx := context.Background()
 	// Exactly 40 bytes = valid header with empty payload
 	data := make([]byte, 40)
-	msg, err := ParseSequencerMessage(ctx, 0, common.Hash{}, data, nil, daprovider.KeysetValidate, nil)
+	msg, err := ParseSequencerMessage(ctx, 0, common.Hash{}, data, nil, daprovider.KeysetValidate, FixedArbOSVersion(daprovider.ZstdMessageArbOSVersion), nil)
 	if err != nil {
 		t.Fatalf("expected no error for empty payload, got: %v", err)
 	}
@@ -140,7 +147,7 @@ func TestParseSequencerMessage_HeaderFieldParsing(t *testing.T) {
 	binary.BigEndian.PutUint64(data[24:32], 20) // MaxL1Block
 	binary.BigEndian.PutUint64(data[32:40], 5)  // AfterDelayedMessages
 
-	msg, err := ParseSequencerMessage(ctx, 0, common.Hash{}, data, nil, daprovider.KeysetValidate, nil)
+	msg, err := ParseSequencerMessage(ctx, 0, common.Hash{}, data, nil, daprovider.KeysetValidate, FixedArbOSVersion(daprovider.ZstdMessageArbOSVersion), nil)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -160,3 +167,141 @@ func TestParseSequencerMessage_HeaderFieldParsing(t *testing.T) {
 		t.Errorf("AfterDelayedMessages = %d, want 5", msg.AfterDelayedMessages)
 	}
 }
+
+func zstdSequencerMsg(t *testing.T, segments [][]byte) []byte {
+	t.Helper()
+	var encoded []byte
+	for _, segment := range segments {
+		segmentBytes, err := rlp.EncodeToBytes(segment)
+		if err != nil {
+			t.Fatal(err)
+		}
+		encoded = append(encoded, segmentBytes...)
+	}
+	compressed, err := arbcompress.CompressZstd(encoded, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return buildSequencerMsg(append([]byte{daprovider.ZstdMessageHeaderByte}, compressed...))
+}
+
+func TestParseSequencerMessage_Zstd(t *testing.T) {
+	ctx := context.Background()
+	segments := [][]byte{{0x01, 0x02, 0x03}, {}, bytes.Repeat([]byte{0x42}, 1000)}
+	data := zstdSequencerMsg(t, segments)
+	msg, err := ParseSequencerMessage(ctx, 0, common.Hash{}, data, nil, daprovider.KeysetValidate, FixedArbOSVersion(daprovider.ZstdMessageArbOSVersion), nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(msg.Segments) != len(segments) {
+		t.Fatalf("got %d segments, want %d", len(msg.Segments), len(segments))
+	}
+	for i, segment := range segments {
+		if !bytes.Equal(msg.Segments[i], segment) {
+			t.Errorf("segment %d = %x, want %x", i, msg.Segments[i], segment)
+		}
+	}
+}
+
+func TestParseSequencerMessage_ZstdBeforeArbOSVersion(t *testing.T) {
+	ctx := context.Background()
+	data := zstdSequencerMsg(t, [][]byte{{0x01, 0x02, 0x03}})
+	msg, err := ParseSequencerMessage(ctx, 0, common.Hash{}, data, nil, daprovider.KeysetValidate, FixedArbOSVersion(daprovider.ZstdMessageArbOSVersion-1), nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(msg.Segments) != 0 {
+		t.Fatalf("got %d segments before ArbOS %d, want an empty batch", len(msg.Segments), daprovider.ZstdMessageArbOSVersion)
+	}
+}
+
+func TestParseSequencerMessage_ArbOSVersionOnlyFetchedForZstd(t *testing.T) {
+	ctx := context.Background()
+	fetchErr := errors.New("ArbOS version not known")
+	failingFetcher := func() (uint64, error) {
+		return 0, fetchErr
+	}
+	if _, err := ParseSequencerMessage(ctx, 0, common.Hash{}, make([]byte, 40), nil, daprovider.KeysetValidate, failingFetcher, nil); err != nil {
+		t.Fatalf("unexpected error for a batch not depending on the ArbOS version: %v", err)
+	}
+	data := zstdSequencerMsg(t, [][]byte{{0x01}})
+	if _, err := ParseSequencerMessage(ctx, 0, common.Hash{}, data, nil, daprovider.KeysetValidate, failingFetcher, nil); !errors.Is(err, fetchErr) {
+		t.Fatalf("got error %v, want %v", err, fetchErr)
+	}
+}
+
+// testHeaderChain returns a header per message, the ArbOS version after each
+// message given by versions.
+func testHeaderChain(versions []uint64) ([]*types.Header, map[common.Hash]*types.Header) {
+	headers := make([]*types.Header, 0, len(versions))
+	byHash := make(map[common.Hash]*types.Header)
+	var parentHash common.Hash
+	for i, version := range versions {
+		header := &types.Header{
+			ParentHash: parentHash,
+			Number:     big.NewInt(int64(i)),
+			Difficulty: big.NewInt(1),
+			BaseFee:    big.NewInt(1),
+		}
+		types.HeaderInfo{ArbOSFormatVersion: version}.UpdateHeaderWithInfo(header)
+		parentHash = header.Hash()
+		headers = append(headers, header)
+		byHash[parentHash] = header
+	}
+	return headers, byHash
+}
+
+func TestArbOSVersionBeforeBatch_UpgradeInsideBatch(t *testing.T) {
+	ctx := context.Background()
+	before := daprovider.ZstdMessageArbOSVersion - 1
+	after := daprovider.ZstdMessageArbOSVersion
+	// The chain is upgraded by message 5, inside the batch of messages 3 to 8
+	versions := []uint64{before, before, before, before, before, after, after, after, after, after, after, after}
+	headers, byHash := testHeaderChain(versions)
+	headerByHash := func(hash common.Hash) (*types.Header, error) {
+		header, ok := byHash[hash]
+		if !ok {
+			return nil, fmt.Errorf("header %v not found", hash)
+		}
+		return header, nil
+	}
+	data := zstdSequencerMsg(t, [][]byte{{0x01, 0x02, 0x03}})
+
+	checkBatch := func(batchStart, batchEnd int, want uint64, wantSegments int) {
+		t.Helper()
+		for msgIdx := batchStart; msgIdx <= batchEnd; msgIdx++ {
+			// #nosec G115
+			posInBatch := uint64(msgIdx - batchStart)
+			version, err := ArbOSVersionBeforeBatch(headers[msgIdx-1], posInBatch, 0, headerByHash)
+			if err != nil {
+				t.Fatalf("message %d: unexpected error: %v", msgIdx, err)
+			}
+			if version != want {
+				t.Fatalf("message %d: got ArbOS version %d, want %d", msgIdx, version, want)
+			}
+			// The batch is decoded the same way at every message
+			msg, err := ParseSequencerMessage(ctx, 0, common.Hash{}, data, nil, daprovider.KeysetValidate, FixedArbOSVersion(version), nil)
+			if err != nil {
+				t.Fatalf("message %d: unexpected error: %v", msgIdx, err)
+			}
+			if len(msg.Segments) != wantSegments {
+				t.Fatalf("message %d: got %d segments, want %d", msgIdx, len(msg.Segments), wantSegments)
+			}
+		}
+	}
+	checkBatch(3, 8, before, 0)
+	checkBatch(9, 11, after, 1)
+
+	// A batch starting with the first message has no version before it
+	headers, byHash = testHeaderChain([]uint64{after, after, after})
+	for msgIdx := 1; msgIdx < len(headers); msgIdx++ {
+		// #nosec G115
+		version, err := ArbOSVersionBeforeBatch(headers[msgIdx-1], uint64(msgIdx), 0, headerByHash)
+		if err != nil {
+			t.Fatalf("message %d: unexpected error: %v", msgIdx, err)
+		}
+		if version != 0 {
+			t.Fatalf("message %d: got ArbOS version %d, want 0", msgIdx, version)
+		}
+	}
+}
@@ -6,7 +6,6 @@ package broadcastclient
 import (
 	"context"
 	"crypto/tls"
-	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -23,6 +22,7 @@ import (
 	"github.com/gobwas/ws/wsflate"
 	"github.com/spf13/pflag"
 
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/metrics"
 
@@ -47,7 +47,10 @@ type FeedConfig struct {
 }
 
 func (fc *FeedConfig) Validate() error {
-	return fc.Output.Validate()
+	if err := fc.Output.Validate(); err != nil {
+		return err
+	}
+	return fc.Input.Validate()
 }
 
 func FeedConfigAddOptions(prefix string, f *pflag.FlagSet, feedInputEnable bool, feedOutputEnable bool) {
@@ -74,12 +77,19 @@ type Config struct {
 	SecondaryURL            []string                 `koanf:"secondary-url"`
 	Verify                  signature.VerifierConfig `koanf:"verify"`
 	EnableCompression       bool                     `koanf:"enable-compression" reload:"hot"`
+	RequestBinaryEncoding   bool                     `koanf:"request-binary-encoding" reload:"hot"`
+	SigningKeys             []string                 `koanf:"signing-keys"`
 }
 
 func (c *Config) Enable() bool {
 	return len(c.URL) > 0 && c.URL[0] != ""
 }
 
+func (c *Config) Validate() error {
+	_, err := parseKeySchedule(c.SigningKeys)
+	return err
+}
+
 type ConfigFetcher func() *Config
 
 func ConfigAddOptions(prefix string, f *pflag.FlagSet) {
@@ -92,6 +102,8 @@ func ConfigAddOptions(prefix string, f *pflag.FlagSet) {
 	f.StringSlice(prefix+".secondary-url", DefaultConfig.SecondaryURL, "list of secondary URLs of sequencer feed source. Would be started in the order they appear in the list when primary feeds fails")
 	signature.FeedVerifierConfigAddOptions(prefix+".verify", f)
 	f.Bool(prefix+".enable-compression", DefaultConfig.EnableCompression, "enable per message deflate compression support")
+	f.Bool(prefix+".request-binary-encoding", DefaultConfig.RequestBinaryEncoding, "ask the feed server for the compact binary encoding of feed messages, falling back to json if unsupported")
+	f.StringSlice(prefix+".signing-keys", DefaultConfig.SigningKeys, "list of feed signing keys formatted as <address>@<activation sequence number>; messages from the activation on must be signed by that key, later keys can be announced in the feed")
 }
 
 var DefaultConfig = Config{
@@ -104,6 +116,8 @@ var DefaultConfig = Config{
 	SecondaryURL:            []string{},
 	Timeout:                 20 * time.Second,
 	EnableCompression:       true,
+	RequestBinaryEncoding:   false,
+	SigningKeys:             []string{},
 }
 
 var DefaultTestConfig = Config{
@@ -116,12 +130,20 @@ var DefaultTestConfig = Config{
 	SecondaryURL:            []string{},
 	Timeout:                 200 * time.Millisecond,
 	EnableCompression:       true,
+	RequestBinaryEncoding:   false,
+	SigningKeys:             []string{},
 }
 
 type TransactionStreamerInterface interface {
 	AddBroadcastMessages(feedMessages []*message.BroadcastFeedMessage) error
 }
 
+// KeyRotationListener can be implemented by a TransactionStreamerInterface to
+// be notified of verified feed key rotations, e.g. to forward them.
+type KeyRotationListener interface {
+	AddKeyRotation(rotation *message.KeyRotationMessage) error
+}
+
 type BroadcastClient struct {
 	stopwaiter.StopWaiter
 
@@ -129,6 +151,7 @@ type BroadcastClient struct {
 	websocketUrl string
 	nextSeqNum   arbutil.MessageIndex
 	sigVerifier  *signature.Verifier
+	keySchedule  *keySchedule
 
 	chainId uint64
 
@@ -146,6 +169,7 @@ type BroadcastClient struct {
 	txStreamer                      TransactionStreamerInterface
 	fatalErrChan                    chan error
 	adjustCount                     func(int32)
+	filter                          *message.Filter
 }
 
 var ErrIncorrectFeedServerVersion = errors.New("incorrect feed server version")
@@ -168,6 +192,10 @@ func NewBroadcastClient(
 	if err != nil {
 		return nil, err
 	}
+	keySchedule, err := parseKeySchedule(config().SigningKeys)
+	if err != nil {
+		return nil, err
+	}
 	return &BroadcastClient{
 		config:                          config,
 		websocketUrl:                    websocketUrl,
@@ -177,11 +205,19 @@ func NewBroadcastClient(
 		confirmedSequenceNumberListener: confirmedSequencerNumberListener,
 		fatalErrChan:                    fatalErrChan,
 		sigVerifier:                     sigVerifier,
+		keySchedule:                     keySchedule,
 		adjustCount:                     adjustCount,
 		firstReconnectAttempt:           true,
 	}, err
 }
 
+// SetFilter subscribes to only the messages matching the filter, plus
+// confirmations. The transaction streamer then receives a stream with gaps,
+// so this is only meant for monitoring and must be called before Start.
+func (bc *BroadcastClient) SetFilter(filter *message.Filter) {
+	bc.filter = filter
+}
+
 func (bc *BroadcastClient) Start(ctxIn context.Context) {
 	bc.StopWaiter.Start(ctxIn, bc)
 	if bc.StopWaiter.Stopped() {
@@ -227,76 +263,72 @@ func (bc *BroadcastClient) connect(ctx context.Context, nextSeqNum arbutil.Messa
 		return nil, nil
 	}
 
-	header := ws.HandshakeHeaderHTTP(http.Header{
+	config := bc.config()
+	httpHeader := http.Header{
 		wsbroadcastserver.HTTPHeaderFeedClientVersion:       []string{strconv.Itoa(wsbroadcastserver.FeedClientVersion)},
 		wsbroadcastserver.HTTPHeaderRequestedSequenceNumber: []string{strconv.FormatUint(uint64(nextSeqNum), 10)},
-	})
+	}
+	if config.RequestBinaryEncoding {
+		httpHeader[wsbroadcastserver.HTTPHeaderFeedEncoding] = []string{message.EncodingNameBinary + "," + message.EncodingNameJSON}
+	}
+	wsbroadcastserver.AddFilterHTTPHeaders(httpHeader, bc.filter)
+	header := ws.HandshakeHeaderHTTP(httpHeader)
 
 	log.Info("connecting to arbitrum inbox message broadcaster", "url", bc.websocketUrl)
 	var foundChainId bool
 	var foundFeedServerVersion bool
 	var chainId uint64
 	var feedServerVersion uint64
+	encoding := message.EncodingJSON
 
-	config := bc.config()
 	var extensions []httphead.Option
 	deflateExt := wsflate.DefaultParameters.Option()
 	if config.EnableCompression {
 		extensions = []httphead.Option{deflateExt}
 	}
-	timeoutDialer := ws.Dialer{
-		Header: header,
-		OnHeader: func(key, value []byte) (err error) {
-			headerName := string(key)
-			headerValue := string(value)
-			if headerName == wsbroadcastserver.HTTPHeaderFeedServerVersion {
-				foundFeedServerVersion = true
-				feedServerVersion, err = strconv.ParseUint(headerValue, 0, 64)
-				if err != nil {
-					return err
-				}
-				if feedServerVersion != wsbroadcastserver.FeedServerVersion {
-					log.Error(
-						"incorrect feed server version",
-						"expectedFeedServerVersion",
-						wsbroadcastserver.FeedServerVersion,
-						"actualFeedServerVersion",
-						feedServerVersion,
-					)
-					return ErrIncorrectFeedServerVersion
-				}
-			} else if headerName == wsbroadcastserver.HTTPHeaderChainId {
-				foundChainId = true
-				chainId, err = strconv.ParseUint(headerValue, 0, 64)
-				if err != nil {
-					return err
-				}
-				if chainId != bc.chainId {
-					log.Error(
-						"incorrect chain id when connecting to server feed",
-						"expectedChainId",
-						bc.chainId,
-						"actualChainId",
-						chainId,
-					)
-					return ErrIncorrectChainId
-				}
+	onHeader := func(key, value []byte) (err error) {
+		headerName := string(key)
+		headerValue := string(value)
+		if headerName == wsbroadcastserver.HTTPHeaderFeedServerVersion {
+			foundFeedServerVersion = true
+			feedServerVersion, err = strconv.ParseUint(headerValue, 0, 64)
+			if err != nil {
+				return err
 			}
-			return nil
-		},
-		Timeout: 10 * time.Second,
-		TLSConfig: &tls.Config{
-			MinVersion: tls.VersionTLS12,
-		},
-		Extensions: extensions,
-		NetDial: func(ctx context.Context, network, addr string) (net.Conn, error) {
-			var netDialer net.Dialer
-			if network == "tcp" {
-				return netDialer.DialContext(ctx, "tcp4", addr)
+			if feedServerVersion != wsbroadcastserver.FeedServerVersion {
+				log.Error(
+					"incorrect feed server version",
+					"expectedFeedServerVersion",
+					wsbroadcastserver.FeedServerVersion,
+					"actualFeedServerVersion",
+					feedServerVersion,
+				)
+				return ErrIncorrectFeedServerVersion
 			}
-			return netDialer.DialContext(ctx, network, addr)
-		},
+		} else if headerName == wsbroadcastserver.HTTPHeaderChainId {
+			foundChainId = true
+			chainId, err = strconv.ParseUint(headerValue, 0, 64)
+			if err != nil {
+				return err
+			}
+			if chainId != bc.chainId {
+				log.Error(
+					"incorrect chain id when connecting to server feed",
+					"expectedChainId",
+					bc.chainId,
+					"actualChainId",
+					chainId,
+				)
+				return ErrIncorrectChainId
+			}
+		} else if headerName == wsbroadcastserver.HTTPHeaderFeedEncoding {
+			if encodings := message.ParseEncodings(headerValue); len(encodings) > 0 {
+				encoding = encodings[0]
+			}
+		}
+		return nil
 	}
+	timeoutDialer := newFeedDialer(header, onHeader, extensions)
 
 	if bc.isShuttingDown() {
 		return nil, nil
@@ -363,11 +395,31 @@ func (bc *BroadcastClient) connect(ctx context.Context, nextSeqNum arbutil.Messa
 	bc.compression = compressionNegotiated
 	bc.firstReconnectAttempt = true
 	bc.connMutex.Unlock()
-	log.Info("Feed connected", "feedServerVersion", feedServerVersion, "chainId", chainId, "requestedSeqNum", nextSeqNum)
+	log.Info("Feed connected", "feedServerVersion", feedServerVersion, "chainId", chainId, "requestedSeqNum", nextSeqNum, "encoding", encoding)
 
 	return earlyFrameData, nil
 }
 
+// newFeedDialer creates the websocket dialer used to connect to feed servers.
+func newFeedDialer(header ws.HandshakeHeader, onHeader func(key, value []byte) error, extensions []httphead.Option) ws.Dialer {
+	return ws.Dialer{
+		Header:   header,
+		OnHeader: onHeader,
+		Timeout:  10 * time.Second,
+		TLSConfig: &tls.Config{
+			MinVersion: tls.VersionTLS12,
+		},
+		Extensions: extensions,
+		NetDial: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			var netDialer net.Dialer
+			if network == "tcp" {
+				return netDialer.DialContext(ctx, "tcp4", addr)
+			}
+			return netDialer.DialContext(ctx, network, addr)
+		},
+	}
+}
+
 func (bc *BroadcastClient) startBackgroundReader(earlyFrameData io.Reader) {
 	bc.LaunchThread(func(ctx context.Context) {
 		connected := false
@@ -437,10 +489,15 @@ func (bc *BroadcastClient) startBackgroundReader(earlyFrameData io.Reader) {
 			backoffDuration = bc.config().ReconnectInitialBackoff
 
 			if msg != nil {
-				res := message.BroadcastMessage{}
-				err = json.Unmarshal(msg, &res)
+				// The frame type tells which encoding the server used, so both
+				// encodings are accepted regardless of what was negotiated.
+				encoding := message.EncodingJSON
+				if op == ws.OpBinary {
+					encoding = message.EncodingBinary
+				}
+				res, err := message.DecodeBroadcastMessage(msg, encoding)
 				if err != nil {
-					log.Error("error unmarshalling message", "msg", msg, "err", err)
+					log.Error("error unmarshalling message", "msg", msg, "encoding", encoding, "err", err)
 					continue
 				}
 
@@ -488,6 +545,15 @@ func (bc *BroadcastClient) startBackgroundReader(earlyFrameData io.Reader) {
 							log.Error("Error adding message from Sequencer Feed", "err", err)
 						}
 					}
+					if res.KeyRotationMessage != nil {
+						if err := bc.handleKeyRotation(ctx, res.KeyRotationMessage); err != nil {
+							log.Error("error handling feed key rotation", "err", err)
+						} else if listener, ok := bc.txStreamer.(KeyRotationListener); ok {
+							if err := listener.AddKeyRotation(res.KeyRotationMessage); err != nil {
+								log.Error("error forwarding feed key rotation", "err", err)
+							}
+						}
+					}
 					if res.ConfirmedSequenceNumberMessage != nil && bc.confirmedSequenceNumberListener != nil {
 						select {
 						case bc.confirmedSequenceNumberListener <- res.ConfirmedSequenceNumberMessage.SequenceNumber:
@@ -554,10 +620,24 @@ func (bc *BroadcastClient) StopAndWait() {
 }
 
 func (bc *BroadcastClient) isValidSignature(ctx context.Context, message *message.BroadcastFeedMessage) error {
-	if bc.config().Verify.Dangerous.AcceptMissing && bc.sigVerifier == nil {
+	config := bc.config()
+	if config.Verify.Dangerous.AcceptMissing && bc.sigVerifier == nil {
 		// Verifier disabled
 		return nil
 	}
 	hash := message.SignatureHash(bc.chainId)
+	if scheduledSigner, ok := bc.keySchedule.signerAt(message.SequenceNumber); ok {
+		// Once a key is scheduled for the message, no other key is accepted
+		// unless signatures are not enforced.
+		if len(message.Signature) > 0 {
+			sigPublicKey, err := crypto.SigToPub(hash.Bytes(), message.Signature)
+			if err == nil && crypto.PubkeyToAddress(*sigPublicKey) == scheduledSigner {
+				return nil
+			}
+		}
+		if !config.Verify.Dangerous.AcceptMissing {
+			return signature.ErrSignerNotApproved
+		}
+	}
 	return bc.sigVerifier.VerifyHash(ctx, message.Signature, hash)
 }
@@ -62,7 +62,24 @@ func TestReceiveMessages(t *testing.T) {
 	})
 }
 
+func TestReceiveMessagesBinaryEncoding(t *testing.T) {
+	t.Parallel()
+	t.Run("withoutCompression", func(t *testing.T) {
+		testReceiveMessagesWithEncoding(t, false, false, false, false, true, true)
+	})
+	t.Run("withCompression", func(t *testing.T) {
+		testReceiveMessagesWithEncoding(t, true, true, false, false, true, true)
+	})
+	t.Run("withServerBinaryEncodingDisabled", func(t *testing.T) {
+		testReceiveMessagesWithEncoding(t, true, true, false, false, true, false)
+	})
+}
+
 func testReceiveMessages(t *testing.T, clientCompression bool, serverCompression bool, serverRequire bool, expectNoMessagesReceived bool) {
+	testReceiveMessagesWithEncoding(t, clientCompression, serverCompression, serverRequire, expectNoMessagesReceived, false, true)
+}
+
+func testReceiveMessagesWithEncoding(t *testing.T, clientCompression bool, serverCompression bool, serverRequire bool, expectNoMessagesReceived bool, clientBinaryEncoding bool, serverBinaryEncoding bool) {
 	t.Parallel()
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -70,6 +87,7 @@ func testReceiveMessages(t *testing.T, clientCompression bool, serverCompression
 	broadcasterConfig := wsbroadcastserver.DefaultTestBroadcasterConfig
 	broadcasterConfig.EnableCompression = serverCompression
 	broadcasterConfig.RequireCompression = serverRequire
+	broadcasterConfig.EnableBinaryEncoding = serverBinaryEncoding
 
 	messageCount := 1000
 	clientCount := 2
@@ -89,6 +107,7 @@ func testReceiveMessages(t *testing.T, clientCompression bool, serverCompression
 
 	config := DefaultTestConfig
 	config.EnableCompression = clientCompression
+	config.RequestBinaryEncoding = clientBinaryEncoding
 	var wg sync.WaitGroup
 	var expectedCount int
 	if expectNoMessagesReceived {
@@ -937,3 +956,337 @@ func Require(t *testing.T, err error, printables ...interface{}) {
 	t.Helper()
 	testhelpers.RequireImpl(t, err, printables...)
 }
+
+func TestBroadcastClientFetchRange(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	config := wsbroadcastserver.DefaultTestBroadcasterConfig
+	config.MaxReplayRange = 5
+
+	privateKey, err := crypto.GenerateKey()
+	Require(t, err)
+	sequencerAddr := crypto.PubkeyToAddress(privateKey.PublicKey)
+	dataSigner := signature.DataSignerFromPrivateKey(privateKey)
+
+	chainId := uint64(9742)
+	feedErrChan := make(chan error, 10)
+	b := broadcaster.NewBroadcaster(func() *wsbroadcastserver.BroadcasterConfig { return &config }, chainId, feedErrChan, dataSigner)
+	Require(t, b.Initialize())
+	Require(t, b.Start(ctx))
+	defer b.StopAndWait()
+
+	for i := arbutil.MessageIndex(0); i < 10; i++ {
+		Require(t, b.BroadcastFeedMessages(feedMessage(t, b, i)))
+	}
+	for start := time.Now(); b.GetCachedMessageCount() < 10; time.Sleep(10 * time.Millisecond) {
+		if time.Since(start) > 5*time.Second {
+			t.Fatal("messages were not added to the backlog")
+		}
+	}
+
+	for _, binaryEncoding := range []bool{false, true} {
+		clientConfig := DefaultTestConfig
+		clientConfig.RequestBinaryEncoding = binaryEncoding
+		broadcastClient, err := newTestBroadcastClient(clientConfig, b.ListenerAddr(), chainId, 0, newDummyTransactionStreamer(chainId, nil), nil, feedErrChan, &sequencerAddr, t)
+		Require(t, err)
+
+		msgs, err := broadcastClient.FetchRange(ctx, 3, 7)
+		Require(t, err)
+		if len(msgs) != 5 {
+			t.Fatalf("expected 5 replayed messages, got %d", len(msgs))
+		}
+		for i, msg := range msgs {
+			// #nosec G115
+			if msg.SequenceNumber != arbutil.MessageIndex(3+i) {
+				t.Errorf("unexpected sequence number %d at index %d", msg.SequenceNumber, i)
+			}
+		}
+
+		if _, err := broadcastClient.FetchRange(ctx, 2, 7); err == nil {
+			t.Error("expected error when exceeding the maximum replay range")
+		}
+		if _, err := broadcastClient.FetchRange(ctx, 8, 12); err == nil {
+			t.Error("expected error when requesting messages which are not available")
+		}
+	}
+}
+
+func TestBroadcastClientFilter(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	config := wsbroadcastserver.DefaultTestBroadcasterConfig
+	privateKey, err := crypto.GenerateKey()
+	Require(t, err)
+	sequencerAddr := crypto.PubkeyToAddress(privateKey.PublicKey)
+	dataSigner := signature.DataSignerFromPrivateKey(privateKey)
+
+	chainId := uint64(9742)
+	feedErrChan := make(chan error, 10)
+	b := broadcaster.NewBroadcaster(func() *wsbroadcastserver.BroadcasterConfig { return &config }, chainId, feedErrChan, dataSigner)
+	Require(t, b.Initialize())
+	Require(t, b.Start(ctx))
+	defer b.StopAndWait()
+
+	// Messages with an even sequence number are L2 messages, the others deposits
+	broadcastKind := func(seqNum arbutil.MessageIndex) {
+		kind := uint8(arbostypes.L1MessageType_L2Message)
+		if seqNum%2 == 1 {
+			kind = arbostypes.L1MessageType_EthDeposit
+		}
+		msg := arbostypes.MessageWithMetadataAndBlockInfo{
+			MessageWithMeta: arbostypes.MessageWithMetadata{
+				Message: &arbostypes.L1IncomingMessage{Header: &arbostypes.L1IncomingMessageHeader{Kind: kind}},
+			},
+		}
+		broadcastMsg, err := b.NewBroadcastFeedMessage(msg, seqNum)
+		Require(t, err)
+		Require(t, b.BroadcastFeedMessages([]*message.BroadcastFeedMessage{broadcastMsg}))
+	}
+
+	// The first messages are sent from the backlog, the others live
+	for i := arbutil.MessageIndex(0); i < 4; i++ {
+		broadcastKind(i)
+	}
+	for start := time.Now(); b.GetCachedMessageCount() < 4; time.Sleep(10 * time.Millisecond) {
+		if time.Since(start) > 5*time.Second {
+			t.Fatal("messages were not added to the backlog")
+		}
+	}
+
+	ts := newDummyTransactionStreamer(chainId, nil)
+	confirmedSequenceNumberListener := make(chan arbutil.MessageIndex, 10)
+	broadcastClient, err := newTestBroadcastClient(DefaultTestConfig, b.ListenerAddr(), chainId, 0, ts, confirmedSequenceNumberListener, feedErrChan, &sequencerAddr, t)
+	Require(t, err)
+	broadcastClient.SetFilter(&message.Filter{Kinds: []uint8{arbostypes.L1MessageType_L2Message}})
+	broadcastClient.Start(ctx)
+	defer broadcastClient.StopAndWait()
+
+	for start := time.Now(); b.ClientCount() < 1; time.Sleep(10 * time.Millisecond) {
+		if time.Since(start) > 5*time.Second {
+			t.Fatal("client did not connect")
+		}
+	}
+	for i := arbutil.MessageIndex(4); i < 10; i++ {
+		broadcastKind(i)
+	}
+	b.Confirm(5)
+
+	timer := time.NewTimer(10 * time.Second)
+	defer timer.Stop()
+	for expected := arbutil.MessageIndex(0); expected < 10; expected += 2 {
+		select {
+		case msg := <-ts.messageReceiver:
+			if msg.SequenceNumber != expected {
+				t.Fatalf("expected message %d, got %d", expected, msg.SequenceNumber)
+			}
+		case err := <-feedErrChan:
+			t.Fatal(err)
+		case <-timer.C:
+			t.Fatalf("timed out waiting for message %d", expected)
+		}
+	}
+	select {
+	case confirmed := <-confirmedSequenceNumberListener:
+		if confirmed != 5 {
+			t.Fatalf("expected confirmation of 5, got %d", confirmed)
+		}
+	case <-timer.C:
+		t.Fatal("timed out waiting for confirmation")
+	}
+}
+
+func TestKeySchedule(t *testing.T) {
+	t.Parallel()
+	first := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	second := common.HexToAddress("0x2222222222222222222222222222222222222222")
+	schedule, err := parseKeySchedule([]string{second.Hex() + "@100", first.Hex()})
+	Require(t, err)
+	for _, tc := range []struct {
+		seqNum   arbutil.MessageIndex
+		expected common.Address
+	}{
+		{0, first},
+		{99, first},
+		{100, second},
+		{1000, second},
+	} {
+		signer, ok := schedule.signerAt(tc.seqNum)
+		if !ok || signer != tc.expected {
+			t.Errorf("expected signer %v at %d, got %v", tc.expected, tc.seqNum, signer)
+		}
+	}
+	if err := schedule.add(signingKey{first, 100}); err == nil {
+		t.Error("expected error adding conflicting signing key")
+	}
+	Require(t, schedule.add(signingKey{second, 100}))
+
+	empty, err := parseKeySchedule(nil)
+	Require(t, err)
+	if _, ok := empty.signerAt(0); ok {
+		t.Error("expected no signer in empty schedule")
+	}
+	if _, err := parseKeySchedule([]string{"0x1234@1"}); err == nil {
+		t.Error("expected error parsing invalid address")
+	}
+	if _, err := parseKeySchedule([]string{first.Hex() + "@abc"}); err == nil {
+		t.Error("expected error parsing invalid activation")
+	}
+}
+
+func TestBroadcastClientKeyRotation(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	config := wsbroadcastserver.DefaultTestBroadcasterConfig
+	oldKey, err := crypto.GenerateKey()
+	Require(t, err)
+	oldAddr := crypto.PubkeyToAddress(oldKey.PublicKey)
+	newKey, err := crypto.GenerateKey()
+	Require(t, err)
+	newAddr := crypto.PubkeyToAddress(newKey.PublicKey)
+
+	chainId := uint64(9742)
+	feedErrChan := make(chan error, 10)
+	b := broadcaster.NewBroadcaster(func() *wsbroadcastserver.BroadcasterConfig { return &config }, chainId, feedErrChan, signature.DataSignerFromPrivateKey(oldKey))
+	Require(t, b.Initialize())
+	Require(t, b.Start(ctx))
+	defer b.StopAndWait()
+
+	for i := arbutil.MessageIndex(0); i < 5; i++ {
+		Require(t, b.BroadcastFeedMessages(feedMessage(t, b, i)))
+	}
+	Require(t, b.RotateDataSigner(signature.DataSignerFromPrivateKey(newKey), newAddr, 5))
+	for i := arbutil.MessageIndex(5); i < 10; i++ {
+		Require(t, b.BroadcastFeedMessages(feedMessage(t, b, i)))
+	}
+	for start := time.Now(); b.GetCachedMessageCount() < 10; time.Sleep(10 * time.Millisecond) {
+		if time.Since(start) > 5*time.Second {
+			t.Fatal("messages were not added to the backlog")
+		}
+	}
+
+	// The client only knows the old key, the new key is announced in the feed
+	clientConfig := DefaultTestConfig
+	clientConfig.Verify = signature.TestingFeedVerifierConfig
+	clientConfig.SigningKeys = []string{oldAddr.Hex()}
+	ts := newDummyTransactionStreamer(chainId, nil)
+	broadcastClient, err := newTestBroadcastClient(clientConfig, b.ListenerAddr(), chainId, 0, ts, nil, feedErrChan, nil, t)
+	Require(t, err)
+	broadcastClient.Start(ctx)
+	defer broadcastClient.StopAndWait()
+
+	timer := time.NewTimer(10 * time.Second)
+	defer timer.Stop()
+	for expected := arbutil.MessageIndex(0); expected < 10; expected++ {
+		select {
+		case msg := <-ts.messageReceiver:
+			if msg.SequenceNumber != expected {
+				t.Fatalf("expected message %d, got %d", expected, msg.SequenceNumber)
+			}
+		case err := <-feedErrChan:
+			t.Fatal(err)
+		case <-timer.C:
+			t.Fatalf("timed out waiting for message %d", expected)
+		}
+	}
+	if signer, ok := broadcastClient.keySchedule.signerAt(5); !ok || signer != newAddr {
+		t.Fatalf("expected new signer %v to be scheduled, got %v", newAddr, signer)
+	}
+
+	// Messages after the activation signed by the old key are rejected
+	oldSigned := feedMessage(t, b, 4)[0]
+	oldSigned.SequenceNumber = 10
+	oldSigned.Signature, err = signature.DataSignerFromPrivateKey(oldKey)(oldSigned.SignatureHash(chainId).Bytes())
+	Require(t, err)
+	if err := broadcastClient.isValidSignature(ctx, oldSigned); !errors.Is(err, signature.ErrSignerNotApproved) {
+		t.Fatalf("expected message signed by the rotated key to be rejected, got %v", err)
+	}
+}
+
+func TestFeedAdminClients(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	config := wsbroadcastserver.DefaultTestBroadcasterConfig
+
+	privateKey, err := crypto.GenerateKey()
+	Require(t, err)
+	sequencerAddr := crypto.PubkeyToAddress(privateKey.PublicKey)
+	dataSigner := signature.DataSignerFromPrivateKey(privateKey)
+
+	chainId := uint64(8742)
+	feedErrChan := make(chan error, 10)
+	b := broadcaster.NewBroadcaster(func() *wsbroadcastserver.BroadcasterConfig { return &config }, chainId, feedErrChan, dataSigner)
+
+	Require(t, b.Initialize())
+	Require(t, b.Start(ctx))
+	defer b.StopAndWait()
+
+	ts := newDummyTransactionStreamer(chainId, nil)
+	broadcastClient, err := newTestBroadcastClient(
+		DefaultTestConfig,
+		b.ListenerAddr(),
+		chainId,
+		0,
+		ts,
+		nil,
+		feedErrChan,
+		&sequencerAddr,
+		t,
+	)
+	Require(t, err)
+	broadcastClient.Start(ctx)
+	defer broadcastClient.StopAndWait()
+
+	for i := 0; i < 3; i++ {
+		Require(t, b.BroadcastFeedMessages(feedMessage(t, b, arbutil.MessageIndex(i))))
+		select {
+		case err := <-feedErrChan:
+			t.Fatalf("Broadcaster error: %s", err.Error())
+		case <-ts.messageReceiver:
+		case <-time.After(5 * time.Second):
+			t.Fatal("Client did not receive batch item")
+		}
+	}
+
+	api := broadcaster.NewFeedAdminAPI(b)
+	var clients []wsbroadcastserver.ClientInfo
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		clients, err = api.Clients(ctx)
+		Require(t, err)
+		if len(clients) == 1 && clients[0].DeliveredSeqNum != nil && *clients[0].DeliveredSeqNum == 2 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("unexpected feed clients %+v", clients)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if clients[0].Lag != 0 {
+		t.Errorf("expected no lag, got %d", clients[0].Lag)
+	}
+	if clients[0].IP == "" {
+		t.Errorf("unexpected client info %+v", clients[0])
+	}
+
+	if err := api.DisconnectClient(ctx, "unknown"); !errors.Is(err, wsbroadcastserver.ErrClientNotFound) {
+		t.Fatalf("expected ErrClientNotFound, got %v", err)
+	}
+	name := clients[0].Name
+	Require(t, api.DisconnectClient(ctx, name))
+	// The client reconnects, but from a different address
+	clients, err = api.Clients(ctx)
+	Require(t, err)
+	for _, client := range clients {
+		if client.Name == name {
+			t.Fatalf("client was not disconnected: %+v", client)
+		}
+	}
+}
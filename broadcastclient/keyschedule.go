@@ -0,0 +1,123 @@
+// Copyright 2026, Offchain Labs, Inc.
+// For license information, see https://github.com/OffchainLabs/nitro/blob/master/LICENSE.md
+
+package broadcastclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/offchainlabs/nitro/arbutil"
+	"github.com/offchainlabs/nitro/broadcaster/message"
+	"github.com/offchainlabs/nitro/util/signature"
+)
+
+// signingKey is a feed signing key which signs all messages from its
+// activation sequence number until the activation of the next key.
+type signingKey struct {
+	address    common.Address
+	activation arbutil.MessageIndex
+}
+
+// keySchedule holds the feed signing keys, ordered by activation sequence
+// number. It is initialized from the configuration and extended by key
+// rotation messages received in the feed.
+type keySchedule struct {
+	mutex sync.RWMutex
+	keys  []signingKey
+}
+
+// parseKeySchedule parses a list of keys formatted as
+// <address>@<activation sequence number>.
+func parseKeySchedule(entries []string) (*keySchedule, error) {
+	schedule := &keySchedule{}
+	for _, entry := range entries {
+		address, activation, found := strings.Cut(strings.TrimSpace(entry), "@")
+		if !found {
+			activation = "0"
+		}
+		if !common.IsHexAddress(address) {
+			return nil, fmt.Errorf("invalid feed signing key address %q", address)
+		}
+		seqNum, err := strconv.ParseUint(activation, 0, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid activation sequence number of feed signing key %q: %w", entry, err)
+		}
+		if err := schedule.add(signingKey{common.HexToAddress(address), arbutil.MessageIndex(seqNum)}); err != nil {
+			return nil, err
+		}
+	}
+	return schedule, nil
+}
+
+func (s *keySchedule) add(key signingKey) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	i := sort.Search(len(s.keys), func(i int) bool { return s.keys[i].activation >= key.activation })
+	if i < len(s.keys) && s.keys[i].activation == key.activation {
+		if s.keys[i].address == key.address {
+			return nil
+		}
+		return fmt.Errorf("conflicting feed signing keys %v and %v activated at sequence number %d", s.keys[i].address, key.address, key.activation)
+	}
+	s.keys = append(s.keys, signingKey{})
+	copy(s.keys[i+1:], s.keys[i:])
+	s.keys[i] = key
+	return nil
+}
+
+// signerAt returns the key which signs the message with the given sequence
+// number, or false if no key is scheduled for it.
+func (s *keySchedule) signerAt(seqNum arbutil.MessageIndex) (common.Address, bool) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	i := sort.Search(len(s.keys), func(i int) bool { return s.keys[i].activation > seqNum })
+	if i == 0 {
+		return common.Address{}, false
+	}
+	return s.keys[i-1].address, true
+}
+
+var ErrInvalidKeyRotation = errors.New("invalid feed key rotation")
+
+// handleKeyRotation verifies that the rotation is signed by the key which
+// signs the messages right before its activation and adds the new key to the
+// schedule. If no key is scheduled for those messages, the rotation needs to
+// be signed by a key accepted by the signature verifier.
+func (bc *BroadcastClient) handleKeyRotation(ctx context.Context, rotation *message.KeyRotationMessage) error {
+	if bc.config().Verify.Dangerous.AcceptMissing {
+		// Without enforced signatures the schedule could only be used to
+		// reject messages, so in-band rotations are ignored.
+		log.Debug("ignoring feed key rotation as feed signatures are not enforced", "newSigner", rotation.NewSigner, "activation", rotation.ActivationSequenceNumber)
+		return nil
+	}
+	if rotation.ActivationSequenceNumber == 0 {
+		return fmt.Errorf("%w: activation at sequence number 0", ErrInvalidKeyRotation)
+	}
+	hash := rotation.SignatureHash(bc.chainId)
+	if currentSigner, ok := bc.keySchedule.signerAt(rotation.ActivationSequenceNumber - 1); ok {
+		sigPublicKey, err := crypto.SigToPub(hash.Bytes(), rotation.Signature)
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidKeyRotation, signature.ErrSignatureNotVerified)
+		}
+		if signer := crypto.PubkeyToAddress(*sigPublicKey); signer != currentSigner {
+			return fmt.Errorf("%w: signed by %v instead of %v", ErrInvalidKeyRotation, signer, currentSigner)
+		}
+	} else if err := bc.sigVerifier.VerifyHash(ctx, rotation.Signature, hash); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidKeyRotation, err)
+	}
+	if err := bc.keySchedule.add(signingKey{rotation.NewSigner, rotation.ActivationSequenceNumber}); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidKeyRotation, err)
+	}
+	log.Info("feed signing key rotation accepted", "newSigner", rotation.NewSigner, "activation", rotation.ActivationSequenceNumber)
+	return nil
+}
@@ -0,0 +1,139 @@
+// Copyright 2026, Offchain Labs, Inc.
+// For license information, see https://github.com/OffchainLabs/nitro/blob/master/LICENSE.md
+
+package broadcastclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gobwas/httphead"
+	"github.com/gobwas/ws"
+	"github.com/gobwas/ws/wsflate"
+	"github.com/gobwas/ws/wsutil"
+
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/offchainlabs/nitro/arbutil"
+	"github.com/offchainlabs/nitro/broadcaster/message"
+	"github.com/offchainlabs/nitro/wsbroadcastserver"
+)
+
+// FetchRange asks the feed server to replay the messages from start to end,
+// which may no longer be in its backlog, and returns them once their
+// signatures have been verified, against the key rotations sent first. The connection is closed by the server
+// after the last message, so FetchRange does not affect the live stream.
+func (bc *BroadcastClient) FetchRange(ctx context.Context, start, end arbutil.MessageIndex) ([]*message.BroadcastFeedMessage, error) {
+	if start > end {
+		return nil, fmt.Errorf("replay start %d is after replay end %d", start, end)
+	}
+	config := bc.config()
+	httpHeader := http.Header{
+		wsbroadcastserver.HTTPHeaderFeedClientVersion: []string{strconv.Itoa(wsbroadcastserver.FeedClientVersion)},
+		wsbroadcastserver.HTTPHeaderReplayStart:       []string{strconv.FormatUint(uint64(start), 10)},
+		wsbroadcastserver.HTTPHeaderReplayEnd:         []string{strconv.FormatUint(uint64(end), 10)},
+	}
+	if config.RequestBinaryEncoding {
+		httpHeader[wsbroadcastserver.HTTPHeaderFeedEncoding] = []string{message.EncodingNameBinary + "," + message.EncodingNameJSON}
+	}
+	var extensions []httphead.Option
+	deflateExt := wsflate.DefaultParameters.Option()
+	if config.EnableCompression {
+		extensions = []httphead.Option{deflateExt}
+	}
+	onHeader := func(key, value []byte) error {
+		if string(key) != wsbroadcastserver.HTTPHeaderChainId {
+			return nil
+		}
+		chainId, err := strconv.ParseUint(string(value), 0, 64)
+		if err != nil {
+			return err
+		}
+		if chainId != bc.chainId {
+			return ErrIncorrectChainId
+		}
+		return nil
+	}
+
+	dialer := newFeedDialer(ws.HandshakeHeaderHTTP(httpHeader), onHeader, extensions)
+	conn, br, hs, err := dialer.Dial(ctx, bc.websocketUrl)
+	if err != nil {
+		return nil, fmt.Errorf("broadcast client unable to connect for replay: %w", err)
+	}
+	defer func() {
+		_ = conn.Close()
+	}()
+	compression := false
+	for _, ext := range hs.Extensions {
+		if ext.Equal(deflateExt) {
+			compression = true
+			break
+		}
+	}
+	var earlyFrameData io.Reader
+	if br != nil {
+		earlyFrameData = io.LimitReader(br, int64(br.Buffered()))
+	}
+
+	flateReader := wsbroadcastserver.NewFlateReader()
+	msgs := make([]*message.BroadcastFeedMessage, 0, end-start+1)
+	for {
+		data, op, err := wsbroadcastserver.ReadData(ctx, conn, earlyFrameData, config.Timeout, ws.StateClientSide, compression, flateReader)
+		if err != nil {
+			var closedErr wsutil.ClosedError
+			// #nosec G115
+			if errors.As(err, &closedErr) && closedErr.Code != ws.StatusNormalClosure {
+				return nil, fmt.Errorf("feed server aborted replay: %s", closedErr.Reason)
+			} else if arbutil.MessageIndex(len(msgs)) == end-start+1 {
+				// The server closes the connection right after the last message,
+				// so replying to its close frame may fail.
+				break
+			}
+			return nil, err
+		}
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if data == nil {
+			continue
+		}
+		encoding := message.EncodingJSON
+		if op == ws.OpBinary {
+			encoding = message.EncodingBinary
+		}
+		res, err := message.DecodeBroadcastMessage(data, encoding)
+		if err != nil {
+			return nil, fmt.Errorf("error unmarshalling replayed message: %w", err)
+		}
+		// The key rotations are sent before the messages, so that messages
+		// signed by any of the feed signers can be verified
+		if res.KeyRotationMessage != nil {
+			if err := bc.handleKeyRotation(ctx, res.KeyRotationMessage); err != nil {
+				log.Error("error handling feed key rotation in replay", "err", err)
+			}
+		}
+		for _, msg := range res.Messages {
+			if msg == nil {
+				continue
+			}
+			// #nosec G115
+			expected := start + arbutil.MessageIndex(len(msgs))
+			if msg.SequenceNumber != expected {
+				return nil, fmt.Errorf("unexpected sequence number %d in replay, expected %d", msg.SequenceNumber, expected)
+			}
+			if err := bc.isValidSignature(ctx, msg); err != nil {
+				return nil, fmt.Errorf("error validating feed signature of replayed message %d: %w", msg.SequenceNumber, err)
+			}
+			msgs = append(msgs, msg)
+		}
+	}
+	// #nosec G115
+	if arbutil.MessageIndex(len(msgs)) != end-start+1 {
+		return nil, fmt.Errorf("replay returned %d messages, expected %d", len(msgs), end-start+1)
+	}
+	return msgs, nil
+}
@@ -40,6 +40,15 @@ func (r *Router) AddBroadcastMessages(feedMessages []*message.BroadcastFeedMessa
 	return nil
 }
 
+// AddKeyRotation forwards verified key rotations if the transaction streamer
+// is interested in them.
+func (r *Router) AddKeyRotation(rotation *message.KeyRotationMessage) error {
+	if listener, ok := r.forwardTxStreamer.(broadcastclient.KeyRotationListener); ok {
+		return listener.AddKeyRotation(rotation)
+	}
+	return nil
+}
+
 type BroadcastClients struct {
 	stopwaiter.StopWaiter
 	primaryClients   []*broadcastclient.BroadcastClient
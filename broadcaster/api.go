@@ -0,0 +1,41 @@
+// Copyright 2026, Offchain Labs, Inc.
+// For license information, see https://github.com/OffchainLabs/nitro/blob/master/LICENSE.md
+
+package broadcaster
+
+import (
+	"context"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+
+	"github.com/offchainlabs/nitro/arbutil"
+	"github.com/offchainlabs/nitro/wsbroadcastserver"
+)
+
+// FeedAdminNamespace is the RPC namespace of the FeedAdminAPI, which should
+// not be exposed publicly.
+const FeedAdminNamespace = "arbfeed"
+
+// FeedAdminAPI lists and disconnects the clients of the feed, and schedules
+// feed signer rotations.
+type FeedAdminAPI struct {
+	broadcaster *Broadcaster
+}
+
+func NewFeedAdminAPI(broadcaster *Broadcaster) *FeedAdminAPI {
+	return &FeedAdminAPI{broadcaster: broadcaster}
+}
+
+func (a *FeedAdminAPI) Clients(ctx context.Context) ([]wsbroadcastserver.ClientInfo, error) {
+	return a.broadcaster.Clients(ctx)
+}
+
+func (a *FeedAdminAPI) DisconnectClient(ctx context.Context, name string) error {
+	return a.broadcaster.DisconnectClient(ctx, name)
+}
+
+// RotateSigner rotates the feed signer to the key configured in
+// signer-rotation.new-signer-key, starting at the activation sequence number.
+func (a *FeedAdminAPI) RotateSigner(ctx context.Context, activation hexutil.Uint64) error {
+	return a.broadcaster.RotateToConfiguredSigner(arbutil.MessageIndex(activation))
+}
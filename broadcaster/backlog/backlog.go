@@ -47,6 +47,10 @@ type backlog struct {
 
 // NewBacklog creates a backlog.
 func NewBacklog(c ConfigFetcher) Backlog {
+	return newBacklog(c)
+}
+
+func newBacklog(c ConfigFetcher) *backlog {
 	b := &backlog{
 		config: c,
 	}
@@ -3,28 +3,72 @@
 package backlog
 
 import (
+	"errors"
+	"time"
+
 	"github.com/spf13/pflag"
 )
 
 type ConfigFetcher func() *Config
 
 type Config struct {
-	SegmentLimit          int  `koanf:"segment-limit" reload:"hot"`
-	EnableBacklogDeepCopy bool `koanf:"enable-backlog-deep-copy" reload:"hot"`
+	SegmentLimit          int              `koanf:"segment-limit" reload:"hot"`
+	EnableBacklogDeepCopy bool             `koanf:"enable-backlog-deep-copy" reload:"hot"`
+	Persistent            PersistentConfig `koanf:"persistent"`
+}
+
+func (c *Config) Validate() error {
+	return c.Persistent.Validate()
 }
 
 func AddOptions(prefix string, f *pflag.FlagSet) {
 	f.Int(prefix+".segment-limit", DefaultConfig.SegmentLimit, "the maximum number of messages each segment within the backlog can contain")
 	f.Bool(prefix+".enable-backlog-deep-copy", DefaultConfig.EnableBacklogDeepCopy, "enable deep copying of L2 messages for memory profiling (debug only)")
+	PersistentConfigAddOptions(prefix+".persistent", f)
+}
+
+type PersistentConfig struct {
+	Enable    bool          `koanf:"enable"`
+	Directory string        `koanf:"directory"`
+	DBEngine  string        `koanf:"db-engine"`
+	MaxAge    time.Duration `koanf:"max-age" reload:"hot"`
+	MaxSize   uint64        `koanf:"max-size" reload:"hot"`
+}
+
+func (c *PersistentConfig) Validate() error {
+	if c.Enable && c.Directory == "" {
+		return errors.New("persistent feed backlog enabled but no directory specified")
+	}
+	if c.DBEngine != "" && c.DBEngine != "pebble" && c.DBEngine != "leveldb" {
+		return errors.New("persistent feed backlog db-engine must be one of 'pebble', 'leveldb' or ''")
+	}
+	return nil
+}
+
+func PersistentConfigAddOptions(prefix string, f *pflag.FlagSet) {
+	f.Bool(prefix+".enable", DefaultPersistentConfig.Enable, "store the feed backlog on disk so that it survives restarts")
+	f.String(prefix+".directory", DefaultPersistentConfig.Directory, "directory of the database storing the feed backlog")
+	f.String(prefix+".db-engine", DefaultPersistentConfig.DBEngine, "backing database implementation to use ('leveldb', 'pebble' or '' = auto-detect)")
+	f.Duration(prefix+".max-age", DefaultPersistentConfig.MaxAge, "messages older than this are removed from the stored feed backlog (0 = no limit)")
+	f.Uint64(prefix+".max-size", DefaultPersistentConfig.MaxSize, "oldest messages are removed from the stored feed backlog when its size in bytes exceeds this (0 = no limit)")
 }
 
 var (
+	DefaultPersistentConfig = PersistentConfig{
+		Enable:    false,
+		Directory: "",
+		DBEngine:  "",
+		MaxAge:    24 * time.Hour,
+		MaxSize:   1024 * 1024 * 1024,
+	}
 	DefaultConfig = Config{
 		SegmentLimit:          240,
 		EnableBacklogDeepCopy: false,
+		Persistent:            DefaultPersistentConfig,
 	}
 	DefaultTestConfig = Config{
 		SegmentLimit:          3,
 		EnableBacklogDeepCopy: false,
+		Persistent:            DefaultPersistentConfig,
 	}
 )
@@ -0,0 +1,417 @@
+// Copyright 2026, Offchain Labs, Inc.
+// For license information, see https://github.com/OffchainLabs/nitro/blob/master/LICENSE.md
+package backlog
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethereum/go-ethereum/node"
+	"github.com/ethereum/go-ethereum/rlp"
+
+	"github.com/offchainlabs/nitro/broadcaster/message"
+)
+
+var (
+	storedMessagePrefix        = []byte("m")          // maps a sequence number to a storedMessage
+	confirmedSequenceNumberKey = []byte("_confirmed") // contains the last confirmed sequence number
+	keyRotationsKey            = []byte("_rotations") // contains the feed key rotations, to verify the stored messages
+
+	persistentBacklogSizeInBytesGauge = metrics.NewRegisteredGauge("arb/feed/backlog/persistent/bytes", nil)
+)
+
+// rehydrateBatchSize is the number of stored messages appended to the
+// in-memory backlog at once when restoring it on startup.
+const rehydrateBatchSize = 1024
+
+// storedMessage is the database representation of a feed message.
+type storedMessage struct {
+	Timestamp uint64
+	Message   *message.BroadcastFeedMessage
+}
+
+// PersistentBacklog is a Backlog which also writes every message appended
+// to it into a key-value store. Stored messages are kept, regardless of
+// confirmations, until they exceed the configured age or size limits. When
+// opened, the messages which had not been confirmed yet are restored into the
+// in-memory backlog so that clients can catch up after a restart.
+type PersistentBacklog struct {
+	*backlog
+
+	config ConfigFetcher
+
+	dbMutex    sync.Mutex
+	db         ethdb.KeyValueStore
+	storedSize uint64
+	lastStored uint64
+	hasStored  bool
+	// The sequence number and timestamp of the first stored message, so that
+	// prune doesn't need to read it back on each Append.
+	hasHead       bool
+	headSeqNum    uint64
+	headTimestamp uint64
+	// The key rotations announcing the signers of the stored messages
+	keyRotations []*message.KeyRotationMessage
+}
+
+// NewPersistentBacklog creates a PersistentBacklog. Open needs to be called
+// before messages are written to disk.
+func NewPersistentBacklog(c ConfigFetcher) *PersistentBacklog {
+	return &PersistentBacklog{
+		backlog: newBacklog(c),
+		config:  c,
+	}
+}
+
+// Open opens the configured database and restores the stored messages into
+// the in-memory backlog.
+func (b *PersistentBacklog) Open() error {
+	config := b.config().Persistent
+	db, err := node.OpenDatabase(node.InternalOpenOptions{
+		DbEngine:  config.DBEngine,
+		Directory: config.Directory,
+		DatabaseOptions: node.DatabaseOptions{
+			MetricsNamespace: "feedbacklog/",
+			NoFreezer:        true,
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("unable to open feed backlog database: %w", err)
+	}
+	if err := b.OpenWithDatabase(db); err != nil {
+		return errors.Join(err, db.Close())
+	}
+	return nil
+}
+
+// OpenWithDatabase uses the given key-value store for the backlog and
+// restores the stored messages into the in-memory backlog.
+func (b *PersistentBacklog) OpenWithDatabase(db ethdb.KeyValueStore) error {
+	b.dbMutex.Lock()
+	defer b.dbMutex.Unlock()
+	if b.db != nil {
+		return errors.New("persistent feed backlog already opened")
+	}
+
+	confirmed, hasConfirmed, err := readConfirmedSequenceNumber(db)
+	if err != nil {
+		return err
+	}
+	b.keyRotations, err = readKeyRotations(db)
+	if err != nil {
+		return err
+	}
+
+	it := db.NewIterator(storedMessagePrefix, nil)
+	defer it.Release()
+	var msgs []*message.BroadcastFeedMessage
+	for it.Next() {
+		seqNum, err := storedMessageSequenceNumber(it.Key())
+		if err != nil {
+			return err
+		}
+		b.storedSize += uint64(len(it.Key()) + len(it.Value()))
+		b.lastStored = seqNum
+		b.hasStored = true
+		isConfirmed := hasConfirmed && seqNum <= confirmed
+		if isConfirmed && b.hasHead {
+			continue
+		}
+		stored, err := decodeStoredMessage(it.Value())
+		if err != nil {
+			return fmt.Errorf("error decoding stored feed message %d: %w", seqNum, err)
+		}
+		if !b.hasHead {
+			b.setHead(seqNum, stored.Timestamp)
+		}
+		if isConfirmed {
+			continue
+		}
+		msgs = append(msgs, stored.Message)
+		if len(msgs) >= rehydrateBatchSize {
+			if err := b.backlog.Append(&message.BroadcastMessage{Version: message.V1, Messages: msgs}); err != nil {
+				return err
+			}
+			msgs = nil
+		}
+	}
+	if err := it.Error(); err != nil {
+		return err
+	}
+	if len(msgs) > 0 {
+		if err := b.backlog.Append(&message.BroadcastMessage{Version: message.V1, Messages: msgs}); err != nil {
+			return err
+		}
+	}
+	b.db = db
+	// #nosec G115
+	persistentBacklogSizeInBytesGauge.Update(int64(b.storedSize))
+	log.Info("restored feed backlog from disk", "messages", b.Count(), "storedBytes", b.storedSize, "lastStored", b.lastStored)
+	return nil
+}
+
+// Close closes the underlying database.
+func (b *PersistentBacklog) Close() error {
+	b.dbMutex.Lock()
+	defer b.dbMutex.Unlock()
+	if b.db == nil {
+		return nil
+	}
+	err := b.db.Close()
+	b.db = nil
+	return err
+}
+
+// KeyRotations returns the stored key rotations, which announce the signers of
+// the messages restored from disk.
+func (b *PersistentBacklog) KeyRotations() []*message.KeyRotationMessage {
+	b.dbMutex.Lock()
+	defer b.dbMutex.Unlock()
+	return slices.Clone(b.keyRotations)
+}
+
+// StoreKeyRotations stores all the key rotations of the feed, so that the
+// messages signed after them can still be verified by clients after a restart.
+func (b *PersistentBacklog) StoreKeyRotations(rotations []*message.KeyRotationMessage) error {
+	b.dbMutex.Lock()
+	defer b.dbMutex.Unlock()
+	if b.db == nil {
+		return errors.New("persistent feed backlog not opened")
+	}
+	data, err := rlp.EncodeToBytes(rotations)
+	if err != nil {
+		return err
+	}
+	if err := b.db.Put(keyRotationsKey, data); err != nil {
+		return err
+	}
+	b.keyRotations = slices.Clone(rotations)
+	return nil
+}
+
+// GetStored reads the messages from the given start to end sequence number
+// from disk, including messages which have already been confirmed.
+func (b *PersistentBacklog) GetStored(start, end uint64) ([]*message.BroadcastFeedMessage, error) {
+	b.dbMutex.Lock()
+	db := b.db
+	b.dbMutex.Unlock()
+	if db == nil || start > end {
+		return nil, errOutOfBounds
+	}
+	it := db.NewIterator(storedMessagePrefix, storedMessageKey(start)[len(storedMessagePrefix):])
+	defer it.Release()
+	msgs := make([]*message.BroadcastFeedMessage, 0, end-start+1)
+	for expected := start; it.Next(); expected++ {
+		seqNum, err := storedMessageSequenceNumber(it.Key())
+		if err != nil {
+			return nil, err
+		}
+		if seqNum != expected {
+			return nil, errOutOfBounds
+		}
+		stored, err := decodeStoredMessage(it.Value())
+		if err != nil {
+			return nil, fmt.Errorf("error decoding stored feed message %d: %w", seqNum, err)
+		}
+		msgs = append(msgs, stored.Message)
+		if seqNum == end {
+			return msgs, nil
+		}
+	}
+	if err := it.Error(); err != nil {
+		return nil, err
+	}
+	return nil, errOutOfBounds
+}
+
+// Append adds the given messages to the in-memory backlog and stores them on
+// disk. Messages appended before the backlog is opened are only kept in memory.
+func (b *PersistentBacklog) Append(bm *message.BroadcastMessage) error {
+	if err := b.backlog.Append(bm); err != nil {
+		return err
+	}
+
+	b.dbMutex.Lock()
+	defer b.dbMutex.Unlock()
+	if b.db == nil {
+		return nil
+	}
+	return b.store(bm)
+}
+
+// store writes the messages and confirmed sequence number to the database.
+// Messages already stored are skipped. If there is a gap between the last
+// stored message and the new messages the previously stored messages are
+// removed, the same way the in-memory backlog drops its segments.
+func (b *PersistentBacklog) store(bm *message.BroadcastMessage) error {
+	batch := b.db.NewBatch()
+	// #nosec G115
+	now := uint64(time.Now().Unix())
+	keepFrom := uint64(0)
+	for _, msg := range bm.Messages {
+		seqNum := uint64(msg.SequenceNumber)
+		if b.hasStored && seqNum <= b.lastStored {
+			continue
+		}
+		if b.hasStored && seqNum != b.lastStored+1 {
+			log.Warn("gap in stored feed backlog, dropping previously stored messages", "lastStored", b.lastStored, "sequenceNumber", seqNum)
+			keepFrom = seqNum
+		}
+		data, err := rlp.EncodeToBytes(&storedMessage{Timestamp: now, Message: msg})
+		if err != nil {
+			return err
+		}
+		key := storedMessageKey(seqNum)
+		if err := batch.Put(key, data); err != nil {
+			return err
+		}
+		b.storedSize += uint64(len(key) + len(data))
+		b.lastStored = seqNum
+		b.hasStored = true
+		if !b.hasHead {
+			b.setHead(seqNum, now)
+		}
+	}
+	if bm.ConfirmedSequenceNumberMessage != nil {
+		confirmed := binary.BigEndian.AppendUint64(nil, uint64(bm.ConfirmedSequenceNumberMessage.SequenceNumber))
+		if err := batch.Put(confirmedSequenceNumberKey, confirmed); err != nil {
+			return err
+		}
+	}
+	if err := batch.Write(); err != nil {
+		return err
+	}
+	return b.prune(keepFrom)
+}
+
+// prune removes messages from the start of the stored backlog while they
+// precede keepFrom, are older than MaxAge, or the stored backlog exceeds
+// MaxSize.
+func (b *PersistentBacklog) prune(keepFrom uint64) error {
+	config := b.config().Persistent
+	var minTimestamp uint64
+	if config.MaxAge > 0 {
+		// #nosec G115
+		minTimestamp = uint64(time.Now().Add(-config.MaxAge).Unix())
+	}
+
+	overSize := config.MaxSize > 0 && b.storedSize > config.MaxSize
+	headKept := b.hasHead && b.headSeqNum >= keepFrom && !overSize && b.headTimestamp >= minTimestamp
+	if headKept || (!b.hasHead && b.storedSize == 0) {
+		// #nosec G115
+		persistentBacklogSizeInBytesGauge.Update(int64(b.storedSize))
+		return nil
+	}
+
+	it := b.db.NewIterator(storedMessagePrefix, nil)
+	defer it.Release()
+	batch := b.db.NewBatch()
+	b.hasHead = false
+	for it.Next() {
+		seqNum, err := storedMessageSequenceNumber(it.Key())
+		if err != nil {
+			return err
+		}
+		tooLarge := config.MaxSize > 0 && b.storedSize > config.MaxSize
+		if seqNum >= keepFrom && !tooLarge {
+			stored, err := decodeStoredMessage(it.Value())
+			if err != nil {
+				return fmt.Errorf("error decoding stored feed message %d: %w", seqNum, err)
+			}
+			if stored.Timestamp >= minTimestamp {
+				b.setHead(seqNum, stored.Timestamp)
+				break
+			}
+		}
+		if err := batch.Delete(common.CopyBytes(it.Key())); err != nil {
+			return err
+		}
+		size := uint64(len(it.Key()) + len(it.Value()))
+		if size > b.storedSize {
+			size = b.storedSize
+		}
+		b.storedSize -= size
+		if batch.ValueSize() >= ethdb.IdealBatchSize {
+			if err := batch.Write(); err != nil {
+				return err
+			}
+			batch.Reset()
+		}
+	}
+	if err := it.Error(); err != nil {
+		return err
+	}
+	if err := batch.Write(); err != nil {
+		return err
+	}
+	// #nosec G115
+	persistentBacklogSizeInBytesGauge.Update(int64(b.storedSize))
+	return nil
+}
+
+func (b *PersistentBacklog) setHead(seqNum, timestamp uint64) {
+	b.hasHead = true
+	b.headSeqNum = seqNum
+	b.headTimestamp = timestamp
+}
+
+func storedMessageKey(seqNum uint64) []byte {
+	return binary.BigEndian.AppendUint64(append([]byte{}, storedMessagePrefix...), seqNum)
+}
+
+func storedMessageSequenceNumber(key []byte) (uint64, error) {
+	if len(key) != len(storedMessagePrefix)+8 {
+		return 0, fmt.Errorf("invalid stored feed message key %x", key)
+	}
+	return binary.BigEndian.Uint64(key[len(storedMessagePrefix):]), nil
+}
+
+func decodeStoredMessage(data []byte) (*storedMessage, error) {
+	var stored storedMessage
+	if err := rlp.DecodeBytes(data, &stored); err != nil {
+		return nil, err
+	}
+	if stored.Message == nil {
+		return nil, errors.New("stored feed message is empty")
+	}
+	return &stored, nil
+}
+
+func readKeyRotations(db ethdb.KeyValueReader) ([]*message.KeyRotationMessage, error) {
+	has, err := db.Has(keyRotationsKey)
+	if err != nil || !has {
+		return nil, err
+	}
+	data, err := db.Get(keyRotationsKey)
+	if err != nil {
+		return nil, err
+	}
+	var rotations []*message.KeyRotationMessage
+	if err := rlp.DecodeBytes(data, &rotations); err != nil {
+		return nil, fmt.Errorf("error decoding stored feed key rotations: %w", err)
+	}
+	return rotations, nil
+}
+
+func readConfirmedSequenceNumber(db ethdb.KeyValueReader) (uint64, bool, error) {
+	has, err := db.Has(confirmedSequenceNumberKey)
+	if err != nil || !has {
+		return 0, false, err
+	}
+	data, err := db.Get(confirmedSequenceNumberKey)
+	if err != nil {
+		return 0, false, err
+	}
+	if len(data) != 8 {
+		return 0, false, fmt.Errorf("invalid stored confirmed sequence number %x", data)
+	}
+	return binary.BigEndian.Uint64(data), true, nil
+}
@@ -0,0 +1,119 @@
+// Copyright 2026, Offchain Labs, Inc.
+// For license information, see https://github.com/OffchainLabs/nitro/blob/master/LICENSE.md
+package backlog
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/ethdb"
+
+	"github.com/offchainlabs/nitro/arbutil"
+	"github.com/offchainlabs/nitro/broadcaster/message"
+)
+
+func newTestPersistentBacklog(t *testing.T, db ethdb.KeyValueStore, config *Config) *PersistentBacklog {
+	t.Helper()
+	b := NewPersistentBacklog(func() *Config { return config })
+	if err := b.OpenWithDatabase(db); err != nil {
+		t.Fatalf("error opening persistent backlog: %v", err)
+	}
+	return b
+}
+
+func countStoredMessages(t *testing.T, db ethdb.KeyValueStore) int {
+	t.Helper()
+	it := db.NewIterator(storedMessagePrefix, nil)
+	defer it.Release()
+	count := 0
+	for it.Next() {
+		count++
+	}
+	return count
+}
+
+func TestPersistentBacklogRehydrate(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+	config := DefaultTestConfig
+	config.Persistent.MaxAge = 0
+	config.Persistent.MaxSize = 0
+
+	b := newTestPersistentBacklog(t, db, &config)
+	indexes := []arbutil.MessageIndex{40, 41, 42, 43, 44, 45, 46}
+	if err := b.Append(&message.BroadcastMessage{Messages: message.CreateDummyBroadcastMessages(indexes)}); err != nil {
+		t.Fatalf("error appending messages: %v", err)
+	}
+
+	restored := newTestPersistentBacklog(t, db, &config)
+	validateBacklog(t, restored.backlog, 7, 40, 46, indexes)
+
+	// Confirmed messages are removed from memory but stay on disk
+	confirm := &message.BroadcastMessage{ConfirmedSequenceNumberMessage: &message.ConfirmedSequenceNumberMessage{SequenceNumber: 43}}
+	if err := restored.Append(confirm); err != nil {
+		t.Fatalf("error confirming messages: %v", err)
+	}
+	restored = newTestPersistentBacklog(t, db, &config)
+	validateBacklog(t, restored.backlog, 3, 44, 46, []arbutil.MessageIndex{44, 45, 46})
+	if count := countStoredMessages(t, db); count != 7 {
+		t.Errorf("expected 7 stored messages, got %d", count)
+	}
+}
+
+func TestPersistentBacklogGap(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+	config := DefaultTestConfig
+	config.Persistent.MaxAge = 0
+	config.Persistent.MaxSize = 0
+
+	b := newTestPersistentBacklog(t, db, &config)
+	if err := b.Append(&message.BroadcastMessage{Messages: message.CreateDummyBroadcastMessages([]arbutil.MessageIndex{40, 41, 42})}); err != nil {
+		t.Fatalf("error appending messages: %v", err)
+	}
+	if err := b.Append(&message.BroadcastMessage{Messages: message.CreateDummyBroadcastMessages([]arbutil.MessageIndex{50, 51})}); err != nil {
+		t.Fatalf("error appending messages: %v", err)
+	}
+	if count := countStoredMessages(t, db); count != 2 {
+		t.Errorf("expected 2 stored messages, got %d", count)
+	}
+	restored := newTestPersistentBacklog(t, db, &config)
+	validateBacklog(t, restored.backlog, 2, 50, 51, []arbutil.MessageIndex{50, 51})
+}
+
+func TestPersistentBacklogPrune(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+	config := DefaultTestConfig
+	config.Persistent.MaxAge = 0
+	config.Persistent.MaxSize = 0
+
+	b := newTestPersistentBacklog(t, db, &config)
+	indexes := []arbutil.MessageIndex{40, 41, 42, 43, 44, 45, 46}
+	if err := b.Append(&message.BroadcastMessage{Messages: message.CreateDummyBroadcastMessages(indexes)}); err != nil {
+		t.Fatalf("error appending messages: %v", err)
+	}
+	messageSize := b.storedSize / uint64(len(indexes))
+
+	config.Persistent.MaxSize = 3 * messageSize
+	if err := b.Append(&message.BroadcastMessage{Messages: message.CreateDummyBroadcastMessages([]arbutil.MessageIndex{47})}); err != nil {
+		t.Fatalf("error appending messages: %v", err)
+	}
+	if count := countStoredMessages(t, db); count != 3 {
+		t.Errorf("expected 3 stored messages after size pruning, got %d", count)
+	}
+	if !b.hasHead || b.headSeqNum != 45 {
+		t.Errorf("expected cached head 45 after size pruning, got %d", b.headSeqNum)
+	}
+
+	config.Persistent.MaxSize = 0
+	config.Persistent.MaxAge = time.Nanosecond
+	time.Sleep(1100 * time.Millisecond)
+	if err := b.Append(&message.BroadcastMessage{Messages: message.CreateDummyBroadcastMessages([]arbutil.MessageIndex{48})}); err != nil {
+		t.Fatalf("error appending messages: %v", err)
+	}
+	if count := countStoredMessages(t, db); count != 1 {
+		t.Errorf("expected 1 stored message after age pruning, got %d", count)
+	}
+	if !b.hasHead || b.headSeqNum != 48 {
+		t.Errorf("expected cached head 48 after age pruning, got %d", b.headSeqNum)
+	}
+}
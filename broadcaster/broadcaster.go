@@ -6,11 +6,16 @@ package broadcaster
 import (
 	"context"
 	"errors"
+	"fmt"
 	"net"
 	"runtime/debug"
+	"slices"
+	"sync"
 
 	"github.com/gobwas/ws"
 
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/log"
 
 	"github.com/offchainlabs/nitro/arbos/arbostypes"
@@ -23,21 +28,80 @@ import (
 
 const SupportedBroadcastVersion = m.V1
 
-type Broadcaster struct {
-	server     *wsbroadcastserver.WSBroadcastServer
-	backlog    backlog.Backlog
-	chainId    uint64
+// FeedMessageSource provides signed feed messages which are no longer
+// available in the backlog, e.g. from the transaction streamer's database.
+type FeedMessageSource interface {
+	FeedMessages(start, end arbutil.MessageIndex) ([]*m.BroadcastFeedMessage, error)
+}
+
+// scheduledSigner signs the feed messages from its activation sequence number
+// on, until the next signer is activated.
+type scheduledSigner struct {
 	dataSigner signature.DataSignerFunc
+	address    common.Address
+	activation arbutil.MessageIndex
+}
+
+type Broadcaster struct {
+	server            *wsbroadcastserver.WSBroadcastServer
+	backlog           backlog.Backlog
+	persistentBacklog *backlog.PersistentBacklog
+	messageSource     FeedMessageSource
+	chainId           uint64
+
+	signersMutex sync.RWMutex
+	signers      []scheduledSigner
+	rotations    []*m.KeyRotationMessage
+
+	// set by SetupSignerRotation
+	rotationDB            ethdb.KeyValueStore
+	rotationSigner        signature.DataSignerFunc
+	rotationSignerAddress common.Address
 }
 
 func NewBroadcaster(config wsbroadcastserver.BroadcasterConfigFetcher, chainId uint64, feedErrChan chan error, dataSigner signature.DataSignerFunc) *Broadcaster {
-	bklg := backlog.NewBacklog(func() *backlog.Config { return &config().Backlog })
-	return &Broadcaster{
-		server:     wsbroadcastserver.NewWSBroadcastServer(config, bklg, chainId, feedErrChan),
-		backlog:    bklg,
-		chainId:    chainId,
-		dataSigner: dataSigner,
+	backlogConfig := func() *backlog.Config { return &config().Backlog }
+	var bklg backlog.Backlog
+	var persistentBacklog *backlog.PersistentBacklog
+	if backlogConfig().Persistent.Enable {
+		persistentBacklog = backlog.NewPersistentBacklog(backlogConfig)
+		bklg = persistentBacklog
+	} else {
+		bklg = backlog.NewBacklog(backlogConfig)
+	}
+	b := &Broadcaster{
+		backlog:           bklg,
+		persistentBacklog: persistentBacklog,
+		chainId:           chainId,
+		signers:           []scheduledSigner{{dataSigner: dataSigner}},
 	}
+	b.server = wsbroadcastserver.NewWSBroadcastServer(config, bklg, b, chainId, feedErrChan)
+	return b
+}
+
+// SetFeedMessageSource sets the source used to replay messages which are
+// neither in the in-memory nor in the persistent backlog. It must be called
+// before the broadcaster is started.
+func (b *Broadcaster) SetFeedMessageSource(source FeedMessageSource) {
+	b.messageSource = source
+}
+
+// GetFeedMessages returns the feed messages from start to end, looking them up
+// in the in-memory backlog, then the persistent backlog and finally the feed
+// message source.
+func (b *Broadcaster) GetFeedMessages(start, end arbutil.MessageIndex) ([]*m.BroadcastFeedMessage, error) {
+	if bm, err := b.backlog.Get(uint64(start), uint64(end)); err == nil && len(bm.Messages) > 0 && bm.Messages[0].SequenceNumber == start {
+		return bm.Messages, nil
+	}
+	if b.persistentBacklog != nil {
+		if msgs, err := b.persistentBacklog.GetStored(uint64(start), uint64(end)); err == nil {
+			return msgs, nil
+		}
+	}
+	if b.messageSource != nil {
+		return b.messageSource.FeedMessages(start, end)
+	}
+	return nil, wsbroadcastserver.ErrReplayRangeNotAvailable
 }
 
 func (b *Broadcaster) NewBroadcastFeedMessage(
@@ -51,10 +115,10 @@ func (b *Broadcaster) NewBroadcastFeedMessage(
 		Signature:      []byte{},
 		BlockMetadata:  message.BlockMetadata,
 	}
-	if b.dataSigner != nil {
+	if dataSigner := b.signerAt(sequenceNumber); dataSigner != nil {
 		hash := feedMessage.SignatureHash(b.chainId)
 		var err error
-		feedMessage.Signature, err = b.dataSigner(hash.Bytes())
+		feedMessage.Signature, err = dataSigner(hash.Bytes())
 		if err != nil {
 			return nil, err
 		}
@@ -62,6 +126,88 @@ func (b *Broadcaster) NewBroadcastFeedMessage(
 	return &feedMessage, nil
 }
 
+func (b *Broadcaster) signerAt(sequenceNumber arbutil.MessageIndex) signature.DataSignerFunc {
+	b.signersMutex.RLock()
+	defer b.signersMutex.RUnlock()
+	for i := len(b.signers) - 1; i > 0; i-- {
+		if sequenceNumber >= b.signers[i].activation {
+			return b.signers[i].dataSigner
+		}
+	}
+	return b.signers[0].dataSigner
+}
+
+// RotateDataSigner signs feed messages starting at the activation sequence
+// number with the new signer. The rotation is announced to clients in a
+// KeyRotationMessage signed by the signer it replaces, so that they accept
+// the new signer without a configuration change. If SetupSignerRotation was
+// called, the rotation is persisted and restored on restart.
+func (b *Broadcaster) RotateDataSigner(newSigner signature.DataSignerFunc, newSignerAddress common.Address, activation arbutil.MessageIndex) error {
+	rotation, err := b.scheduleSigner(newSigner, newSignerAddress, activation)
+	if err != nil {
+		return err
+	}
+	log.Info("rotating feed signer", "newSigner", newSignerAddress, "activation", activation)
+	b.BroadcastKeyRotation(rotation)
+	return nil
+}
+
+func (b *Broadcaster) scheduleSigner(newSigner signature.DataSignerFunc, newSignerAddress common.Address, activation arbutil.MessageIndex) (*m.KeyRotationMessage, error) {
+	if newSigner == nil {
+		return nil, errors.New("cannot rotate to an empty feed signer")
+	}
+	if activation == 0 {
+		return nil, errors.New("feed signer rotation cannot be activated at sequence number 0")
+	}
+	b.signersMutex.Lock()
+	defer b.signersMutex.Unlock()
+	last := b.signers[len(b.signers)-1]
+	if last.dataSigner == nil {
+		return nil, errors.New("cannot rotate the signer of an unsigned feed")
+	}
+	if len(b.signers) > 1 && activation <= last.activation {
+		return nil, fmt.Errorf("feed signer rotation at %d must be after the last rotation at %d", activation, last.activation)
+	}
+	rotation := &m.KeyRotationMessage{
+		NewSigner:                newSignerAddress,
+		ActivationSequenceNumber: activation,
+	}
+	var err error
+	rotation.Signature, err = last.dataSigner(rotation.SignatureHash(b.chainId).Bytes())
+	if err != nil {
+		return nil, err
+	}
+	rotations := append(slices.Clone(b.rotations), rotation)
+	if b.rotationDB != nil {
+		if err := writeSignerRotations(b.rotationDB, rotations); err != nil {
+			return nil, err
+		}
+	}
+	b.rotations = rotations
+	b.signers = append(b.signers, scheduledSigner{dataSigner: newSigner, address: newSignerAddress, activation: activation})
+	return rotation, nil
+}
+
+// BroadcastKeyRotation sends a signed KeyRotationMessage to all clients,
+// including clients connecting later on.
+func (b *Broadcaster) BroadcastKeyRotation(rotation *m.KeyRotationMessage) {
+	b.server.BroadcastKeyRotation(rotation)
+	b.storeKeyRotations()
+}
+
+// storeKeyRotations stores the key rotations along with the persistent
+// backlog, so that after a restart the restored messages signed by previous
+// signers can be verified by clients, even by relays which only learn of the
+// rotations from their upstream feed.
+func (b *Broadcaster) storeKeyRotations() {
+	if b.persistentBacklog == nil {
+		return
+	}
+	if err := b.persistentBacklog.StoreKeyRotations(b.server.KeyRotations()); err != nil {
+		log.Warn("error storing feed key rotations with the backlog", "err", err)
+	}
+}
+
 func (b *Broadcaster) BroadcastFeedMessages(messages []*m.BroadcastFeedMessage) (err error) {
 	defer func() {
 		if r := recover(); r != nil {
@@ -100,6 +246,16 @@ func (b *Broadcaster) ClientCount() int32 {
 	return b.server.ClientCount()
 }
 
+// Clients returns information about the connected feed clients.
+func (b *Broadcaster) Clients(ctx context.Context) ([]wsbroadcastserver.ClientInfo, error) {
+	return b.server.Clients(ctx)
+}
+
+// DisconnectClient disconnects the feed clients with the given name.
+func (b *Broadcaster) DisconnectClient(ctx context.Context, name string) error {
+	return b.server.DisconnectClient(ctx, name)
+}
+
 func (b *Broadcaster) ListenerAddr() net.Addr {
 	return b.server.ListenerAddr()
 }
@@ -110,6 +266,15 @@ func (b *Broadcaster) GetCachedMessageCount() int {
 }
 
 func (b *Broadcaster) Initialize() error {
+	if b.persistentBacklog != nil {
+		if err := b.persistentBacklog.Open(); err != nil {
+			return err
+		}
+		for _, rotation := range b.persistentBacklog.KeyRotations() {
+			b.server.AddKeyRotation(rotation)
+		}
+		b.storeKeyRotations()
+	}
 	return b.server.Initialize()
 }
 
@@ -123,6 +288,11 @@ func (b *Broadcaster) StartWithHeader(ctx context.Context, header ws.HandshakeHe
 
 func (b *Broadcaster) StopAndWait() {
 	b.server.StopAndWait()
+	if b.persistentBacklog != nil {
+		if err := b.persistentBacklog.Close(); err != nil {
+			log.Warn("error closing feed backlog database", "err", err)
+		}
+	}
 }
 
 func (b *Broadcaster) Started() bool {
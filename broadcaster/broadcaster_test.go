@@ -5,13 +5,19 @@ package broadcaster
 
 import (
 	"context"
+	"encoding/hex"
 	"fmt"
 	"testing"
 	"time"
 
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/crypto"
+
 	"github.com/offchainlabs/nitro/arbos/arbostypes"
 	"github.com/offchainlabs/nitro/arbutil"
 	"github.com/offchainlabs/nitro/broadcaster/message"
+	"github.com/offchainlabs/nitro/util/signature"
 	"github.com/offchainlabs/nitro/util/testhelpers"
 	"github.com/offchainlabs/nitro/wsbroadcastserver"
 )
@@ -126,6 +132,87 @@ func TestBroadcasterMessagesRemovedOnConfirmation(t *testing.T) {
 		"clear all messages after confirmed 1 beyond latest"))
 }
 
+func TestBroadcasterSignerRotationPersisted(t *testing.T) {
+	ctx, cancelFunc := context.WithCancel(context.Background())
+	defer cancelFunc()
+
+	config := wsbroadcastserver.DefaultTestBroadcasterConfig
+	chainId := uint64(5555)
+	db := rawdb.NewMemoryDatabase()
+	oldKey, err := crypto.GenerateKey()
+	Require(t, err)
+	oldAddr := crypto.PubkeyToAddress(oldKey.PublicKey)
+	newKey, err := crypto.GenerateKey()
+	Require(t, err)
+	newAddr := crypto.PubkeyToAddress(newKey.PublicKey)
+	rotationConfig := wsbroadcastserver.DefaultSignerRotationConfig
+	rotationConfig.NewSignerKey = "0x" + hex.EncodeToString(crypto.FromECDSA(newKey))
+
+	b := NewBroadcaster(func() *wsbroadcastserver.BroadcasterConfig { return &config }, chainId, make(chan error, 10), signature.DataSignerFromPrivateKey(oldKey))
+	Require(t, b.SetupSignerRotation(db, &rotationConfig))
+	Require(t, b.Initialize())
+	Require(t, b.Start(ctx))
+	Require(t, b.RotateToConfiguredSigner(5))
+	b.StopAndWait()
+
+	signerOf := func(b *Broadcaster, seqNum arbutil.MessageIndex) common.Address {
+		t.Helper()
+		msg := feedMessage(t, b, seqNum)[0]
+		pubKey, err := crypto.SigToPub(msg.SignatureHash(chainId).Bytes(), msg.Signature)
+		Require(t, err)
+		return crypto.PubkeyToAddress(*pubKey)
+	}
+
+	// After a restart the rotation is restored from the database
+	restarted := NewBroadcaster(func() *wsbroadcastserver.BroadcasterConfig { return &config }, chainId, make(chan error, 10), signature.DataSignerFromPrivateKey(oldKey))
+	Require(t, restarted.SetupSignerRotation(db, &rotationConfig))
+	if signer := signerOf(restarted, 4); signer != oldAddr {
+		t.Fatalf("expected message 4 to be signed by %v, got %v", oldAddr, signer)
+	}
+	if signer := signerOf(restarted, 5); signer != newAddr {
+		t.Fatalf("expected message 5 to be signed by %v, got %v", newAddr, signer)
+	}
+	rotations := restarted.server.KeyRotations()
+	if len(rotations) != 1 || rotations[0].NewSigner != newAddr || rotations[0].ActivationSequenceNumber != 5 {
+		t.Fatalf("expected the rotation to %v at 5 to be announced to clients, got %v", newAddr, rotations)
+	}
+
+	// The key of the new signer has to stay configured
+	unconfigured := NewBroadcaster(func() *wsbroadcastserver.BroadcasterConfig { return &config }, chainId, make(chan error, 10), signature.DataSignerFromPrivateKey(oldKey))
+	if err := unconfigured.SetupSignerRotation(db, &wsbroadcastserver.DefaultSignerRotationConfig); err == nil {
+		t.Fatal("expected restoring the rotation without its key to fail")
+	}
+}
+
+func TestBroadcasterKeyRotationsPersistedWithBacklog(t *testing.T) {
+	ctx, cancelFunc := context.WithCancel(context.Background())
+	defer cancelFunc()
+
+	config := wsbroadcastserver.DefaultTestBroadcasterConfig
+	config.Backlog.Persistent.Enable = true
+	config.Backlog.Persistent.Directory = t.TempDir()
+	chainId := uint64(5555)
+	rotation := &message.KeyRotationMessage{NewSigner: common.Address{1}, ActivationSequenceNumber: 5}
+
+	// A relay only learns of the rotation from its upstream feed
+	b := NewBroadcaster(func() *wsbroadcastserver.BroadcasterConfig { return &config }, chainId, make(chan error, 10), nil)
+	Require(t, b.Initialize())
+	Require(t, b.Start(ctx))
+	b.BroadcastKeyRotation(rotation)
+	b.StopAndWait()
+
+	// After a restart the messages restored from the backlog can be verified
+	// before the rotation is received again
+	restarted := NewBroadcaster(func() *wsbroadcastserver.BroadcasterConfig { return &config }, chainId, make(chan error, 10), nil)
+	Require(t, restarted.Initialize())
+	Require(t, restarted.Start(ctx))
+	defer restarted.StopAndWait()
+	rotations := restarted.server.KeyRotations()
+	if len(rotations) != 1 || rotations[0].NewSigner != rotation.NewSigner || rotations[0].ActivationSequenceNumber != rotation.ActivationSequenceNumber {
+		t.Fatalf("expected the rotation to %v at 5 to be restored with the backlog, got %v", rotation.NewSigner, rotations)
+	}
+}
+
 func Require(t *testing.T, err error, printables ...interface{}) {
 	t.Helper()
 	testhelpers.RequireImpl(t, err, printables...)
@@ -0,0 +1,140 @@
+// Copyright 2026, Offchain Labs, Inc.
+// For license information, see https://github.com/OffchainLabs/nitro/blob/master/LICENSE.md
+
+package message
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// Encoding identifies the wire format used to serialize BroadcastMessages
+// sent over the feed. The encoding is negotiated during the websocket
+// handshake; JSON is used whenever the client does not ask for anything else.
+type Encoding uint8
+
+const (
+	EncodingJSON Encoding = iota
+	EncodingBinary
+)
+
+const (
+	EncodingNameJSON   = "json"
+	EncodingNameBinary = "rlp-v1"
+
+	// BinaryEncodingVersion is the first byte of every binary encoded
+	// BroadcastMessage, so that the format can evolve without another
+	// handshake header.
+	BinaryEncodingVersion = byte(1)
+)
+
+var ErrUnknownBinaryEncodingVersion = errors.New("unknown binary feed encoding version")
+
+func (e Encoding) String() string {
+	switch e {
+	case EncodingBinary:
+		return EncodingNameBinary
+	default:
+		return EncodingNameJSON
+	}
+}
+
+// IsBinary returns true if messages in this encoding have to be sent in
+// binary websocket frames.
+func (e Encoding) IsBinary() bool {
+	return e == EncodingBinary
+}
+
+// ParseEncodings parses a comma separated list of encoding names, as sent by
+// a client in the feed handshake, in order of preference. Unknown names are
+// skipped so that newer clients can talk to older servers.
+func ParseEncodings(value string) []Encoding {
+	var encodings []Encoding
+	for _, name := range strings.Split(value, ",") {
+		switch strings.TrimSpace(strings.ToLower(name)) {
+		case EncodingNameJSON:
+			encodings = append(encodings, EncodingJSON)
+		case EncodingNameBinary:
+			encodings = append(encodings, EncodingBinary)
+		}
+	}
+	return encodings
+}
+
+// rlpBroadcastMessage mirrors BroadcastMessage with RLP friendly field types.
+type rlpBroadcastMessage struct {
+	Version                        uint64
+	Messages                       []*BroadcastFeedMessage
+	ConfirmedSequenceNumberMessage *ConfirmedSequenceNumberMessage `rlp:"nil"`
+	KeyRotationMessage             *KeyRotationMessage             `rlp:"optional"`
+}
+
+// Encode serializes the BroadcastMessage in the given encoding. The JSON
+// encoding is newline terminated to stay byte compatible with json.Encoder.
+func (bm *BroadcastMessage) Encode(encoding Encoding) ([]byte, error) {
+	switch encoding {
+	case EncodingJSON:
+		data, err := json.Marshal(bm)
+		if err != nil {
+			return nil, err
+		}
+		return append(data, '\n'), nil
+	case EncodingBinary:
+		if bm.Version < 0 {
+			return nil, fmt.Errorf("invalid broadcast message version %d", bm.Version)
+		}
+		data, err := rlp.EncodeToBytes(&rlpBroadcastMessage{
+			// #nosec G115
+			Version:                        uint64(bm.Version),
+			Messages:                       bm.Messages,
+			ConfirmedSequenceNumberMessage: bm.ConfirmedSequenceNumberMessage,
+			KeyRotationMessage:             bm.KeyRotationMessage,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return append([]byte{BinaryEncodingVersion}, data...), nil
+	default:
+		return nil, fmt.Errorf("unknown feed encoding %d", encoding)
+	}
+}
+
+// DecodeBroadcastMessage deserializes a BroadcastMessage that was serialized
+// with BroadcastMessage.Encode.
+func DecodeBroadcastMessage(data []byte, encoding Encoding) (*BroadcastMessage, error) {
+	switch encoding {
+	case EncodingJSON:
+		var bm BroadcastMessage
+		if err := json.Unmarshal(data, &bm); err != nil {
+			return nil, err
+		}
+		return &bm, nil
+	case EncodingBinary:
+		if len(data) == 0 {
+			return nil, errors.New("empty binary broadcast message")
+		}
+		if data[0] != BinaryEncodingVersion {
+			return nil, fmt.Errorf("%w: %d", ErrUnknownBinaryEncodingVersion, data[0])
+		}
+		var decoded rlpBroadcastMessage
+		if err := rlp.DecodeBytes(data[1:], &decoded); err != nil {
+			return nil, err
+		}
+		if decoded.Version > uint64(^uint(0)>>1) {
+			return nil, fmt.Errorf("invalid broadcast message version %d", decoded.Version)
+		}
+		return &BroadcastMessage{
+			// #nosec G115
+			Version:                        int(decoded.Version),
+			Messages:                       decoded.Messages,
+			ConfirmedSequenceNumberMessage: decoded.ConfirmedSequenceNumberMessage,
+			KeyRotationMessage:             decoded.KeyRotationMessage,
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown feed encoding %d", encoding)
+	}
+}
@@ -0,0 +1,83 @@
+// Copyright 2026, Offchain Labs, Inc.
+// For license information, see https://github.com/OffchainLabs/nitro/blob/master/LICENSE.md
+
+package message
+
+import (
+	"slices"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/offchainlabs/nitro/arbos/arbostypes"
+)
+
+// Filter selects which feed messages are delivered to a subscriber. A message
+// matches if it satisfies every criterion which is set; Kinds and Posters
+// match if the message has any of the listed values.
+type Filter struct {
+	// Kinds of the L1IncomingMessage header to deliver
+	Kinds []uint8
+	// Posters of the L1IncomingMessage header to deliver
+	Posters []common.Address
+	// TimeboostedOnly only delivers messages containing a timeboosted transaction
+	TimeboostedOnly bool
+}
+
+// IsEmpty returns true if the filter matches every message.
+func (f *Filter) IsEmpty() bool {
+	return f == nil || (len(f.Kinds) == 0 && len(f.Posters) == 0 && !f.TimeboostedOnly)
+}
+
+// Matches returns true if the message should be delivered to the subscriber.
+func (f *Filter) Matches(msg *BroadcastFeedMessage) bool {
+	if f.IsEmpty() {
+		return true
+	}
+	if msg == nil {
+		return false
+	}
+	var header *arbostypes.L1IncomingMessageHeader
+	if msg.Message.Message != nil {
+		header = msg.Message.Message.Header
+	}
+	if len(f.Kinds) > 0 && (header == nil || !slices.Contains(f.Kinds, header.Kind)) {
+		return false
+	}
+	if len(f.Posters) > 0 && (header == nil || !slices.Contains(f.Posters, header.Poster)) {
+		return false
+	}
+	if f.TimeboostedOnly && !msg.HasTimeboostedTx() {
+		return false
+	}
+	return true
+}
+
+// Apply returns the messages matching the filter. The given slice is returned
+// unchanged if the filter is empty.
+func (f *Filter) Apply(msgs []*BroadcastFeedMessage) []*BroadcastFeedMessage {
+	if f.IsEmpty() {
+		return msgs
+	}
+	var matching []*BroadcastFeedMessage
+	for _, msg := range msgs {
+		if f.Matches(msg) {
+			matching = append(matching, msg)
+		}
+	}
+	return matching
+}
+
+// HasTimeboostedTx returns true if the message's block metadata marks any of
+// its transactions as timeboosted. The first byte of the block metadata is
+// its version, followed by a bit per transaction.
+func (m *BroadcastFeedMessage) HasTimeboostedTx() bool {
+	if len(m.BlockMetadata) < 2 {
+		return false
+	}
+	for _, b := range m.BlockMetadata[1:] {
+		if b != 0 {
+			return true
+		}
+	}
+	return false
+}
@@ -38,16 +38,17 @@ type BroadcastMessage struct {
 	// TODO better name than messages since there are different types of messages
 	Messages                       []*BroadcastFeedMessage         `json:"messages,omitempty"`
 	ConfirmedSequenceNumberMessage *ConfirmedSequenceNumberMessage `json:"confirmedSequenceNumberMessage,omitempty"`
+	KeyRotationMessage             *KeyRotationMessage             `json:"keyRotationMessage,omitempty"`
 }
 
 type BroadcastFeedMessage struct {
 	SequenceNumber arbutil.MessageIndex           `json:"sequenceNumber"`
 	Message        arbostypes.MessageWithMetadata `json:"message"`
-	BlockHash      *common.Hash                   `json:"blockHash,omitempty"`
+	BlockHash      *common.Hash                   `json:"blockHash,omitempty" rlp:"nil"`
 	Signature      []byte                         `json:"signatureV2"`
 	BlockMetadata  common.BlockMetadata           `json:"blockMetadata,omitempty"`
 
-	CumulativeSumMsgSize uint64 `json:"-"`
+	CumulativeSumMsgSize uint64 `json:"-" rlp:"-"`
 }
 
 func (m *BroadcastFeedMessage) Size() uint64 {
@@ -92,3 +93,25 @@ func (m *BroadcastFeedMessage) SignatureHash(chainId uint64) common.Hash {
 type ConfirmedSequenceNumberMessage struct {
 	SequenceNumber arbutil.MessageIndex `json:"sequenceNumber"`
 }
+
+// KeyRotationMessage announces that feed messages starting at
+// ActivationSequenceNumber are signed by NewSigner. It is signed by the key
+// which signs the messages before the activation.
+type KeyRotationMessage struct {
+	NewSigner                common.Address       `json:"newSigner"`
+	ActivationSequenceNumber arbutil.MessageIndex `json:"activationSequenceNumber"`
+	Signature                []byte               `json:"signature"`
+}
+
+var keyRotationUniquifyingPrefix = []byte("Arbitrum Nitro Feed Key Rotation:")
+
+// SignatureHash creates the hash of the key rotation which is signed by the
+// previous feed signing key.
+func (m *KeyRotationMessage) SignatureHash(chainId uint64) common.Hash {
+	data := []byte{}
+	data = append(data, keyRotationUniquifyingPrefix...)
+	data = binary.BigEndian.AppendUint64(data, chainId)
+	data = append(data, m.NewSigner.Bytes()...)
+	data = binary.BigEndian.AppendUint64(data, uint64(m.ActivationSequenceNumber))
+	return crypto.Keccak256Hash(data)
+}
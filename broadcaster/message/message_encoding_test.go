@@ -0,0 +1,139 @@
+// Copyright 2026, Offchain Labs, Inc.
+// For license information, see https://github.com/OffchainLabs/nitro/blob/master/LICENSE.md
+
+package message
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/offchainlabs/nitro/arbos/arbostypes"
+)
+
+func testBroadcastMessage() *BroadcastMessage {
+	requestId := common.Hash{0: 0x01}
+	return &BroadcastMessage{
+		Version: V1,
+		Messages: []*BroadcastFeedMessage{
+			{
+				SequenceNumber: 12345,
+				Message: arbostypes.MessageWithMetadata{
+					Message: &arbostypes.L1IncomingMessage{
+						Header: &arbostypes.L1IncomingMessageHeader{
+							Kind:        3,
+							Poster:      common.Address{19: 0xaa},
+							BlockNumber: 10,
+							Timestamp:   20,
+							RequestId:   &requestId,
+							L1BaseFee:   big.NewInt(7),
+						},
+						L2msg: []byte{0xde, 0xad, 0xbe, 0xef},
+					},
+					DelayedMessagesRead: 3333,
+				},
+				BlockHash:     &common.Hash{0: 0xff},
+				Signature:     []byte{1, 2, 3},
+				BlockMetadata: []byte{0, 2},
+			},
+			{
+				SequenceNumber: 12346,
+				Message: arbostypes.MessageWithMetadata{
+					Message: &arbostypes.L1IncomingMessage{
+						Header: &arbostypes.L1IncomingMessageHeader{
+							L1BaseFee: big.NewInt(0),
+						},
+						L2msg: []byte{0xbe, 0xef},
+					},
+					DelayedMessagesRead: 3333,
+				},
+				Signature: []byte{},
+			},
+		},
+		ConfirmedSequenceNumberMessage: &ConfirmedSequenceNumberMessage{
+			SequenceNumber: 12000,
+		},
+	}
+}
+
+func TestBinaryEncodingRoundTrip(t *testing.T) {
+	bm := testBroadcastMessage()
+	data, err := bm.Encode(EncodingBinary)
+	require.NoError(t, err)
+	require.Equal(t, BinaryEncodingVersion, data[0])
+
+	decoded, err := DecodeBroadcastMessage(data, EncodingBinary)
+	require.NoError(t, err)
+	require.Equal(t, bm.Version, decoded.Version)
+	require.Equal(t, bm.ConfirmedSequenceNumberMessage, decoded.ConfirmedSequenceNumberMessage)
+	require.Len(t, decoded.Messages, len(bm.Messages))
+	for i := range bm.Messages {
+		require.Equal(t, bm.Messages[i].SequenceNumber, decoded.Messages[i].SequenceNumber)
+		require.Equal(t, bm.Messages[i].BlockHash, decoded.Messages[i].BlockHash)
+		require.Equal(t, bm.Messages[i].Message.Hash(), decoded.Messages[i].Message.Hash())
+		require.Equal(t, bm.Messages[i].SignatureHash(0xa4b1), decoded.Messages[i].SignatureHash(0xa4b1))
+	}
+
+	jsonData, err := bm.Encode(EncodingJSON)
+	require.NoError(t, err)
+	require.Less(t, len(data), len(jsonData))
+}
+
+func TestBinaryEncodingConfirmationOnly(t *testing.T) {
+	bm := &BroadcastMessage{
+		Version: V1,
+		ConfirmedSequenceNumberMessage: &ConfirmedSequenceNumberMessage{
+			SequenceNumber: 1234,
+		},
+	}
+	data, err := bm.Encode(EncodingBinary)
+	require.NoError(t, err)
+	decoded, err := DecodeBroadcastMessage(data, EncodingBinary)
+	require.NoError(t, err)
+	require.Empty(t, decoded.Messages)
+	require.Equal(t, bm.ConfirmedSequenceNumberMessage, decoded.ConfirmedSequenceNumberMessage)
+
+	bm.ConfirmedSequenceNumberMessage = nil
+	data, err = bm.Encode(EncodingBinary)
+	require.NoError(t, err)
+	decoded, err = DecodeBroadcastMessage(data, EncodingBinary)
+	require.NoError(t, err)
+	require.Nil(t, decoded.ConfirmedSequenceNumberMessage)
+}
+
+func TestBinaryEncodingUnknownVersion(t *testing.T) {
+	data, err := testBroadcastMessage().Encode(EncodingBinary)
+	require.NoError(t, err)
+	data[0] = BinaryEncodingVersion + 1
+	_, err = DecodeBroadcastMessage(data, EncodingBinary)
+	require.ErrorIs(t, err, ErrUnknownBinaryEncodingVersion)
+}
+
+func TestParseEncodings(t *testing.T) {
+	require.Equal(t, []Encoding{EncodingBinary, EncodingJSON}, ParseEncodings("rlp-v1, json"))
+	require.Equal(t, []Encoding{EncodingJSON}, ParseEncodings("cbor,JSON"))
+	require.Empty(t, ParseEncodings(""))
+}
+
+func TestBinaryEncodingKeyRotation(t *testing.T) {
+	bm := &BroadcastMessage{
+		Version: V1,
+		KeyRotationMessage: &KeyRotationMessage{
+			NewSigner:                common.Address{19: 0xbb},
+			ActivationSequenceNumber: 4321,
+			Signature:                []byte{4, 5, 6},
+		},
+	}
+	for _, encoding := range []Encoding{EncodingJSON, EncodingBinary} {
+		data, err := bm.Encode(encoding)
+		require.NoError(t, err)
+		decoded, err := DecodeBroadcastMessage(data, encoding)
+		require.NoError(t, err)
+		require.Empty(t, decoded.Messages)
+		require.Nil(t, decoded.ConfirmedSequenceNumberMessage)
+		require.Equal(t, bm.KeyRotationMessage, decoded.KeyRotationMessage)
+	}
+}
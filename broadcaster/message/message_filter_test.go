@@ -0,0 +1,62 @@
+// Copyright 2026, Offchain Labs, Inc.
+// For license information, see https://github.com/OffchainLabs/nitro/blob/master/LICENSE.md
+
+package message
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/offchainlabs/nitro/arbos/arbostypes"
+)
+
+func TestFilterMatches(t *testing.T) {
+	t.Parallel()
+	poster := common.HexToAddress("0xa4b000000000000000000073657175656e636572")
+	newMessage := func(kind uint8, poster common.Address, blockMetadata common.BlockMetadata) *BroadcastFeedMessage {
+		return &BroadcastFeedMessage{
+			Message: arbostypes.MessageWithMetadata{
+				Message: &arbostypes.L1IncomingMessage{
+					Header: &arbostypes.L1IncomingMessageHeader{Kind: kind, Poster: poster},
+				},
+			},
+			BlockMetadata: blockMetadata,
+		}
+	}
+	l2Message := newMessage(arbostypes.L1MessageType_L2Message, poster, nil)
+	deposit := newMessage(arbostypes.L1MessageType_EthDeposit, common.Address{1}, nil)
+	timeboosted := newMessage(arbostypes.L1MessageType_L2Message, poster, []byte{0, 2})
+	notTimeboosted := newMessage(arbostypes.L1MessageType_L2Message, poster, []byte{0, 0, 0})
+
+	for _, tc := range []struct {
+		name    string
+		filter  *Filter
+		msg     *BroadcastFeedMessage
+		matches bool
+	}{
+		{"nil filter", nil, deposit, true},
+		{"empty filter", &Filter{}, deposit, true},
+		{"kind matches", &Filter{Kinds: []uint8{arbostypes.L1MessageType_EthDeposit, arbostypes.L1MessageType_L2Message}}, l2Message, true},
+		{"kind does not match", &Filter{Kinds: []uint8{arbostypes.L1MessageType_EthDeposit}}, l2Message, false},
+		{"poster matches", &Filter{Posters: []common.Address{poster}}, l2Message, true},
+		{"poster does not match", &Filter{Posters: []common.Address{poster}}, deposit, false},
+		{"timeboosted", &Filter{TimeboostedOnly: true}, timeboosted, true},
+		{"not timeboosted", &Filter{TimeboostedOnly: true}, notTimeboosted, false},
+		{"no block metadata", &Filter{TimeboostedOnly: true}, l2Message, false},
+		{"all criteria", &Filter{Kinds: []uint8{arbostypes.L1MessageType_L2Message}, Posters: []common.Address{poster}, TimeboostedOnly: true}, timeboosted, true},
+		{"one criterion fails", &Filter{Kinds: []uint8{arbostypes.L1MessageType_EthDeposit}, TimeboostedOnly: true}, timeboosted, false},
+		{"missing header", &Filter{Kinds: []uint8{arbostypes.L1MessageType_L2Message}}, &BroadcastFeedMessage{}, false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.filter.Matches(tc.msg); got != tc.matches {
+				t.Errorf("Matches returned %v, expected %v", got, tc.matches)
+			}
+		})
+	}
+
+	filter := &Filter{Kinds: []uint8{arbostypes.L1MessageType_L2Message}}
+	if got := filter.Apply([]*BroadcastFeedMessage{l2Message, deposit, timeboosted}); len(got) != 2 || got[0] != l2Message || got[1] != timeboosted {
+		t.Errorf("unexpected filtered messages %v", got)
+	}
+}
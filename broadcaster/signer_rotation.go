@@ -0,0 +1,140 @@
+// Copyright 2026, Offchain Labs, Inc.
+// For license information, see https://github.com/OffchainLabs/nitro/blob/master/LICENSE.md
+
+package broadcaster
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rlp"
+
+	"github.com/offchainlabs/nitro/arbnode/db/schema"
+	"github.com/offchainlabs/nitro/arbutil"
+	m "github.com/offchainlabs/nitro/broadcaster/message"
+	"github.com/offchainlabs/nitro/util/signature"
+	"github.com/offchainlabs/nitro/wsbroadcastserver"
+)
+
+// SetupSignerRotation restores the feed signer rotations persisted in db and
+// persists the rotations scheduled from then on. The key of the last restored
+// rotation has to be configured in config. If config schedules a rotation
+// which isn't stored yet, it is scheduled and persisted. It must be called
+// before the broadcaster is started.
+func (b *Broadcaster) SetupSignerRotation(db ethdb.KeyValueStore, config *wsbroadcastserver.SignerRotationConfig) error {
+	if config.NewSignerKey != "" {
+		var err error
+		b.rotationSigner, b.rotationSignerAddress, err = loadSignerRotationKey(config.NewSignerKey)
+		if err != nil {
+			return err
+		}
+	}
+	stored, err := readSignerRotations(db)
+	if err != nil {
+		return err
+	}
+	if len(stored) > 0 {
+		last := stored[len(stored)-1]
+		if b.rotationSigner == nil || last.NewSigner != b.rotationSignerAddress {
+			return fmt.Errorf("feed signer was rotated to %v at sequence number %d, its key has to be configured in signer-rotation.new-signer-key", last.NewSigner, last.ActivationSequenceNumber)
+		}
+	}
+	b.signersMutex.Lock()
+	for _, rotation := range stored {
+		dataSigner := b.rotationSigner
+		if rotation.NewSigner != b.rotationSignerAddress {
+			dataSigner = missingSignerKey(rotation.NewSigner)
+		}
+		b.signers = append(b.signers, scheduledSigner{dataSigner: dataSigner, address: rotation.NewSigner, activation: rotation.ActivationSequenceNumber})
+		b.rotations = append(b.rotations, rotation)
+	}
+	b.rotationDB = db
+	b.signersMutex.Unlock()
+	for _, rotation := range stored {
+		b.server.AddKeyRotation(rotation)
+	}
+	if len(stored) > 0 {
+		log.Info("restored feed signer rotations", "count", len(stored), "signer", b.rotationSignerAddress)
+	}
+
+	activation := arbutil.MessageIndex(config.ActivationSequenceNumber)
+	if activation == 0 {
+		return nil
+	}
+	for _, rotation := range stored {
+		if rotation.NewSigner == b.rotationSignerAddress && rotation.ActivationSequenceNumber == activation {
+			return nil
+		}
+	}
+	if b.rotationSigner == nil {
+		return errors.New("feed signer rotation scheduled without signer-rotation.new-signer-key")
+	}
+	rotation, err := b.scheduleSigner(b.rotationSigner, b.rotationSignerAddress, activation)
+	if err != nil {
+		return err
+	}
+	log.Info("rotating feed signer", "newSigner", b.rotationSignerAddress, "activation", activation)
+	b.server.AddKeyRotation(rotation)
+	return nil
+}
+
+// RotateToConfiguredSigner rotates the feed signer to the key configured in
+// signer-rotation.new-signer-key, starting at the activation sequence number.
+func (b *Broadcaster) RotateToConfiguredSigner(activation arbutil.MessageIndex) error {
+	if b.rotationSigner == nil {
+		return errors.New("no feed signer rotation key configured")
+	}
+	return b.RotateDataSigner(b.rotationSigner, b.rotationSignerAddress, activation)
+}
+
+func loadSignerRotationKey(key string) (signature.DataSignerFunc, common.Address, error) {
+	var privateKey *ecdsa.PrivateKey
+	var err error
+	if strings.HasPrefix(key, "0x") {
+		privateKey, err = crypto.HexToECDSA(key[2:])
+	} else {
+		privateKey, err = crypto.LoadECDSA(key)
+	}
+	if err != nil {
+		return nil, common.Address{}, fmt.Errorf("error loading feed signer rotation key: %w", err)
+	}
+	return signature.DataSignerFromPrivateKey(privateKey), crypto.PubkeyToAddress(privateKey.PublicKey), nil
+}
+
+// missingSignerKey is the signer of a restored rotation which was superseded
+// by a later rotation, and whose key is no longer configured.
+func missingSignerKey(address common.Address) signature.DataSignerFunc {
+	return func([]byte) ([]byte, error) {
+		return nil, fmt.Errorf("key of feed signer %v is no longer configured", address)
+	}
+}
+
+func readSignerRotations(db ethdb.KeyValueStore) ([]*m.KeyRotationMessage, error) {
+	has, err := db.Has(schema.FeedSignerRotationsKey)
+	if err != nil || !has {
+		return nil, err
+	}
+	data, err := db.Get(schema.FeedSignerRotationsKey)
+	if err != nil {
+		return nil, err
+	}
+	var rotations []*m.KeyRotationMessage
+	if err := rlp.DecodeBytes(data, &rotations); err != nil {
+		return nil, fmt.Errorf("error decoding feed signer rotations: %w", err)
+	}
+	return rotations, nil
+}
+
+func writeSignerRotations(db ethdb.KeyValueStore, rotations []*m.KeyRotationMessage) error {
+	data, err := rlp.EncodeToBytes(rotations)
+	if err != nil {
+		return err
+	}
+	return db.Put(schema.FeedSignerRotationsKey, data)
+}
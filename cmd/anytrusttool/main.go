@@ -23,8 +23,10 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/ethereum/go-ethereum/log"
 
+	"github.com/offchainlabs/nitro/blsSignatures"
 	"github.com/offchainlabs/nitro/cmd/genericconf"
 	"github.com/offchainlabs/nitro/cmd/util"
 	"github.com/offchainlabs/nitro/cmd/util/confighelpers"
@@ -40,7 +42,7 @@ func main() {
 	}
 	args := os.Args
 	if len(args) < 2 {
-		panic("Usage: anytrusttool [client|keygen|generatehash|dumpkeyset] ...")
+		panic("Usage: anytrusttool [client|keygen|generatehash|dumpkeyset|migrate|rotatekeyset] ...")
 	}
 
 	var err error
@@ -53,8 +55,12 @@ func main() {
 		err = generateHash(args[2])
 	case "dumpkeyset":
 		err = dumpKeyset(args[2:])
+	case "migrate":
+		err = migrateStorage(args[2:])
+	case "rotatekeyset":
+		err = rotateKeyset(args[2:])
 	default:
-		panic(fmt.Sprintf("Unknown tool '%s' specified, valid tools are 'client', 'keygen', 'generatehash'", args[1]))
+		panic(fmt.Sprintf("Unknown tool '%s' specified, valid tools are 'client', 'keygen', 'generatehash', 'dumpkeyset', 'migrate', 'rotatekeyset'", args[1]))
 	}
 	if err != nil {
 		panic(err)
@@ -126,33 +132,9 @@ func startClientStore(args []string) error {
 		return err
 	}
 
-	var signer signature.DataSignerFunc
-	if config.SigningKey != "" {
-		var privateKey *ecdsa.PrivateKey
-		if config.SigningKey[:2] == "0x" {
-			privateKey, err = crypto.HexToECDSA(config.SigningKey[2:])
-			if err != nil {
-				return err
-			}
-		} else {
-			privateKey, err = crypto.LoadECDSA(config.SigningKey)
-			if err != nil {
-				return err
-			}
-		}
-		signer = signature.DataSignerFromPrivateKey(privateKey)
-	} else if config.SigningWallet != "" {
-		walletConf := &genericconf.WalletConfig{
-			Pathname:      config.SigningWallet,
-			Password:      config.SigningWalletPassword,
-			PrivateKey:    "",
-			Account:       "",
-			OnlyCreateKey: false,
-		}
-		_, signer, err = util.OpenWallet("datool", walletConf, nil)
-		if err != nil {
-			return err
-		}
+	signer, err := openSigner(config.SigningKey, config.SigningWallet, config.SigningWalletPassword)
+	if err != nil {
+		return err
 	}
 
 	client, err := anytrust.NewRPCClient(&config.RPCClient, signer)
@@ -189,6 +171,36 @@ func startClientStore(args []string) error {
 	return nil
 }
 
+// openSigner returns the signer of the ecdsa key or wallet given, or nil if
+// neither is given.
+func openSigner(signingKey, signingWallet, signingWalletPassword string) (signature.DataSignerFunc, error) {
+	if signingKey != "" {
+		var privateKey *ecdsa.PrivateKey
+		var err error
+		if signingKey[:2] == "0x" {
+			privateKey, err = crypto.HexToECDSA(signingKey[2:])
+		} else {
+			privateKey, err = crypto.LoadECDSA(signingKey)
+		}
+		if err != nil {
+			return nil, err
+		}
+		return signature.DataSignerFromPrivateKey(privateKey), nil
+	}
+	if signingWallet != "" {
+		walletConf := &genericconf.WalletConfig{
+			Pathname:      signingWallet,
+			Password:      signingWalletPassword,
+			PrivateKey:    "",
+			Account:       "",
+			OnlyCreateKey: false,
+		}
+		_, signer, err := util.OpenWallet("datool", walletConf, nil)
+		return signer, err
+	}
+	return nil, nil
+}
+
 // datool client rest getbyhash
 
 type RESTClientGetByHashConfig struct {
@@ -384,3 +396,321 @@ func dumpKeyset(args []string) error {
 
 	return err
 }
+
+// anytrusttool migrate
+
+type MigrateStorageBackendConfig struct {
+	LocalFileStorage   anytrust.LocalFileStorageConfig          `koanf:"local-file-storage"`
+	S3Storage          anytrust.S3StorageServiceConfig          `koanf:"s3-storage"`
+	GoogleCloudStorage anytrust.GoogleCloudStorageServiceConfig `koanf:"google-cloud-storage"`
+}
+
+func migrateStorageBackendConfigAddOptions(prefix string, f *pflag.FlagSet) {
+	anytrust.LocalFileStorageConfigAddOptions(prefix+".local-file-storage", f)
+	anytrust.S3ConfigAddOptions(prefix+".s3-storage", f)
+	anytrust.GoogleCloudConfigAddOptions(prefix+".google-cloud-storage", f)
+}
+
+func (c *MigrateStorageBackendConfig) validate(prefix string) error {
+	enabled := 0
+	for _, enable := range []bool{c.LocalFileStorage.Enable, c.S3Storage.Enable, c.GoogleCloudStorage.Enable} {
+		if enable {
+			enabled++
+		}
+	}
+	if enabled != 1 {
+		return fmt.Errorf("exactly one of --%[1]s.local-file-storage.enable, --%[1]s.s3-storage.enable and --%[1]s.google-cloud-storage.enable must be set", prefix)
+	}
+	return nil
+}
+
+type MigrateConfig struct {
+	From             MigrateStorageBackendConfig `koanf:"from"`
+	To               MigrateStorageBackendConfig `koanf:"to"`
+	ProgressFile     string                      `koanf:"progress-file"`
+	DefaultRetention time.Duration               `koanf:"default-retention"`
+	SkipExpired      bool                        `koanf:"skip-expired"`
+}
+
+func parseMigrateConfig(args []string) (*MigrateConfig, error) {
+	f := pflag.NewFlagSet("anytrusttool migrate", pflag.ContinueOnError)
+	migrateStorageBackendConfigAddOptions("from", f)
+	migrateStorageBackendConfigAddOptions("to", f)
+	f.String("progress-file", "", "file recording the hash of the last migrated batch, the migration resumes after it if the file exists")
+	f.Duration("default-retention", anytrust.DefaultLocalFileStorageConfig.MaxRetention, "retention period from now of batches whose source storage doesn't record their expiry")
+	f.Bool("skip-expired", false, "don't migrate batches whose expiry has passed")
+
+	k, err := confighelpers.BeginCommonParse(f, args)
+	if err != nil {
+		return nil, err
+	}
+
+	var config MigrateConfig
+	if err := confighelpers.EndCommonParse(k, &config); err != nil {
+		return nil, err
+	}
+	if err := config.From.validate("from"); err != nil {
+		return nil, err
+	}
+	if err := config.To.validate("to"); err != nil {
+		return nil, err
+	}
+	return &config, nil
+}
+
+func createMigrationStorageService(ctx context.Context, config *MigrateStorageBackendConfig) (anytrust.StorageService, *anytrust.LifecycleManager, error) {
+	storageConfig := anytrust.DefaultConfig
+	storageConfig.LocalFileStorage = config.LocalFileStorage
+	storageConfig.S3Storage = config.S3Storage
+	storageConfig.GoogleCloudStorage = config.GoogleCloudStorage
+	return anytrust.CreatePersistentStorageService(ctx, &storageConfig)
+}
+
+func readMigrationProgress(progressFile string) (common.Hash, error) {
+	if progressFile == "" {
+		return common.Hash{}, nil
+	}
+	contents, err := os.ReadFile(progressFile)
+	if errors.Is(err, os.ErrNotExist) {
+		return common.Hash{}, nil
+	}
+	if err != nil {
+		return common.Hash{}, err
+	}
+	last, err := hexutil.Decode(strings.TrimSpace(string(contents)))
+	if err != nil || len(last) != common.HashLength {
+		return common.Hash{}, fmt.Errorf("invalid progress file %s: %q", progressFile, contents)
+	}
+	return common.BytesToHash(last), nil
+}
+
+func writeMigrationProgress(progressFile string, last common.Hash) error {
+	tmpFile := progressFile + ".tmp"
+	if err := os.WriteFile(tmpFile, []byte(last.Hex()+"\n"), 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmpFile, progressFile)
+}
+
+func migrateStorage(args []string) error {
+	config, err := parseMigrateConfig(args)
+	if err != nil {
+		return err
+	}
+	startAfter, err := readMigrationProgress(config.ProgressFile)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	from, fromLifecycleManager, err := createMigrationStorageService(ctx, &config.From)
+	if err != nil {
+		return err
+	}
+	defer fromLifecycleManager.StopAndWaitUntil(time.Minute)
+	iterableFrom, ok := from.(anytrust.IterableStorageService)
+	if !ok {
+		return fmt.Errorf("can't iterate over the batches of %v", from)
+	}
+	to, toLifecycleManager, err := createMigrationStorageService(ctx, &config.To)
+	if err != nil {
+		return err
+	}
+	defer toLifecycleManager.StopAndWaitUntil(time.Minute)
+
+	opts := anytrust.StorageMigrationOpts{
+		StartAfter: startAfter,
+		// #nosec G115
+		DefaultExpiry: uint64(time.Now().Add(config.DefaultRetention).Unix()),
+		SkipExpired:   config.SkipExpired,
+	}
+	if config.ProgressFile != "" {
+		opts.OnBatch = func(key common.Hash) error {
+			return writeMigrationProgress(config.ProgressFile, key)
+		}
+	}
+	if startAfter != (common.Hash{}) {
+		fmt.Printf("Resuming migration after batch %v\n", startAfter)
+	}
+	stats, err := anytrust.MigrateStorage(ctx, iterableFrom, to, opts)
+	fmt.Printf("Copied %d batches, skipped %d expired batches, last batch %v\n", stats.Copied, stats.Skipped, stats.Last)
+	return err
+}
+
+// anytrusttool rotatekeyset
+
+type RotateKeysetConfig struct {
+	Keyset                anytrust.AggregatorConfig `koanf:"keyset"`
+	CurrentKeyset         string                    `koanf:"current-keyset"`
+	CurrentKeysetHash     string                    `koanf:"current-keyset-hash"`
+	ParentChainNodeURL    string                    `koanf:"parent-chain-node-url"`
+	SequencerInboxAddress string                    `koanf:"sequencer-inbox-address"`
+	Validate              bool                      `koanf:"validate"`
+	RetentionPeriod       time.Duration             `koanf:"retention-period"`
+	RequestTimeout        time.Duration             `koanf:"request-timeout"`
+	SigningKey            string                    `koanf:"signing-key"`
+	SigningWallet         string                    `koanf:"signing-wallet"`
+	SigningWalletPassword string                    `koanf:"signing-wallet-password"`
+	Conf                  genericconf.ConfConfig    `koanf:"conf"`
+}
+
+func parseRotateKeysetConfig(args []string) (*RotateKeysetConfig, error) {
+	f := pflag.NewFlagSet("anytrusttool rotatekeyset", pflag.ContinueOnError)
+
+	anytrust.AggregatorConfigAddOptions("keyset", f)
+	f.String("current-keyset", "", "hex encoded current keyset, to compare the proposed keyset with")
+	f.String("current-keyset-hash", "", "hash of the current keyset, to fetch it from the sequencer inbox on the parent chain and compare the proposed keyset with")
+	f.String("parent-chain-node-url", "", "URL of a parent chain node, used with current-keyset-hash")
+	f.String("sequencer-inbox-address", "", "address of the sequencer inbox, used with current-keyset-hash")
+	f.Bool("validate", true, "store a message with each member of the proposed committee, and check that it signs it with its public key in the proposed keyset")
+	f.Duration("retention-period", time.Hour, "the period for which committee members are requested to retain the message stored to validate the proposed keyset")
+	f.Duration("request-timeout", anytrust.DefaultConfig.RequestTimeout, "timeout for the request storing the message with each member of the proposed committee")
+	f.String("signing-key", "", "ecdsa private key of the batch poster to sign the message stored to validate the proposed keyset with, treated as a hex string if prefixed with 0x otherwise treated as a file")
+	f.String("signing-wallet", "", "wallet containing the ecdsa key of the batch poster to sign the message stored to validate the proposed keyset with")
+	f.String("signing-wallet-password", genericconf.PASSWORD_NOT_SET, "password to unlock the wallet, if not specified the user is prompted for the password")
+	genericconf.ConfConfigAddOptions("conf", f)
+
+	k, err := confighelpers.BeginCommonParse(f, args)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = anytrust.FixKeysetCLIParsing("keyset.backends", k); err != nil {
+		return nil, err
+	}
+
+	var config RotateKeysetConfig
+	if err := confighelpers.EndCommonParse(k, &config); err != nil {
+		return nil, err
+	}
+
+	if config.Conf.Dump {
+		c, err := k.Marshal(json.Parser())
+		if err != nil {
+			return nil, fmt.Errorf("unable to marshal config file to JSON: %w", err)
+		}
+
+		fmt.Println(string(c))
+		os.Exit(0)
+	}
+
+	if config.Keyset.AssumedHonest == 0 {
+		return nil, errors.New("--keyset.assumed-honest must be set")
+	}
+	if config.Keyset.Backends == nil {
+		return nil, errors.New("--keyset.backends must be set")
+	}
+	if config.CurrentKeyset != "" && config.CurrentKeysetHash != "" {
+		return nil, errors.New("only one of --current-keyset and --current-keyset-hash can be set")
+	}
+	if config.CurrentKeysetHash != "" && (config.ParentChainNodeURL == "" || !common.IsHexAddress(config.SequencerInboxAddress)) {
+		return nil, errors.New("--parent-chain-node-url and --sequencer-inbox-address must be set to fetch the current keyset")
+	}
+
+	return &config, nil
+}
+
+func fetchCurrentKeyset(ctx context.Context, config *RotateKeysetConfig) ([]byte, error) {
+	if config.CurrentKeyset != "" {
+		return hexutil.Decode(config.CurrentKeyset)
+	}
+	keysetHash, err := hexutil.Decode(config.CurrentKeysetHash)
+	if err != nil {
+		return nil, err
+	}
+	l1Client, err := ethclient.DialContext(ctx, config.ParentChainNodeURL)
+	if err != nil {
+		return nil, err
+	}
+	defer l1Client.Close()
+	keysetFetcher, err := anytrust.NewKeysetFetcher(l1Client, common.HexToAddress(config.SequencerInboxAddress))
+	if err != nil {
+		return nil, err
+	}
+	return keysetFetcher.GetKeysetByHash(ctx, common.BytesToHash(keysetHash))
+}
+
+func encodePubKey(pubKey blsSignatures.PublicKey) string {
+	return base64.StdEncoding.EncodeToString(blsSignatures.PublicKeyToBytes(pubKey))
+}
+
+func rotateKeyset(args []string) error {
+	config, err := parseRotateKeysetConfig(args)
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	signer, err := openSigner(config.SigningKey, config.SigningWallet, config.SigningWalletPassword)
+	if err != nil {
+		return err
+	}
+	if signer == nil {
+		// As in dumpkeyset, chunked store requires a signer
+		config.Keyset.RPCClient.EnableChunkedStore = false
+	}
+	services, err := anytrust.ParseServices(config.Keyset, signer)
+	if err != nil {
+		return err
+	}
+
+	// #nosec G115
+	keysetHash, keysetBytes, err := anytrust.KeysetHashFromServices(services, uint64(config.Keyset.AssumedHonest))
+	if err != nil {
+		return err
+	}
+	proposed, err := anytrustutil.DeserializeKeyset(bytes.NewReader(keysetBytes), false)
+	if err != nil {
+		return fmt.Errorf("invalid proposed keyset: %w", err)
+	}
+	fmt.Printf("Keyset: %s\n", hexutil.Encode(keysetBytes))
+	fmt.Printf("KeysetHash: %s\n", hexutil.Encode(keysetHash[:]))
+
+	if config.CurrentKeyset != "" || config.CurrentKeysetHash != "" {
+		currentBytes, err := fetchCurrentKeyset(ctx, config)
+		if err != nil {
+			return fmt.Errorf("couldn't get the current keyset: %w", err)
+		}
+		current, err := anytrustutil.DeserializeKeyset(bytes.NewReader(currentBytes), true)
+		if err != nil {
+			return fmt.Errorf("invalid current keyset: %w", err)
+		}
+		diff := anytrust.DiffKeysets(current, proposed)
+		fmt.Printf("Current KeysetHash: %s\n", hexutil.Encode(tree.HashBytes(currentBytes)))
+		fmt.Printf("Assumed honest: %d -> %d\n", diff.CurrentAssumedHonest, diff.ProposedAssumedHonest)
+		fmt.Printf("Members: %d -> %d (%d kept)\n", len(current.PubKeys), len(proposed.PubKeys), len(diff.Kept))
+		for _, pubKey := range diff.Added {
+			fmt.Printf("+ %s\n", encodePubKey(pubKey))
+		}
+		for _, pubKey := range diff.Removed {
+			fmt.Printf("- %s\n", encodePubKey(pubKey))
+		}
+	}
+
+	if !config.Validate {
+		return nil
+	}
+	message := make([]byte, 32)
+	if _, err := rand.Read(message); err != nil {
+		return err
+	}
+	// #nosec G115
+	checks := anytrust.CheckKeysetMembers(ctx, services, message, uint64(time.Now().Add(config.RetentionPeriod).Unix()), config.RequestTimeout)
+	failures := 0
+	for _, check := range checks {
+		if check.Err != nil {
+			failures++
+			fmt.Printf("FAILED %s: %v\n", check.Member, check.Err)
+		} else {
+			fmt.Printf("OK     %s\n", check.Member)
+		}
+	}
+	// A certificate needs all but at most assumed-honest - 1 members to sign
+	if failures >= config.Keyset.AssumedHonest {
+		return fmt.Errorf("%d of %d members of the proposed committee failed validation, so it can't sign certificates", failures, len(checks))
+	}
+	if failures > 0 {
+		fmt.Printf("%d of %d members of the proposed committee failed validation, but it can still sign certificates\n", failures, len(checks))
+	}
+	return nil
+}
@@ -0,0 +1,157 @@
+// Copyright 2026, Offchain Labs, Inc.
+// For license information, see https://github.com/OffchainLabs/nitro/blob/master/LICENSE.md
+
+// This is a command line tool for simulating the batches the batch poster
+// would post for a range of messages of a node database, to tune the batch
+// poster config before deploying it.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	flag "github.com/spf13/pflag"
+
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/node"
+	"github.com/ethereum/go-ethereum/params"
+
+	"github.com/offchainlabs/nitro/arbnode"
+	"github.com/offchainlabs/nitro/arbnode/db/read"
+	"github.com/offchainlabs/nitro/arbos/arbostypes"
+	"github.com/offchainlabs/nitro/arbutil"
+	"github.com/offchainlabs/nitro/cmd/util/confighelpers"
+	"github.com/offchainlabs/nitro/util/dbutil"
+	"github.com/offchainlabs/nitro/util/floatmath"
+)
+
+func main() {
+	if err := simulate(os.Args[1:]); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+type BatchSimToolConfig struct {
+	Data                       string                    `koanf:"data"`
+	DBEngine                   string                    `koanf:"db-engine"`
+	From                       uint64                    `koanf:"from"`
+	To                         uint64                    `koanf:"to"`
+	Backlog                    uint64                    `koanf:"backlog"`
+	MaxUncompressedBatchSize   uint64                    `koanf:"max-uncompressed-batch-size"`
+	MaxBlobGasPerBlock         uint64                    `koanf:"max-blob-gas-per-block"`
+	ParentChainBaseFeeGwei     float64                   `koanf:"parent-chain-base-fee-gwei"`
+	ParentChainBlobBaseFeeGwei float64                   `koanf:"parent-chain-blob-base-fee-gwei"`
+	ParentChainEIP7623         bool                      `koanf:"parent-chain-eip7623"`
+	PrintBatches               bool                      `koanf:"print-batches"`
+	BatchPoster                arbnode.BatchPosterConfig `koanf:"batch-poster"`
+}
+
+func parseConfig(args []string) (*BatchSimToolConfig, error) {
+	f := flag.NewFlagSet("batchsimtool", flag.ContinueOnError)
+	f.String("data", "", "directory of the node's arbitrumdata database to read the messages from")
+	f.String("db-engine", "", "backing database implementation ('leveldb', 'pebble' or '' = auto-detect)")
+	f.Uint64("from", 0, "first message to put into batches")
+	f.Uint64("to", 0, "message to stop before (0 = the node's message count)")
+	f.Uint64("backlog", 0, "batch backlog selecting the compression level of batch-poster.compression-levels")
+	f.Uint64("max-uncompressed-batch-size", params.DefaultMaxUncompressedBatchSize, "maximum uncompressed batch size of the chain")
+	f.Uint64("max-blob-gas-per-block", 9*params.BlobTxBlobGasPerBlob, "max blob gas per parent chain block, sizing 4844 batches if batch-poster.max-4844-batch-size isn't set")
+	f.Float64("parent-chain-base-fee-gwei", 1, "parent chain base fee to estimate the cost of the batches with")
+	f.Float64("parent-chain-blob-base-fee-gwei", 1, "parent chain blob base fee to estimate the cost of 4844 batches with")
+	f.Bool("parent-chain-eip7623", true, "if the parent chain charges the EIP-7623 calldata floor")
+	f.Bool("print-batches", false, "print every simulated batch, not only the totals")
+	arbnode.BatchPosterConfigAddOptions("batch-poster", f)
+
+	k, err := confighelpers.BeginCommonParse(f, args)
+	if err != nil {
+		return nil, err
+	}
+	if err := arbnode.FixCompressionLevelsCLIParsing("batch-poster.compression-levels", k); err != nil {
+		return nil, err
+	}
+
+	var config BatchSimToolConfig
+	if err := confighelpers.EndCommonParse(k, &config); err != nil {
+		return nil, err
+	}
+
+	if config.Data == "" {
+		return nil, errors.New("--data is required")
+	}
+	if err := config.BatchPoster.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &config, nil
+}
+
+type dbMessageSource struct {
+	db ethdb.Database
+}
+
+func (s *dbMessageSource) GetMessage(msgIdx arbutil.MessageIndex) (*arbostypes.MessageWithMetadata, error) {
+	return read.Message(s.db, msgIdx)
+}
+
+func simulate(args []string) error {
+	config, err := parseConfig(args)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	db, err := node.OpenDatabase(node.InternalOpenOptions{
+		DbEngine:  config.DBEngine,
+		Directory: config.Data,
+		DatabaseOptions: node.DatabaseOptions{
+			MetricsNamespace: "arbitrumdata/",
+			NoFreezer:        true,
+			ReadOnly:         true,
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("opening database %s: %w", config.Data, err)
+	}
+	defer db.Close()
+	if err := dbutil.UnfinishedConversionCheck(db); err != nil {
+		return err
+	}
+
+	end := arbutil.MessageIndex(config.To)
+	if end == 0 {
+		end, err = read.MessageCount(db)
+		if err != nil {
+			return fmt.Errorf("reading message count: %w", err)
+		}
+	}
+	result, err := arbnode.SimulateBatches(ctx, &arbnode.BatchSimulationOpts{
+		Config:                   &config.BatchPoster,
+		Messages:                 &dbMessageSource{db: db},
+		Start:                    arbutil.MessageIndex(config.From),
+		End:                      end,
+		Backlog:                  config.Backlog,
+		MaxUncompressedBatchSize: config.MaxUncompressedBatchSize,
+		MaxBlobGasPerBlock:       config.MaxBlobGasPerBlock,
+		ParentChainBaseFee:       floatmath.FloatToBig(config.ParentChainBaseFeeGwei * params.GWei),
+		ParentChainBlobBaseFee:   floatmath.FloatToBig(config.ParentChainBlobBaseFeeGwei * params.GWei),
+		ParentChainEIP7623:       config.ParentChainEIP7623,
+	})
+	if err != nil {
+		return err
+	}
+	if !config.PrintBatches {
+		result.Batches = nil
+	}
+	output, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(output))
+	return nil
+}
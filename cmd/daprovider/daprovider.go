@@ -311,20 +311,32 @@ func startup() error {
 		if err := factory.ValidateConfig(); err != nil {
 			return err
 		}
-		reader, _, err = factory.CreateReader(ctx)
+		var readerCleanup func()
+		reader, readerCleanup, err = factory.CreateReader(ctx)
 		if err != nil {
 			return err
 		}
+		if readerCleanup != nil {
+			cleanupFuncs = append(cleanupFuncs, readerCleanup)
+		}
 		if config.ProviderServer.EnableDAWriter {
-			writer, _, err = factory.CreateWriter(ctx)
+			var writerCleanup func()
+			writer, writerCleanup, err = factory.CreateWriter(ctx)
 			if err != nil {
 				return err
 			}
+			if writerCleanup != nil {
+				cleanupFuncs = append(cleanupFuncs, writerCleanup)
+			}
 		}
-		validator, _, err = factory.CreateValidator(ctx)
+		var validatorCleanup func()
+		validator, validatorCleanup, err = factory.CreateValidator(ctx)
 		if err != nil {
 			return err
 		}
+		if validatorCleanup != nil {
+			cleanupFuncs = append(cleanupFuncs, validatorCleanup)
+		}
 		headerBytes = []byte{daprovider.DACertificateMessageHeaderFlag}
 
 	default:
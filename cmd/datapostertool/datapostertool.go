@@ -0,0 +1,176 @@
+// Copyright 2026, Offchain Labs, Inc.
+// For license information, see https://github.com/OffchainLabs/nitro/blob/master/LICENSE.md
+
+// This is a command line tool for inspecting the data poster queues of a
+// node, and intervening on stuck transactions, through the dataposter admin
+// RPC namespace.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	flag "github.com/spf13/pflag"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/offchainlabs/nitro/arbnode/dataposter"
+	"github.com/offchainlabs/nitro/cmd/util/confighelpers"
+)
+
+func main() {
+	args := os.Args
+	if len(args) < 2 {
+		fmt.Println("Usage: datapostertool [list-posters|list-queue|bump-fees|cancel-nonce|drop-confirmed] ...")
+		os.Exit(1)
+	}
+
+	var err error
+	switch strings.ToLower(args[1]) {
+	case "list-posters":
+		err = listPosters(args[2:])
+	case "list-queue":
+		err = listQueue(args[2:])
+	case "bump-fees":
+		err = bumpFees(args[2:])
+	case "cancel-nonce":
+		err = cancelNonce(args[2:])
+	case "drop-confirmed":
+		err = dropConfirmed(args[2:])
+	default:
+		err = fmt.Errorf("unknown command '%s', valid commands are: list-posters, list-queue, bump-fees, cancel-nonce, drop-confirmed", args[1])
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+type DataPosterToolConfig struct {
+	URL    string `koanf:"url"`
+	Poster string `koanf:"poster"`
+	Nonce  uint64 `koanf:"nonce"`
+	Max    uint64 `koanf:"max"`
+}
+
+func parseConfig(command string, args []string, needsPoster bool, needsNonce bool) (*DataPosterToolConfig, error) {
+	f := flag.NewFlagSet("datapostertool "+command, flag.ContinueOnError)
+	f.String("url", "", "url of the node's RPC endpoint exposing the "+dataposter.AdminNamespace+" namespace, for example ws://localhost:8549")
+	if needsPoster {
+		f.String("poster", "batch-poster", "name of the data poster, batch-poster or staker")
+	}
+	if needsNonce {
+		f.Uint64("nonce", 0, "nonce of the queued transaction")
+	}
+	if command == "list-queue" {
+		f.Uint64("max", 1000, "maximum number of queued transactions to list")
+	}
+
+	k, err := confighelpers.BeginCommonParse(f, args)
+	if err != nil {
+		return nil, err
+	}
+
+	var config DataPosterToolConfig
+	if err := confighelpers.EndCommonParse(k, &config); err != nil {
+		return nil, err
+	}
+
+	if config.URL == "" {
+		return nil, fmt.Errorf("--url is required")
+	}
+	if needsNonce && !k.Exists("nonce") {
+		return nil, fmt.Errorf("--nonce is required")
+	}
+
+	return &config, nil
+}
+
+func call(config *DataPosterToolConfig, result interface{}, method string, args ...interface{}) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	client, err := rpc.DialContext(ctx, config.URL)
+	if err != nil {
+		return fmt.Errorf("failed to connect to %s: %w", config.URL, err)
+	}
+	defer client.Close()
+	return client.CallContext(ctx, result, dataposter.AdminNamespace+"_"+method, args...)
+}
+
+func printJSON(value interface{}) error {
+	output, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(output))
+	return nil
+}
+
+func listPosters(args []string) error {
+	config, err := parseConfig("list-posters", args, false, false)
+	if err != nil {
+		return err
+	}
+	var posters map[string]common.Address
+	if err := call(config, &posters, "posters"); err != nil {
+		return fmt.Errorf("failed to list data posters: %w", err)
+	}
+	return printJSON(posters)
+}
+
+func listQueue(args []string) error {
+	config, err := parseConfig("list-queue", args, true, false)
+	if err != nil {
+		return err
+	}
+	var queue []dataposter.QueuedTransactionInfo
+	if err := call(config, &queue, "queue", config.Poster, config.Max); err != nil {
+		return fmt.Errorf("failed to list queue: %w", err)
+	}
+	return printJSON(queue)
+}
+
+func bumpFees(args []string) error {
+	config, err := parseConfig("bump-fees", args, true, true)
+	if err != nil {
+		return err
+	}
+	var hash common.Hash
+	if err := call(config, &hash, "bumpFees", config.Poster, config.Nonce); err != nil {
+		return fmt.Errorf("failed to bump fees: %w", err)
+	}
+	fmt.Printf("Replaced transaction with nonce %d by %s\n", config.Nonce, hash)
+	return nil
+}
+
+func cancelNonce(args []string) error {
+	config, err := parseConfig("cancel-nonce", args, true, true)
+	if err != nil {
+		return err
+	}
+	var hash common.Hash
+	if err := call(config, &hash, "cancelNonce", config.Poster, config.Nonce); err != nil {
+		return fmt.Errorf("failed to cancel nonce: %w", err)
+	}
+	fmt.Printf("Cancelled transaction with nonce %d by self transfer %s\n", config.Nonce, hash)
+	return nil
+}
+
+func dropConfirmed(args []string) error {
+	config, err := parseConfig("drop-confirmed", args, true, false)
+	if err != nil {
+		return err
+	}
+	var dropped int
+	if err := call(config, &dropped, "dropConfirmed", config.Poster); err != nil {
+		return fmt.Errorf("failed to drop confirmed transactions: %w", err)
+	}
+	fmt.Printf("Dropped %d confirmed transactions\n", dropped)
+	return nil
+}
@@ -0,0 +1,160 @@
+// Copyright 2026, Offchain Labs, Inc.
+// For license information, see https://github.com/OffchainLabs/nitro/blob/master/LICENSE.md
+
+// This is a command line tool for inspecting and requeuing the dead letters of
+// pubsub streams, such as the validation request streams.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	flag "github.com/spf13/pflag"
+
+	"github.com/offchainlabs/nitro/cmd/util/confighelpers"
+	"github.com/offchainlabs/nitro/pubsub"
+)
+
+func main() {
+	args := os.Args
+	if len(args) < 2 {
+		fmt.Println("Usage: pubsubtool [list-dead-letters|requeue-dead-letter|drop-dead-letter] ...")
+		os.Exit(1)
+	}
+
+	var err error
+	switch strings.ToLower(args[1]) {
+	case "list-dead-letters":
+		err = listDeadLetters(args[2:])
+	case "requeue-dead-letter":
+		err = requeueDeadLetter(args[2:])
+	case "drop-dead-letter":
+		err = dropDeadLetter(args[2:])
+	default:
+		err = fmt.Errorf("unknown command '%s', valid commands are: list-dead-letters, requeue-dead-letter, drop-dead-letter", args[1])
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+type DeadLetterConfig struct {
+	URL    string `koanf:"url"`
+	Stream string `koanf:"stream"`
+	ID     string `koanf:"id"`
+	Start  string `koanf:"start"`
+	Count  int64  `koanf:"count"`
+}
+
+func parseDeadLetterConfig(command string, args []string, needsID bool) (*DeadLetterConfig, error) {
+	f := flag.NewFlagSet("pubsubtool "+command, flag.ContinueOnError)
+	f.String("url", "", "pubsub transport url, for example redis://localhost:6379 or pubsub://localhost:9650")
+	f.String("stream", "", "name of the stream whose dead letters to use, for example the validation stream of a module root")
+	if needsID {
+		f.String("id", "", "ID of the dead letter")
+	} else {
+		f.String("start", "", "ID of the first dead letter to list (default the oldest one)")
+		f.Int64("count", 100, "maximum number of dead letters to list")
+	}
+
+	k, err := confighelpers.BeginCommonParse(f, args)
+	if err != nil {
+		return nil, err
+	}
+
+	var config DeadLetterConfig
+	if err := confighelpers.EndCommonParse(k, &config); err != nil {
+		return nil, err
+	}
+
+	if config.URL == "" {
+		return nil, fmt.Errorf("--url is required")
+	}
+	if config.Stream == "" {
+		return nil, fmt.Errorf("--stream is required")
+	}
+	if needsID && config.ID == "" {
+		return nil, fmt.Errorf("--id is required")
+	}
+
+	return &config, nil
+}
+
+func deadLetterQueue(config *DeadLetterConfig) (*pubsub.DeadLetterQueue, error) {
+	transport, err := pubsub.TransportFromURL(config.URL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create transport: %w", err)
+	}
+	return pubsub.NewDeadLetterQueue(transport, config.Stream), nil
+}
+
+func listDeadLetters(args []string) error {
+	config, err := parseDeadLetterConfig("list-dead-letters", args, false)
+	if err != nil {
+		return err
+	}
+	queue, err := deadLetterQueue(config)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	letters, err := queue.List(ctx, config.Start, config.Count)
+	if err != nil {
+		return fmt.Errorf("failed to list dead letters: %w", err)
+	}
+	output, err := json.MarshalIndent(letters, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(output))
+	return nil
+}
+
+func requeueDeadLetter(args []string) error {
+	config, err := parseDeadLetterConfig("requeue-dead-letter", args, true)
+	if err != nil {
+		return err
+	}
+	queue, err := deadLetterQueue(config)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	requestID, err := queue.Requeue(ctx, config.ID)
+	if err != nil {
+		return fmt.Errorf("failed to requeue dead letter: %w", err)
+	}
+	fmt.Printf("Requeued dead letter %s as request %s\n", config.ID, requestID)
+	return nil
+}
+
+func dropDeadLetter(args []string) error {
+	config, err := parseDeadLetterConfig("drop-dead-letter", args, true)
+	if err != nil {
+		return err
+	}
+	queue, err := deadLetterQueue(config)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	if err := queue.Drop(ctx, config.ID); err != nil {
+		return fmt.Errorf("failed to drop dead letter: %w", err)
+	}
+	fmt.Printf("Dropped dead letter %s\n", config.ID)
+	return nil
+}
@@ -347,7 +347,22 @@ func main() {
 			panic(fmt.Sprintf("Failed to register DA Certificate reader: %v", err))
 		}
 
-		inboxMultiplexer := arbstate.NewInboxMultiplexer(backend, delayedMessagesRead, dapReaders, keysetValidationMode, chainConfig)
+		// Like the inbox tracker, the batch is decoded with the ArbOS version
+		// before its first message, even past its first message.
+		arbOSVersion := func() (uint64, error) {
+			if lastBlockHeader == nil {
+				return 0, nil
+			}
+			return arbstate.ArbOSVersionBeforeBatch(
+				lastBlockHeader,
+				backend.GetPositionWithinMessage(),
+				chainConfig.ArbitrumChainParams.GenesisBlockNum,
+				func(hash common.Hash) (*types.Header, error) {
+					return getBlockHeaderByHash(hash), nil
+				},
+			)
+		}
+		inboxMultiplexer := arbstate.NewInboxMultiplexer(backend, delayedMessagesRead, dapReaders, keysetValidationMode, arbOSVersion, chainConfig)
 		ctx := context.Background()
 		message, err := inboxMultiplexer.Pop(ctx)
 		if err != nil {
@@ -66,3 +66,32 @@ pub extern "C" fn brotli_decompress(
     }
     BrotliStatus::Success
 }
+
+/// Zstd compresses the given Go data into a buffer of limited capacity.
+#[unsafe(no_mangle)]
+pub extern "C" fn zstd_compress(
+    input: BrotliBuffer,
+    mut output: BrotliBuffer,
+    level: u32,
+) -> BrotliStatus {
+    let compressed = crate::zstd::compress(input.as_slice(), level);
+    let buffer = output.as_uninit();
+    if compressed.len() > buffer.len() {
+        return BrotliStatus::NeedsMoreOutput;
+    }
+    for (dest, byte) in buffer.iter_mut().zip(&compressed) {
+        dest.write(*byte);
+    }
+    unsafe { *output.len = compressed.len() }
+    BrotliStatus::Success
+}
+
+/// Zstd decompresses the given Go data into a buffer of limited capacity.
+#[unsafe(no_mangle)]
+pub extern "C" fn zstd_decompress(input: BrotliBuffer, mut output: BrotliBuffer) -> BrotliStatus {
+    match crate::zstd::decompress_fixed(input.as_slice(), output.as_uninit()) {
+        Ok(slice) => unsafe { *output.len = slice.len() },
+        Err(status) => return status,
+    }
+    BrotliStatus::Success
+}
@@ -16,6 +16,7 @@ use core::{
 pub mod cgo;
 mod dicts;
 mod types;
+pub mod zstd;
 
 #[cfg(feature = "wasmer_traits")]
 mod wasmer_traits;
@@ -0,0 +1,45 @@
+// Copyright 2026, Offchain Labs, Inc.
+// For license information, see https://github.com/OffchainLabs/nitro/blob/master/LICENSE.md
+
+//! Zstandard compression, an alternative to brotli for batch data.
+
+#[cfg(target_arch = "wasm32")]
+use alloc::vec::Vec;
+use core::{mem::MaybeUninit, slice};
+
+use ruzstd::{
+    decoding::{FrameDecoder, errors::FrameDecoderError},
+    encoding::{CompressionLevel, compress_to_vec},
+};
+
+use crate::BrotliStatus;
+
+/// Zstd compresses a slice into a vec.
+/// Only the fastest zstd level is implemented, which any non-zero level uses.
+pub fn compress(input: &[u8], level: u32) -> Vec<u8> {
+    let level = match level {
+        0 => CompressionLevel::Uncompressed,
+        _ => CompressionLevel::Fastest,
+    };
+    compress_to_vec(input, level)
+}
+
+/// Zstd decompresses a slice into a buffer of limited capacity.
+/// Returns `NeedsMoreOutput` if the decompressed data doesn't fit.
+pub fn decompress_fixed<'a>(
+    input: &[u8],
+    output: &'a mut [MaybeUninit<u8>],
+) -> Result<&'a [u8], BrotliStatus> {
+    output.fill(MaybeUninit::new(0));
+
+    // SAFETY: the span of bytes was just initialized
+    let len = output.len();
+    let output = unsafe { slice::from_raw_parts_mut(output.as_mut_ptr() as *mut u8, len) };
+
+    let mut decoder = FrameDecoder::new();
+    match decoder.decode_all(input, output) {
+        Ok(len) => Ok(&output[..len]),
+        Err(FrameDecoderError::TargetTooSmall) => Err(BrotliStatus::NeedsMoreOutput),
+        Err(_) => Err(BrotliStatus::Failure),
+    }
+}
@@ -72,3 +72,52 @@ pub fn brotli_decompress<M: MemAccess, E: ExecEnv>(
         Err(status) => status,
     }
 }
+
+/// Zstd compresses a go slice
+///
+/// The output buffer must be sufficiently large.
+/// The pointers must not be null.
+pub fn zstd_compress<M: MemAccess, E: ExecEnv>(
+    mem: &mut M,
+    _env: &mut E,
+    in_buf_ptr: GuestPtr,
+    in_buf_len: u32,
+    out_buf_ptr: GuestPtr,
+    out_len_ptr: GuestPtr,
+    level: u32,
+) -> BrotliStatus {
+    let input = mem.read_slice(in_buf_ptr, in_buf_len as usize);
+    let output = brotli::zstd::compress(&input, level);
+    if output.len() > mem.read_u32(out_len_ptr) as usize {
+        return BrotliStatus::NeedsMoreOutput;
+    }
+    mem.write_slice(out_buf_ptr, &output);
+    mem.write_u32(out_len_ptr, output.len() as u32);
+    BrotliStatus::Success
+}
+
+/// Zstd decompresses a go slice
+///
+/// The output buffer must be sufficiently large.
+/// The pointers must not be null.
+pub fn zstd_decompress<M: MemAccess, E: ExecEnv>(
+    mem: &mut M,
+    _env: &mut E,
+    in_buf_ptr: GuestPtr,
+    in_buf_len: u32,
+    out_buf_ptr: GuestPtr,
+    out_len_ptr: GuestPtr,
+) -> BrotliStatus {
+    let input = mem.read_slice(in_buf_ptr, in_buf_len as usize);
+    let mut output = Vec::with_capacity(mem.read_u32(out_len_ptr) as usize);
+
+    let result = brotli::zstd::decompress_fixed(&input, output.spare_capacity_mut());
+    match result {
+        Ok(slice) => {
+            mem.write_slice(out_buf_ptr, slice);
+            mem.write_u32(out_len_ptr, slice.len() as u32);
+            BrotliStatus::Success
+        }
+        Err(status) => status,
+    }
+}
@@ -39,5 +39,20 @@ wrap! {
         out_buf_ptr: GuestPtr,
         out_len_ptr: GuestPtr,
         dictionary: Dictionary
+    ) -> BrotliStatus;
+
+    fn zstd_compress(
+        in_buf_ptr: GuestPtr,
+        in_buf_len: u32,
+        out_buf_ptr: GuestPtr,
+        out_len_ptr: GuestPtr,
+        level: u32
+    ) -> BrotliStatus;
+
+    fn zstd_decompress(
+        in_buf_ptr: GuestPtr,
+        in_buf_len: u32,
+        out_buf_ptr: GuestPtr,
+        out_len_ptr: GuestPtr
     ) -> BrotliStatus
 }
@@ -122,6 +122,8 @@ fn imports(store: &mut Store, func_env: &FunctionEnv<WasmEnv>) -> wasmer::Import
         "arbcompress" => {
             "brotli_compress" => func!(arbcompress::brotli_compress),
             "brotli_decompress" => func!(arbcompress::brotli_decompress),
+            "zstd_compress" => func!(arbcompress::zstd_compress),
+            "zstd_decompress" => func!(arbcompress::zstd_decompress),
         },
         "arbcrypto" => {
             "ecrecovery" => func!(arbcrypto::ecrecovery),
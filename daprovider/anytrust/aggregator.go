@@ -41,6 +41,7 @@ type AggregatorConfig struct {
 	AssumedHonest int               `koanf:"assumed-honest"`
 	Backends      BackendConfigList `koanf:"backends"`
 	RPCClient     RPCClientConfig   `koanf:"rpc-client"`
+	Sharding      ShardingConfig    `koanf:"sharding"`
 }
 
 var DefaultAggregatorConfig = AggregatorConfig{
@@ -51,6 +52,7 @@ var DefaultAggregatorConfig = AggregatorConfig{
 		DataStream:         data_streaming.DefaultDataStreamerConfig(DefaultDataStreamRpcMethods),
 		RPC:                rpcclient.DefaultClientConfig,
 	},
+	Sharding: DefaultShardingConfig,
 }
 
 var parsedBackendsConf BackendConfigList
@@ -60,6 +62,7 @@ func AggregatorConfigAddOptions(prefix string, f *pflag.FlagSet) {
 	f.Int(prefix+".assumed-honest", DefaultAggregatorConfig.AssumedHonest, "Number of assumed honest backends (H). If there are N backends, K=N+1-H valid responses are required to consider an Store request to be successful.")
 	f.Var(&parsedBackendsConf, prefix+".backends", "JSON RPC backend configuration. This can be specified on the command line as a JSON array, eg: [{\"url\": \"...\", \"pubkey\": \"...\"},...], or as a JSON array in the config file.")
 	RPCClientConfigAddOptions(prefix+".rpc-client", f)
+	ShardingConfigAddOptions(prefix+".sharding", f)
 }
 
 type Aggregator struct {
@@ -108,12 +111,27 @@ func newAggregator(
 		return nil, err
 	}
 
+	// Of the K signers of a certificate, at least K-(N-H) are honest. Without
+	// sharding one of them is enough to retrieve the batch, with sharding D of
+	// them are needed.
+	requiredHonestSigners := 1
+	if config.RPCAggregator.Sharding.Enable {
+		dataShards := config.RPCAggregator.Sharding.DataShards
+		if dataShards < 1 || dataShards > config.RPCAggregator.AssumedHonest {
+			return nil, fmt.Errorf("sharding data-shards is %d, but must be between 1 and assumed-honest %d", dataShards, config.RPCAggregator.AssumedHonest)
+		}
+		if len(services) > maxTotalShards {
+			return nil, fmt.Errorf("sharding supports at most %d backends, but %d are configured", maxTotalShards, len(services))
+		}
+		requiredHonestSigners = dataShards
+	}
+
 	return &Aggregator{
 		config:                         config.RPCAggregator,
 		services:                       services,
 		requestTimeout:                 config.RequestTimeout,
-		requiredServicesForStore:       len(services) + 1 - config.RPCAggregator.AssumedHonest,
-		maxAllowedServiceStoreFailures: config.RPCAggregator.AssumedHonest - 1,
+		requiredServicesForStore:       len(services) + requiredHonestSigners - config.RPCAggregator.AssumedHonest,
+		maxAllowedServiceStoreFailures: config.RPCAggregator.AssumedHonest - requiredHonestSigners,
 		keysetHash:                     keysetHash,
 		keysetBytes:                    keysetBytes,
 	}, nil
@@ -154,8 +172,18 @@ func (a *Aggregator) Store(ctx context.Context, message []byte, timeout uint64)
 	responses := make(chan storeResponse, len(a.services))
 
 	expectedHash := tree.Hash(message)
-	for _, d := range a.services {
-		go func(ctx context.Context, d ServiceDetails) {
+	// With sharding, the backend with index i is asked to store shard i
+	messages := make([][]byte, len(a.services))
+	for i := range messages {
+		messages[i] = message
+	}
+	if a.config.Sharding.Enable {
+		for i := range messages {
+			messages[i] = EncodeShardRequest(message, a.config.Sharding.DataShards, len(a.services), i)
+		}
+	}
+	for i, d := range a.services {
+		go func(ctx context.Context, d ServiceDetails, message []byte) {
 			storeCtx, cancel := context.WithTimeout(ctx, a.requestTimeout)
 			var metricWithServiceName = metricBase + "/" + d.metricName
 			defer cancel()
@@ -206,7 +234,7 @@ func (a *Aggregator) Store(ctx context.Context, message []byte, timeout uint64)
 			metrics.GetOrRegisterCounter(metricWithServiceName+"/success/total", nil).Inc(1)
 			metrics.GetOrRegisterCounter(metricBase+"/success/all/total", nil).Inc(1)
 			responses <- storeResponse{d, cert.Sig, nil}
-		}(ctx, d)
+		}(ctx, d, messages[i])
 	}
 
 	var aggCert anytrustutil.DataAvailabilityCertificate
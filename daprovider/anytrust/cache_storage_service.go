@@ -60,7 +60,11 @@ func (c *CacheStorageService) GetByHash(ctx context.Context, key common.Hash) ([
 		return nil, err
 	}
 
-	c.cache.Add(key, val)
+	// Only complete batches are cached from reads, a shard envelope read
+	// through is never verified on its own.
+	if tree.ValidHash(key, val) {
+		c.cache.Add(key, val)
+	}
 
 	return val, nil
 }
@@ -71,7 +75,7 @@ func (c *CacheStorageService) Put(ctx context.Context, value []byte, timeout uin
 	if err != nil {
 		return err
 	}
-	c.cache.Add(common.Hash(tree.Hash(value)), value)
+	c.cache.Add(StorageKey(value), value)
 	return nil
 }
 
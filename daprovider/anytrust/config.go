@@ -43,10 +43,13 @@ type Config struct {
 	RPCAggregator  AggregatorConfig              `koanf:"rpc-aggregator"`
 	RestAggregator RestfulClientAggregatorConfig `koanf:"rest-aggregator"`
 
+	KeysetPrefetch KeysetPrefetchConfig `koanf:"keyset-prefetch"`
+
 	ExtraSignatureCheckingPublicKey string `koanf:"extra-signature-checking-public-key"`
 
 	PanicOnError             bool `koanf:"panic-on-error"`
 	DisableSignatureChecking bool `koanf:"disable-signature-checking"`
+	AcceptShards             bool `koanf:"accept-shards"`
 }
 
 // DefaultConfig includes defaults for daserver-specific fields.
@@ -63,9 +66,11 @@ var DefaultConfig = Config{
 	Key:                             DefaultKeyConfig,
 	RPCAggregator:                   DefaultAggregatorConfig,
 	RestAggregator:                  DefaultRestfulClientAggregatorConfig,
+	KeysetPrefetch:                  DefaultKeysetPrefetchConfig,
 	ExtraSignatureCheckingPublicKey: "",
 	PanicOnError:                    false,
 	DisableSignatureChecking:        false,
+	AcceptShards:                    false,
 }
 
 // DefaultConfigForNode only sets defaults for fields with CLI
@@ -83,9 +88,11 @@ var DefaultConfigForNode = Config{
 	Key:                             KeyConfig{},
 	RPCAggregator:                   DefaultAggregatorConfig,
 	RestAggregator:                  DefaultRestfulClientAggregatorConfig,
+	KeysetPrefetch:                  DefaultKeysetPrefetchConfig,
 	ExtraSignatureCheckingPublicKey: "",
 	PanicOnError:                    false,
 	DisableSignatureChecking:        false,
+	AcceptShards:                    false,
 }
 
 func OptionalAddressFromString(s string) (*common.Address, error) {
@@ -123,6 +130,7 @@ func configAddOptions(prefix string, f *pflag.FlagSet, r role) {
 
 	if r == roleAnyTrustServer {
 		f.Bool(prefix+".disable-signature-checking", DefaultConfig.DisableSignatureChecking, "disables signature checking on Data Availability Store requests (DANGEROUS, FOR TESTING ONLY)")
+		f.Bool(prefix+".accept-shards", DefaultConfig.AcceptShards, "accept shard requests from an aggregator with sharding enabled, signing certificates for their batches while only storing the requested erasure coded shards of them")
 
 		// Cache options
 		CacheConfigAddOptions(prefix+".local-cache", f)
@@ -143,6 +151,8 @@ func configAddOptions(prefix string, f *pflag.FlagSet, r role) {
 		AggregatorConfigAddOptions(prefix+".rpc-aggregator", f)
 		f.Duration(prefix+".request-timeout", DefaultConfig.RequestTimeout, "Data Availability Service timeout duration for Store requests")
 		f.Int(prefix+".max-batch-size", DefaultConfig.MaxBatchSize, "maximum batch size for AnyTrust DA (compressed)")
+
+		KeysetPrefetchConfigAddOptions(prefix+".keyset-prefetch", f)
 	}
 
 	// Both the Nitro node and daserver can use these options.
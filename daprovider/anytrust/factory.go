@@ -137,6 +137,10 @@ func CreateDAReaderAndWriter(
 	if err != nil {
 		return nil, nil, nil, nil, err
 	}
+	if config.KeysetPrefetch.Enable {
+		keysetFetcher.StartPrefetching(ctx, l1Reader, config.KeysetPrefetch)
+		lifecycleManager.Register(keysetFetcher)
+	}
 
 	return daWriter, daReader, keysetFetcher, &lifecycleManager, nil
 }
@@ -182,6 +186,9 @@ func CreateDAComponentsForAnyTrustServer(
 		retentionPeriodSeconds := uint64(syncConf.RetentionPeriod.Seconds())
 
 		if syncConf.Eager {
+			if config.AcceptShards {
+				return nil, nil, nil, nil, nil, errors.New("sync-to-storage.eager stores full batches, so it can't be used along with accept-shards")
+			}
 			if l1Reader == nil || seqInboxAddress == nil {
 				return nil, nil, nil, nil, nil, errors.New("l1-node-url and sequencer-inbox-address must be specified along with sync-to-storage.eager")
 			}
@@ -198,8 +205,12 @@ func CreateDAComponentsForAnyTrustServer(
 				return nil, nil, nil, nil, nil, err
 			}
 		} else {
-			storageService = NewFallbackStorageService(storageService, restAgg, restAgg,
-				retentionPeriodSeconds, syncConf.IgnoreWriteErrors, true)
+			if config.AcceptShards {
+				storageService = NewFallbackStorageServiceWithoutBackupWrites(storageService, restAgg, restAgg, true)
+			} else {
+				storageService = NewFallbackStorageService(storageService, restAgg, restAgg,
+					retentionPeriodSeconds, syncConf.IgnoreWriteErrors, true)
+			}
 			anyTrustLifecycleManager.Register(storageService)
 		}
 
@@ -282,7 +293,10 @@ func CreateDAReader(
 		if err != nil {
 			return nil, nil, nil, err
 		}
-
+		if config.KeysetPrefetch.Enable {
+			keysetFetcher.StartPrefetching(ctx, (*l1Reader).Client(), config.KeysetPrefetch)
+			lifecycleManager.Register(keysetFetcher)
+		}
 	}
 
 	return daReader, keysetFetcher, &lifecycleManager, nil
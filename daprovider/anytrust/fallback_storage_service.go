@@ -24,6 +24,7 @@ type FallbackStorageService struct {
 	backupRetentionSeconds     uint64
 	ignoreRetentionWriteErrors bool
 	preventRecursiveGets       bool
+	skipBackupWrites           bool
 	currentlyFetching          map[[32]byte]bool
 	currentlyFetchingMutex     sync.RWMutex
 }
@@ -46,6 +47,28 @@ func NewFallbackStorageService(
 		backupRetentionSeconds:     backupRetentionSeconds,
 		ignoreRetentionWriteErrors: ignoreRetentionWriteErrors,
 		preventRecursiveGets:       preventRecursiveGets,
+		skipBackupWrites:           false,
+		currentlyFetching:          make(map[[32]byte]bool),
+		currentlyFetchingMutex:     sync.RWMutex{},
+	}
+}
+
+// NewFallbackStorageServiceWithoutBackupWrites is a FallbackStorageService which never Puts results from the
+// backup into the primary, for primaries which only keep shards of batches.
+func NewFallbackStorageServiceWithoutBackupWrites(
+	primary StorageService,
+	backup anytrustutil.Reader,
+	backupHealthChecker ServiceHealthChecker,
+	preventRecursiveGets bool, // if true, return NotFound on simultaneous calls to Gets that miss in primary (prevents infinite recursion)
+) *FallbackStorageService {
+	return &FallbackStorageService{
+		StorageService:             primary,
+		backup:                     backup,
+		backupHealthChecker:        backupHealthChecker,
+		backupRetentionSeconds:     0,
+		ignoreRetentionWriteErrors: false,
+		preventRecursiveGets:       preventRecursiveGets,
+		skipBackupWrites:           true,
 		currentlyFetching:          make(map[[32]byte]bool),
 		currentlyFetchingMutex:     sync.RWMutex{},
 	}
@@ -84,7 +107,7 @@ func (f *FallbackStorageService) GetByHash(ctx context.Context, key common.Hash)
 		if err != nil {
 			return nil, err
 		}
-		if tree.ValidHash(key, data) {
+		if !f.skipBackupWrites && tree.ValidHash(key, data) {
 			putErr := f.StorageService.Put(
 				// #nosec G115
 				ctx, data, arbmath.SaturatingUAdd(uint64(time.Now().Unix()), f.backupRetentionSeconds),
@@ -68,3 +68,25 @@ func TestFallbackStorageServiceRecursive(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+func TestFallbackStorageServiceSkipBackupWrites(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	val := []byte("Batch of a shard")
+	hash := tree.Hash(val)
+
+	primary := NewMemoryBackedStorageService(ctx)
+	fallback := NewMemoryBackedStorageService(ctx)
+	Require(t, fallback.Put(ctx, val, math.MaxUint64))
+
+	fss := NewFallbackStorageServiceWithoutBackupWrites(primary, fallback, fallback, true)
+	res, err := fss.GetByHash(ctx, hash)
+	Require(t, err)
+	if !bytes.Equal(res, val) {
+		t.Fatal()
+	}
+	if _, err := primary.GetByHash(ctx, hash); !errors.Is(err, ErrNotFound) {
+		t.Fatal("batch from the backup was written to the primary", err)
+	}
+}
@@ -4,21 +4,23 @@ package anytrust
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"math"
 	"sort"
+	"strings"
 	"time"
 
 	googlestorage "cloud.google.com/go/storage"
 	"github.com/google/go-cmp/cmp"
 	"github.com/spf13/pflag"
+	"google.golang.org/api/iterator"
 	"google.golang.org/api/option"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/log"
 
-	"github.com/offchainlabs/nitro/daprovider/anytrust/tree"
 	anytrustutil "github.com/offchainlabs/nitro/daprovider/anytrust/util"
 	"github.com/offchainlabs/nitro/util/pretty"
 )
@@ -27,6 +29,7 @@ type GoogleCloudStorageOperator interface {
 	Bucket(name string) *googlestorage.BucketHandle
 	Upload(ctx context.Context, bucket, objectPrefix string, value []byte, discardAfterTimeout bool, timeout uint64) error
 	Download(ctx context.Context, bucket, objectPrefix string, key common.Hash) ([]byte, error)
+	Iterate(ctx context.Context, bucket, objectPrefix string, startAfter common.Hash, fn func(common.Hash, uint64) error) error
 	Close(ctx context.Context) error
 }
 
@@ -39,7 +42,7 @@ func (g *GoogleCloudStorageClient) Bucket(name string) *googlestorage.BucketHand
 }
 
 func (g *GoogleCloudStorageClient) Upload(ctx context.Context, bucket, objectPrefix string, value []byte, discardAfterTimeout bool, timeout uint64) error {
-	obj := g.client.Bucket(bucket).Object(objectPrefix + EncodeStorageServiceKey(tree.Hash(value)))
+	obj := g.client.Bucket(bucket).Object(objectPrefix + EncodeStorageServiceKey(StorageKey(value)))
 	w := obj.NewWriter(ctx)
 
 	if discardAfterTimeout && timeout <= math.MaxInt64 {
@@ -64,6 +67,43 @@ func (g *GoogleCloudStorageClient) Download(ctx context.Context, bucket, objectP
 	return io.ReadAll(reader)
 }
 
+func (g *GoogleCloudStorageClient) Iterate(ctx context.Context, bucket, objectPrefix string, startAfter common.Hash, fn func(common.Hash, uint64) error) error {
+	query := &googlestorage.Query{Prefix: objectPrefix}
+	if startAfter != (common.Hash{}) {
+		// StartOffset is inclusive, startAfter is skipped below
+		query.StartOffset = objectPrefix + EncodeStorageServiceKey(startAfter)
+	}
+	objects := g.client.Bucket(bucket).Objects(ctx, query)
+	for {
+		attrs, err := objects.Next()
+		if errors.Is(err, iterator.Done) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		name := strings.TrimPrefix(attrs.Name, objectPrefix)
+		if !isStorageServiceKey(name) {
+			continue
+		}
+		key, err := DecodeStorageServiceKey(name)
+		if err != nil {
+			return err
+		}
+		if key == startAfter {
+			continue
+		}
+		var expiry uint64
+		if attrs.Retention != nil && attrs.Retention.RetainUntil.Unix() > 0 {
+			// #nosec G115
+			expiry = uint64(attrs.Retention.RetainUntil.Unix())
+		}
+		if err := fn(key, expiry); err != nil {
+			return err
+		}
+	}
+}
+
 func (g *GoogleCloudStorageClient) Close(ctx context.Context) error {
 	return g.client.Close()
 }
@@ -139,6 +179,12 @@ func (gcs *GoogleCloudStorageService) GetByHash(ctx context.Context, key common.
 	return buf, nil
 }
 
+// IterateBatches lists the objects under the object prefix, with the
+// retention set on them as their expiration time.
+func (gcs *GoogleCloudStorageService) IterateBatches(ctx context.Context, startAfter common.Hash, fn func(common.Hash, uint64) error) error {
+	return gcs.operator.Iterate(ctx, gcs.bucket, gcs.objectPrefix, startAfter, fn)
+}
+
 func (gcs *GoogleCloudStorageService) ExpirationPolicy(ctx context.Context) (anytrustutil.ExpirationPolicy, error) {
 	if gcs.discardAfterTimeout {
 		return anytrustutil.DiscardAfterDataTimeout, nil
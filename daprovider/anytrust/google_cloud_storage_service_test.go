@@ -6,6 +6,8 @@ import (
 	"bytes"
 	"context"
 	"errors"
+	"slices"
+	"strings"
 	"testing"
 	"time"
 
@@ -32,6 +34,26 @@ func (c *mockGCSClient) Download(ctx context.Context, bucket, objectPrefix strin
 	return value, nil
 }
 
+func (c *mockGCSClient) Iterate(ctx context.Context, bucket, objectPrefix string, startAfter common.Hash, fn func(common.Hash, uint64) error) error {
+	var keys []common.Hash
+	for name := range c.storage {
+		key, err := DecodeStorageServiceKey(strings.TrimPrefix(name, objectPrefix))
+		if err != nil {
+			return err
+		}
+		if key.Cmp(startAfter) > 0 {
+			keys = append(keys, key)
+		}
+	}
+	slices.SortFunc(keys, func(a, b common.Hash) int { return a.Cmp(b) })
+	for _, key := range keys {
+		if err := fn(key, 0); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (c *mockGCSClient) Close(ctx context.Context) error {
 	return nil
 }
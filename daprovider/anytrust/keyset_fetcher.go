@@ -4,20 +4,61 @@
 package anytrust
 
 import (
+	"bytes"
+	"cmp"
 	"context"
 	"errors"
+	"slices"
 	"sync"
+	"time"
+
+	"github.com/spf13/pflag"
 
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
 
 	"github.com/offchainlabs/nitro/daprovider/anytrust/tree"
+	anytrustutil "github.com/offchainlabs/nitro/daprovider/anytrust/util"
 	"github.com/offchainlabs/nitro/solgen/go/bridgegen"
 	"github.com/offchainlabs/nitro/util/pretty"
+	"github.com/offchainlabs/nitro/util/stopwaiter"
 )
 
+type KeysetPrefetchConfig struct {
+	Enable         bool          `koanf:"enable"`
+	PollInterval   time.Duration `koanf:"poll-interval"`
+	LookbackBlocks uint64        `koanf:"lookback-blocks"`
+	MaxBlockRange  uint64        `koanf:"max-block-range"`
+	Confirmations  uint64        `koanf:"confirmations"`
+}
+
+var DefaultKeysetPrefetchConfig = KeysetPrefetchConfig{
+	Enable:         false,
+	PollInterval:   time.Minute,
+	LookbackBlocks: 50_400, // About a week of parent chain blocks on Ethereum
+	MaxBlockRange:  10_000,
+	Confirmations:  64, // About two epochs, after which blocks are final on Ethereum
+}
+
+func KeysetPrefetchConfigAddOptions(prefix string, f *pflag.FlagSet) {
+	f.Bool(prefix+".enable", DefaultKeysetPrefetchConfig.Enable, "enable fetching and verifying keysets as soon as they are registered as valid in the sequencer inbox, rather than when the first certificate using them is read")
+	f.Duration(prefix+".poll-interval", DefaultKeysetPrefetchConfig.PollInterval, "how often to check the parent chain for newly registered keysets")
+	f.Uint64(prefix+".lookback-blocks", DefaultKeysetPrefetchConfig.LookbackBlocks, "number of parent chain blocks before the head in which to look for registered keysets at startup")
+	f.Uint64(prefix+".max-block-range", DefaultKeysetPrefetchConfig.MaxBlockRange, "maximum number of parent chain blocks to get keyset registration logs for in a single request")
+	f.Uint64(prefix+".confirmations", DefaultKeysetPrefetchConfig.Confirmations, "number of parent chain blocks a keyset registration or invalidation must be buried under before it's applied, so that a reorg can't undo it")
+}
+
+func (c *KeysetPrefetchConfig) Validate() error {
+	if c.Enable && c.MaxBlockRange == 0 {
+		return errors.New("keyset-prefetch.max-block-range must be greater than 0")
+	}
+	return nil
+}
+
 type syncedKeysetCache struct {
 	cache map[[32]byte][]byte
 	sync.RWMutex
@@ -36,10 +77,17 @@ func (c *syncedKeysetCache) put(key [32]byte, value []byte) {
 	c.cache[key] = value
 }
 
+var validKeysetsGauge = metrics.NewRegisteredGauge("arb/das/keysets/valid", nil)
+
 type KeysetFetcher struct {
+	stopwaiter.StopWaiter
 	seqInboxCaller   *bridgegen.SequencerInboxCaller
 	seqInboxFilterer *bridgegen.SequencerInboxFilterer
 	keysetCache      syncedKeysetCache
+
+	// validKeysets are the prefetched keysets which are valid
+	validKeysets      map[common.Hash]struct{}
+	validKeysetsMutex sync.Mutex
 }
 
 func NewKeysetFetcher(l1client *ethclient.Client, seqInboxAddr common.Address) (*KeysetFetcher, error) {
@@ -56,6 +104,7 @@ func NewKeysetFetcherWithSeqInbox(seqInbox *bridgegen.SequencerInbox) (*KeysetFe
 		seqInboxCaller:   &seqInbox.SequencerInboxCaller,
 		seqInboxFilterer: &seqInbox.SequencerInboxFilterer,
 		keysetCache:      syncedKeysetCache{cache: make(map[[32]byte][]byte)},
+		validKeysets:     make(map[common.Hash]struct{}),
 	}, nil
 }
 
@@ -102,3 +151,166 @@ func (c *KeysetFetcher) GetKeysetByHash(ctx context.Context, hash common.Hash) (
 
 	return nil, ErrNotFound
 }
+
+type blockNumberReader interface {
+	BlockNumber(ctx context.Context) (uint64, error)
+}
+
+// StartPrefetching periodically caches the keysets newly registered as valid
+// in the sequencer inbox, once verified, so that certificates signed by a new
+// committee can be read right away after a keyset rotation. It also tracks
+// which of them are valid, see applyKeysetEvents. The scanned blocks are never
+// scanned again, so only the blocks with enough confirmations are scanned.
+func (c *KeysetFetcher) StartPrefetching(ctx context.Context, l1Client blockNumberReader, config KeysetPrefetchConfig) {
+	c.StopWaiter.Start(ctx, c)
+	var nextBlock uint64
+	started := false
+	c.CallIteratively(func(ctx context.Context) time.Duration {
+		head, err := l1Client.BlockNumber(ctx)
+		if err != nil {
+			log.Warn("Error getting parent chain head to prefetch AnyTrust keysets", "err", err)
+			return config.PollInterval
+		}
+		if head < config.Confirmations {
+			return config.PollInterval
+		}
+		confirmedHead := head - config.Confirmations
+		if !started {
+			nextBlock = confirmedHead - min(confirmedHead, config.LookbackBlocks)
+			started = true
+		}
+		for nextBlock <= confirmedHead {
+			toBlock := min(confirmedHead, nextBlock+config.MaxBlockRange-1)
+			if err := c.prefetchKeysets(ctx, nextBlock, toBlock); err != nil {
+				log.Warn("Error prefetching AnyTrust keysets", "fromBlock", nextBlock, "toBlock", toBlock, "err", err)
+				return config.PollInterval
+			}
+			nextBlock = toBlock + 1
+		}
+		return config.PollInterval
+	})
+}
+
+// keysetEvent is the registration of a keyset as valid in the sequencer
+// inbox, or its invalidation.
+type keysetEvent struct {
+	raw         types.Log
+	hash        common.Hash
+	keysetBytes []byte
+	invalidated bool
+}
+
+func (c *KeysetFetcher) prefetchKeysets(ctx context.Context, fromBlock, toBlock uint64) error {
+	filterOpts := &bind.FilterOpts{
+		Start:   fromBlock,
+		End:     &toBlock,
+		Context: ctx,
+	}
+	var events []keysetEvent
+	setIter, err := c.seqInboxFilterer.FilterSetValidKeyset(filterOpts, nil)
+	if err != nil {
+		return err
+	}
+	defer setIter.Close()
+	for setIter.Next() {
+		events = append(events, keysetEvent{
+			raw:         setIter.Event.Raw,
+			hash:        setIter.Event.KeysetHash,
+			keysetBytes: setIter.Event.KeysetBytes,
+		})
+	}
+	if err := setIter.Error(); err != nil {
+		return err
+	}
+	invalidateIter, err := c.seqInboxFilterer.FilterInvalidateKeyset(filterOpts, nil)
+	if err != nil {
+		return err
+	}
+	defer invalidateIter.Close()
+	for invalidateIter.Next() {
+		events = append(events, keysetEvent{
+			raw:         invalidateIter.Event.Raw,
+			hash:        invalidateIter.Event.KeysetHash,
+			invalidated: true,
+		})
+	}
+	if err := invalidateIter.Error(); err != nil {
+		return err
+	}
+	slices.SortFunc(events, func(a, b keysetEvent) int {
+		if a.raw.BlockNumber != b.raw.BlockNumber {
+			return cmp.Compare(a.raw.BlockNumber, b.raw.BlockNumber)
+		}
+		return cmp.Compare(a.raw.Index, b.raw.Index)
+	})
+	c.applyKeysetEvents(events)
+	return nil
+}
+
+// applyKeysetEvents caches the keysets registered as valid, once verified, and
+// tracks which are valid. During a committee rotation the old and new keysets
+// are both valid, until the old one is invalidated. Invalidated keysets stay
+// cached, as they're needed to read the batches posted before.
+func (c *KeysetFetcher) applyKeysetEvents(events []keysetEvent) {
+	c.validKeysetsMutex.Lock()
+	defer c.validKeysetsMutex.Unlock()
+	for _, event := range events {
+		if event.invalidated {
+			if _, ok := c.validKeysets[event.hash]; !ok {
+				continue
+			}
+			delete(c.validKeysets, event.hash)
+			log.Info("AnyTrust keyset invalidated", "hash", event.hash, "block", event.raw.BlockNumber, "validKeysets", len(c.validKeysets))
+			continue
+		}
+		if _, ok := c.keysetCache.get(event.hash); !ok {
+			if !tree.ValidHash(event.hash, event.keysetBytes) {
+				log.Warn("Registered AnyTrust keyset doesn't match its hash", "hash", event.hash, "block", event.raw.BlockNumber)
+				continue
+			}
+			keyset, err := anytrustutil.DeserializeKeyset(bytes.NewReader(event.keysetBytes), false)
+			if err != nil {
+				log.Warn("Registered AnyTrust keyset is invalid", "hash", event.hash, "block", event.raw.BlockNumber, "err", err)
+				continue
+			}
+			c.keysetCache.put(event.hash, event.keysetBytes)
+			log.Info("Prefetched AnyTrust keyset", "hash", event.hash, "block", event.raw.BlockNumber, "assumedHonest", keyset.AssumedHonest, "members", len(keyset.PubKeys))
+		}
+		c.validKeysets[event.hash] = struct{}{}
+		if len(c.validKeysets) > 1 {
+			log.Info("Several AnyTrust keysets are valid, until the committee rotation is completed by invalidating the old ones", "validKeysets", len(c.validKeysets))
+		}
+	}
+	validKeysetsGauge.Update(int64(len(c.validKeysets)))
+}
+
+// ValidKeysets returns the hashes of the prefetched keysets which are valid in
+// the sequencer inbox.
+func (c *KeysetFetcher) ValidKeysets() []common.Hash {
+	c.validKeysetsMutex.Lock()
+	defer c.validKeysetsMutex.Unlock()
+	hashes := make([]common.Hash, 0, len(c.validKeysets))
+	for hash := range c.validKeysets {
+		hashes = append(hashes, hash)
+	}
+	slices.SortFunc(hashes, func(a, b common.Hash) int { return a.Cmp(b) })
+	return hashes
+}
+
+func (c *KeysetFetcher) Close(ctx context.Context) error {
+	c.StopWaiter.StopOnly()
+	waitChan, err := c.StopWaiter.GetWaitChannel()
+	if err != nil {
+		return err
+	}
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-waitChan:
+		return nil
+	}
+}
+
+func (c *KeysetFetcher) String() string {
+	return "anytrust.KeysetFetcher"
+}
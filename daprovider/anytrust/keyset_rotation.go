@@ -0,0 +1,105 @@
+// Copyright 2026, Offchain Labs, Inc.
+// For license information, see https://github.com/OffchainLabs/nitro/blob/master/LICENSE.md
+
+package anytrust
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/offchainlabs/nitro/blsSignatures"
+	"github.com/offchainlabs/nitro/daprovider/anytrust/tree"
+	anytrustutil "github.com/offchainlabs/nitro/daprovider/anytrust/util"
+)
+
+// KeysetDiff is the change of committee from a current keyset to a proposed
+// one. Members are identified by their BLS public keys.
+type KeysetDiff struct {
+	CurrentAssumedHonest  uint64
+	ProposedAssumedHonest uint64
+	Added                 []blsSignatures.PublicKey
+	Removed               []blsSignatures.PublicKey
+	Kept                  []blsSignatures.PublicKey
+}
+
+func DiffKeysets(current, proposed *anytrustutil.DataAvailabilityKeyset) KeysetDiff {
+	diff := KeysetDiff{
+		CurrentAssumedHonest:  current.AssumedHonest,
+		ProposedAssumedHonest: proposed.AssumedHonest,
+	}
+	contains := func(keys []blsSignatures.PublicKey, key blsSignatures.PublicKey) bool {
+		for _, k := range keys {
+			if bytes.Equal(blsSignatures.PublicKeyToBytes(k), blsSignatures.PublicKeyToBytes(key)) {
+				return true
+			}
+		}
+		return false
+	}
+	for _, key := range proposed.PubKeys {
+		if contains(current.PubKeys, key) {
+			diff.Kept = append(diff.Kept, key)
+		} else {
+			diff.Added = append(diff.Added, key)
+		}
+	}
+	for _, key := range current.PubKeys {
+		if !contains(proposed.PubKeys, key) {
+			diff.Removed = append(diff.Removed, key)
+		}
+	}
+	return diff
+}
+
+// KeysetMemberCheck is the result of storing a message with a member of a
+// proposed committee and verifying its signature.
+type KeysetMemberCheck struct {
+	Member string
+	Err    error
+}
+
+// CheckKeysetMembers stores message with each of the committee members in
+// services, and checks that each signs a certificate for it with the public
+// key it has in the keyset. Members not responding within requestTimeout fail
+// the check.
+func CheckKeysetMembers(ctx context.Context, services []ServiceDetails, message []byte, timeout uint64, requestTimeout time.Duration) []KeysetMemberCheck {
+	checks := make([]KeysetMemberCheck, len(services))
+	var wg sync.WaitGroup
+	for i, d := range services {
+		wg.Add(1)
+		go func(i int, d ServiceDetails) {
+			defer wg.Done()
+			checks[i] = KeysetMemberCheck{
+				Member: d.service.String(),
+				Err:    checkKeysetMember(ctx, d, message, timeout, requestTimeout),
+			}
+		}(i, d)
+	}
+	wg.Wait()
+	return checks
+}
+
+func checkKeysetMember(ctx context.Context, d ServiceDetails, message []byte, timeout uint64, requestTimeout time.Duration) error {
+	storeCtx, cancel := context.WithTimeout(ctx, requestTimeout)
+	defer cancel()
+	cert, err := d.service.Store(storeCtx, message, timeout)
+	if err != nil {
+		return err
+	}
+	if cert.DataHash != tree.Hash(message) {
+		return fmt.Errorf("got a certificate for data hash %v, expected %v", cert.DataHash, tree.Hash(message))
+	}
+	if cert.Timeout != timeout {
+		return fmt.Errorf("got a certificate with timeout %d, expected %d", cert.Timeout, timeout)
+	}
+	verified, err := blsSignatures.VerifySignature(cert.Sig, cert.SerializeSignableFields(), d.pubKey)
+	if err != nil {
+		return err
+	}
+	if !verified {
+		return errors.New("signature doesn't match the public key in the keyset")
+	}
+	return nil
+}
@@ -0,0 +1,273 @@
+// Copyright 2026, Offchain Labs, Inc.
+// For license information, see https://github.com/OffchainLabs/nitro/blob/master/LICENSE.md
+
+package anytrust
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"slices"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/offchainlabs/nitro/blsSignatures"
+	anytrustutil "github.com/offchainlabs/nitro/daprovider/anytrust/util"
+	"github.com/offchainlabs/nitro/solgen/go/bridgegen"
+)
+
+func TestDiffKeysets(t *testing.T) {
+	var keys []blsSignatures.PublicKey
+	for i := 0; i < 4; i++ {
+		pubKey, _, err := blsSignatures.GenerateKeys()
+		Require(t, err)
+		keys = append(keys, pubKey)
+	}
+	current := &anytrustutil.DataAvailabilityKeyset{AssumedHonest: 1, PubKeys: keys[:3]}
+	proposed := &anytrustutil.DataAvailabilityKeyset{AssumedHonest: 2, PubKeys: []blsSignatures.PublicKey{keys[1], keys[3], keys[2]}}
+	diff := DiffKeysets(current, proposed)
+	if diff.CurrentAssumedHonest != 1 || diff.ProposedAssumedHonest != 2 {
+		Fail(t, "unexpected assumed honest", diff.CurrentAssumedHonest, diff.ProposedAssumedHonest)
+	}
+	if len(diff.Added) != 1 || len(diff.Removed) != 1 || len(diff.Kept) != 2 {
+		Fail(t, "unexpected diff", len(diff.Added), len(diff.Removed), len(diff.Kept))
+	}
+	if !bytes.Equal(blsSignatures.PublicKeyToBytes(diff.Added[0]), blsSignatures.PublicKeyToBytes(keys[3])) {
+		Fail(t, "unexpected added member")
+	}
+	if !bytes.Equal(blsSignatures.PublicKeyToBytes(diff.Removed[0]), blsSignatures.PublicKeyToBytes(keys[0])) {
+		Fail(t, "unexpected removed member")
+	}
+}
+
+func TestCheckKeysetMembers(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var services []ServiceDetails
+	for i := 0; i < 4; i++ {
+		privKey, err := blsSignatures.GeneratePrivKeyString()
+		Require(t, err)
+		config := DefaultConfig
+		config.Key.PrivKey = privKey
+		writer, err := NewSignAfterStoreWriter(ctx, config, NewMemoryBackedStorageService(ctx))
+		Require(t, err)
+		pubKey := *writer.pubKey
+		if i == 2 {
+			// The keyset has the wrong public key for the last member
+			pubKey, _, err = blsSignatures.GenerateKeys()
+			Require(t, err)
+		}
+		var service anytrustutil.Writer = writer
+		if i == 3 {
+			// The last member never responds
+			service = hangingWriter{}
+		}
+		details, err := NewServiceDetails(service, pubKey, uint64(1<<i), "service"+strconv.Itoa(i))
+		Require(t, err)
+		services = append(services, *details)
+	}
+
+	// #nosec G115
+	checks := CheckKeysetMembers(ctx, services, []byte("keyset rotation check"), uint64(time.Now().Add(time.Hour).Unix()), time.Second)
+	if len(checks) != len(services) {
+		Fail(t, "got", len(checks), "checks for", len(services), "members")
+	}
+	for i, check := range checks {
+		if (check.Err != nil) != (i >= 2) {
+			Fail(t, "unexpected result checking member", i, check.Err)
+		}
+	}
+	if !errors.Is(checks[3].Err, context.DeadlineExceeded) {
+		Fail(t, "unexpected error checking the member which never responds", checks[3].Err)
+	}
+}
+
+// hangingWriter only returns once its request is cancelled.
+type hangingWriter struct{}
+
+func (hangingWriter) Store(ctx context.Context, message []byte, timeout uint64) (*anytrustutil.DataAvailabilityCertificate, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func (hangingWriter) String() string {
+	return "hangingWriter"
+}
+
+func testKeysets(t *testing.T, n int) ([]common.Hash, [][]byte) {
+	t.Helper()
+	var hashes []common.Hash
+	var keysets [][]byte
+	for i := 0; i < n; i++ {
+		pubKey, _, err := blsSignatures.GenerateKeys()
+		Require(t, err)
+		keyset := &anytrustutil.DataAvailabilityKeyset{AssumedHonest: 1, PubKeys: []blsSignatures.PublicKey{pubKey}}
+		buf := new(bytes.Buffer)
+		Require(t, keyset.Serialize(buf))
+		hash, err := keyset.Hash()
+		Require(t, err)
+		hashes = append(hashes, hash)
+		keysets = append(keysets, buf.Bytes())
+	}
+	return hashes, keysets
+}
+
+func TestKeysetValidityWindow(t *testing.T) {
+	hashes, keysets := testKeysets(t, 2)
+	fetcher := &KeysetFetcher{
+		keysetCache:  syncedKeysetCache{cache: make(map[[32]byte][]byte)},
+		validKeysets: make(map[common.Hash]struct{}),
+	}
+	checkValid := func(expected ...common.Hash) {
+		t.Helper()
+		valid := fetcher.ValidKeysets()
+		slices.SortFunc(expected, func(a, b common.Hash) int { return a.Cmp(b) })
+		if !slices.Equal(valid, expected) {
+			Fail(t, "got valid keysets", valid, "expected", expected)
+		}
+	}
+	register := func(block uint64, i int) keysetEvent {
+		return keysetEvent{raw: types.Log{BlockNumber: block}, hash: hashes[i], keysetBytes: keysets[i]}
+	}
+	invalidate := func(block uint64, i int) keysetEvent {
+		return keysetEvent{raw: types.Log{BlockNumber: block}, hash: hashes[i], invalidated: true}
+	}
+
+	// Both keysets are valid during the rotation, until the old one is
+	// invalidated
+	fetcher.applyKeysetEvents([]keysetEvent{register(1, 0)})
+	checkValid(hashes[0])
+	fetcher.applyKeysetEvents([]keysetEvent{register(2, 1)})
+	checkValid(hashes[0], hashes[1])
+	fetcher.applyKeysetEvents([]keysetEvent{invalidate(3, 0)})
+	checkValid(hashes[1])
+
+	// The invalidated keyset stays cached to read the batches posted before
+	for i, hash := range hashes {
+		cached, err := fetcher.GetKeysetByHash(context.Background(), hash)
+		Require(t, err)
+		if !bytes.Equal(cached, keysets[i]) {
+			Fail(t, "unexpected cached keyset", i)
+		}
+	}
+
+	// Keysets not matching their hash aren't valid
+	forged := register(4, 0)
+	forged.hash = common.Hash{1}
+	fetcher.applyKeysetEvents([]keysetEvent{forged, invalidate(4, 1)})
+	checkValid()
+}
+
+// keysetLogsFilterer serves the sequencer inbox keyset logs of a parent chain
+// which can be reorged.
+type keysetLogsFilterer struct {
+	mutex sync.Mutex
+	logs  []types.Log
+}
+
+func (f *keysetLogsFilterer) setLogs(logs ...types.Log) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.logs = logs
+}
+
+func (f *keysetLogsFilterer) FilterLogs(ctx context.Context, query ethereum.FilterQuery) ([]types.Log, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	var logs []types.Log
+	for _, log := range f.logs {
+		if log.BlockNumber < query.FromBlock.Uint64() || log.BlockNumber > query.ToBlock.Uint64() {
+			continue
+		}
+		if len(query.Topics) > 0 && !slices.Contains(query.Topics[0], log.Topics[0]) {
+			continue
+		}
+		logs = append(logs, log)
+	}
+	return logs, nil
+}
+
+func (f *keysetLogsFilterer) SubscribeFilterLogs(context.Context, ethereum.FilterQuery, chan<- types.Log) (ethereum.Subscription, error) {
+	return nil, errors.New("subscriptions aren't supported")
+}
+
+// testHeads hands out the parent chain heads sent to it. Once a head has been
+// received, the prefetching iteration of the one before is done.
+type testHeads chan uint64
+
+func (h testHeads) BlockNumber(ctx context.Context) (uint64, error) {
+	select {
+	case head := <-h:
+		return head, nil
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	}
+}
+
+func TestKeysetPrefetchIgnoresReorgedEvents(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	hashes, keysets := testKeysets(t, 2)
+	seqInboxAbi, err := bridgegen.SequencerInboxMetaData.GetAbi()
+	Require(t, err)
+	register := func(block uint64, i int) types.Log {
+		event := seqInboxAbi.Events["SetValidKeyset"]
+		data, err := event.Inputs.NonIndexed().Pack(keysets[i])
+		Require(t, err)
+		return types.Log{Topics: []common.Hash{event.ID, hashes[i]}, Data: data, BlockNumber: block}
+	}
+	invalidate := func(block uint64, i int) types.Log {
+		return types.Log{Topics: []common.Hash{seqInboxAbi.Events["InvalidateKeyset"].ID, hashes[i]}, BlockNumber: block}
+	}
+
+	logs := &keysetLogsFilterer{}
+	filterer, err := bridgegen.NewSequencerInboxFilterer(common.Address{}, logs)
+	Require(t, err)
+	fetcher := &KeysetFetcher{
+		seqInboxFilterer: filterer,
+		keysetCache:      syncedKeysetCache{cache: make(map[[32]byte][]byte)},
+		validKeysets:     make(map[common.Hash]struct{}),
+	}
+	heads := make(testHeads)
+	config := DefaultKeysetPrefetchConfig
+	config.Enable = true
+	config.PollInterval = time.Millisecond
+	config.Confirmations = 5
+	fetcher.StartPrefetching(ctx, heads, config)
+	defer fetcher.StopAndWait()
+	poll := func(head uint64) {
+		heads <- head
+		heads <- head
+	}
+	checkValid := func(expected ...common.Hash) {
+		t.Helper()
+		valid := fetcher.ValidKeysets()
+		slices.SortFunc(expected, func(a, b common.Hash) int { return a.Cmp(b) })
+		if !slices.Equal(valid, expected) {
+			Fail(t, "got valid keysets", valid, "expected", expected)
+		}
+	}
+
+	// The invalidation isn't applied before it's confirmed
+	logs.setLogs(register(1, 0), invalidate(8, 0))
+	poll(10)
+	checkValid(hashes[0])
+
+	// Once reorged out, the keyset stays valid
+	logs.setLogs(register(1, 0), register(9, 1))
+	poll(16)
+	checkValid(hashes[0], hashes[1])
+
+	// A confirmed invalidation is applied
+	logs.setLogs(register(1, 0), register(9, 1), invalidate(12, 0))
+	poll(20)
+	checkValid(hashes[1])
+}
@@ -146,7 +146,7 @@ func (s *LocalFileStorageService) Put(ctx context.Context, data []byte, expiry u
 		return fmt.Errorf("requested expiry time (%v) exceeds current time plus maximum allowed retention period(%v)", expiryTime, currentTimePlusRetention)
 	}
 
-	key := tree.Hash(data)
+	key := StorageKey(data)
 	var batchPath string
 	if !s.enableLegacyLayout {
 		s.layout.writeMutex.Lock()
@@ -258,6 +258,59 @@ func (s *LocalFileStorageService) HealthCheck(ctx context.Context) error {
 	return nil
 }
 
+func (s *LocalFileStorageService) IterateBatches(ctx context.Context, startAfter common.Hash, fn func(common.Hash, uint64) error) error {
+	if s.enableLegacyLayout {
+		return errors.New("iterating batches is not supported with the legacy local file storage layout")
+	}
+
+	// A batch stored several times has an expiry index entry for each
+	// expiry, the latest of which is when it gets pruned.
+	expiries := make(map[common.Hash]uint64)
+	expiryIt, err := s.layout.iterateBatchesByTimestamp(time.Unix(math.MaxInt64, 0))
+	if err != nil {
+		return err
+	}
+	for pathByTimestamp, err := expiryIt.next(); !errors.Is(err, io.EOF); pathByTimestamp, err = expiryIt.next() {
+		if err != nil {
+			return err
+		}
+		key, err := DecodeStorageServiceKey(path.Base(pathByTimestamp))
+		if err != nil {
+			return err
+		}
+		secondDir := path.Dir(pathByTimestamp)
+		expiry, err := strconv.ParseUint(path.Base(path.Dir(secondDir))+path.Base(secondDir), 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid expiry index entry %s: %w", pathByTimestamp, err)
+		}
+		expiries[key] = max(expiries[key], expiry)
+	}
+
+	it, err := s.layout.iterateBatches()
+	if err != nil {
+		return err
+	}
+	for pathByHash, err := it.next(); !errors.Is(err, io.EOF); pathByHash, err = it.next() {
+		if err != nil {
+			return err
+		}
+		key, err := DecodeStorageServiceKey(path.Base(pathByHash))
+		if err != nil {
+			return err
+		}
+		if key.Cmp(startAfter) <= 0 {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := fn(key, expiries[key]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func listDir(dir string) ([]string, error) {
 	d, err := os.Open(dir)
 	if err != nil {
@@ -6,12 +6,12 @@ package anytrust
 import (
 	"context"
 	"errors"
+	"slices"
 	"sync"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/log"
 
-	"github.com/offchainlabs/nitro/daprovider/anytrust/tree"
 	anytrustutil "github.com/offchainlabs/nitro/daprovider/anytrust/util"
 )
 
@@ -50,7 +50,30 @@ func (m *MemoryBackedStorageService) Put(ctx context.Context, data []byte, expir
 	if m.closed {
 		return ErrClosed
 	}
-	m.contents[tree.Hash(data)] = append([]byte{}, data...)
+	m.contents[StorageKey(data)] = append([]byte{}, data...)
+	return nil
+}
+
+func (m *MemoryBackedStorageService) IterateBatches(ctx context.Context, startAfter common.Hash, fn func(common.Hash, uint64) error) error {
+	m.rwmutex.RLock()
+	if m.closed {
+		m.rwmutex.RUnlock()
+		return ErrClosed
+	}
+	var keys []common.Hash
+	for key := range m.contents {
+		if common.Hash(key).Cmp(startAfter) > 0 {
+			keys = append(keys, key)
+		}
+	}
+	m.rwmutex.RUnlock()
+	slices.SortFunc(keys, func(a, b common.Hash) int { return a.Cmp(b) })
+	for _, key := range keys {
+		// The expiration time isn't kept
+		if err := fn(key, 0); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
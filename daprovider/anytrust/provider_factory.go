@@ -75,6 +75,9 @@ func (f *Factory) ValidateConfig() error {
 			return errors.New("rest-aggregator.enable must be set for reader mode")
 		}
 	}
+	if err := f.config.KeysetPrefetch.Validate(); err != nil {
+		return err
+	}
 
 	return nil
 }
@@ -8,6 +8,7 @@ import (
 	"errors"
 	"fmt"
 	"math"
+	"slices"
 	"strings"
 	"sync"
 	"time"
@@ -36,8 +37,10 @@ type RestfulClientAggregatorConfig struct {
 	WaitBeforeTryNext            time.Duration                      `koanf:"wait-before-try-next"`
 	MaxPerEndpointStats          int                                `koanf:"max-per-endpoint-stats"`
 	SimpleExploreExploitStrategy SimpleExploreExploitStrategyConfig `koanf:"simple-explore-exploit-strategy"`
+	LatencyPercentileStrategy    LatencyPercentileStrategyConfig    `koanf:"latency-percentile-strategy"`
 	SyncToStorage                SyncToStorageConfig                `koanf:"sync-to-storage"`
 	ConnectionWait               time.Duration                      `koanf:"connection-wait"`
+	ReadShards                   bool                               `koanf:"read-shards"`
 }
 
 var DefaultRestfulClientAggregatorConfig = RestfulClientAggregatorConfig{
@@ -49,8 +52,10 @@ var DefaultRestfulClientAggregatorConfig = RestfulClientAggregatorConfig{
 	WaitBeforeTryNext:            2 * time.Second,
 	MaxPerEndpointStats:          20,
 	SimpleExploreExploitStrategy: DefaultSimpleExploreExploitStrategyConfig,
+	LatencyPercentileStrategy:    DefaultLatencyPercentileStrategyConfig,
 	SyncToStorage:                DefaultSyncToStorageConfig,
 	ConnectionWait:               time.Second,
+	ReadShards:                   false,
 }
 
 type SimpleExploreExploitStrategyConfig struct {
@@ -63,17 +68,48 @@ var DefaultSimpleExploreExploitStrategyConfig = SimpleExploreExploitStrategyConf
 	ExploitIterations: 1000,
 }
 
+type LatencyPercentileStrategyConfig struct {
+	HedgePercentile        float64       `koanf:"hedge-percentile"`
+	MinHedgeDelay          time.Duration `koanf:"min-hedge-delay"`
+	MinSamples             int           `koanf:"min-samples"`
+	MaxErrorRate           float64       `koanf:"max-error-rate"`
+	CircuitBreakerCooldown time.Duration `koanf:"circuit-breaker-cooldown"`
+}
+
+var DefaultLatencyPercentileStrategyConfig = LatencyPercentileStrategyConfig{
+	HedgePercentile:        0.95,
+	MinHedgeDelay:          100 * time.Millisecond,
+	MinSamples:             5,
+	MaxErrorRate:           0.5,
+	CircuitBreakerCooldown: time.Minute,
+}
+
+func (c *LatencyPercentileStrategyConfig) Validate() error {
+	if c.HedgePercentile <= 0 || c.HedgePercentile > 1 {
+		return fmt.Errorf("latency-percentile-strategy.hedge-percentile must be in (0, 1], got %v", c.HedgePercentile)
+	}
+	if c.MaxErrorRate < 0 || c.MaxErrorRate >= 1 {
+		return fmt.Errorf("latency-percentile-strategy.max-error-rate must be in [0, 1), got %v", c.MaxErrorRate)
+	}
+	if c.MinSamples < 1 {
+		return fmt.Errorf("latency-percentile-strategy.min-samples must be at least 1, got %d", c.MinSamples)
+	}
+	return nil
+}
+
 func RestfulClientAggregatorConfigAddOptions(prefix string, f *pflag.FlagSet) {
 	f.Bool(prefix+".enable", DefaultRestfulClientAggregatorConfig.Enable, "enable retrieval of sequencer batch data from a list of remote REST endpoints; if other AnyTrust storage types are enabled, this mode is used as a fallback")
 	f.StringSlice(prefix+".urls", DefaultRestfulClientAggregatorConfig.Urls, "list of URLs including 'http://' or 'https://' prefixes and port numbers to REST AnyTrust endpoints; additive with the online-url-list option")
 	f.String(prefix+".online-url-list", DefaultRestfulClientAggregatorConfig.OnlineUrlList, "a URL to a list of URLs of REST AnyTrust endpoints that is checked at startup; additive with the url option")
 	f.Duration(prefix+".online-url-list-fetch-interval", DefaultRestfulClientAggregatorConfig.OnlineUrlListFetchInterval, "time interval to periodically fetch url list from online-url-list")
-	f.String(prefix+".strategy", DefaultRestfulClientAggregatorConfig.Strategy, "strategy to use to determine order and parallelism of calling REST endpoint URLs; valid options are 'simple-explore-exploit'")
+	f.String(prefix+".strategy", DefaultRestfulClientAggregatorConfig.Strategy, "strategy to use to determine order and parallelism of calling REST endpoint URLs; valid options are 'simple-explore-exploit' and 'latency-percentile'")
 	f.Duration(prefix+".strategy-update-interval", DefaultRestfulClientAggregatorConfig.StrategyUpdateInterval, "how frequently to update the strategy with endpoint latency and error rate data")
 	f.Duration(prefix+".wait-before-try-next", DefaultRestfulClientAggregatorConfig.WaitBeforeTryNext, "time to wait until trying the next set of REST endpoints while waiting for a response; the next set of REST endpoints is determined by the strategy selected")
 	f.Int(prefix+".max-per-endpoint-stats", DefaultRestfulClientAggregatorConfig.MaxPerEndpointStats, "number of stats entries (latency and success rate) to keep for each REST endpoint; controls whether strategy is faster or slower to respond to changing conditions")
 	f.Duration(prefix+".connection-wait", DefaultRestfulClientAggregatorConfig.ConnectionWait, "how long to wait for initial connection")
+	f.Bool(prefix+".read-shards", DefaultRestfulClientAggregatorConfig.ReadShards, "reconstruct batches from the erasure coded shards stored by committee members with accept-shards enabled; when disabled only complete batches are accepted from REST endpoints")
 	SimpleExploreExploitStrategyConfigAddOptions(prefix+".simple-explore-exploit-strategy", f)
+	LatencyPercentileStrategyConfigAddOptions(prefix+".latency-percentile-strategy", f)
 	SyncToStorageConfigAddOptions(prefix+".sync-to-storage", f)
 }
 
@@ -82,6 +118,14 @@ func SimpleExploreExploitStrategyConfigAddOptions(prefix string, f *pflag.FlagSe
 	f.Uint32(prefix+".exploit-iterations", DefaultSimpleExploreExploitStrategyConfig.ExploitIterations, "number of consecutive GetByHash calls to the aggregator where each call will cause it to select from REST endpoints in order of best latency and success rate, before switching to explore mode")
 }
 
+func LatencyPercentileStrategyConfigAddOptions(prefix string, f *pflag.FlagSet) {
+	f.Float64(prefix+".hedge-percentile", DefaultLatencyPercentileStrategyConfig.HedgePercentile, "percentile (between 0 and 1) of the latency of a REST endpoint after which the next REST endpoint is also tried, if it hasn't responded; wait-before-try-next is used if it is lower, or if there are fewer than min-samples stats for the endpoint")
+	f.Duration(prefix+".min-hedge-delay", DefaultLatencyPercentileStrategyConfig.MinHedgeDelay, "minimum time to wait for a REST endpoint before also trying the next one")
+	f.Int(prefix+".min-samples", DefaultLatencyPercentileStrategyConfig.MinSamples, "minimum number of stats entries for a REST endpoint before its latency percentile is used, or its circuit can be broken")
+	f.Float64(prefix+".max-error-rate", DefaultLatencyPercentileStrategyConfig.MaxErrorRate, "error rate (between 0 and 1) of a REST endpoint above which its circuit is broken, so that it is only tried after all the other REST endpoints failed")
+	f.Duration(prefix+".circuit-breaker-cooldown", DefaultLatencyPercentileStrategyConfig.CircuitBreakerCooldown, "time after which a REST endpoint whose circuit is broken is probed again; its circuit is closed if the probe succeeds")
+}
+
 func NewRestfulClientAggregator(ctx context.Context, config *RestfulClientAggregatorConfig) (*SimpleReaderAggregator, error) {
 	a := SimpleReaderAggregator{
 		config: config,
@@ -113,7 +157,7 @@ func NewRestfulClientAggregator(ctx context.Context, config *RestfulClientAggreg
 	log.Info("REST Aggregator URLs", "urls", urls)
 
 	for _, url := range urls {
-		reader, err := NewRestfulClientFromURL(url)
+		reader, err := newShardRestfulClientFromURL(url, config.ReadShards)
 		if err != nil {
 			return nil, err
 		}
@@ -128,6 +172,11 @@ func NewRestfulClientAggregator(ctx context.Context, config *RestfulClientAggreg
 			exploreIterations: config.SimpleExploreExploitStrategy.ExploreIterations,
 			exploitIterations: config.SimpleExploreExploitStrategy.ExploitIterations,
 		}
+	case "latency-percentile":
+		if err := config.LatencyPercentileStrategy.Validate(); err != nil {
+			return nil, err
+		}
+		a.strategy = newLatencyPercentileStrategy(config.LatencyPercentileStrategy, config.MaxPerEndpointStats)
 	case "testing-sequential":
 		a.strategy = &testingSequentialStrategy{}
 	default:
@@ -158,6 +207,47 @@ func (s *readerStats) successRatioWeightedMeanLatency() time.Duration {
 	return time.Duration(avgLatency / successRatio)
 }
 
+// Return the ratio of failures : total attempts
+func (s *readerStats) errorRate() float64 {
+	if len(*s) == 0 {
+		return 0
+	}
+	failures := 0
+	for _, stat := range *s {
+		if !stat.success {
+			failures++
+		}
+	}
+	return float64(failures) / float64(len(*s))
+}
+
+// Return the latency at percentile p (between 0 and 1) of the successful
+// attempts, or 0 if there were none
+func (s *readerStats) latencyPercentile(p float64) time.Duration {
+	var latencies []time.Duration
+	for _, stat := range *s {
+		if stat.success {
+			latencies = append(latencies, stat.latency)
+		}
+	}
+	if len(latencies) == 0 {
+		return 0
+	}
+	slices.Sort(latencies)
+	index := int(math.Ceil(p*float64(len(latencies)))) - 1
+	return latencies[max(index, 0)]
+}
+
+// Return the latency at percentile p, weighted inversely by the ratio of
+// successes : total attempts
+func (s *readerStats) successRatioWeightedPercentileLatency(p float64) time.Duration {
+	successRatio := 1 - s.errorRate()
+	if len(*s) == 0 || successRatio == 0 {
+		return time.Duration(math.MaxInt64)
+	}
+	return time.Duration(float64(s.latencyPercentile(p)) / successRatio)
+}
+
 type readerStat struct {
 	latency time.Duration
 	success bool
@@ -194,22 +284,35 @@ func (a *SimpleReaderAggregator) GetByHash(ctx context.Context, hash common.Hash
 	}
 
 	results := make(chan dataErrorPair, len(a.readers))
+	// needMore makes the next readers get tried right away, when a shard of
+	// the batch was returned rather than the batch.
+	needMore := make(chan struct{}, 1)
 	subCtx, cancel := context.WithCancel(ctx)
 	defer cancel()
 
 	go func() {
 		si := a.strategy.newInstance()
+		hedging, isHedging := si.(hedgingInstance)
+		hedged := false
 		for readers := si.nextReaders(); len(readers) != 0 && subCtx.Err() == nil; readers = si.nextReaders() {
+			if hedged {
+				hedgedRequestsCounter.Inc(1)
+				hedged = false
+			}
+			wait := a.config.WaitBeforeTryNext
+			var hedgeDelay time.Duration
+			if isHedging {
+				wait = hedging.waitBeforeTryNext(wait)
+				hedgeDelay = wait
+			}
 			wg := sync.WaitGroup{}
 			waitChan := make(chan interface{})
 			for _, reader := range readers {
 				wg.Add(1)
 				go func(reader anytrustutil.Reader) {
 					defer wg.Done()
-					data, err := a.tryGetByHash(subCtx, hash, reader)
+					data, err := a.tryGetByHash(subCtx, hash, reader, hedgeDelay)
 					if err != nil && errors.Is(ctx.Err(), context.Canceled) {
-						// Don't record a stats data point when a different
-						// client returned faster than this one.
 						return
 					}
 					results <- dataErrorPair{data, err}
@@ -222,7 +325,10 @@ func (a *SimpleReaderAggregator) GetByHash(ctx context.Context, hash common.Hash
 			select {
 			case <-subCtx.Done():
 				return
-			case <-time.After(a.config.WaitBeforeTryNext):
+			case <-time.After(wait):
+				// Only counted once the next readers are tried
+				hedged = isHedging
+			case <-needMore:
 			case <-waitChan:
 				// Yield to give the collector a chance to run in case a request succeeded
 				time.Sleep(10 * time.Millisecond)
@@ -231,6 +337,7 @@ func (a *SimpleReaderAggregator) GetByHash(ctx context.Context, hash common.Hash
 	}()
 
 	errorCollection := make([]error, 0, len(a.readers))
+	shards := newShardCollector(hash)
 	for i := 0; i < len(a.readers); i++ {
 		select {
 		case <-ctx.Done():
@@ -238,8 +345,19 @@ func (a *SimpleReaderAggregator) GetByHash(ctx context.Context, hash common.Hash
 		case result := <-results:
 			if result.err != nil {
 				errorCollection = append(errorCollection, result.err)
-			} else {
+			} else if tree.ValidHash(hash, result.data) {
 				return result.data, nil
+			} else {
+				data, err := shards.add(result.data)
+				if err != nil {
+					errorCollection = append(errorCollection, err)
+				} else if data != nil {
+					return data, nil
+				}
+				select {
+				case needMore <- struct{}{}:
+				default:
+				}
 			}
 		}
 	}
@@ -247,22 +365,31 @@ func (a *SimpleReaderAggregator) GetByHash(ctx context.Context, hash common.Hash
 	return nil, fmt.Errorf("data wasn't able to be retrieved from any AnyTrust Reader: %v", errorCollection)
 }
 
+// tryGetByHash gets the data from reader, and records the attempt in its
+// stats. hedgeDelay is how long the strategy waits for the reader before
+// trying the next ones, or 0 if it doesn't hedge.
 func (a *SimpleReaderAggregator) tryGetByHash(
-	ctx context.Context, hash common.Hash, reader anytrustutil.Reader,
+	ctx context.Context, hash common.Hash, reader anytrustutil.Reader, hedgeDelay time.Duration,
 ) ([]byte, error) {
 	stat := readerStatMessage{reader: reader}
 	stat.success = false
 
 	start := time.Now()
 	result, err := reader.GetByHash(ctx, hash)
+	stat.latency = time.Since(start)
+	if err != nil && ctx.Err() != nil && (hedgeDelay == 0 || stat.latency < hedgeDelay) {
+		// Don't record a stats data point when a different client returned
+		// faster than this one. Once the reader has been hedged against it's
+		// recorded as failing though, or a reader which hangs would never be.
+		return nil, err
+	}
 	if err == nil {
-		if tree.ValidHash(hash, result) {
+		if validStoredData(hash, result, a.config.ReadShards) {
 			stat.success = true
 		} else {
 			err = fmt.Errorf("SimpleReaderAggregator got result from reader(%v) not matching hash", reader)
 		}
 	}
-	stat.latency = time.Since(start)
 
 	select {
 	case a.statMessages <- stat:
@@ -285,7 +412,7 @@ func (a *SimpleReaderAggregator) Start(ctx context.Context) {
 		combinedUrls = append(combinedUrls, urls...)
 		combinedReaders := make(map[anytrustutil.Reader]bool)
 		for _, url := range combinedUrls {
-			reader, err := NewRestfulClientFromURL(url)
+			reader, err := newShardRestfulClientFromURL(url, a.config.ReadShards)
 			if err != nil {
 				return
 			}
@@ -317,6 +444,9 @@ func (a *SimpleReaderAggregator) Start(ctx context.Context) {
 			case <-innerCtx.Done():
 				return
 			case stat := <-a.statMessages:
+				if observer, ok := a.strategy.(readerStatObserver); ok {
+					observer.observe(stat.reader, stat.readerStat)
+				}
 				a.stats[stat.reader] = append(a.stats[stat.reader], stat.readerStat)
 				statsLen := len(a.stats[stat.reader])
 				if statsLen > a.config.MaxPerEndpointStats {
@@ -4,14 +4,21 @@
 package anytrust
 
 import (
+	"cmp"
 	"errors"
 	"maps"
 	"math/rand"
+	"slices"
 	"sort"
 	"sync"
 	"sync/atomic"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
 
 	anytrustutil "github.com/offchainlabs/nitro/daprovider/anytrust/util"
+	"github.com/offchainlabs/nitro/util/metricsutil"
 )
 
 var ErrNoReadersResponded = errors.New("no AnyTrust readers responded successfully")
@@ -21,6 +28,13 @@ type aggregatorStrategy interface {
 	update([]anytrustutil.Reader, map[anytrustutil.Reader]readerStats)
 }
 
+// Strategies implementing readerStatObserver are also given the stat of each
+// request as soon as it completes, rather than only every
+// strategy-update-interval.
+type readerStatObserver interface {
+	observe(anytrustutil.Reader, readerStat)
+}
+
 type abstractAggregatorStrategy struct {
 	sync.RWMutex
 	readers []anytrustutil.Reader
@@ -95,11 +109,221 @@ func (s *testingSequentialStrategy) newInstance() aggregatorStrategyInstance {
 	return &si
 }
 
+// Metric path uses "das" like the other AnyTrust metrics
+const readerMetricBase string = "arb/das/rest/aggregator"
+
+var hedgedRequestsCounter = metrics.NewRegisteredCounter(readerMetricBase+"/hedged", nil)
+
+// Strategy tracking the latency percentiles and error rate of each reader.
+// Readers are tried one at a time in order of median latency weighted by
+// success ratio, and the next one is tried if a reader hasn't responded
+// within its hedge percentile latency. Readers whose error rate exceeds
+// max-error-rate have their circuit broken: they're only tried once all
+// others failed, until a probe request succeeds after the cooldown.
+type latencyPercentileStrategy struct {
+	config LatencyPercentileStrategyConfig
+	// window is the number of recent outcomes the circuit breakers use.
+	window int
+
+	// summaries and breakers are guarded by the abstractAggregatorStrategy
+	// lock.
+	summaries map[anytrustutil.Reader]readerSummary
+	breakers  map[anytrustutil.Reader]*circuitBreaker
+
+	abstractAggregatorStrategy
+}
+
+type readerSummary struct {
+	samples    int
+	errorRate  float64
+	p50        time.Duration
+	p99        time.Duration
+	hedgeDelay time.Duration
+	// score orders the readers, lower is better.
+	score time.Duration
+}
+
+type circuitBreaker struct {
+	// outcomes are the recent outcomes of requests to the reader since the
+	// breaker last closed.
+	outcomes  []bool
+	open      bool
+	openUntil time.Time
+	// probeStarted is when a probe request to the reader was last handed out
+	// while its breaker was open.
+	probeStarted time.Time
+}
+
+// halfOpen returns whether the reader may be probed to check whether its
+// circuit can be closed again.
+func (b *circuitBreaker) halfOpen(now time.Time, cooldown time.Duration) bool {
+	return b.open && !now.Before(b.openUntil) && now.Sub(b.probeStarted) >= cooldown
+}
+
+func newLatencyPercentileStrategy(config LatencyPercentileStrategyConfig, window int) *latencyPercentileStrategy {
+	return &latencyPercentileStrategy{
+		config:    config,
+		window:    window,
+		summaries: make(map[anytrustutil.Reader]readerSummary),
+		breakers:  make(map[anytrustutil.Reader]*circuitBreaker),
+	}
+}
+
+func (s *latencyPercentileStrategy) update(readers []anytrustutil.Reader, stats map[anytrustutil.Reader]readerStats) {
+	s.abstractAggregatorStrategy.update(readers, stats)
+
+	s.Lock()
+	defer s.Unlock()
+	s.summaries = make(map[anytrustutil.Reader]readerSummary, len(readers))
+	for _, reader := range readers {
+		readerStats := stats[reader]
+		summary := readerSummary{
+			samples:   len(readerStats),
+			errorRate: readerStats.errorRate(),
+			p50:       readerStats.latencyPercentile(0.5),
+			p99:       readerStats.latencyPercentile(0.99),
+			score:     readerStats.successRatioWeightedPercentileLatency(0.5),
+		}
+		if summary.samples >= s.config.MinSamples {
+			summary.hedgeDelay = max(readerStats.latencyPercentile(s.config.HedgePercentile), s.config.MinHedgeDelay)
+		}
+		s.summaries[reader] = summary
+
+		metricBase := readerMetricBase + "/" + readerMetricName(reader)
+		metrics.GetOrRegisterGauge(metricBase+"/latency/p50", nil).Update(summary.p50.Milliseconds())
+		metrics.GetOrRegisterGauge(metricBase+"/latency/p99", nil).Update(summary.p99.Milliseconds())
+		metrics.GetOrRegisterGaugeFloat64(metricBase+"/error_rate", nil).Update(summary.errorRate)
+		var open int64
+		if breaker, ok := s.breakers[reader]; ok && breaker.open {
+			open = 1
+		}
+		metrics.GetOrRegisterGauge(metricBase+"/circuit_open", nil).Update(open)
+	}
+	for reader := range s.breakers {
+		if _, ok := s.summaries[reader]; !ok {
+			delete(s.breakers, reader)
+		}
+	}
+}
+
+// observe updates the circuit breaker of the reader with the outcome of a
+// request to it.
+func (s *latencyPercentileStrategy) observe(reader anytrustutil.Reader, stat readerStat) {
+	s.Lock()
+	defer s.Unlock()
+
+	breaker, ok := s.breakers[reader]
+	if !ok {
+		breaker = &circuitBreaker{}
+		s.breakers[reader] = breaker
+	}
+	now := time.Now()
+	if breaker.open {
+		// Outcomes of requests made before the cooldown ended don't tell
+		// whether the reader has recovered
+		if now.Before(breaker.openUntil) {
+			return
+		}
+		if stat.success {
+			log.Info("Closing circuit of AnyTrust reader", "reader", reader)
+			*breaker = circuitBreaker{}
+		} else {
+			breaker.openUntil = now.Add(s.config.CircuitBreakerCooldown)
+			breaker.probeStarted = time.Time{}
+		}
+		return
+	}
+
+	breaker.outcomes = append(breaker.outcomes, stat.success)
+	if len(breaker.outcomes) > s.window {
+		breaker.outcomes = breaker.outcomes[len(breaker.outcomes)-s.window:]
+	}
+	if len(breaker.outcomes) < s.config.MinSamples {
+		return
+	}
+	failures := 0
+	for _, success := range breaker.outcomes {
+		if !success {
+			failures++
+		}
+	}
+	if errorRate := float64(failures) / float64(len(breaker.outcomes)); errorRate > s.config.MaxErrorRate {
+		log.Warn("Opening circuit of AnyTrust reader", "reader", reader, "errorRate", errorRate, "cooldown", s.config.CircuitBreakerCooldown)
+		*breaker = circuitBreaker{
+			open:      true,
+			openUntil: now.Add(s.config.CircuitBreakerCooldown),
+		}
+	}
+}
+
+func (s *latencyPercentileStrategy) newInstance() aggregatorStrategyInstance {
+	s.Lock()
+	defer s.Unlock()
+
+	now := time.Now()
+	var closed, probes, open []anytrustutil.Reader
+	for _, reader := range s.readers {
+		breaker, ok := s.breakers[reader]
+		switch {
+		case !ok || !breaker.open:
+			closed = append(closed, reader)
+		case breaker.halfOpen(now, s.config.CircuitBreakerCooldown):
+			probes = append(probes, reader)
+		default:
+			open = append(open, reader)
+		}
+	}
+	byScore := func(a, b anytrustutil.Reader) int {
+		return cmp.Compare(s.summaries[a].score, s.summaries[b].score)
+	}
+	slices.SortStableFunc(closed, byScore)
+	slices.SortStableFunc(open, byScore)
+
+	si := &hedgingStrategyInstance{}
+	addSet := func(readers []anytrustutil.Reader) {
+		si.readerSets = append(si.readerSets, readers)
+		si.hedgeDelays = append(si.hedgeDelays, s.summaries[readers[0]].hedgeDelay)
+	}
+	for i, reader := range closed {
+		set := []anytrustutil.Reader{reader}
+		if i == 0 && len(probes) > 0 {
+			// Probe a reader whose circuit is broken alongside the best one,
+			// so the probe doesn't delay the request
+			set = append(set, probes[0])
+			s.breakers[probes[0]].probeStarted = now
+			probes = probes[1:]
+		}
+		addSet(set)
+	}
+	for _, reader := range probes {
+		s.breakers[reader].probeStarted = now
+		addSet([]anytrustutil.Reader{reader})
+	}
+	for _, reader := range open {
+		addSet([]anytrustutil.Reader{reader})
+	}
+	return si
+}
+
+func readerMetricName(reader anytrustutil.Reader) string {
+	if client, ok := reader.(*RestfulClient); ok {
+		return metricsutil.CanonicalizeMetricName(client.url)
+	}
+	return metricsutil.CanonicalizeMetricName(reader.String())
+}
+
 // Instance of a strategy that returns readers in an order according to the strategy
 type aggregatorStrategyInstance interface {
 	nextReaders() []anytrustutil.Reader
 }
 
+// Strategy instances implementing hedgingInstance choose how long to wait for
+// each set of readers before trying the next one, rather than always waiting
+// wait-before-try-next.
+type hedgingInstance interface {
+	waitBeforeTryNext(defaultWait time.Duration) time.Duration
+}
+
 type basicStrategyInstance struct {
 	readerSets [][]anytrustutil.Reader
 }
@@ -112,3 +336,29 @@ func (si *basicStrategyInstance) nextReaders() []anytrustutil.Reader {
 	si.readerSets = si.readerSets[1:]
 	return next
 }
+
+// hedgingStrategyInstance waits for each set of readers for the hedge delay of
+// its first reader.
+type hedgingStrategyInstance struct {
+	basicStrategyInstance
+	hedgeDelays []time.Duration
+	current     time.Duration
+}
+
+func (si *hedgingStrategyInstance) nextReaders() []anytrustutil.Reader {
+	if len(si.hedgeDelays) == 0 {
+		return nil
+	}
+	si.current = si.hedgeDelays[0]
+	si.hedgeDelays = si.hedgeDelays[1:]
+	return si.basicStrategyInstance.nextReaders()
+}
+
+// waitBeforeTryNext returns how long to wait for the readers last returned
+// before trying the next ones, which is at most defaultWait.
+func (si *hedgingStrategyInstance) waitBeforeTryNext(defaultWait time.Duration) time.Duration {
+	if si.current == 0 {
+		return defaultWait
+	}
+	return min(si.current, defaultWait)
+}
@@ -121,3 +121,139 @@ func TestAnyTrust_SimpleExploreExploit(t *testing.T) {
 	}
 
 }
+
+func TestAnyTrust_LatencyPercentile(t *testing.T) {
+	readers := []anytrustutil.Reader{&dummyReader{0}, &dummyReader{1}, &dummyReader{2}}
+	stats := make(map[anytrustutil.Reader]readerStats)
+	for i := 1; i <= 20; i++ {
+		stats[readers[0]] = append(stats[readers[0]], readerStat{5 * time.Second, true})
+		stats[readers[1]] = append(stats[readers[1]], readerStat{time.Duration(i) * 100 * time.Millisecond, true})
+		stats[readers[2]] = append(stats[readers[2]], readerStat{2 * time.Second, i%4 == 0})
+	}
+	fastStats, failingStats := stats[readers[1]], stats[readers[2]]
+	if p50 := fastStats.latencyPercentile(0.5); p50 != time.Second {
+		Fail(t, "unexpected p50", p50)
+	}
+	if errorRate := failingStats.errorRate(); errorRate != 0.75 {
+		Fail(t, "unexpected error rate", errorRate)
+	}
+
+	config := DefaultLatencyPercentileStrategyConfig
+	config.CircuitBreakerCooldown = 100 * time.Millisecond
+	strategy := newLatencyPercentileStrategy(config, 20)
+	strategy.update(readers, stats)
+
+	checkOrder := func(si aggregatorStrategyInstance, expected ...[]int) {
+		t.Helper()
+		for _, expectedSet := range expected {
+			set := si.nextReaders()
+			if len(set) != len(expectedSet) {
+				Fail(t, "got readers", set, "expected", expectedSet)
+			}
+			for i, reader := range set {
+				if reader.(*dummyReader).int != expectedSet[i] {
+					Fail(t, "got readers", set, "expected", expectedSet)
+				}
+			}
+		}
+		if set := si.nextReaders(); set != nil {
+			Fail(t, "got unexpected readers", set)
+		}
+	}
+
+	// The fastest reader is hedged after its p95 latency
+	si := strategy.newInstance()
+	si.nextReaders()
+	if wait := si.(hedgingInstance).waitBeforeTryNext(time.Hour); wait != 1900*time.Millisecond {
+		Fail(t, "unexpected hedge delay", wait)
+	}
+	if wait := si.(hedgingInstance).waitBeforeTryNext(time.Second); wait != time.Second {
+		Fail(t, "hedge delay exceeds wait-before-try-next", wait)
+	}
+	checkOrder(strategy.newInstance(), []int{1}, []int{0}, []int{2})
+
+	// A failing reader has its circuit broken, until a probe succeeds
+	for i := 0; i < config.MinSamples; i++ {
+		strategy.observe(readers[1], readerStat{time.Second, false})
+	}
+	checkOrder(strategy.newInstance(), []int{0}, []int{2}, []int{1})
+	time.Sleep(config.CircuitBreakerCooldown)
+	checkOrder(strategy.newInstance(), []int{0, 1}, []int{2})
+	checkOrder(strategy.newInstance(), []int{0}, []int{2}, []int{1})
+	strategy.observe(readers[1], readerStat{time.Second, true})
+	checkOrder(strategy.newInstance(), []int{1}, []int{0}, []int{2})
+}
+
+// hangingReader only returns once its request is cancelled.
+type hangingReader struct {
+	dummyReader
+}
+
+func (*hangingReader) GetByHash(ctx context.Context, _ common.Hash) ([]byte, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestAnyTrust_HedgedCancellationsRecorded(t *testing.T) {
+	a := &SimpleReaderAggregator{statMessages: make(chan readerStatMessage, 3)}
+	reader := &hangingReader{}
+	try := func(cancelAfter, hedgeDelay time.Duration) {
+		t.Helper()
+		ctx, cancel := context.WithTimeout(context.Background(), cancelAfter)
+		defer cancel()
+		if _, err := a.tryGetByHash(ctx, common.Hash{}, reader, hedgeDelay); err == nil {
+			Fail(t, "expected an error from a cancelled request")
+		}
+	}
+
+	// Requests cancelled as another reader returned first aren't recorded
+	try(10*time.Millisecond, time.Hour)
+	try(10*time.Millisecond, 0)
+	select {
+	case stat := <-a.statMessages:
+		Fail(t, "recorded cancelled request", stat)
+	default:
+	}
+
+	// Requests cancelled after being hedged against are recorded as failing
+	try(50*time.Millisecond, 10*time.Millisecond)
+	select {
+	case stat := <-a.statMessages:
+		if stat.success || stat.latency < 50*time.Millisecond {
+			Fail(t, "unexpected stat", stat.readerStat)
+		}
+	default:
+		Fail(t, "hedged request wasn't recorded")
+	}
+}
+
+func TestAnyTrust_HedgedRequestsCounted(t *testing.T) {
+	config := DefaultRestfulClientAggregatorConfig
+	config.WaitBeforeTryNext = 20 * time.Millisecond
+	getByHash := func(readers ...anytrustutil.Reader) int64 {
+		t.Helper()
+		strategy := newLatencyPercentileStrategy(config.LatencyPercentileStrategy, config.MaxPerEndpointStats)
+		strategy.update(readers, make(map[anytrustutil.Reader]readerStats))
+		a := &SimpleReaderAggregator{
+			config:       &config,
+			readers:      readers,
+			strategy:     strategy,
+			statMessages: make(chan readerStatMessage, len(readers)),
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+		defer cancel()
+		before := hedgedRequestsCounter.Snapshot().Count()
+		if _, err := a.GetByHash(ctx, common.Hash{}); err == nil {
+			Fail(t, "expected an error from hanging readers")
+		}
+		return hedgedRequestsCounter.Snapshot().Count() - before
+	}
+
+	// Waiting for the last reader set to time out doesn't launch another one
+	if hedged := getByHash(&hangingReader{dummyReader{0}}); hedged != 0 {
+		Fail(t, "counted", hedged, "hedged requests with a single reader")
+	}
+	if hedged := getByHash(&hangingReader{dummyReader{0}}, &hangingReader{dummyReader{1}}); hedged != 1 {
+		Fail(t, "counted", hedged, "hedged requests with two readers")
+	}
+}
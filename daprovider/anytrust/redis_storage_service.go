@@ -109,6 +109,11 @@ func (rs *RedisStorageService) GetByHash(ctx context.Context, key common.Hash) (
 		if err != nil {
 			return nil, err
 		}
+		// Only complete batches are cached from reads, a shard envelope read
+		// through is never verified on its own.
+		if !tree.ValidHash(key, ret) {
+			return ret, nil
+		}
 
 		err = rs.client.Set(ctx, string(key.Bytes()), rs.signMessage(ret), rs.redisConfig.Expiration).Err()
 		if err != nil {
@@ -127,7 +132,7 @@ func (rs *RedisStorageService) Put(ctx context.Context, value []byte, timeout ui
 		return err
 	}
 	err = rs.client.Set(
-		ctx, string(tree.Hash(value).Bytes()), rs.signMessage(value), rs.redisConfig.Expiration,
+		ctx, string(StorageKey(value).Bytes()), rs.signMessage(value), rs.redisConfig.Expiration,
 	).Err()
 	if err != nil {
 		log.Error("anytrust.RedisStorageService.Store", "err", err)
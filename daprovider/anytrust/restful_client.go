@@ -15,13 +15,15 @@ import (
 
 	"github.com/ethereum/go-ethereum/common"
 
-	"github.com/offchainlabs/nitro/daprovider/anytrust/tree"
 	anytrustutil "github.com/offchainlabs/nitro/daprovider/anytrust/util"
 )
 
 // RestfulClient implements anytrustutil.Reader
 type RestfulClient struct {
 	url string
+	// acceptShards is whether shard envelopes are returned rather than
+	// rejected, for readers which reconstruct the batch from them.
+	acceptShards bool
 }
 
 func (c *RestfulClient) String() string {
@@ -30,7 +32,8 @@ func (c *RestfulClient) String() string {
 
 func NewRestfulClient(protocol string, host string, port int) *RestfulClient {
 	return &RestfulClient{
-		url: fmt.Sprintf("%s://%s:%d", protocol, host, port),
+		url:          fmt.Sprintf("%s://%s:%d", protocol, host, port),
+		acceptShards: false,
 	}
 }
 
@@ -40,10 +43,24 @@ func NewRestfulClientFromURL(url string) (*RestfulClient, error) {
 
 	}
 	return &RestfulClient{
-		url: url,
+		url:          url,
+		acceptShards: false,
 	}, nil
 }
 
+// newShardRestfulClientFromURL creates a RestfulClient which also returns the
+// shard envelopes stored by committee members with accept-shards enabled.
+func newShardRestfulClientFromURL(url string, acceptShards bool) (*RestfulClient, error) {
+	client, err := NewRestfulClientFromURL(url)
+	if err != nil {
+		return nil, err
+	}
+	client.acceptShards = acceptShards
+	return client, nil
+}
+
+// GetByHash returns the batch, or, if the client accepts shards, the shard
+// envelope of it stored by a committee member with accept-shards enabled.
 func (c *RestfulClient) GetByHash(ctx context.Context, hash common.Hash) ([]byte, error) {
 	req, err := http.NewRequestWithContext(ctx, "GET", c.url+getByHashRequestPath+EncodeStorageServiceKey(hash), nil)
 	if err != nil {
@@ -71,7 +88,7 @@ func (c *RestfulClient) GetByHash(ctx context.Context, hash common.Hash) ([]byte
 	if err != nil {
 		return nil, err
 	}
-	if !tree.ValidHash(hash, decodedBytes) {
+	if !validStoredData(hash, decodedBytes, c.acceptShards) {
 		return nil, anytrustutil.ErrHashMismatch
 	}
 
@@ -8,6 +8,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
@@ -17,7 +18,6 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/log"
 
-	"github.com/offchainlabs/nitro/daprovider/anytrust/tree"
 	anytrustutil "github.com/offchainlabs/nitro/daprovider/anytrust/util"
 	"github.com/offchainlabs/nitro/util/pretty"
 	"github.com/offchainlabs/nitro/util/s3client"
@@ -79,7 +79,7 @@ func (s3s *S3StorageService) Put(ctx context.Context, value []byte, _ uint64) er
 	logPut("anytrust.S3StorageService.Store", value, 0, s3s)
 	putObjectInput := s3.PutObjectInput{
 		Bucket: aws.String(s3s.bucket),
-		Key:    aws.String(s3s.objectPrefix + EncodeStorageServiceKey(tree.Hash(value))),
+		Key:    aws.String(s3s.objectPrefix + EncodeStorageServiceKey(StorageKey(value))),
 		Body:   bytes.NewReader(value)}
 	_, err := s3s.client.Upload(ctx, &putObjectInput)
 	if err != nil {
@@ -88,6 +88,39 @@ func (s3s *S3StorageService) Put(ctx context.Context, value []byte, _ uint64) er
 	return err
 }
 
+// IterateBatches lists the objects under the object prefix. Their expiration
+// is configured on the bucket, so it is reported as 0.
+func (s3s *S3StorageService) IterateBatches(ctx context.Context, startAfter common.Hash, fn func(common.Hash, uint64) error) error {
+	input := &s3.ListObjectsV2Input{
+		Bucket: aws.String(s3s.bucket),
+		Prefix: aws.String(s3s.objectPrefix),
+	}
+	if startAfter != (common.Hash{}) {
+		input.StartAfter = aws.String(s3s.objectPrefix + EncodeStorageServiceKey(startAfter))
+	}
+	paginator := s3.NewListObjectsV2Paginator(s3s.client.Client(), input)
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return err
+		}
+		for _, object := range page.Contents {
+			name := strings.TrimPrefix(aws.ToString(object.Key), s3s.objectPrefix)
+			if !isStorageServiceKey(name) {
+				continue
+			}
+			key, err := DecodeStorageServiceKey(name)
+			if err != nil {
+				return err
+			}
+			if err := fn(key, 0); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
 func (s3s *S3StorageService) Sync(ctx context.Context) error {
 	return nil
 }
@@ -0,0 +1,362 @@
+// Copyright 2026, Offchain Labs, Inc.
+// For license information, see https://github.com/OffchainLabs/nitro/blob/master/LICENSE.md
+
+package anytrust
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/klauspost/reedsolomon"
+	"github.com/spf13/pflag"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/offchainlabs/nitro/daprovider/anytrust/tree"
+)
+
+type ShardingConfig struct {
+	Enable     bool `koanf:"enable"`
+	DataShards int  `koanf:"data-shards"`
+}
+
+var DefaultShardingConfig = ShardingConfig{
+	Enable:     false,
+	DataShards: 1,
+}
+
+func ShardingConfigAddOptions(prefix string, f *pflag.FlagSet) {
+	f.Bool(prefix+".enable", DefaultShardingConfig.Enable, "have each backend erasure code each batch into one shard per backend and only store its own shard; all backends must have accept-shards enabled")
+	f.Int(prefix+".data-shards", DefaultShardingConfig.DataShards, "number of shards (D) needed to reconstruct a batch, at most assumed-honest (H); N+D-H valid responses are required for a Store request to be successful")
+}
+
+// A shard request is what an aggregator with sharding enabled sends to each
+// committee member instead of the batch. The member hashes the batch itself,
+// and erasure codes it to store only its own shard, so that it signs nothing
+// it hasn't verified. It's made of:
+//
+//	shardRequestPrefix
+//	data shards     uint16
+//	total shards    uint16
+//	shard index     uint16
+//	data            []byte
+var shardRequestPrefix = []byte("anytrust-shard-request-v1")
+
+const shardRequestHeaderSize = 2 + 2 + 2
+
+// maxTotalShards is the most shards the Reed-Solomon code over GF(2^8)
+// supports.
+const maxTotalShards = 256
+
+type shardRequest struct {
+	dataShards  int
+	totalShards int
+	index       int
+	data        []byte
+}
+
+func IsShardRequest(message []byte) bool {
+	return bytes.HasPrefix(message, shardRequestPrefix)
+}
+
+// EncodeShardRequest makes the request to store the shard with the given
+// index of data, erasure coded into totalShards shards any dataShards of which
+// reconstruct it.
+func EncodeShardRequest(data []byte, dataShards, totalShards, index int) []byte {
+	buf := make([]byte, 0, len(shardRequestPrefix)+shardRequestHeaderSize+len(data))
+	buf = append(buf, shardRequestPrefix...)
+	// #nosec G115
+	buf = binary.BigEndian.AppendUint16(buf, uint16(dataShards))
+	// #nosec G115
+	buf = binary.BigEndian.AppendUint16(buf, uint16(totalShards))
+	// #nosec G115
+	buf = binary.BigEndian.AppendUint16(buf, uint16(index))
+	return append(buf, data...)
+}
+
+func decodeShardRequest(message []byte) (*shardRequest, error) {
+	if !IsShardRequest(message) {
+		return nil, errors.New("not a shard request")
+	}
+	message = message[len(shardRequestPrefix):]
+	if len(message) < shardRequestHeaderSize {
+		return nil, errors.New("shard request too short")
+	}
+	r := &shardRequest{
+		dataShards:  int(binary.BigEndian.Uint16(message[0:2])),
+		totalShards: int(binary.BigEndian.Uint16(message[2:4])),
+		index:       int(binary.BigEndian.Uint16(message[4:6])),
+		data:        message[shardRequestHeaderSize:],
+	}
+	if r.dataShards == 0 || r.dataShards > r.totalShards || r.totalShards > maxTotalShards || r.index >= r.totalShards {
+		return nil, fmt.Errorf("invalid request for shard %d of %d shards, %d of which are data shards", r.index, r.totalShards, r.dataShards)
+	}
+	return r, nil
+}
+
+// encodeShards erasure codes data into totalShards shards of equal size, any
+// dataShards of which reconstruct it.
+func encodeShards(data []byte, dataShards, totalShards int) ([][]byte, error) {
+	if len(data) == 0 {
+		shards := make([][]byte, totalShards)
+		for i := range shards {
+			shards[i] = []byte{}
+		}
+		return shards, nil
+	}
+	encoder, err := reedsolomon.New(dataShards, totalShards-dataShards)
+	if err != nil {
+		return nil, err
+	}
+	// Split pads the data shards within the spare capacity of its argument
+	shards, err := encoder.Split(bytes.Clone(data))
+	if err != nil {
+		return nil, err
+	}
+	if err := encoder.Encode(shards); err != nil {
+		return nil, err
+	}
+	return shards, nil
+}
+
+// reconstructShards reconstructs data of size dataSize from shards, in which
+// missing shards are nil.
+func reconstructShards(shards [][]byte, dataShards int, dataSize uint64) ([]byte, error) {
+	if dataSize == 0 {
+		return []byte{}, nil
+	}
+	encoder, err := reedsolomon.New(dataShards, len(shards)-dataShards)
+	if err != nil {
+		return nil, err
+	}
+	if err := encoder.ReconstructData(shards); err != nil {
+		return nil, err
+	}
+	var data bytes.Buffer
+	// #nosec G115
+	if err := encoder.Join(&data, shards, int(dataSize)); err != nil {
+		return nil, err
+	}
+	return data.Bytes(), nil
+}
+
+// A shard envelope is what a committee member stores instead of the batch
+// when it gets a shard request. It's stored under the data hash of the batch,
+// and made of:
+//
+//	shardEnvelopePrefix
+//	data hash       [32]byte
+//	data size       uint64
+//	data shards     uint16
+//	total shards    uint16
+//	shard index     uint16
+//	shard hashes    [total shards][32]byte
+//	shard           []byte
+//
+// As the erasure coding is deterministic, the shard hashes of honest members
+// match, which readers check by only reconstructing batches from shards with
+// the same shard hashes.
+var shardEnvelopePrefix = []byte("anytrust-shard-v1")
+
+const shardEnvelopeHeaderSize = 32 + 8 + 2 + 2 + 2
+
+type ShardEnvelope struct {
+	DataHash    common.Hash
+	DataSize    uint64
+	DataShards  uint16
+	TotalShards uint16
+	Index       uint16
+	// ShardHashes are the keccak256 hashes of all the shards of the batch.
+	ShardHashes []common.Hash
+	Shard       []byte
+}
+
+func IsShardEnvelope(data []byte) bool {
+	return bytes.HasPrefix(data, shardEnvelopePrefix)
+}
+
+// EncodeShardEnvelopes erasure codes data into totalShards shard envelopes,
+// any dataShards of which reconstruct it.
+func EncodeShardEnvelopes(data []byte, dataShards, totalShards int) ([]*ShardEnvelope, error) {
+	if dataShards <= 0 || dataShards > totalShards || totalShards > maxTotalShards {
+		return nil, fmt.Errorf("can't erasure code data into %d shards, %d of which are data shards", totalShards, dataShards)
+	}
+	shards, err := encodeShards(data, dataShards, totalShards)
+	if err != nil {
+		return nil, err
+	}
+	shardHashes := make([]common.Hash, len(shards))
+	for i, shard := range shards {
+		shardHashes[i] = crypto.Keccak256Hash(shard)
+	}
+	dataHash := tree.Hash(data)
+	envelopes := make([]*ShardEnvelope, len(shards))
+	for i, shard := range shards {
+		envelopes[i] = &ShardEnvelope{
+			DataHash: dataHash,
+			DataSize: uint64(len(data)),
+			// #nosec G115
+			DataShards: uint16(dataShards),
+			// #nosec G115
+			TotalShards: uint16(totalShards),
+			// #nosec G115
+			Index:       uint16(i),
+			ShardHashes: shardHashes,
+			Shard:       shard,
+		}
+	}
+	return envelopes, nil
+}
+
+func (e *ShardEnvelope) Serialize() []byte {
+	buf := make([]byte, 0, len(shardEnvelopePrefix)+shardEnvelopeHeaderSize+32*len(e.ShardHashes)+len(e.Shard))
+	buf = append(buf, shardEnvelopePrefix...)
+	buf = append(buf, e.DataHash.Bytes()...)
+	buf = binary.BigEndian.AppendUint64(buf, e.DataSize)
+	buf = binary.BigEndian.AppendUint16(buf, e.DataShards)
+	buf = binary.BigEndian.AppendUint16(buf, e.TotalShards)
+	buf = binary.BigEndian.AppendUint16(buf, e.Index)
+	for _, hash := range e.ShardHashes {
+		buf = append(buf, hash.Bytes()...)
+	}
+	return append(buf, e.Shard...)
+}
+
+// DecodeShardEnvelope parses a shard envelope, and checks that its shard
+// matches its hash.
+func DecodeShardEnvelope(data []byte) (*ShardEnvelope, error) {
+	if !IsShardEnvelope(data) {
+		return nil, errors.New("not a shard envelope")
+	}
+	data = data[len(shardEnvelopePrefix):]
+	if len(data) < shardEnvelopeHeaderSize {
+		return nil, errors.New("shard envelope too short")
+	}
+	e := &ShardEnvelope{
+		DataHash:    common.BytesToHash(data[:32]),
+		DataSize:    binary.BigEndian.Uint64(data[32:40]),
+		DataShards:  binary.BigEndian.Uint16(data[40:42]),
+		TotalShards: binary.BigEndian.Uint16(data[42:44]),
+		Index:       binary.BigEndian.Uint16(data[44:46]),
+	}
+	data = data[shardEnvelopeHeaderSize:]
+	if e.DataShards == 0 || e.DataShards > e.TotalShards || e.TotalShards > maxTotalShards || e.Index >= e.TotalShards {
+		return nil, fmt.Errorf("invalid shard %d of %d shards, %d of which are data shards", e.Index, e.TotalShards, e.DataShards)
+	}
+	if len(data) < 32*int(e.TotalShards) {
+		return nil, errors.New("shard envelope too short")
+	}
+	for i := 0; i < int(e.TotalShards); i++ {
+		e.ShardHashes = append(e.ShardHashes, common.BytesToHash(data[32*i:32*(i+1)]))
+	}
+	e.Shard = data[32*int(e.TotalShards):]
+	dataShards := uint64(e.DataShards)
+	if shardSize := e.DataSize/dataShards + min(e.DataSize%dataShards, 1); uint64(len(e.Shard)) != shardSize {
+		return nil, fmt.Errorf("shard has size %d, expected %d for data of size %d in %d data shards", len(e.Shard), shardSize, e.DataSize, e.DataShards)
+	}
+	if crypto.Keccak256Hash(e.Shard) != e.ShardHashes[e.Index] {
+		return nil, fmt.Errorf("shard %d doesn't match its hash", e.Index)
+	}
+	return e, nil
+}
+
+// commitment identifies the erasure coding the shard belongs to. Shards with
+// the same commitment can be reconstructed together.
+func (e *ShardEnvelope) commitment() common.Hash {
+	header := ShardEnvelope{
+		DataHash:    e.DataHash,
+		DataSize:    e.DataSize,
+		DataShards:  e.DataShards,
+		TotalShards: e.TotalShards,
+		ShardHashes: e.ShardHashes,
+	}
+	return crypto.Keccak256Hash(header.Serialize())
+}
+
+// StorageKey is the key under which data is stored: the data hash of the
+// batch for shard envelopes, and the hash of the data otherwise.
+func StorageKey(data []byte) common.Hash {
+	if IsShardEnvelope(data) {
+		if envelope, err := DecodeShardEnvelope(data); err == nil {
+			return envelope.DataHash
+		}
+	}
+	return tree.Hash(data)
+}
+
+// validStoredData returns whether data is the preimage of hash, or, if
+// acceptShards is set, a shard of it. A shard alone doesn't prove anything
+// about the batch, so it's only valid where it's then reconstructed.
+func validStoredData(hash common.Hash, data []byte, acceptShards bool) bool {
+	if tree.ValidHash(hash, data) {
+		return true
+	}
+	if !acceptShards || !IsShardEnvelope(data) {
+		return false
+	}
+	envelope, err := DecodeShardEnvelope(data)
+	return err == nil && envelope.DataHash == hash
+}
+
+// shardCollector reconstructs a batch from shard envelopes. A committee member
+// may return a shard of a different erasure coding, so shards are grouped by
+// commitment, and a group whose reconstruction doesn't match the data hash is
+// discarded.
+type shardCollector struct {
+	hash   common.Hash
+	groups map[common.Hash]*shardGroup
+}
+
+type shardGroup struct {
+	shards  [][]byte
+	count   int
+	invalid bool
+}
+
+func newShardCollector(hash common.Hash) *shardCollector {
+	return &shardCollector{
+		hash:   hash,
+		groups: make(map[common.Hash]*shardGroup),
+	}
+}
+
+// add adds a shard envelope, returning the batch once enough shards have been
+// added to reconstruct it, and nil before.
+func (c *shardCollector) add(data []byte) ([]byte, error) {
+	envelope, err := DecodeShardEnvelope(data)
+	if err != nil {
+		return nil, err
+	}
+	if envelope.DataHash != c.hash {
+		return nil, fmt.Errorf("got shard of batch %v, expected %v", envelope.DataHash, c.hash)
+	}
+	commitment := envelope.commitment()
+	group, ok := c.groups[commitment]
+	if !ok {
+		group = &shardGroup{shards: make([][]byte, envelope.TotalShards)}
+		c.groups[commitment] = group
+	}
+	if group.invalid {
+		return nil, fmt.Errorf("shard of batch %v belongs to an invalid erasure coding", c.hash)
+	}
+	if group.shards[envelope.Index] != nil {
+		return nil, nil
+	}
+	group.shards[envelope.Index] = envelope.Shard
+	group.count++
+	if group.count < int(envelope.DataShards) {
+		return nil, nil
+	}
+	batch, err := reconstructShards(group.shards, int(envelope.DataShards), envelope.DataSize)
+	if err == nil && tree.Hash(batch) != c.hash {
+		err = fmt.Errorf("batch reconstructed from shards doesn't match data hash %v", c.hash)
+	}
+	if err != nil {
+		group.invalid = true
+		return nil, err
+	}
+	return batch, nil
+}
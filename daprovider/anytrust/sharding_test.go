@@ -0,0 +1,250 @@
+// Copyright 2026, Offchain Labs, Inc.
+// For license information, see https://github.com/OffchainLabs/nitro/blob/master/LICENSE.md
+
+package anytrust
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"math/rand"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/offchainlabs/nitro/blsSignatures"
+	"github.com/offchainlabs/nitro/daprovider/anytrust/tree"
+	anytrustutil "github.com/offchainlabs/nitro/daprovider/anytrust/util"
+	"github.com/offchainlabs/nitro/util/testhelpers"
+)
+
+func TestReconstructShardsFromAnySubset(t *testing.T) {
+	for _, params := range [][2]int{{1, 1}, {1, 4}, {3, 5}, {4, 10}, {10, 10}} {
+		dataShards, totalShards := params[0], params[1]
+		for _, size := range []int{0, 1, dataShards, 1000, 4097} {
+			data := testhelpers.RandomSlice(uint64(size))
+			shards, err := encodeShards(data, dataShards, totalShards)
+			Require(t, err)
+			for trial := 0; trial < 20; trial++ {
+				available := make([][]byte, totalShards)
+				for _, index := range rand.Perm(totalShards)[:dataShards] {
+					available[index] = bytes.Clone(shards[index])
+				}
+				reconstructed, err := reconstructShards(available, dataShards, uint64(size))
+				Require(t, err, dataShards, "of", totalShards, "shards of", size, "bytes")
+				if !bytes.Equal(reconstructed, data) {
+					Fail(t, dataShards, "of", totalShards, "shards of", size, "bytes: reconstructed data differs")
+				}
+			}
+		}
+	}
+}
+
+func serializeShardEnvelopes(t *testing.T, data []byte, dataShards, totalShards int) [][]byte {
+	t.Helper()
+	envelopes, err := EncodeShardEnvelopes(data, dataShards, totalShards)
+	Require(t, err)
+	serialized := make([][]byte, len(envelopes))
+	for i, envelope := range envelopes {
+		serialized[i] = envelope.Serialize()
+	}
+	return serialized
+}
+
+func TestShardCollector(t *testing.T) {
+	data := testhelpers.RandomSlice(10000)
+	envelopes := serializeShardEnvelopes(t, data, 3, 5)
+	for _, envelope := range envelopes {
+		if StorageKey(envelope) != tree.Hash(data) {
+			Fail(t, "shard envelope isn't stored under the data hash")
+		}
+		if len(envelope) >= len(data) {
+			Fail(t, "shard envelope of size", len(envelope), "isn't smaller than the data of size", len(data))
+		}
+	}
+
+	collector := newShardCollector(tree.Hash(data))
+	for i, index := range rand.Perm(len(envelopes))[:3] {
+		reconstructed, err := collector.add(envelopes[index])
+		Require(t, err)
+		if i < 2 && reconstructed != nil {
+			Fail(t, "reconstructed the data from", i+1, "shards")
+		}
+		if i == 2 && !bytes.Equal(reconstructed, data) {
+			Fail(t, "reconstructed data differs")
+		}
+	}
+
+	corrupted := bytes.Clone(envelopes[0])
+	corrupted[len(corrupted)-1] ^= 1
+	if _, err := DecodeShardEnvelope(corrupted); err == nil {
+		Fail(t, "expected an error decoding a corrupted shard")
+	}
+	if StorageKey(corrupted) != tree.Hash(corrupted) {
+		Fail(t, "corrupted shard envelope isn't stored under its own hash")
+	}
+
+	// Shards of a different erasure coding of the same data hash can't be
+	// mixed in, and a coding not reconstructing the data is rejected
+	otherEnvelopes := serializeShardEnvelopes(t, testhelpers.RandomSlice(10000), 2, 5)
+	forge := func(envelope []byte) []byte {
+		decoded, err := DecodeShardEnvelope(envelope)
+		Require(t, err)
+		decoded.DataHash = tree.Hash(data)
+		return decoded.Serialize()
+	}
+	collector = newShardCollector(tree.Hash(data))
+	_, err := collector.add(envelopes[0])
+	Require(t, err)
+	reconstructed, err := collector.add(forge(otherEnvelopes[1]))
+	if reconstructed != nil || err != nil {
+		Fail(t, "unexpected result adding the first forged shard", reconstructed, err)
+	}
+	reconstructed, err = collector.add(forge(otherEnvelopes[2]))
+	if reconstructed != nil || err == nil {
+		Fail(t, "expected an error reconstructing from forged shards")
+	}
+	reconstructed, err = collector.add(envelopes[1])
+	Require(t, err)
+	if reconstructed != nil {
+		Fail(t, "reconstructed the data from 2 shards")
+	}
+	reconstructed, err = collector.add(envelopes[2])
+	Require(t, err)
+	if !bytes.Equal(reconstructed, data) {
+		Fail(t, "reconstructed data differs")
+	}
+}
+
+func TestAnyTrust_ShardedAggregation(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	numBackends := 5
+	var backends []ServiceDetails
+	var storageServices []StorageService
+	var servers []*RestfulServer
+	var urls []string
+	for i := 0; i < numBackends; i++ {
+		privKey, err := blsSignatures.GeneratePrivKeyString()
+		Require(t, err)
+
+		config := DefaultConfig
+		config.Enable = true
+		config.Key.PrivKey = privKey
+		config.AcceptShards = true
+
+		storageServices = append(storageServices, NewMemoryBackedStorageService(ctx))
+		writer, err := NewSignAfterStoreWriter(ctx, config, storageServices[i])
+		Require(t, err)
+		details, err := NewServiceDetails(writer, *writer.pubKey, uint64(1<<i), "service"+strconv.Itoa(i))
+		Require(t, err)
+		backends = append(backends, *details)
+
+		server, port, err := NewRestfulServerOnRandomPort(LocalServerAddressForTest, storageServices[i])
+		Require(t, err)
+		servers = append(servers, server)
+		urls = append(urls, "http://localhost:"+strconv.Itoa(port))
+	}
+	defer func() {
+		for _, server := range servers {
+			Require(t, server.Shutdown())
+		}
+	}()
+
+	daConfig := DefaultConfig
+	daConfig.RPCAggregator.AssumedHonest = 3
+	daConfig.RPCAggregator.Sharding = ShardingConfig{Enable: true, DataShards: 4}
+	if _, err := newAggregator(daConfig, backends); err == nil {
+		Fail(t, "expected an error for more data shards than assumed honest backends")
+	}
+	daConfig.RPCAggregator.Sharding.DataShards = 2
+	aggregator, err := newAggregator(daConfig, backends)
+	Require(t, err)
+	if aggregator.requiredServicesForStore != 4 || aggregator.maxAllowedServiceStoreFailures != 1 {
+		Fail(t, "unexpected store requirements", aggregator.requiredServicesForStore, aggregator.maxAllowedServiceStoreFailures)
+	}
+
+	rawMsg := testhelpers.RandomSlice(5000)
+	// #nosec G115
+	cert, err := aggregator.Store(ctx, rawMsg, uint64(time.Now().Add(time.Hour).Unix()))
+	Require(t, err)
+	if cert.DataHash != tree.Hash(rawMsg) {
+		Fail(t, "certificate isn't for the stored message")
+	}
+	// Each backend erasure coded the batch itself, into the same shards
+	var commitment common.Hash
+	for i, storageService := range storageServices {
+		stored, err := storageService.GetByHash(ctx, cert.DataHash)
+		Require(t, err)
+		envelope, err := DecodeShardEnvelope(stored)
+		Require(t, err)
+		if int(envelope.Index) != i {
+			Fail(t, "backend", i, "stored shard", envelope.Index)
+		}
+		if i == 0 {
+			commitment = envelope.commitment()
+		} else if envelope.commitment() != commitment {
+			Fail(t, "backend", i, "erasure coded the batch differently")
+		}
+	}
+
+	// Shards are only accepted by readers which reconstruct the batch
+	client, err := NewRestfulClientFromURL(urls[3])
+	Require(t, err)
+	if _, err := client.GetByHash(ctx, cert.DataHash); !errors.Is(err, anytrustutil.ErrHashMismatch) {
+		Fail(t, "expected a hash mismatch for a shard without read-shards, got", err)
+	}
+	readerConfig := DefaultRestfulClientAggregatorConfig
+	readerConfig.Urls = urls[3:]
+	readerConfig.Strategy = "testing-sequential"
+	readerConfig.WaitBeforeTryNext = time.Hour
+	reader, err := NewRestfulClientAggregator(ctx, &readerConfig)
+	Require(t, err)
+	if _, err := reader.GetByHash(ctx, cert.DataHash); err == nil {
+		Fail(t, "expected an error reading shards without read-shards")
+	}
+
+	// The batch is reconstructed from the shards of any two backends
+	readerConfig.ReadShards = true
+	reader, err = NewRestfulClientAggregator(ctx, &readerConfig)
+	Require(t, err)
+	retrieved, err := reader.GetByHash(ctx, cert.DataHash)
+	Require(t, err)
+	if !bytes.Equal(retrieved, rawMsg) {
+		Fail(t, "batch reconstructed from shards differs")
+	}
+
+	// Shards read through a cache aren't cached, only complete batches are
+	cache := NewCacheStorageService(TestCacheConfig, storageServices[0])
+	if _, err := cache.GetByHash(ctx, cert.DataHash); err != nil {
+		Fail(t, "couldn't read the shard through the cache", err)
+	}
+	if cache.cache.Contains(cert.DataHash) {
+		Fail(t, "shard envelope was cached")
+	}
+
+	// Backends refuse shard envelopes, which they can't check against the
+	// data hash they sign
+	writer := backends[0].service.(*SignAfterStoreWriter)
+	envelopes := serializeShardEnvelopes(t, rawMsg, 2, 5)
+	if _, err := writer.Store(ctx, envelopes[0], 0); err == nil {
+		Fail(t, "expected an error storing a shard envelope")
+	}
+	if _, err := writer.Store(ctx, EncodeShardRequest(rawMsg, 2, 5, 5), 0); err == nil {
+		Fail(t, "expected an error storing a shard out of range")
+	}
+
+	// Backends without accept-shards refuse shard requests
+	privKey, err := blsSignatures.GeneratePrivKeyString()
+	Require(t, err)
+	config := DefaultConfig
+	config.Key.PrivKey = privKey
+	writer, err = NewSignAfterStoreWriter(ctx, config, NewMemoryBackedStorageService(ctx))
+	Require(t, err)
+	if _, err := writer.Store(ctx, EncodeShardRequest(rawMsg, 2, 5, 0), 0); err == nil {
+		Fail(t, "expected an error storing a shard without accept-shards")
+	}
+}
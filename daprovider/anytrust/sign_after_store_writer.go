@@ -63,12 +63,16 @@ func KeyConfigAddOptions(prefix string, f *pflag.FlagSet) {
 //
 // 1) SignAfterStoreWriter.Store(...) assembles the returned hash into a
 // DataAvailabilityCertificate and signs it with its BLS private key.
+//
+// Shard requests are only accepted if acceptShards. The certificate is for the
+// batch of the request, and only the requested shard of it is stored.
 type SignAfterStoreWriter struct {
 	privKey        blsSignatures.PrivateKey
 	pubKey         *blsSignatures.PublicKey
 	keysetHash     [32]byte
 	keysetBytes    []byte
 	storageService StorageService
+	acceptShards   bool
 }
 
 func NewSignAfterStoreWriter(ctx context.Context, config Config, storageService StorageService) (*SignAfterStoreWriter, error) {
@@ -102,15 +106,37 @@ func NewSignAfterStoreWriter(ctx context.Context, config Config, storageService
 		keysetHash:     ksHash,
 		keysetBytes:    ksBuf.Bytes(),
 		storageService: storageService,
+		acceptShards:   config.AcceptShards,
 	}, nil
 }
 
 func (d *SignAfterStoreWriter) Store(ctx context.Context, message []byte, timeout uint64) (c *anytrustutil.DataAvailabilityCertificate, err error) {
 	// #nosec G115
 	log.Trace("anytrust.SignAfterStoreWriter.Store", "message", pretty.FirstFewBytes(message), "timeout", time.Unix(int64(timeout), 0), "this", d)
+	if IsShardEnvelope(message) {
+		// Its shard can't be checked against its data hash
+		return nil, errors.New("shard envelopes can't be stored, only shard requests")
+	}
+	dataHash := tree.Hash(message)
+	stored := message
+	if IsShardRequest(message) {
+		if !d.acceptShards {
+			return nil, errors.New("got a shard request, but accept-shards isn't enabled")
+		}
+		request, err := decodeShardRequest(message)
+		if err != nil {
+			return nil, err
+		}
+		envelopes, err := EncodeShardEnvelopes(request.data, request.dataShards, request.totalShards)
+		if err != nil {
+			return nil, err
+		}
+		dataHash = envelopes[request.index].DataHash
+		stored = envelopes[request.index].Serialize()
+	}
 	c = &anytrustutil.DataAvailabilityCertificate{
 		Timeout:     timeout,
-		DataHash:    tree.Hash(message),
+		DataHash:    dataHash,
 		Version:     1,
 		SignersMask: 1, // The aggregator will override this if we're part of a committee.
 	}
@@ -121,7 +147,7 @@ func (d *SignAfterStoreWriter) Store(ctx context.Context, message []byte, timeou
 		return nil, err
 	}
 
-	err = d.storageService.Put(ctx, message, timeout)
+	err = d.storageService.Put(ctx, stored, timeout)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,95 @@
+// Copyright 2026, Offchain Labs, Inc.
+// For license information, see https://github.com/OffchainLabs/nitro/blob/master/LICENSE.md
+
+package anytrust
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+type StorageMigrationOpts struct {
+	// StartAfter resumes a migration after the batch with this hash.
+	StartAfter common.Hash
+	// DefaultExpiry is the expiration time of batches whose source doesn't
+	// record one.
+	DefaultExpiry uint64
+	// SkipExpired skips batches whose expiration time has passed.
+	SkipExpired bool
+	// OnBatch is called with the hash of each batch once it has been copied
+	// or skipped, so that the migration can be resumed after it.
+	OnBatch func(key common.Hash) error
+}
+
+type StorageMigrationStats struct {
+	Copied  uint64
+	Skipped uint64
+	Last    common.Hash
+}
+
+// MigrateStorage copies every batch of from to to, in ascending hash order.
+// The hash of each batch is checked when it is read from from, and the batch
+// is read back from to after it has been stored.
+func MigrateStorage(ctx context.Context, from IterableStorageService, to StorageService, opts StorageMigrationOpts) (StorageMigrationStats, error) {
+	stats := StorageMigrationStats{Last: opts.StartAfter}
+	start := time.Now()
+	lastLog := start
+	err := from.IterateBatches(ctx, opts.StartAfter, func(key common.Hash, expiry uint64) error {
+		if expiry == 0 {
+			expiry = opts.DefaultExpiry
+		}
+		// #nosec G115
+		if opts.SkipExpired && expiry < uint64(time.Now().Unix()) {
+			stats.Skipped++
+		} else {
+			if err := migrateBatch(ctx, from, to, key, expiry); err != nil {
+				return err
+			}
+			stats.Copied++
+		}
+		stats.Last = key
+		if opts.OnBatch != nil {
+			if err := opts.OnBatch(key); err != nil {
+				return err
+			}
+		}
+		if time.Since(lastLog) > time.Minute {
+			log.Info("Migrating AnyTrust storage", "from", from, "to", to, "copied", stats.Copied, "skipped", stats.Skipped, "last", key)
+			lastLog = time.Now()
+		}
+		return nil
+	})
+	if err != nil {
+		return stats, err
+	}
+	log.Info("AnyTrust storage migration complete", "from", from, "to", to, "copied", stats.Copied, "skipped", stats.Skipped, "duration", time.Since(start))
+	return stats, nil
+}
+
+func migrateBatch(ctx context.Context, from, to StorageService, key common.Hash, expiry uint64) error {
+	data, err := from.GetByHash(ctx, key)
+	if err != nil {
+		return fmt.Errorf("reading batch %v from %v: %w", key, from, err)
+	}
+	// Storage services key batches by StorageKey, so only those can be read
+	// back from to
+	if StorageKey(data) != key {
+		return fmt.Errorf("batch %v read from %v doesn't match its hash", key, from)
+	}
+	if err := to.Put(ctx, data, expiry); err != nil {
+		return fmt.Errorf("storing batch %v in %v: %w", key, to, err)
+	}
+	stored, err := to.GetByHash(ctx, key)
+	if err != nil {
+		return fmt.Errorf("reading back batch %v from %v: %w", key, to, err)
+	}
+	if !bytes.Equal(stored, data) {
+		return fmt.Errorf("batch %v read back from %v differs from the batch stored", key, to)
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+// Copyright 2026, Offchain Labs, Inc.
+// For license information, see https://github.com/OffchainLabs/nitro/blob/master/LICENSE.md
+
+package anytrust
+
+import (
+	"context"
+	"os"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/offchainlabs/nitro/daprovider/anytrust/tree"
+)
+
+func TestMigrateStorage(t *testing.T) {
+	ctx := context.Background()
+	from, err := NewLocalFileStorageService(LocalFileStorageConfig{
+		Enable:       true,
+		DataDir:      t.TempDir(),
+		MaxRetention: time.Hour,
+	})
+	Require(t, err)
+
+	// #nosec G115
+	now := uint64(time.Now().Unix())
+	Require(t, from.Put(ctx, []byte("a"), now+10))
+	Require(t, from.Put(ctx, []byte("a"), now+100))
+	Require(t, from.Put(ctx, []byte("b"), now+20))
+	Require(t, from.Put(ctx, []byte("expired"), now-10))
+
+	wantExpiries := map[common.Hash]uint64{
+		tree.Hash([]byte("a")):       now + 100,
+		tree.Hash([]byte("b")):       now + 20,
+		tree.Hash([]byte("expired")): now - 10,
+	}
+	var keys []common.Hash
+	Require(t, from.IterateBatches(ctx, common.Hash{}, func(key common.Hash, expiry uint64) error {
+		if expiry != wantExpiries[key] {
+			Fail(t, "unexpected expiry", key, expiry, "want", wantExpiries[key])
+		}
+		keys = append(keys, key)
+		return nil
+	}))
+	if len(keys) != len(wantExpiries) || !slices.IsSortedFunc(keys, func(a, b common.Hash) int { return a.Cmp(b) }) {
+		Fail(t, "unexpected batches", keys)
+	}
+
+	to := NewMemoryBackedStorageService(ctx)
+	var migrated []common.Hash
+	stats, err := MigrateStorage(ctx, from, to, StorageMigrationOpts{
+		SkipExpired: true,
+		OnBatch: func(key common.Hash) error {
+			migrated = append(migrated, key)
+			return nil
+		},
+	})
+	Require(t, err)
+	if stats.Copied != 2 || stats.Skipped != 1 || stats.Last != keys[len(keys)-1] || !slices.Equal(migrated, keys) {
+		Fail(t, "unexpected migration", stats, migrated)
+	}
+	for _, data := range []string{"a", "b"} {
+		stored, err := to.GetByHash(ctx, tree.Hash([]byte(data)))
+		Require(t, err)
+		if string(stored) != data {
+			Fail(t, "unexpected migrated batch", stored, "want", data)
+		}
+	}
+
+	// Resuming only migrates the batches after the given one
+	resumed := NewMemoryBackedStorageService(ctx)
+	stats, err = MigrateStorage(ctx, from, resumed, StorageMigrationOpts{StartAfter: keys[0]})
+	Require(t, err)
+	// #nosec G115
+	if stats.Copied != uint64(len(keys)-1) {
+		Fail(t, "unexpected number of resumed batches", stats.Copied)
+	}
+	if _, err := resumed.GetByHash(ctx, keys[0]); err == nil {
+		Fail(t, "batch before the resumption point was migrated")
+	}
+
+	// A batch not matching its hash stops the migration
+	Require(t, os.WriteFile(from.layout.batchPath(keys[0]), []byte("corrupted"), 0o600))
+	_, err = MigrateStorage(ctx, from, NewMemoryBackedStorageService(ctx), StorageMigrationOpts{})
+	if err == nil {
+		Fail(t, "expected an error migrating a corrupted batch")
+	}
+}
@@ -27,6 +27,17 @@ type StorageService interface {
 	HealthCheck(ctx context.Context) error
 }
 
+// IterableStorageService is a StorageService which can list the batches it
+// stores, for example to migrate them to another StorageService.
+type IterableStorageService interface {
+	StorageService
+	// IterateBatches calls fn with the hash and expiration time of each stored
+	// batch with a hash greater than startAfter, in ascending hash order. The
+	// expiration time is 0 if the service doesn't record it. Iteration stops
+	// at the first error returned by fn.
+	IterateBatches(ctx context.Context, startAfter common.Hash, fn func(key common.Hash, expirationTime uint64) error) error
+}
+
 const defaultStorageRetention = time.Hour * 24 * 21 // 6 days longer than the batch poster default
 
 func EncodeStorageServiceKey(key common.Hash) string {
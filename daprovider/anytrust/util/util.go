@@ -10,6 +10,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"math/big"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/crypto"
@@ -140,6 +141,12 @@ func (d *writer) Store(message []byte, timeout uint64) containers.PromiseInterfa
 	})
 }
 
+// EstimateStoreCost quotes no cost, as the committee members don't charge on
+// the parent chain for storing messages.
+func (d *writer) EstimateStoreCost(size int) containers.PromiseInterface[*big.Int] {
+	return containers.NewReadyPromise(new(big.Int), nil)
+}
+
 func (d *writer) GetMaxMessageSize() containers.PromiseInterface[int] {
 	return containers.NewReadyPromise(d.maxMessageSize, nil)
 }
@@ -5,13 +5,16 @@ package daclient
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"math/big"
 	"strings"
 
 	"github.com/spf13/pflag"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
 
 	"github.com/offchainlabs/nitro/daprovider"
 	"github.com/offchainlabs/nitro/daprovider/data_streaming"
@@ -126,6 +129,27 @@ func (c *Client) GetMaxMessageSize() containers.PromiseInterface[int] {
 	})
 }
 
+// methodNotFoundErrorCode is the JSON-RPC error code of calls to unknown methods.
+const methodNotFoundErrorCode = -32601
+
+// EstimateStoreCost asks the DA provider for the cost of storing a message of
+// the given size. The cost is nil if the DA provider can't quote it.
+func (c *Client) EstimateStoreCost(size int) containers.PromiseInterface[*big.Int] {
+	return containers.DoPromise(context.Background(), func(ctx context.Context) (*big.Int, error) {
+		var result server_api.StoreCostResult
+		// #nosec G115
+		if err := c.CallContext(ctx, &result, "daprovider_estimateStoreCost", hexutil.Uint64(size)); err != nil {
+			// DA providers predating the method can't quote the cost
+			var rpcErr rpc.Error
+			if errors.As(err, &rpcErr) && rpcErr.ErrorCode() == methodNotFoundErrorCode {
+				return nil, nil
+			}
+			return nil, fmt.Errorf("error returned from daprovider_estimateStoreCost rpc method: %w", err)
+		}
+		return result.Cost.ToInt(), nil
+	})
+}
+
 // RecoverPayload fetches the underlying payload from the DA provider
 func (c *Client) RecoverPayload(
 	batchNum uint64,
@@ -4,7 +4,13 @@
 package referenceda
 
 import (
+	"errors"
+	"fmt"
+	"time"
+
 	flag "github.com/spf13/pflag"
+
+	"github.com/offchainlabs/nitro/daprovider/anytrust"
 )
 
 type Config struct {
@@ -12,6 +18,7 @@ type Config struct {
 	SigningKey        SigningKeyConfig `koanf:"signing-key"`
 	ValidatorContract string           `koanf:"validator-contract"`
 	MaxBatchSize      int              `koanf:"max-batch-size"`
+	Storage           StorageConfig    `koanf:"storage"`
 }
 
 type SigningKeyConfig struct {
@@ -24,11 +31,59 @@ var DefaultSigningKeyConfig = SigningKeyConfig{
 	KeyFile:    "",
 }
 
+const (
+	StorageBackendMemory   = "memory"
+	StorageBackendDB       = "db"
+	StorageBackendAnyTrust = "anytrust"
+)
+
+type StorageConfig struct {
+	Backend  string `koanf:"backend"`
+	DataDir  string `koanf:"data-dir"`
+	DBEngine string `koanf:"db-engine"`
+	// RequestTimeout bounds the requests to the AnyTrust storage services.
+	RequestTimeout     time.Duration                            `koanf:"request-timeout"`
+	LocalFileStorage   anytrust.LocalFileStorageConfig          `koanf:"local-file-storage"`
+	S3Storage          anytrust.S3StorageServiceConfig          `koanf:"s3-storage"`
+	GoogleCloudStorage anytrust.GoogleCloudStorageServiceConfig `koanf:"google-cloud-storage"`
+}
+
+var DefaultStorageConfig = StorageConfig{
+	Backend:            StorageBackendMemory,
+	DataDir:            "",
+	DBEngine:           "",
+	RequestTimeout:     5 * time.Second,
+	LocalFileStorage:   anytrust.DefaultLocalFileStorageConfig,
+	S3Storage:          anytrust.DefaultS3StorageServiceConfig,
+	GoogleCloudStorage: anytrust.DefaultGoogleCloudStorageServiceConfig,
+}
+
 var DefaultConfig = Config{
 	Enable:            false,
 	SigningKey:        DefaultSigningKeyConfig,
 	ValidatorContract: "",
 	MaxBatchSize:      1_000_000, // 1MB default
+	Storage:           DefaultStorageConfig,
+}
+
+func (c *StorageConfig) Validate() error {
+	switch c.Backend {
+	case StorageBackendMemory:
+		return nil
+	case StorageBackendDB, StorageBackendAnyTrust:
+	default:
+		return fmt.Errorf("invalid referenceda storage backend \"%v\", must be \"%v\", \"%v\" or \"%v\"", c.Backend, StorageBackendMemory, StorageBackendDB, StorageBackendAnyTrust)
+	}
+	if c.DataDir == "" {
+		return fmt.Errorf("referenceda storage backend %v requires a data directory", c.Backend)
+	}
+	if c.Backend == StorageBackendAnyTrust {
+		// Certificates stay valid forever, so their preimages must never expire
+		if c.LocalFileStorage.EnableExpiry || c.GoogleCloudStorage.DiscardAfterTimeout {
+			return errors.New("referenceda anytrust storage must not expire data")
+		}
+	}
+	return nil
 }
 
 func SigningKeyConfigAddOptions(prefix string, f *flag.FlagSet) {
@@ -41,4 +96,15 @@ func ConfigAddOptions(prefix string, f *flag.FlagSet) {
 	SigningKeyConfigAddOptions(prefix+".signing-key", f)
 	f.String(prefix+".validator-contract", DefaultConfig.ValidatorContract, "address of the ReferenceDAProofValidator contract")
 	f.Int(prefix+".max-batch-size", DefaultConfig.MaxBatchSize, "maximum batch size for reference DA")
+	StorageConfigAddOptions(prefix+".storage", f)
+}
+
+func StorageConfigAddOptions(prefix string, f *flag.FlagSet) {
+	f.String(prefix+".backend", DefaultStorageConfig.Backend, "where to store the certificates' preimages (\"memory\", \"db\" for a local database, or \"anytrust\" for the anytrust storage services indexed by a local database)")
+	f.String(prefix+".data-dir", DefaultStorageConfig.DataDir, "directory of the local database of the db and anytrust backends")
+	f.String(prefix+".db-engine", DefaultStorageConfig.DBEngine, "backing database implementation ('leveldb', 'pebble' or '' = auto-detect)")
+	f.Duration(prefix+".request-timeout", DefaultStorageConfig.RequestTimeout, "timeout of requests to the anytrust storage services")
+	anytrust.LocalFileStorageConfigAddOptions(prefix+".local-file-storage", f)
+	anytrust.S3ConfigAddOptions(prefix+".s3-storage", f)
+	anytrust.GoogleCloudConfigAddOptions(prefix+".google-cloud-storage", f)
 }
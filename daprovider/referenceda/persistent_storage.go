@@ -0,0 +1,142 @@
+// Copyright 2026, Offchain Labs, Inc.
+// For license information, see https://github.com/OffchainLabs/nitro/blob/master/LICENSE.md
+
+package referenceda
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/node"
+
+	"github.com/offchainlabs/nitro/daprovider/anytrust"
+	"github.com/offchainlabs/nitro/daprovider/anytrust/tree"
+)
+
+var (
+	preimagePrefix  = []byte("p") // preimagePrefix + SHA256 hash -> preimage
+	treeIndexPrefix = []byte("t") // treeIndexPrefix + SHA256 hash -> AnyTrust tree hash
+)
+
+func preimageKey(prefix []byte, hash common.Hash) []byte {
+	return append(append([]byte{}, prefix...), hash.Bytes()...)
+}
+
+// DBStorage implements PreimageStorage on a key-value store, so that the
+// preimages outlive the process.
+type DBStorage struct {
+	db ethdb.KeyValueStore
+}
+
+func NewDBStorage(db ethdb.KeyValueStore) *DBStorage {
+	return &DBStorage{db: db}
+}
+
+func (s *DBStorage) Store(data []byte) error {
+	hash := sha256.Sum256(data)
+	return s.db.Put(preimageKey(preimagePrefix, common.Hash(hash)), data)
+}
+
+func (s *DBStorage) GetByHash(hash common.Hash) ([]byte, error) {
+	key := preimageKey(preimagePrefix, hash)
+	has, err := s.db.Has(key)
+	if err != nil || !has {
+		return nil, err
+	}
+	return s.db.Get(key)
+}
+
+// AnyTrustStorage implements PreimageStorage on AnyTrust storage services.
+// These key the preimages by their AnyTrust tree hash, so a key-value store
+// indexes the tree hashes by the SHA256 hashes of the certificates.
+type AnyTrustStorage struct {
+	storage   anytrust.StorageService
+	index     ethdb.KeyValueStore
+	timeout   time.Duration
+	retention time.Duration
+}
+
+// NewAnyTrustStorage creates an AnyTrustStorage requesting the preimages to be
+// kept for retention, which must be within the storage's max retention.
+func NewAnyTrustStorage(storage anytrust.StorageService, index ethdb.KeyValueStore, timeout time.Duration, retention time.Duration) *AnyTrustStorage {
+	return &AnyTrustStorage{
+		storage:   storage,
+		index:     index,
+		timeout:   timeout,
+		retention: retention,
+	}
+}
+
+func (s *AnyTrustStorage) Store(data []byte) error {
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+	// ReferenceDA preimages are never expired, see StorageConfig.Validate, but
+	// the storage services reject expiries past their max retention
+	// #nosec G115
+	expiry := uint64(time.Now().Add(s.retention).Unix())
+	if err := s.storage.Put(ctx, data, expiry); err != nil {
+		return err
+	}
+	hash := sha256.Sum256(data)
+	return s.index.Put(preimageKey(treeIndexPrefix, common.Hash(hash)), tree.HashBytes(data))
+}
+
+func (s *AnyTrustStorage) GetByHash(hash common.Hash) ([]byte, error) {
+	key := preimageKey(treeIndexPrefix, hash)
+	has, err := s.index.Has(key)
+	if err != nil || !has {
+		return nil, err
+	}
+	treeHash, err := s.index.Get(key)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+	data, err := s.storage.GetByHash(ctx, common.BytesToHash(treeHash))
+	if errors.Is(err, anytrust.ErrNotFound) {
+		return nil, nil
+	}
+	return data, err
+}
+
+// NewPreimageStorage creates the storage selected by the config, and a
+// function closing it.
+func NewPreimageStorage(ctx context.Context, config *StorageConfig) (PreimageStorage, func(), error) {
+	if config.Backend == StorageBackendMemory {
+		return GetInMemoryStorage(), func() {}, nil
+	}
+	db, err := node.OpenDatabase(node.InternalOpenOptions{
+		DbEngine:  config.DBEngine,
+		Directory: config.DataDir,
+		DatabaseOptions: node.DatabaseOptions{
+			MetricsNamespace: "referenceda/",
+			NoFreezer:        true,
+		},
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("opening referenceda database %s: %w", config.DataDir, err)
+	}
+	if config.Backend == StorageBackendDB {
+		return NewDBStorage(db), func() { _ = db.Close() }, nil
+	}
+	storageConfig := anytrust.DefaultConfig
+	storageConfig.LocalFileStorage = config.LocalFileStorage
+	storageConfig.S3Storage = config.S3Storage
+	storageConfig.GoogleCloudStorage = config.GoogleCloudStorage
+	storageService, lifecycleManager, err := anytrust.CreatePersistentStorageService(ctx, &storageConfig)
+	if err != nil {
+		_ = db.Close()
+		return nil, nil, err
+	}
+	cleanup := func() {
+		lifecycleManager.StopAndWaitUntil(time.Second)
+		_ = db.Close()
+	}
+	return NewAnyTrustStorage(storageService, db, config.RequestTimeout, config.LocalFileStorage.MaxRetention), cleanup, nil
+}
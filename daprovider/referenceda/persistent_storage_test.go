@@ -0,0 +1,86 @@
+// Copyright 2026, Offchain Labs, Inc.
+// For license information, see https://github.com/OffchainLabs/nitro/blob/master/LICENSE.md
+
+package referenceda
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+
+	"github.com/offchainlabs/nitro/daprovider/anytrust"
+)
+
+func testPreimageStorage(t *testing.T, storage PreimageStorage) {
+	t.Helper()
+	data := []byte("referenceda batch data")
+	hash := common.Hash(sha256.Sum256(data))
+	got, err := storage.GetByHash(hash)
+	if err != nil || got != nil {
+		t.Fatalf("got %x and err %v before storing, want nothing", got, err)
+	}
+	if err := storage.Store(data); err != nil {
+		t.Fatal(err)
+	}
+	got, err = storage.GetByHash(hash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, data) {
+		t.Fatalf("got %x, want %x", got, data)
+	}
+}
+
+func TestDBStorage(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+	testPreimageStorage(t, NewDBStorage(db))
+	// A new storage on the same database finds the preimages
+	got, err := NewDBStorage(db).GetByHash(sha256.Sum256([]byte("referenceda batch data")))
+	if err != nil || got == nil {
+		t.Fatalf("got %x and err %v from reopened storage", got, err)
+	}
+}
+
+func TestAnyTrustStorage(t *testing.T) {
+	storageService := anytrust.NewMemoryBackedStorageService(context.Background())
+	testPreimageStorage(t, NewAnyTrustStorage(storageService, rawdb.NewMemoryDatabase(), time.Second, anytrust.DefaultLocalFileStorageConfig.MaxRetention))
+}
+
+func TestAnyTrustLocalFileStorage(t *testing.T) {
+	config := anytrust.DefaultLocalFileStorageConfig
+	config.DataDir = t.TempDir()
+	storageService, err := anytrust.NewLocalFileStorageService(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testPreimageStorage(t, NewAnyTrustStorage(storageService, rawdb.NewMemoryDatabase(), time.Second, config.MaxRetention))
+}
+
+func TestStorageConfigValidate(t *testing.T) {
+	config := DefaultStorageConfig
+	if err := config.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	config.Backend = StorageBackendDB
+	if err := config.Validate(); err == nil {
+		t.Fatal("expected an error for the db backend without a data directory")
+	}
+	config.DataDir = t.TempDir()
+	if err := config.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	config.Backend = StorageBackendAnyTrust
+	config.LocalFileStorage.EnableExpiry = true
+	if err := config.Validate(); err == nil {
+		t.Fatal("expected an error for anytrust storage with expiry")
+	}
+	config.Backend = "files"
+	if err := config.Validate(); err == nil {
+		t.Fatal("expected an error for an unknown backend")
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"sync"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/crypto"
@@ -24,6 +25,11 @@ type Factory struct {
 	enableWriter bool
 	dataSigner   signature.DataSignerFunc
 	l1Client     *ethclient.Client
+
+	storageMutex sync.Mutex
+	storage      PreimageStorage
+	storageUsers int
+	closeStorage func()
 }
 
 // NewFactory creates a new ReferenceDA provider factory.
@@ -38,6 +44,10 @@ func NewFactory(
 		enableWriter: enableWriter,
 		dataSigner:   dataSigner,
 		l1Client:     l1Client,
+		storageMutex: sync.Mutex{},
+		storage:      nil,
+		storageUsers: 0,
+		closeStorage: nil,
 	}
 }
 
@@ -45,7 +55,38 @@ func (f *Factory) ValidateConfig() error {
 	if !f.config.Enable {
 		return errors.New("referenceda must be enabled")
 	}
-	return nil
+	return f.config.Storage.Validate()
+}
+
+// getStorage returns the storage shared by the reader, writer and validator,
+// and a function releasing it. The storage is closed once all its users have
+// released it.
+func (f *Factory) getStorage(ctx context.Context) (PreimageStorage, func(), error) {
+	f.storageMutex.Lock()
+	defer f.storageMutex.Unlock()
+	if f.storage == nil {
+		storage, closeStorage, err := NewPreimageStorage(ctx, &f.config.Storage)
+		if err != nil {
+			return nil, nil, err
+		}
+		f.storage = storage
+		f.closeStorage = closeStorage
+	}
+	f.storageUsers++
+	var once sync.Once
+	release := func() {
+		once.Do(func() {
+			f.storageMutex.Lock()
+			defer f.storageMutex.Unlock()
+			f.storageUsers--
+			if f.storageUsers == 0 {
+				f.closeStorage()
+				f.storage = nil
+				f.closeStorage = nil
+			}
+		})
+	}
+	return f.storage, release, nil
 }
 
 func (f *Factory) CreateReader(ctx context.Context) (daprovider.Reader, func(), error) {
@@ -53,9 +94,12 @@ func (f *Factory) CreateReader(ctx context.Context) (daprovider.Reader, func(),
 		return nil, nil, errors.New("validator-contract address not configured for reference DA reader")
 	}
 	validatorAddr := common.HexToAddress(f.config.ValidatorContract)
-	storage := GetInMemoryStorage()
+	storage, cleanup, err := f.getStorage(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
 	reader := NewReader(storage, f.l1Client, validatorAddr)
-	return reader, nil, nil
+	return reader, cleanup, nil
 }
 
 func (f *Factory) CreateWriter(ctx context.Context) (daprovider.Writer, func(), error) {
@@ -87,8 +131,12 @@ func (f *Factory) CreateWriter(ctx context.Context) (daprovider.Writer, func(),
 		f.dataSigner = signer
 	}
 
-	writer := NewWriter(f.dataSigner, f.config.MaxBatchSize)
-	return writer, nil, nil
+	storage, cleanup, err := f.getStorage(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	writer := NewWriter(storage, f.dataSigner, f.config.MaxBatchSize)
+	return writer, cleanup, nil
 }
 
 func (f *Factory) CreateValidator(ctx context.Context) (daprovider.Validator, func(), error) {
@@ -96,5 +144,9 @@ func (f *Factory) CreateValidator(ctx context.Context) (daprovider.Validator, fu
 		return nil, nil, errors.New("validator-contract address not configured for reference DA validator")
 	}
 	validatorAddr := common.HexToAddress(f.config.ValidatorContract)
-	return NewValidator(f.l1Client, validatorAddr), nil, nil
+	storage, cleanup, err := f.getStorage(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	return NewValidator(storage, f.l1Client, validatorAddr), cleanup, nil
 }
@@ -0,0 +1,69 @@
+// Copyright 2026, Offchain Labs, Inc.
+// For license information, see https://github.com/OffchainLabs/nitro/blob/master/LICENSE.md
+
+package referenceda
+
+import (
+	"context"
+	"crypto/sha256"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/offchainlabs/nitro/util/signature"
+)
+
+func TestFactorySharesStorage(t *testing.T) {
+	ctx := context.Background()
+	privKey, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := DefaultConfig
+	config.Enable = true
+	config.ValidatorContract = common.Address{1}.Hex()
+	config.Storage.Backend = StorageBackendDB
+	config.Storage.DataDir = t.TempDir()
+	factory := NewFactory(&config, signature.DataSignerFromPrivateKey(privKey), nil, true)
+	if err := factory.ValidateConfig(); err != nil {
+		t.Fatal(err)
+	}
+
+	_, releaseReader, err := factory.CreateReader(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	readerStorage := factory.storage
+	_, releaseWriter, err := factory.CreateWriter(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if factory.storage != readerStorage || factory.storageUsers != 2 {
+		t.Fatalf("reader and writer don't share the storage, %d users", factory.storageUsers)
+	}
+
+	// Releasing the reader, even twice, leaves the storage open for the writer
+	releaseReader()
+	releaseReader()
+	if factory.storage == nil || factory.storageUsers != 1 {
+		t.Fatalf("storage closed while still used, %d users", factory.storageUsers)
+	}
+	testPreimageStorage(t, factory.storage)
+
+	releaseWriter()
+	if factory.storage != nil || factory.storageUsers != 0 {
+		t.Fatalf("storage not closed once released by all users, %d users", factory.storageUsers)
+	}
+
+	// The database can only be opened again if it was closed
+	_, releaseValidator, err := factory.CreateValidator(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer releaseValidator()
+	got, err := factory.storage.GetByHash(sha256.Sum256([]byte("referenceda batch data")))
+	if err != nil || got == nil {
+		t.Fatalf("got %x and err %v from reopened storage", got, err)
+	}
+}
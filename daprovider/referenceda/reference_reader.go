@@ -22,13 +22,13 @@ import (
 
 // Reader implements the daprovider.Reader interface for ReferenceDA
 type Reader struct {
-	storage       *InMemoryStorage
+	storage       PreimageStorage
 	l1Client      *ethclient.Client
 	validatorAddr common.Address
 }
 
 // NewReader creates a new ReferenceDA reader
-func NewReader(storage *InMemoryStorage, l1Client *ethclient.Client, validatorAddr common.Address) *Reader {
+func NewReader(storage PreimageStorage, l1Client *ethclient.Client, validatorAddr common.Address) *Reader {
 	return &Reader{
 		storage:       storage,
 		l1Client:      l1Client,
@@ -18,14 +18,14 @@ import (
 )
 
 type Validator struct {
-	storage       *InMemoryStorage
+	storage       PreimageStorage
 	l1Client      *ethclient.Client
 	validatorAddr common.Address
 }
 
-func NewValidator(l1Client *ethclient.Client, validatorAddr common.Address) *Validator {
+func NewValidator(storage PreimageStorage, l1Client *ethclient.Client, validatorAddr common.Address) *Validator {
 	return &Validator{
-		storage:       GetInMemoryStorage(),
+		storage:       storage,
 		l1Client:      l1Client,
 		validatorAddr: validatorAddr,
 	}
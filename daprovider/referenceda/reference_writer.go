@@ -5,6 +5,7 @@ package referenceda
 
 import (
 	"fmt"
+	"math/big"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/log"
@@ -15,15 +16,15 @@ import (
 
 // Writer implements the daprovider.Writer interface for ReferenceDA
 type Writer struct {
-	storage        *InMemoryStorage
+	storage        PreimageStorage
 	signer         signature.DataSignerFunc
 	maxMessageSize int
 }
 
 // NewWriter creates a new ReferenceDA writer
-func NewWriter(signer signature.DataSignerFunc, maxMessageSize int) *Writer {
+func NewWriter(storage PreimageStorage, signer signature.DataSignerFunc, maxMessageSize int) *Writer {
 	return &Writer{
-		storage:        GetInMemoryStorage(),
+		storage:        storage,
 		signer:         signer,
 		maxMessageSize: maxMessageSize,
 	}
@@ -37,6 +38,12 @@ func (w *Writer) Store(
 	return containers.NewReadyPromise(certificate, err)
 }
 
+// EstimateStoreCost quotes no cost, as ReferenceDA stores messages itself
+// without charging on the parent chain.
+func (w *Writer) EstimateStoreCost(size int) containers.PromiseInterface[*big.Int] {
+	return containers.NewReadyPromise(new(big.Int), nil)
+}
+
 func (w *Writer) GetMaxMessageSize() containers.PromiseInterface[int] {
 	return containers.NewReadyPromise(w.maxMessageSize, nil)
 }
@@ -10,6 +10,14 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 )
 
+// PreimageStorage stores the preimages of ReferenceDA certificates by their
+// SHA256 hash.
+type PreimageStorage interface {
+	Store(data []byte) error
+	// GetByHash returns nil if no preimage is stored for the hash.
+	GetByHash(hash common.Hash) ([]byte, error)
+}
+
 // InMemoryStorage implements PreimageStorage interface for in-memory storage
 type InMemoryStorage struct {
 	mu        sync.RWMutex
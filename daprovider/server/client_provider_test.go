@@ -71,8 +71,8 @@ func setupProviderServer(ctx context.Context, t *testing.T) *http.Server {
 	dummyAddress := common.HexToAddress("0x0")
 	storage := referenceda.GetInMemoryStorage()
 	reader := referenceda.NewReader(storage, nil, dummyAddress)
-	writer := referenceda.NewWriter(dataSigner, referenceda.DefaultConfig.MaxBatchSize)
-	validator := referenceda.NewValidator(nil, dummyAddress)
+	writer := referenceda.NewWriter(storage, dataSigner, referenceda.DefaultConfig.MaxBatchSize)
+	validator := referenceda.NewValidator(storage, nil, dummyAddress)
 	headerBytes := []byte{daprovider.DACertificateMessageHeaderFlag}
 
 	providerServer, err := NewServerWithDAPProvider(ctx, &providerServerConfig, reader, writer, validator, headerBytes, data_streaming.PayloadCommitmentVerifier())
@@ -277,6 +277,19 @@ func (s *WriterServer) Store(ctx context.Context, message hexutil.Bytes, timeout
 	return &server_api.StoreResult{SerializedDACert: serializedDACert}, err
 }
 
+func (s *WriterServer) EstimateStoreCost(ctx context.Context, size hexutil.Uint64) (*server_api.StoreCostResult, error) {
+	estimator, ok := s.writer.(daprovider.StoreCostEstimator)
+	if !ok {
+		return &server_api.StoreCostResult{}, nil
+	}
+	// #nosec G115
+	cost, err := estimator.EstimateStoreCost(int(size)).Await(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &server_api.StoreCostResult{Cost: (*hexutil.Big)(cost)}, nil
+}
+
 func (s *WriterServer) GetMaxMessageSize(ctx context.Context) (*server_api.MaxMessageSizeResult, error) {
 	maxSize, err := s.writer.GetMaxMessageSize().Await(ctx)
 	if err != nil {
@@ -17,6 +17,12 @@ type MaxMessageSizeResult struct {
 	MaxSize int `json:"maxSize"`
 }
 
+// StoreCostResult is the result struct for daprovider_estimateStoreCost. Cost
+// is nil if the DA provider can't quote the cost of storing the message.
+type StoreCostResult struct {
+	Cost *hexutil.Big `json:"cost,omitempty"`
+}
+
 // StoreResult is the result struct that data availability providers should use to respond with a commitment to a Store request for posting batch data to their DA service
 type StoreResult struct {
 	SerializedDACert hexutil.Bytes `json:"serialized-da-cert,omitempty"`
@@ -10,6 +10,7 @@ import (
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+	"github.com/ethereum/go-ethereum/params"
 
 	"github.com/offchainlabs/nitro/arbutil"
 )
@@ -77,6 +78,15 @@ const BlobHashesHeaderFlag byte = L1AuthenticatedMessageHeaderFlag | 0x10 // 0x5
 // BrotliMessageHeaderByte indicates that the message is brotli-compressed.
 const BrotliMessageHeaderByte byte = 0
 
+// ZstdMessageHeaderByte indicates that the message is zstd-compressed.
+// Such messages are only decoded from ZstdMessageArbOSVersion on, and are
+// empty batches before like other unknown formats.
+const ZstdMessageHeaderByte byte = 0x02
+
+// ZstdMessageArbOSVersion is the first ArbOS version batches may be zstd-compressed in.
+// It's the ArbOS version after 60, which isn't released yet.
+const ZstdMessageArbOSVersion = params.ArbosVersion_60 + 1
+
 // DACertificateMessageHeaderFlag indicates that this message uses a custom data availability system.
 // Anytrust uses the legacy AnyTrustTreeMessageHeaderFlag instead despite also having a certificate.
 const DACertificateMessageHeaderFlag byte = 0x01
@@ -119,7 +129,12 @@ func IsBrotliMessageHeaderByte(b uint8) bool {
 	return b == BrotliMessageHeaderByte
 }
 
-// IsKnownHeaderByte returns true if the supplied header byte has only known bits
+func IsZstdMessageHeaderByte(b uint8) bool {
+	return b == ZstdMessageHeaderByte
+}
+
+// IsKnownHeaderByte returns true if the supplied header byte has only known bits,
+// or is the zstd header byte, which is a value rather than a flag.
 func IsKnownHeaderByte(b uint8) bool {
-	return b&^KnownHeaderBits == 0
+	return b == ZstdMessageHeaderByte || b&^KnownHeaderBits == 0
 }
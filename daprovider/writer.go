@@ -5,6 +5,7 @@ package daprovider
 
 import (
 	"errors"
+	"math/big"
 
 	"github.com/offchainlabs/nitro/util/containers"
 )
@@ -40,3 +41,13 @@ type Writer interface {
 	// dynamically (e.g., due to backend conditions or fallback scenarios).
 	GetMaxMessageSize() containers.PromiseInterface[int]
 }
+
+// StoreCostEstimator is optionally implemented by Writers which can quote the
+// cost of storing a message, so that the batch poster can compare it to the
+// cost of posting the message to the parent chain.
+type StoreCostEstimator interface {
+	// EstimateStoreCost returns the cost in parent chain wei of storing a
+	// message of the given size, excluding posting the certificate. A nil
+	// cost means that the writer can't quote it.
+	EstimateStoreCost(size int) containers.PromiseInterface[*big.Int]
+}
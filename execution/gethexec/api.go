@@ -56,6 +56,30 @@ func (a *ArbAPI) CheckPublisherHealth(ctx context.Context) error {
 	return a.txPublisher.CheckHealth(ctx)
 }
 
+// SendBundle submits a bundle of signed transactions, which are sequenced
+// contiguously and in order in a single block. If any of them fails or
+// reverts, none of them is sequenced and the error is returned. It returns the
+// hashes of the transactions.
+func (a *ArbAPI) SendBundle(ctx context.Context, encodedTxs []hexutil.Bytes) ([]common.Hash, error) {
+	if len(encodedTxs) == 0 {
+		return nil, errors.New("empty bundle")
+	}
+	txs := make(types.Transactions, 0, len(encodedTxs))
+	hashes := make([]common.Hash, 0, len(encodedTxs))
+	for i, encoded := range encodedTxs {
+		tx := new(types.Transaction)
+		if err := tx.UnmarshalBinary(encoded); err != nil {
+			return nil, fmt.Errorf("invalid bundle transaction %d: %w", i, err)
+		}
+		txs = append(txs, tx)
+		hashes = append(hashes, tx.Hash())
+	}
+	if err := a.txPublisher.PublishBundle(ctx, txs); err != nil {
+		return nil, err
+	}
+	return hashes, nil
+}
+
 func (a *ArbAPI) GetRawBlockMetadata(ctx context.Context, fromBlock, toBlock rpc.BlockNumber) ([]NumberAndBlockMetadata, error) {
 	if a.bulkBlockMetadataFetcher == nil {
 		return nil, errors.New("arb_getRawBlockMetadata is not available")
@@ -17,6 +17,7 @@ type TransactionPublisher interface {
 	PublishAuctionResolutionTransaction(ctx context.Context, tx *types.Transaction) error
 	PublishExpressLaneTransaction(ctx context.Context, msg *timeboost.ExpressLaneSubmission) error
 	PublishTransaction(ctx context.Context, tx *types.Transaction, options *arbitrum_types.ConditionalOptions) error
+	PublishBundle(ctx context.Context, txs types.Transactions) error
 	CheckHealth(ctx context.Context) error
 	Initialize(context.Context) error
 	Start(context.Context) error
@@ -18,10 +18,12 @@ import (
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rlp"
 
 	"github.com/offchainlabs/nitro/arbos"
 	"github.com/offchainlabs/nitro/arbos/arbosState"
 	"github.com/offchainlabs/nitro/arbos/arbostypes"
+	"github.com/offchainlabs/nitro/arbstate"
 	"github.com/offchainlabs/nitro/arbutil"
 	"github.com/offchainlabs/nitro/execution"
 )
@@ -101,10 +103,12 @@ func stateLogFunc(targetHeader *types.Header) arbitrum.StateBuildingLogFunction
 
 // If msg is nil, this will record block creation up to the point where message would be accessed (for a "too far" proof)
 // If keepreference == true, reference to state of prevHeader is added (no reference added if an error is returned)
+// posInBatch is the number of messages of pos's batch before it, whose headers replay may read to decode the batch.
 func (r *BlockRecorder) RecordBlockCreation(
 	ctx context.Context,
 	pos arbutil.MessageIndex,
 	msg *arbostypes.MessageWithMetadata,
+	posInBatch uint64,
 	wasmTargets []rawdb.WasmTarget,
 ) (*execution.RecordResult, error) {
 	blockNum := r.execEngine.MessageIndexToBlockNumber(pos)
@@ -178,6 +182,27 @@ func (r *BlockRecorder) RecordBlockCreation(
 	if err != nil {
 		return nil, err
 	}
+	if prevHeader != nil {
+		// Record the headers replay reads for the ArbOS version before the batch
+		_, err = arbstate.ArbOSVersionBeforeBatch(prevHeader, posInBatch, chainConfig.ArbitrumChainParams.GenesisBlockNum, func(hash common.Hash) (*types.Header, error) {
+			header := r.execEngine.bc.GetHeaderByHash(hash)
+			if header == nil {
+				return nil, fmt.Errorf("header %v not found", hash)
+			}
+			enc, err := rlp.EncodeToBytes(header)
+			if err != nil {
+				return nil, err
+			}
+			if preimages == nil {
+				preimages = make(map[common.Hash][]byte)
+			}
+			preimages[hash] = enc
+			return header, nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
 
 	// check we got the canonical hash
 	canonicalHash := r.execEngine.bc.GetCanonicalHash(uint64(blockNum))
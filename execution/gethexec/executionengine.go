@@ -1087,6 +1087,23 @@ func (s *ExecutionEngine) getL1PricingSurplus() (int64, error) {
 	return surplus.Int64(), nil
 }
 
+// CollectTips returns whether the transactions of the block after header pay
+// their tips, rather than just the base fee.
+func (s *ExecutionEngine) CollectTips(header *types.Header) (bool, error) {
+	statedb, err := s.bc.StateAt(header.Root)
+	if err != nil {
+		return false, err
+	}
+	arbState, err := arbosState.OpenSystemArbosState(statedb, nil, true)
+	if err != nil {
+		return false, err
+	}
+	if arbState.ArbOSVersion() == params.ArbosVersion_9 {
+		return true, nil
+	}
+	return arbState.CollectTips()
+}
+
 func (s *ExecutionEngine) cacheL1PriceDataOfMsg(msgIdx arbutil.MessageIndex, block *types.Block, blockBuiltUsingDelayedMessage bool) {
 	var callDataUnits uint64
 	if !blockBuiltUsingDelayedMessage {
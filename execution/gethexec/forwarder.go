@@ -18,6 +18,7 @@ import (
 
 	"github.com/ethereum/go-ethereum/arbitrum"
 	"github.com/ethereum/go-ethereum/arbitrum_types"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/ethereum/go-ethereum/log"
@@ -151,6 +152,37 @@ func (f *TxForwarder) PublishTransaction(inctx context.Context, tx *types.Transa
 	return errors.New("failed to publish transaction to any of the forwarding targets")
 }
 
+func (f *TxForwarder) PublishBundle(inctx context.Context, txs types.Transactions) error {
+	if !f.enabled.Load() {
+		return ErrNoSequencer
+	}
+	ctx, cancelFunc := f.ctxWithTimeout()
+	defer cancelFunc()
+	for pos, rpcClient := range f.rpcClients {
+		err := sendBundleRPC(ctx, rpcClient, txs)
+		if err != nil {
+			log.Warn("error forwarding bundle to a backup target", "target", f.targets[pos], "err", err)
+		}
+		if err == nil || !f.tryNewForwarderErrors.MatchString(err.Error()) {
+			return err
+		}
+	}
+	log.Error("Failed to publish bundle to any of the forwarding targets", "numTargets", len(f.rpcClients))
+	return errors.New("failed to publish bundle to any of the forwarding targets")
+}
+
+func sendBundleRPC(ctx context.Context, rpcClient *rpc.Client, txs types.Transactions) error {
+	encodedTxs := make([]hexutil.Bytes, 0, len(txs))
+	for _, tx := range txs {
+		encoded, err := tx.MarshalBinary()
+		if err != nil {
+			return err
+		}
+		encodedTxs = append(encodedTxs, encoded)
+	}
+	return rpcClient.CallContext(ctx, nil, "arb_sendBundle", encodedTxs)
+}
+
 func (f *TxForwarder) PublishExpressLaneTransaction(inctx context.Context, msg *timeboost.ExpressLaneSubmission) error {
 	if !f.enabled.Load() {
 		return ErrNoSequencer
@@ -297,6 +329,10 @@ func (f *TxDropper) PublishTransaction(ctx context.Context, tx *types.Transactio
 	return txDropperErr
 }
 
+func (f *TxDropper) PublishBundle(ctx context.Context, txs types.Transactions) error {
+	return txDropperErr
+}
+
 func (f *TxDropper) PublishExpressLaneTransaction(ctx context.Context, msg *timeboost.ExpressLaneSubmission) error {
 	return txDropperErr
 }
@@ -348,6 +384,14 @@ func (f *RedisTxForwarder) PublishTransaction(ctx context.Context, tx *types.Tra
 	return forwarder.PublishTransaction(ctx, tx, options)
 }
 
+func (f *RedisTxForwarder) PublishBundle(ctx context.Context, txs types.Transactions) error {
+	forwarder := f.getForwarder()
+	if forwarder == nil {
+		return ErrNoSequencer
+	}
+	return forwarder.PublishBundle(ctx, txs)
+}
+
 func (f *RedisTxForwarder) PublishExpressLaneTransaction(ctx context.Context, msg *timeboost.ExpressLaneSubmission) error {
 	forwarder := f.getForwarder()
 	if forwarder == nil {
@@ -220,6 +220,9 @@ func (c *Config) Validate() error {
 	if c.forwardingTarget != "" && c.Sequencer.Enable {
 		return errors.New("ForwardingTarget set and sequencer enabled")
 	}
+	if err := c.TxPreChecker.Validate(); err != nil {
+		return err
+	}
 	if err := c.TransactionFiltering.Validate(); err != nil {
 		return err
 	}
@@ -524,6 +527,14 @@ func CreateExecutionNode(
 		Service:   eth.NewDebugAPI(eth.NewArbEthereum(l2BlockChain, executionDB)),
 		Public:    false,
 	})
+	if sequencer != nil {
+		apis = append(apis, rpc.API{
+			Namespace: SequencerAdminNamespace,
+			Version:   "1.0",
+			Service:   NewSequencerAdminAPI(sequencer),
+			Public:    false,
+		})
+	}
 	if config.RPCServer.Enable {
 		apis = append(apis, rpc.API{
 			Namespace:     execution.RPCNamespace,
@@ -695,10 +706,11 @@ func (n *ExecutionNode) ArbOSVersionForMessageIndex(msgIdx arbutil.MessageIndex)
 func (n *ExecutionNode) RecordBlockCreation(
 	pos arbutil.MessageIndex,
 	msg *arbostypes.MessageWithMetadata,
+	posInBatch uint64,
 	wasmTargets []rawdb.WasmTarget,
 ) containers.PromiseInterface[*execution.RecordResult] {
 	return stopwaiter.LaunchPromiseThread(n, func(ctx context.Context) (*execution.RecordResult, error) {
-		return n.Recorder.RecordBlockCreation(ctx, pos, msg, wasmTargets)
+		return n.Recorder.RecordBlockCreation(ctx, pos, msg, posInBatch, wasmTargets)
 	})
 }
 
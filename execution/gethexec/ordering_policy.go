@@ -0,0 +1,190 @@
+// Copyright 2026, Offchain Labs, Inc.
+// For license information, see https://github.com/OffchainLabs/nitro/blob/master/LICENSE.md
+
+package gethexec
+
+import (
+	"container/heap"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+const (
+	OrderingPolicyFIFO           = "fifo"
+	OrderingPolicyTipPriority    = "tip-priority"
+	OrderingPolicySenderFairness = "sender-fairness"
+)
+
+// OrderingCandidate is a transaction read from the sequencer queue for the
+// next block.
+type OrderingCandidate struct {
+	Tx              *types.Transaction
+	Sender          common.Address
+	Timeboosted     bool
+	FirstAppearance time.Time
+}
+
+// OrderingPolicy decides the order in which the transactions read from the
+// queue are sequenced in a block. The candidates are passed in the order they
+// were queued, and their nonces have already been checked in that order.
+type OrderingPolicy interface {
+	// Order returns a permutation of the indices of the candidates. The
+	// candidates of each sender must keep their relative order, otherwise
+	// the sequencer falls back to the queue order.
+	Order(candidates []OrderingCandidate, baseFee *big.Int) []int
+}
+
+func NewOrderingPolicy(name string) (OrderingPolicy, error) {
+	switch name {
+	case OrderingPolicyFIFO:
+		return FIFOOrderingPolicy{}, nil
+	case OrderingPolicyTipPriority:
+		return TipPriorityOrderingPolicy{}, nil
+	case OrderingPolicySenderFairness:
+		return SenderFairnessOrderingPolicy{}, nil
+	default:
+		return nil, fmt.Errorf("unknown ordering policy %q, valid policies are: %s, %s, %s", name, OrderingPolicyFIFO, OrderingPolicyTipPriority, OrderingPolicySenderFairness)
+	}
+}
+
+// FIFOOrderingPolicy sequences transactions in the order they were queued.
+type FIFOOrderingPolicy struct{}
+
+func (FIFOOrderingPolicy) Order(candidates []OrderingCandidate, _ *big.Int) []int {
+	order := make([]int, len(candidates))
+	for i := range order {
+		order[i] = i
+	}
+	return order
+}
+
+// senderQueue is the indices of the candidates of a sender which have not been
+// ordered yet, in queue order.
+type senderQueue struct {
+	indices []int
+	taken   int
+}
+
+// senderHeap orders the candidates by repeatedly taking the next candidate of
+// the sender whose next candidate is preferred, so that the candidates of each
+// sender keep their order. Express lane candidates are always preferred.
+type senderHeap struct {
+	candidates []OrderingCandidate
+	queues     []*senderQueue
+	prefer     func(a, b *senderQueue) bool
+}
+
+func (h *senderHeap) Len() int { return len(h.queues) }
+
+func (h *senderHeap) Less(i, j int) bool {
+	a, b := h.queues[i], h.queues[j]
+	aBoosted, bBoosted := h.candidates[a.indices[0]].Timeboosted, h.candidates[b.indices[0]].Timeboosted
+	if aBoosted != bBoosted {
+		return aBoosted
+	}
+	if !aBoosted {
+		if h.prefer(a, b) {
+			return true
+		}
+		if h.prefer(b, a) {
+			return false
+		}
+	}
+	return a.indices[0] < b.indices[0]
+}
+
+func (h *senderHeap) Swap(i, j int) { h.queues[i], h.queues[j] = h.queues[j], h.queues[i] }
+
+func (h *senderHeap) Push(x any) { h.queues = append(h.queues, x.(*senderQueue)) }
+
+func (h *senderHeap) Pop() any {
+	last := h.queues[len(h.queues)-1]
+	h.queues = h.queues[:len(h.queues)-1]
+	return last
+}
+
+func orderBySender(candidates []OrderingCandidate, prefer func(a, b *senderQueue) bool) []int {
+	h := &senderHeap{candidates: candidates, prefer: prefer}
+	bySender := make(map[common.Address]*senderQueue)
+	for i, candidate := range candidates {
+		queue, ok := bySender[candidate.Sender]
+		if !ok {
+			queue = &senderQueue{}
+			bySender[candidate.Sender] = queue
+			h.queues = append(h.queues, queue)
+		}
+		queue.indices = append(queue.indices, i)
+	}
+	heap.Init(h)
+	order := make([]int, 0, len(candidates))
+	for h.Len() > 0 {
+		queue := h.queues[0]
+		order = append(order, queue.indices[0])
+		queue.indices = queue.indices[1:]
+		queue.taken++
+		if len(queue.indices) > 0 {
+			heap.Fix(h, 0)
+		} else {
+			heap.Pop(h)
+		}
+	}
+	return order
+}
+
+// TipPriorityOrderingPolicy sequences the transactions paying the highest
+// effective tip first, keeping the nonce order of each sender. Ties are
+// broken by queue order. The sequencer only applies it when the chain collects
+// tips, as otherwise all transactions pay the base fee.
+type TipPriorityOrderingPolicy struct{}
+
+func (TipPriorityOrderingPolicy) Order(candidates []OrderingCandidate, baseFee *big.Int) []int {
+	tips := make([]*big.Int, len(candidates))
+	for i, candidate := range candidates {
+		tip, err := candidate.Tx.EffectiveGasTip(baseFee)
+		if err != nil {
+			// The fee cap is below the base fee, the block will reject it anyway
+			tip = new(big.Int)
+		}
+		tips[i] = tip
+	}
+	return orderBySender(candidates, func(a, b *senderQueue) bool {
+		return tips[a.indices[0]].Cmp(tips[b.indices[0]]) > 0
+	})
+}
+
+// SenderFairnessOrderingPolicy sequences the transactions of the senders in
+// rounds, taking one transaction of every sender per round in queue order, so
+// that a sender submitting many transactions cannot delay the others.
+type SenderFairnessOrderingPolicy struct{}
+
+func (SenderFairnessOrderingPolicy) Order(candidates []OrderingCandidate, _ *big.Int) []int {
+	return orderBySender(candidates, func(a, b *senderQueue) bool {
+		return a.taken < b.taken
+	})
+}
+
+// validOrder checks that the order is a permutation of the candidates which
+// keeps the relative order of each sender's candidates.
+func validOrder(candidates []OrderingCandidate, order []int) error {
+	if len(order) != len(candidates) {
+		return fmt.Errorf("ordered %d transactions out of %d", len(order), len(candidates))
+	}
+	seen := make([]bool, len(candidates))
+	lastOfSender := make(map[common.Address]int)
+	for _, idx := range order {
+		if idx < 0 || idx >= len(candidates) || seen[idx] {
+			return fmt.Errorf("invalid or duplicate transaction index %d", idx)
+		}
+		seen[idx] = true
+		sender := candidates[idx].Sender
+		if last, ok := lastOfSender[sender]; ok && last > idx {
+			return fmt.Errorf("transactions of sender %v reordered", sender)
+		}
+		lastOfSender[sender] = idx
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+// Copyright 2026, Offchain Labs, Inc.
+// For license information, see https://github.com/OffchainLabs/nitro/blob/master/LICENSE.md
+
+package gethexec
+
+import (
+	"math/big"
+	"slices"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func orderingCandidate(sender byte, nonce uint64, tip int64, timeboosted bool) OrderingCandidate {
+	return OrderingCandidate{
+		Tx: types.NewTx(&types.DynamicFeeTx{
+			Nonce:     nonce,
+			GasTipCap: big.NewInt(tip),
+			GasFeeCap: big.NewInt(100 + tip),
+		}),
+		Sender:      common.Address{sender},
+		Timeboosted: timeboosted,
+	}
+}
+
+func TestOrderingPolicies(t *testing.T) {
+	baseFee := big.NewInt(100)
+	candidates := []OrderingCandidate{
+		orderingCandidate(1, 0, 1, false),
+		orderingCandidate(1, 1, 50, false),
+		orderingCandidate(1, 2, 1, false),
+		orderingCandidate(2, 0, 10, false),
+		orderingCandidate(3, 0, 5, false),
+		orderingCandidate(3, 1, 30, false),
+		orderingCandidate(4, 0, 0, true),
+	}
+	for _, tc := range []struct {
+		policy string
+		want   []int
+	}{
+		// Queue order
+		{OrderingPolicyFIFO, []int{0, 1, 2, 3, 4, 5, 6}},
+		// Express lane first, then the highest tip available without
+		// reordering a sender's transactions
+		{OrderingPolicyTipPriority, []int{6, 3, 4, 5, 0, 1, 2}},
+		// Express lane first, then one transaction per sender and round
+		{OrderingPolicySenderFairness, []int{6, 0, 3, 4, 1, 5, 2}},
+	} {
+		policy, err := NewOrderingPolicy(tc.policy)
+		if err != nil {
+			t.Fatalf("NewOrderingPolicy(%q) unexpected error: %v", tc.policy, err)
+		}
+		order := policy.Order(candidates, baseFee)
+		if !slices.Equal(order, tc.want) {
+			t.Errorf("%s order: got %v, want %v", tc.policy, order, tc.want)
+		}
+		if err := validOrder(candidates, order); err != nil {
+			t.Errorf("%s order is invalid: %v", tc.policy, err)
+		}
+	}
+	if _, err := NewOrderingPolicy("random"); err == nil {
+		t.Error("expected an error for an unknown ordering policy")
+	}
+}
+
+func TestValidOrder(t *testing.T) {
+	candidates := []OrderingCandidate{
+		orderingCandidate(1, 0, 0, false),
+		orderingCandidate(1, 1, 0, false),
+		orderingCandidate(2, 0, 0, false),
+	}
+	for _, order := range [][]int{
+		{0, 1},    // missing transaction
+		{0, 1, 1}, // duplicate transaction
+		{0, 1, 3}, // out of range
+		{1, 0, 2}, // nonces of a sender reordered
+	} {
+		if err := validOrder(candidates, order); err == nil {
+			t.Errorf("expected order %v to be invalid", order)
+		}
+	}
+	if err := validOrder(candidates, []int{2, 0, 1}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
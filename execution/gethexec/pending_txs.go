@@ -0,0 +1,236 @@
+// Copyright 2026, Offchain Labs, Inc.
+// For license information, see https://github.com/OffchainLabs/nitro/blob/master/LICENSE.md
+
+package gethexec
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// SequencerAdminNamespace is the RPC namespace of the SequencerAdminAPI, which
+// should not be exposed publicly.
+const SequencerAdminNamespace = "arbsequencer"
+
+// pendingTxSubscriptionBuffer is the number of notifications buffered for a
+// pending transactions subscriber before notifications are dropped.
+const pendingTxSubscriptionBuffer = 4096
+
+var pendingTxNotificationsDroppedCounter = metrics.NewRegisteredCounter("arb/sequencer/pending/notifications/dropped", nil)
+
+// PendingTx describes a transaction waiting in the sequencer.
+type PendingTx struct {
+	Hash   common.Hash    `json:"hash"`
+	Sender common.Address `json:"sender"`
+	Nonce  uint64         `json:"nonce"`
+	// Source is the queue the transaction was last added to.
+	Source          string    `json:"source"`
+	Bundle          bool      `json:"bundle,omitempty"`
+	FirstAppearance time.Time `json:"firstAppearance"`
+	Age             string    `json:"age"`
+	// StateNonce is the nonce the sender had when a transaction with a nonce
+	// gap was parked waiting for its predecessor.
+	StateNonce *uint64 `json:"stateNonce,omitempty"`
+}
+
+type trackedTx struct {
+	tx              *types.Transaction
+	bundle          bool
+	firstAppearance time.Time
+	source          TxSource
+	stateNonce      *uint64
+	returnedResult  *atomic.Bool
+}
+
+// pendingTxTracker keeps track of the transactions waiting in the sequencer's
+// queues, which can't be inspected directly, until their result is returned.
+type pendingTxTracker struct {
+	mutex       sync.Mutex
+	txs         map[common.Hash]*trackedTx
+	subscribers map[chan common.Hash]struct{}
+}
+
+func newPendingTxTracker() *pendingTxTracker {
+	return &pendingTxTracker{
+		txs:         make(map[common.Hash]*trackedTx),
+		subscribers: make(map[chan common.Hash]struct{}),
+	}
+}
+
+// track starts tracking the transactions of a queue item which was added to
+// the source queue, and notifies the subscribers of new transactions.
+func (t *pendingTxTracker) track(item *txQueueItem, source TxSource) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	for _, tx := range item.txs() {
+		if tracked, ok := t.txs[tx.Hash()]; ok && !tracked.returnedResult.Load() {
+			tracked.source = source
+			continue
+		}
+		t.txs[tx.Hash()] = &trackedTx{
+			tx:              tx,
+			bundle:          item.bundle != nil,
+			firstAppearance: item.firstAppearance,
+			source:          source,
+			returnedResult:  item.returnedResult,
+		}
+		for sub := range t.subscribers {
+			select {
+			case sub <- tx.Hash():
+			default:
+				pendingTxNotificationsDroppedCounter.Inc(1)
+			}
+		}
+	}
+}
+
+// moved records that a tracked queue item was added to another queue.
+func (t *pendingTxTracker) moved(item *txQueueItem, source TxSource) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	for _, tx := range item.txs() {
+		if tracked, ok := t.txs[tx.Hash()]; ok {
+			tracked.source = source
+			tracked.stateNonce = nil
+		}
+	}
+}
+
+// parked records that a queue item was parked in the nonce failure cache.
+func (t *pendingTxTracker) parked(item *txQueueItem, err NonceError) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	stateNonce := err.stateNonce
+	for _, tx := range item.txs() {
+		if tracked, ok := t.txs[tx.Hash()]; ok {
+			tracked.source = NonceFailures
+			tracked.stateNonce = &stateNonce
+		}
+	}
+}
+
+// forget stops tracking a queue item which couldn't be queued.
+func (t *pendingTxTracker) forget(item *txQueueItem) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	for _, tx := range item.txs() {
+		delete(t.txs, tx.Hash())
+	}
+}
+
+// prune stops tracking the transactions whose result was returned.
+func (t *pendingTxTracker) prune() {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	for hash, tracked := range t.txs {
+		if tracked.returnedResult.Load() {
+			delete(t.txs, hash)
+		}
+	}
+}
+
+// list returns the tracked transactions from the given sources, or from all
+// sources if none is given, oldest first.
+func (t *pendingTxTracker) list(signer types.Signer, sources ...TxSource) []PendingTx {
+	t.prune()
+	t.mutex.Lock()
+	tracked := make([]*trackedTx, 0, len(t.txs))
+	for _, tx := range t.txs {
+		if len(sources) == 0 || slices.Contains(sources, tx.source) {
+			copied := *tx
+			tracked = append(tracked, &copied)
+		}
+	}
+	t.mutex.Unlock()
+
+	now := time.Now()
+	pending := make([]PendingTx, 0, len(tracked))
+	for _, tx := range tracked {
+		// The sender has usually been recovered and cached by the sequencer already
+		sender, _ := types.Sender(signer, tx.tx)
+		pending = append(pending, PendingTx{
+			Hash:            tx.tx.Hash(),
+			Sender:          sender,
+			Nonce:           tx.tx.Nonce(),
+			Source:          tx.source.String(),
+			Bundle:          tx.bundle,
+			FirstAppearance: tx.firstAppearance,
+			Age:             now.Sub(tx.firstAppearance).Round(time.Millisecond).String(),
+			StateNonce:      tx.stateNonce,
+		})
+	}
+	slices.SortStableFunc(pending, func(a, b PendingTx) int {
+		return a.FirstAppearance.Compare(b.FirstAppearance)
+	})
+	return pending
+}
+
+func (t *pendingTxTracker) subscribe() chan common.Hash {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	sub := make(chan common.Hash, pendingTxSubscriptionBuffer)
+	t.subscribers[sub] = struct{}{}
+	return sub
+}
+
+func (t *pendingTxTracker) unsubscribe(sub chan common.Hash) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	delete(t.subscribers, sub)
+}
+
+// SequencerAdminAPI inspects the transactions waiting in the sequencer, to
+// troubleshoot stuck transactions.
+type SequencerAdminAPI struct {
+	sequencer *Sequencer
+}
+
+func NewSequencerAdminAPI(sequencer *Sequencer) *SequencerAdminAPI {
+	return &SequencerAdminAPI{sequencer: sequencer}
+}
+
+// PendingTransactions lists the transactions waiting in the sequencer's
+// queues, including the ones parked because of a nonce gap.
+func (a *SequencerAdminAPI) PendingTransactions(ctx context.Context) []PendingTx {
+	return a.sequencer.pendingTxs.list(a.sequencer.pendingTxSigner())
+}
+
+// ParkedTransactions lists the transactions parked because of a nonce gap,
+// waiting for their predecessor.
+func (a *SequencerAdminAPI) ParkedTransactions(ctx context.Context) []PendingTx {
+	return a.sequencer.pendingTxs.list(a.sequencer.pendingTxSigner(), NonceFailures)
+}
+
+// NewPendingTransactions notifies the hashes of the transactions added to the
+// sequencer's queue. Notifications are dropped if the subscriber falls too far
+// behind.
+func (a *SequencerAdminAPI) NewPendingTransactions(ctx context.Context) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+	rpcSub := notifier.CreateSubscription()
+	sub := a.sequencer.pendingTxs.subscribe()
+	go func() {
+		defer a.sequencer.pendingTxs.unsubscribe(sub)
+		for {
+			select {
+			case hash := <-sub:
+				if err := notifier.Notify(rpcSub.ID, hash); err != nil {
+					return
+				}
+			case <-rpcSub.Err():
+				return
+			}
+		}
+	}()
+	return rpcSub, nil
+}
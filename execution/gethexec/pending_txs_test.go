@@ -0,0 +1,86 @@
+// Copyright 2026, Offchain Labs, Inc.
+// For license information, see https://github.com/OffchainLabs/nitro/blob/master/LICENSE.md
+
+package gethexec
+
+import (
+	"math/big"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestPendingTxTracker(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sender := crypto.PubkeyToAddress(key.PublicKey)
+	signer := types.LatestSignerForChainID(big.NewInt(1))
+	newItem := func(nonce uint64, age time.Duration) *txQueueItem {
+		tx, err := types.SignNewTx(key, signer, &types.DynamicFeeTx{ChainID: big.NewInt(1), Nonce: nonce})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return &txQueueItem{
+			tx:              tx,
+			returnedResult:  &atomic.Bool{},
+			firstAppearance: time.Now().Add(-age),
+		}
+	}
+
+	tracker := newPendingTxTracker()
+	sub := tracker.subscribe()
+	defer tracker.unsubscribe(sub)
+
+	queued := newItem(0, time.Second)
+	parked := newItem(2, 2*time.Second)
+	forgotten := newItem(3, 0)
+	tracker.track(queued, TxQueue)
+	tracker.track(parked, TxQueue)
+	tracker.track(forgotten, TxQueue)
+	tracker.forget(forgotten)
+	tracker.parked(parked, NonceError{sender: sender, txNonce: 2, stateNonce: 1})
+
+	for _, want := range []*txQueueItem{queued, parked, forgotten} {
+		select {
+		case hash := <-sub:
+			if hash != want.tx.Hash() {
+				t.Errorf("notified %v, want %v", hash, want.tx.Hash())
+			}
+		default:
+			t.Fatalf("missing notification for %v", want.tx.Hash())
+		}
+	}
+
+	pending := tracker.list(signer)
+	if len(pending) != 2 {
+		t.Fatalf("got %d pending transactions, want 2: %+v", len(pending), pending)
+	}
+	// Oldest first
+	if pending[0].Hash != parked.tx.Hash() || pending[1].Hash != queued.tx.Hash() {
+		t.Errorf("unexpected pending transactions %+v", pending)
+	}
+	if pending[0].Sender != sender || pending[0].Nonce != 2 || pending[0].Source != NonceFailures.String() ||
+		pending[0].StateNonce == nil || *pending[0].StateNonce != 1 {
+		t.Errorf("unexpected parked transaction %+v", pending[0])
+	}
+	if pending[1].Source != TxQueue.String() || pending[1].StateNonce != nil {
+		t.Errorf("unexpected queued transaction %+v", pending[1])
+	}
+
+	parkedOnly := tracker.list(signer, NonceFailures)
+	if len(parkedOnly) != 1 || parkedOnly[0].Hash != parked.tx.Hash() {
+		t.Errorf("unexpected parked transactions %+v", parkedOnly)
+	}
+
+	tracker.moved(parked, RetryQueue)
+	queued.returnedResult.Store(true)
+	pending = tracker.list(signer)
+	if len(pending) != 1 || pending[0].Source != RetryQueue.String() || pending[0].StateNonce != nil {
+		t.Errorf("unexpected pending transactions after retry and result %+v", pending)
+	}
+}
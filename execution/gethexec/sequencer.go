@@ -75,6 +75,8 @@ var (
 	dataLimitedBlocksCounter = metrics.NewRegisteredCounter("arb/sequencer/block/datalimited", nil)
 	// number of blocks ended because of exhausting the transactions to sequence
 	txExhaustedBlocksCounter = metrics.NewRegisteredCounter("arb/sequencer/block/txexhausted", nil)
+	bundlesSequencedCounter  = metrics.NewRegisteredCounter("arb/sequencer/bundles/sequenced", nil)
+	bundlesFailedCounter     = metrics.NewRegisteredCounter("arb/sequencer/bundles/failed", nil)
 )
 
 type SequencerConfig struct {
@@ -89,12 +91,14 @@ type SequencerConfig struct {
 	QueueTimeout                 time.Duration    `koanf:"queue-timeout" reload:"hot"`
 	NonceCacheSize               int              `koanf:"nonce-cache-size" reload:"hot"`
 	MaxTxDataSize                int              `koanf:"max-tx-data-size" reload:"hot"`
+	MaxBundleSize                int              `koanf:"max-bundle-size" reload:"hot"`
 	NonceFailureCacheSize        int              `koanf:"nonce-failure-cache-size" reload:"hot"`
 	NonceFailureCacheExpiry      time.Duration    `koanf:"nonce-failure-cache-expiry" reload:"hot"`
 	ExpectedSurplusGasPriceMode  string           `koanf:"expected-surplus-gas-price-mode"`
 	ExpectedSurplusSoftThreshold string           `koanf:"expected-surplus-soft-threshold" reload:"hot"`
 	ExpectedSurplusHardThreshold string           `koanf:"expected-surplus-hard-threshold" reload:"hot"`
 	EnableProfiling              bool             `koanf:"enable-profiling" reload:"hot"`
+	OrderingPolicy               string           `koanf:"ordering-policy"`
 	Timeboost                    timeboost.Config `koanf:"timeboost"`
 	Dangerous                    DangerousConfig  `koanf:"dangerous"`
 	expectedSurplusSoftThreshold int
@@ -155,6 +159,12 @@ func (c *SequencerConfig) Validate() error {
 			}
 		}
 	}
+	if _, err := NewOrderingPolicy(c.OrderingPolicy); err != nil {
+		return err
+	}
+	if c.MaxBundleSize < 0 {
+		return errors.New("sequencer max-bundle-size cannot be negative")
+	}
 	if c.ReadFromTxQueueTimeout >= c.MaxBlockSpeed {
 		log.Warn("Sequencer ReadFromTxQueueTimeout is higher than MaxBlockSpeed", "ReadFromTxQueueTimeout", c.ReadFromTxQueueTimeout, "MaxBlockSpeed", c.MaxBlockSpeed)
 	}
@@ -178,12 +188,14 @@ var DefaultSequencerConfig = SequencerConfig{
 	// 95% of the default batch poster limit, leaving 5KB for headers and such
 	// This default is overridden for L3 chains in applyChainParameters in cmd/nitro/nitro.go
 	MaxTxDataSize:                95000,
+	MaxBundleSize:                0,
 	NonceFailureCacheSize:        1024,
 	NonceFailureCacheExpiry:      time.Second,
 	ExpectedSurplusGasPriceMode:  "BlobPrice",
 	ExpectedSurplusSoftThreshold: "default",
 	ExpectedSurplusHardThreshold: "default",
 	EnableProfiling:              false,
+	OrderingPolicy:               OrderingPolicyFIFO,
 	Timeboost:                    timeboost.DefaultConfig,
 	Dangerous:                    DefaultDangerousConfig,
 }
@@ -207,12 +219,14 @@ func SequencerConfigAddOptions(prefix string, f *pflag.FlagSet) {
 	f.Duration(prefix+".queue-timeout", DefaultSequencerConfig.QueueTimeout, "maximum amount of time transaction can wait in queue")
 	f.Int(prefix+".nonce-cache-size", DefaultSequencerConfig.NonceCacheSize, "size of the tx sender nonce cache")
 	f.Int(prefix+".max-tx-data-size", DefaultSequencerConfig.MaxTxDataSize, "maximum transaction size the sequencer will accept")
+	f.Int(prefix+".max-bundle-size", DefaultSequencerConfig.MaxBundleSize, "maximum number of transactions in a bundle submitted with arb_sendBundle; the transactions of a failed bundle are executed without being paid for, so bundles should only be enabled behind access control (0 = bundles disabled)")
 	f.Int(prefix+".nonce-failure-cache-size", DefaultSequencerConfig.NonceFailureCacheSize, "number of transactions with too high of a nonce to keep in memory while waiting for their predecessor")
 	f.Duration(prefix+".nonce-failure-cache-expiry", DefaultSequencerConfig.NonceFailureCacheExpiry, "maximum amount of time to wait for a predecessor before rejecting a tx with nonce too high")
 	f.String(prefix+".expected-surplus-gas-price-mode", DefaultSequencerConfig.ExpectedSurplusGasPriceMode, "gas price setting to be used in calculating estimated surplus. Allowed values- CalldataPrice, BlobPrice and CalldataPrice7523")
 	f.String(prefix+".expected-surplus-soft-threshold", DefaultSequencerConfig.ExpectedSurplusSoftThreshold, "if expected surplus is lower than this value, warnings are posted")
 	f.String(prefix+".expected-surplus-hard-threshold", DefaultSequencerConfig.ExpectedSurplusHardThreshold, "if expected surplus is lower than this value, new incoming transactions will be denied")
 	f.Bool(prefix+".enable-profiling", DefaultSequencerConfig.EnableProfiling, "enable CPU profiling and tracing")
+	f.String(prefix+".ordering-policy", DefaultSequencerConfig.OrderingPolicy, "order in which the transactions read from the queue are sequenced in a block. Allowed values- fifo, tip-priority (highest tip first, only applied when the chain collects tips) and sender-fairness (round robin over senders)")
 }
 
 func DangerousAddOptions(prefix string, f *pflag.FlagSet) {
@@ -234,6 +248,49 @@ type txQueueItem struct {
 	firstAppearance time.Time
 	isTimeboosted   bool
 	blockStamp      uint64 // block number at which timeboosted tx was added to the txQueue
+	// bundle is set if the item is a bundle, tx is then its first transaction
+	// and txSize the size of all its transactions.
+	bundle *txBundle
+}
+
+// txBundle is a list of transactions which must be sequenced contiguously and
+// in order in a single block, or not at all.
+type txBundle struct {
+	txs  types.Transactions
+	size int
+}
+
+func (i *txQueueItem) txs() types.Transactions {
+	if i.bundle != nil {
+		return i.bundle.txs
+	}
+	return types.Transactions{i.tx}
+}
+
+// expandBundle returns one queue item per transaction of the bundle, sharing
+// the bundle's result. The sequencing hooks sequence these items.
+func (i *txQueueItem) expandBundle() []txQueueItem {
+	items := make([]txQueueItem, 0, len(i.bundle.txs))
+	for _, tx := range i.bundle.txs {
+		item := *i
+		item.tx = tx
+		item.txSize = int(tx.Size()) // #nosec G115
+		items = append(items, item)
+	}
+	return items
+}
+
+// collapseBundle returns the bundle queue item from one of its expanded items.
+func (i *txQueueItem) collapseBundle() txQueueItem {
+	item := *i
+	item.tx = i.bundle.txs[0]
+	item.txSize = i.bundle.size
+	return item
+}
+
+// startsBundle returns whether the item is the first expanded item of a bundle.
+func (i *txQueueItem) startsBundle() bool {
+	return i.bundle != nil && i.tx == i.bundle.txs[0]
 }
 
 func (i *txQueueItem) returnResultMaybeLog(err error, outputLog bool) {
@@ -345,6 +402,7 @@ type nonceFailure struct {
 type nonceFailureCache struct {
 	*containers.LruCache[addressAndNonce, *nonceFailure]
 	getExpiry func() time.Duration
+	pending   *pendingTxTracker
 }
 
 func (c nonceFailureCache) Contains(err NonceError) bool {
@@ -369,17 +427,22 @@ func (c nonceFailureCache) Add(err NonceError, queueItem txQueueItem) {
 	if evicted {
 		nonceFailureCacheOverflowCounter.Inc(1)
 	}
+	c.pending.parked(&queueItem, err)
 }
 
 type synchronizedTxQueue struct {
-	queue containers.Queue[txQueueItem]
-	mutex sync.RWMutex
+	queue   containers.Queue[txQueueItem]
+	mutex   sync.RWMutex
+	pending *pendingTxTracker
 }
 
 func (q *synchronizedTxQueue) Push(item txQueueItem) {
 	q.mutex.Lock()
 	q.queue.Push(item)
 	q.mutex.Unlock()
+	if q.pending != nil {
+		q.pending.moved(&item, RetryQueue)
+	}
 }
 
 func (q *synchronizedTxQueue) Pop() txQueueItem {
@@ -406,6 +469,8 @@ type Sequencer struct {
 	senderWhitelist    map[common.Address]struct{}
 	nonceCache         *nonceCache
 	nonceFailures      *nonceFailureCache
+	pendingTxs         *pendingTxTracker
+	orderingPolicy     OrderingPolicy
 	expressLaneService *timeboost.ExpressLaneService
 	onForwarderSet     chan struct{}
 	parentChain        *parent.ParentChain
@@ -451,6 +516,10 @@ func NewSequencer(
 		}
 		senderWhitelist[common.HexToAddress(address)] = struct{}{}
 	}
+	orderingPolicy, err := NewOrderingPolicy(config.OrderingPolicy)
+	if err != nil {
+		return nil, err
+	}
 
 	s := &Sequencer{
 		execEngine:                        execEngine,
@@ -459,6 +528,8 @@ func NewSequencer(
 		config:                            configFetcher,
 		senderWhitelist:                   senderWhitelist,
 		nonceCache:                        newNonceCache(config.NonceCacheSize),
+		pendingTxs:                        newPendingTxTracker(),
+		orderingPolicy:                    orderingPolicy,
 		l1Timestamp:                       0,
 		pauseChan:                         nil,
 		onForwarderSet:                    make(chan struct{}, 1),
@@ -470,7 +541,9 @@ func NewSequencer(
 	s.nonceFailures = &nonceFailureCache{
 		containers.NewLruCacheWithOnEvict(config.NonceCacheSize, s.onNonceFailureEvict),
 		func() time.Duration { return configFetcher().NonceFailureCacheExpiry },
+		s.pendingTxs,
 	}
+	s.txRetryQueue.pending = s.pendingTxs
 	s.Pause()
 	execEngine.EnableReorgSequencing()
 	execEngine.SetEventFilter(eventFilter)
@@ -531,7 +604,46 @@ func (s *Sequencer) PublishTransaction(parentCtx context.Context, tx *types.Tran
 	if err != nil {
 		return err
 	}
+	return awaitQueueResult(parentCtx, resultChan, queueTimeout, tx.Hash())
+}
 
+// PublishBundle sequences the transactions of the bundle contiguously and in
+// order in a single block. If any of them fails or reverts, none of them is
+// sequenced and the error is returned.
+func (s *Sequencer) PublishBundle(parentCtx context.Context, txs types.Transactions) error {
+	_, forwarder := s.GetPauseAndForwarder()
+	if forwarder != nil {
+		err := forwarder.PublishBundle(parentCtx, txs)
+		if !errors.Is(err, ErrNoSequencer) {
+			return err
+		}
+	}
+
+	config := s.config()
+	if config.MaxBundleSize == 0 {
+		return errors.New("bundles are disabled")
+	}
+	if len(txs) == 0 {
+		return errors.New("empty bundle")
+	}
+	if len(txs) > config.MaxBundleSize {
+		return fmt.Errorf("bundle has %d transactions, the maximum is %d", len(txs), config.MaxBundleSize)
+	}
+	queueTimeout := config.QueueTimeout
+	queueCtx, cancelFunc := ctxhelper.WithTimeoutOrCancel(parentCtx, queueTimeout+config.Timeboost.ExpressLaneAdvantage) // Include timeboost delay in ctx timeout
+	defer cancelFunc()
+
+	resultChan := make(chan error, 1)
+	err := s.publishBundleToQueue(queueCtx, txs, resultChan)
+	if err != nil {
+		return err
+	}
+	return awaitQueueResult(parentCtx, resultChan, queueTimeout, txs[0].Hash())
+}
+
+// awaitQueueResult waits for the result of a queue item published with a
+// queue context derived from parentCtx.
+func awaitQueueResult(parentCtx context.Context, resultChan chan error, queueTimeout time.Duration, txHash common.Hash) error {
 	now := time.Now()
 	// Just to be safe, make sure we don't run over twice the queue timeout
 	abortCtx, cancel := ctxhelper.WithTimeoutOrCancel(parentCtx, queueTimeout*2)
@@ -546,7 +658,7 @@ func (s *Sequencer) PublishTransaction(parentCtx context.Context, tx *types.Tran
 		err := abortCtx.Err()
 		if parentCtx.Err() == nil {
 			// If we've hit the abort deadline (as opposed to parentCtx being canceled), something went wrong.
-			log.Warn("Transaction sequencing hit abort deadline", "err", err, "submittedAt", now, "queueTimeout", queueTimeout*2, "txHash", tx.Hash())
+			log.Warn("Transaction sequencing hit abort deadline", "err", err, "submittedAt", now, "queueTimeout", queueTimeout*2, "txHash", txHash)
 		}
 		return err
 	}
@@ -592,7 +704,7 @@ func (s *Sequencer) PublishAuctionResolutionTransaction(ctx context.Context, tx
 		return fmt.Errorf("transaction arrival time not within auction closure window: %v", arrivalTime)
 	}
 	log.Info("Prioritizing auction resolution transaction from auctioneer", "txHash", tx.Hash().Hex())
-	s.timeboostAuctionResolutionTxQueue <- txQueueItem{
+	queueItem := txQueueItem{
 		tx:              tx,
 		txSize:          int(tx.Size()), // #nosec G115
 		options:         nil,
@@ -602,6 +714,8 @@ func (s *Sequencer) PublishAuctionResolutionTransaction(ctx context.Context, tx
 		firstAppearance: time.Now(),
 		isTimeboosted:   false,
 	}
+	s.pendingTxs.track(&queueItem, TimeboostAuctionResolutionTxQueue)
+	s.timeboostAuctionResolutionTxQueue <- queueItem
 	return nil
 }
 
@@ -641,21 +755,20 @@ func (s *Sequencer) PublishTimeboostedTransaction(queueCtx context.Context, tx *
 	return s.publishTransactionToQueue(queueCtx, tx, options, resultChan, true)
 }
 
-func (s *Sequencer) publishTransactionToQueue(queueCtx context.Context, tx *types.Transaction, options *arbitrum_types.ConditionalOptions, resultChan chan error, isExpressLaneController bool) error {
-	config := s.config()
+func (s *Sequencer) checkExpectedSurplus(config *SequencerConfig) error {
 	// Only try to acquire Rlock and check for hard threshold if l1reader is not nil
 	// And hard threshold was enabled, this prevents spamming of read locks when not needed
 	if s.l1Reader != nil && config.ExpectedSurplusHardThreshold != "default" {
 		s.expectedSurplusMutex.RLock()
+		defer s.expectedSurplusMutex.RUnlock()
 		if s.expectedSurplusUpdated && s.expectedSurplus < int64(config.expectedSurplusHardThreshold) {
 			return errors.New("currently not accepting transactions due to expected surplus being below threshold")
 		}
-		s.expectedSurplusMutex.RUnlock()
 	}
+	return nil
+}
 
-	sequencerBacklogGauge.Inc(1)
-	defer sequencerBacklogGauge.Dec(1)
-
+func (s *Sequencer) checkTxAcceptable(tx *types.Transaction) error {
 	if len(s.senderWhitelist) > 0 {
 		signer := types.LatestSigner(s.execEngine.bc.Config())
 		sender, err := types.Sender(signer, tx)
@@ -672,12 +785,31 @@ func (s *Sequencer) publishTransactionToQueue(queueCtx context.Context, tx *type
 		// and we want to disallow BlobTxType since Arbitrum doesn't support EIP-4844 txs yet.
 		return types.ErrTxTypeNotSupported
 	}
+	return nil
+}
 
+func (s *Sequencer) delayForExpressLane(isExpressLaneController bool) {
 	if s.config().Timeboost.Enable && s.expressLaneService != nil {
 		if !isExpressLaneController && s.expressLaneService.CurrentRoundHasController() {
 			time.Sleep(s.config().Timeboost.ExpressLaneAdvantage)
 		}
 	}
+}
+
+func (s *Sequencer) publishTransactionToQueue(queueCtx context.Context, tx *types.Transaction, options *arbitrum_types.ConditionalOptions, resultChan chan error, isExpressLaneController bool) error {
+	config := s.config()
+	if err := s.checkExpectedSurplus(config); err != nil {
+		return err
+	}
+
+	sequencerBacklogGauge.Inc(1)
+	defer sequencerBacklogGauge.Dec(1)
+
+	if err := s.checkTxAcceptable(tx); err != nil {
+		return err
+	}
+
+	s.delayForExpressLane(isExpressLaneController)
 
 	var blockStamp uint64
 	if isExpressLaneController && config.Timeboost.QueueTimeoutInBlocks > 0 {
@@ -695,9 +827,53 @@ func (s *Sequencer) publishTransactionToQueue(queueCtx context.Context, tx *type
 		isTimeboosted:   isExpressLaneController,
 		blockStamp:      blockStamp,
 	}
+	s.pendingTxs.track(&queueItem, TxQueue)
 	select {
 	case s.txQueue <- queueItem:
 	case <-queueCtx.Done():
+		s.pendingTxs.forget(&queueItem)
+		return queueCtx.Err()
+	}
+	return nil
+}
+
+// publishBundleToQueue pushes the bundle as a single queue item, so that its
+// transactions can't be interleaved with other queue items.
+func (s *Sequencer) publishBundleToQueue(queueCtx context.Context, txs types.Transactions, resultChan chan error) error {
+	config := s.config()
+	if err := s.checkExpectedSurplus(config); err != nil {
+		return err
+	}
+
+	sequencerBacklogGauge.Inc(1)
+	defer sequencerBacklogGauge.Dec(1)
+
+	bundle := &txBundle{txs: txs}
+	for _, tx := range txs {
+		if err := s.checkTxAcceptable(tx); err != nil {
+			return fmt.Errorf("bundle transaction %v: %w", tx.Hash(), err)
+		}
+		bundle.size += int(tx.Size()) // #nosec G115
+	}
+
+	s.delayForExpressLane(false)
+
+	queueItem := txQueueItem{
+		tx:              txs[0],
+		txSize:          bundle.size,
+		options:         nil,
+		resultChan:      resultChan,
+		returnedResult:  &atomic.Bool{},
+		ctx:             queueCtx,
+		firstAppearance: time.Now(),
+		isTimeboosted:   false,
+		bundle:          bundle,
+	}
+	s.pendingTxs.track(&queueItem, TxQueue)
+	select {
+	case s.txQueue <- queueItem:
+	case <-queueCtx.Done():
+		s.pendingTxs.forget(&queueItem)
 		return queueCtx.Err()
 	}
 	return nil
@@ -771,6 +947,16 @@ func (s *Sequencer) postTxFilter(header *types.Header, statedb *state.StateDB, _
 	return nil
 }
 
+// bundleFailed is called after the block being built was rolled back to before
+// a failed bundle. The nonces cached while sequencing the bundle are discarded.
+func (s *Sequencer) bundleFailed(header *types.Header) {
+	s.nonceCache.Reset(header.ParentHash)
+}
+
+func (s *Sequencer) pendingTxSigner() types.Signer {
+	return types.LatestSigner(s.execEngine.bc.Config())
+}
+
 func (s *Sequencer) CheckHealth(ctx context.Context) error {
 	pauseChan, forwarder := s.GetPauseAndForwarder()
 	if forwarder != nil {
@@ -893,7 +1079,12 @@ func (s *Sequencer) handleInactive(ctx context.Context, queueItems []txQueueItem
 	for _, item := range queueItems {
 		item := item
 		go func() {
-			res := forwarder.PublishTransaction(item.ctx, item.tx, item.options)
+			var res error
+			if item.bundle != nil {
+				res = forwarder.PublishBundle(item.ctx, item.bundle.txs)
+			} else {
+				res = forwarder.PublishTransaction(item.ctx, item.tx, item.options)
+			}
 			if errors.Is(res, ErrNoSequencer) {
 				publishResults <- &item
 			} else {
@@ -924,7 +1115,10 @@ type FullSequencingHooks struct {
 	preTxFilter              func(*params.ChainConfig, *types.Header, *state.StateDB, *arbosState.ArbosState, *types.Transaction, *arbitrum_types.ConditionalOptions, common.Address, *arbos.L1Info) error
 	postTxFilter             func(*types.Header, *state.StateDB, *arbosState.ArbosState, *types.Transaction, common.Address, uint64, *core.ExecutionResult) error
 	blockFilter              func(*types.Header, *state.StateDB, types.Transactions, types.Receipts) error
+	bundleFailed             func(*types.Header)
 	txSizeLimitReached       bool
+	// bundleStart is the index of the first queue item of the last bundle
+	bundleStart int
 }
 
 func (s *FullSequencingHooks) MessageFromTxes(header *arbostypes.L1IncomingMessageHeader) (*arbostypes.L1IncomingMessage, error) {
@@ -988,6 +1182,7 @@ func (s *FullSequencingHooks) TxFailed(err error) {
 
 // NextTxToSequence returns the next transaction to be included in the block, or nil if there are no more transactions to include.
 // It will skip transactions that would cause the total size of included transactions to exceed maxSequencedTxsSize.
+// Bundles are skipped as a whole if all their transactions don't fit.
 func (s *FullSequencingHooks) NextTxToSequence() (*types.Transaction, *arbitrum_types.ConditionalOptions, error) {
 	for {
 		// This is not supposed to happen, if so we have a bug
@@ -1000,7 +1195,19 @@ func (s *FullSequencingHooks) NextTxToSequence() (*types.Transaction, *arbitrum_
 		if s.sequencedQueueItemsCount >= len(s.queueItems) {
 			return nil, nil, nil
 		}
-		if s.sequencedTxsSizeSoFar+s.queueItems[s.sequencedQueueItemsCount].txSize > s.maxSequencedTxsSize {
+		next := &s.queueItems[s.sequencedQueueItemsCount]
+		if next.startsBundle() {
+			s.bundleStart = s.sequencedQueueItemsCount
+			if s.sequencedTxsSizeSoFar+next.bundle.size > s.maxSequencedTxsSize {
+				for range next.bundle.txs {
+					s.sequencedQueueItemsCount += 1
+					s.TxFailed(core.ErrGasLimitReached)
+				}
+				s.txSizeLimitReached = true
+				continue
+			}
+		}
+		if s.sequencedTxsSizeSoFar+next.txSize > s.maxSequencedTxsSize {
 			s.sequencedQueueItemsCount += 1
 			s.TxFailed(core.ErrGasLimitReached)
 			s.txSizeLimitReached = true
@@ -1012,6 +1219,41 @@ func (s *FullSequencingHooks) NextTxToSequence() (*types.Transaction, *arbitrum_
 	return s.queueItems[s.sequencedQueueItemsCount-1].tx, s.queueItems[s.sequencedQueueItemsCount-1].options, nil
 }
 
+// BundleSize returns the number of transactions of the bundle started by the
+// last transaction returned by NextTxToSequence, or 0 if it doesn't start one.
+func (s *FullSequencingHooks) BundleSize() int {
+	if s.sequencedQueueItemsCount == 0 {
+		return 0
+	}
+	item := &s.queueItems[s.sequencedQueueItemsCount-1]
+	if !item.startsBundle() {
+		return 0
+	}
+	return len(item.bundle.txs)
+}
+
+// BundleFailed records the error for all transactions of the current bundle,
+// discarding the results of those which were already sequenced, and skips the
+// rest of them.
+func (s *FullSequencingHooks) BundleFailed(header *types.Header, err error) {
+	bundleEnd := s.bundleStart + len(s.queueItems[s.bundleStart].bundle.txs)
+	for i := s.bundleStart; i < len(s.txErrors); i++ {
+		if s.txErrors[i] == nil {
+			// The size of a sequenced transaction is accounted once the next one is requested
+			s.sequencedTxsSizeSoFar -= s.queueItems[i].txSize
+		}
+		s.txErrors[i] = err
+	}
+	s.txErrors = append(s.txErrors, err)
+	for s.sequencedQueueItemsCount < bundleEnd {
+		s.sequencedQueueItemsCount += 1
+		s.txErrors = append(s.txErrors, err)
+	}
+	if s.bundleFailed != nil {
+		s.bundleFailed(header)
+	}
+}
+
 func (s *FullSequencingHooks) CanDiscardTx() bool { return true }
 
 func (s *FullSequencingHooks) SupportsGroupRollback() bool { return true }
@@ -1130,20 +1372,37 @@ func (s *Sequencer) precheckNonces(queueItems []txQueueItem) []txQueueItem {
 	arbosVersion := types.DeserializeHeaderExtraInformation(latestHeader).ArbOSFormatVersion
 	signer := types.MakeSigner(bc.Config(), nextHeaderNumber, latestHeader.Time, arbosVersion)
 	outputQueueItems := make([]txQueueItem, 0, len(queueItems))
-	var nextQueueItem *txQueueItem
+	// revivedQueueItems are the items which failed their nonce checks earlier
+	// and whose predecessors have been seen, they are checked before the
+	// remaining queue items.
+	var revivedQueueItems []txQueueItem
 	var queueItemsIdx int
 	pendingNonces := make(map[common.Address]uint64)
 	for {
 		var queueItem txQueueItem
-		if nextQueueItem != nil {
-			queueItem = *nextQueueItem
-			nextQueueItem = nil
+		if len(revivedQueueItems) > 0 {
+			queueItem = revivedQueueItems[0]
+			revivedQueueItems = revivedQueueItems[1:]
 		} else if queueItemsIdx < len(queueItems) {
 			queueItem = queueItems[queueItemsIdx]
 			queueItemsIdx++
 		} else {
 			break
 		}
+		if queueItem.bundle != nil {
+			nextNonces, err := s.precheckBundleNonces(queueItem.bundle, signer, latestHeader, latestState, pendingNonces)
+			if err != nil {
+				queueItem.returnResult(err)
+				continue
+			}
+			for _, next := range nextNonces {
+				if revived := s.reviveNonceFailure(next.address, next.nonce); revived != nil {
+					revivedQueueItems = append(revivedQueueItems, *revived)
+				}
+			}
+			outputQueueItems = append(outputQueueItems, queueItem)
+			continue
+		}
 		tx := queueItem.tx
 		sender, err := types.Sender(signer, tx)
 		if err != nil {
@@ -1158,19 +1417,8 @@ func (s *Sequencer) precheckNonces(queueItems []txQueueItem) []txQueueItem {
 		txNonce := tx.Nonce()
 		if txNonce == pendingNonce {
 			pendingNonces[sender] = txNonce + 1
-			nextKey := addressAndNonce{sender, txNonce + 1}
-			revivingFailure, exists := s.nonceFailures.Get(nextKey)
-			if exists {
-				// This tx was the predecessor to one that had failed its nonce check
-				// Re-enqueue the tx whose nonce should now be correct, unless it expired
-				revivingFailure.revived = true
-				s.nonceFailures.Remove(nextKey)
-				err := revivingFailure.queueItem.ctx.Err()
-				if err != nil {
-					revivingFailure.queueItem.returnResult(err)
-				} else {
-					nextQueueItem = &revivingFailure.queueItem
-				}
+			if revived := s.reviveNonceFailure(sender, txNonce+1); revived != nil {
+				revivedQueueItems = append(revivedQueueItems, *revived)
 			}
 		} else if txNonce < stateNonce || txNonce > pendingNonce {
 			// It's impossible for this tx to succeed so far,
@@ -1203,6 +1451,109 @@ func (s *Sequencer) precheckNonces(queueItems []txQueueItem) []txQueueItem {
 	return outputQueueItems
 }
 
+// reviveNonceFailure returns the queue item which failed its nonce check with
+// the given sender and nonce, once its predecessor has been seen, unless it
+// expired in the meantime.
+func (s *Sequencer) reviveNonceFailure(sender common.Address, nonce uint64) *txQueueItem {
+	key := addressAndNonce{sender, nonce}
+	revivingFailure, exists := s.nonceFailures.Get(key)
+	if !exists {
+		return nil
+	}
+	revivingFailure.revived = true
+	s.nonceFailures.Remove(key)
+	if err := revivingFailure.queueItem.ctx.Err(); err != nil {
+		revivingFailure.queueItem.returnResult(err)
+		return nil
+	}
+	return &revivingFailure.queueItem
+}
+
+// precheckBundleNonces checks that the nonces of the bundle's transactions
+// follow the pending nonces of their senders, and updates the pending nonces
+// if they do. It returns the next nonce of each sender of the bundle, in the
+// order the senders appear in the bundle. Unlike
+// single transactions, bundles with too high nonces are rejected rather than
+// kept until their predecessors appear, here and when the block is built.
+func (s *Sequencer) precheckBundleNonces(bundle *txBundle, signer types.Signer, latestHeader *types.Header, latestState *state.StateDB, pendingNonces map[common.Address]uint64) ([]addressAndNonce, error) {
+	bundleNonces := make(map[common.Address]uint64)
+	var senders []common.Address
+	for _, tx := range bundle.txs {
+		sender, err := types.Sender(signer, tx)
+		if err != nil {
+			return nil, err
+		}
+		expectedNonce, ok := bundleNonces[sender]
+		if !ok {
+			senders = append(senders, sender)
+			expectedNonce, ok = pendingNonces[sender]
+			if !ok {
+				expectedNonce = s.nonceCache.Get(latestHeader, latestState, sender)
+			}
+		}
+		if err := MakeNonceError(sender, tx.Nonce(), expectedNonce); err != nil {
+			nonceCacheRejectedCounter.Inc(1)
+			return nil, fmt.Errorf("bundle transaction %v: %w", tx.Hash(), err)
+		}
+		bundleNonces[sender] = expectedNonce + 1
+	}
+	nextNonces := make([]addressAndNonce, 0, len(senders))
+	for _, sender := range senders {
+		pendingNonces[sender] = bundleNonces[sender]
+		nextNonces = append(nextNonces, addressAndNonce{sender, bundleNonces[sender]})
+	}
+	return nextNonces, nil
+}
+
+// orderQueueItems applies the ordering policy to the queue items which passed
+// the nonce checks, falling back to the queue order if the policy fails.
+func (s *Sequencer) orderQueueItems(queueItems []txQueueItem, lastBlock *types.Header) []txQueueItem {
+	if _, fifo := s.orderingPolicy.(FIFOOrderingPolicy); fifo || len(queueItems) < 2 {
+		return queueItems
+	}
+	if _, tipPriority := s.orderingPolicy.(TipPriorityOrderingPolicy); tipPriority {
+		// Without tip collection every transaction pays the base fee, so
+		// there is no price to order by.
+		collectTips, err := s.execEngine.CollectTips(lastBlock)
+		if err != nil {
+			log.Error("failed to read whether tips are collected, using queue order", "err", err)
+			return queueItems
+		}
+		if !collectTips {
+			return queueItems
+		}
+	}
+	signer := types.LatestSignerForChainID(s.execEngine.bc.Config().ChainID)
+	candidates := make([]OrderingCandidate, len(queueItems))
+	for i, queueItem := range queueItems {
+		// The sender has already been recovered by precheckNonces. A bundle is
+		// ordered as a transaction of the sender of its first transaction; if
+		// that moves another sender's transaction ahead of its predecessor in
+		// the bundle, it fails its nonce check and is retried after the bundle.
+		sender, err := types.Sender(signer, queueItem.tx)
+		if err != nil {
+			log.Error("failed to recover sender for ordering, using queue order", "tx", queueItem.tx.Hash(), "err", err)
+			return queueItems
+		}
+		candidates[i] = OrderingCandidate{
+			Tx:              queueItem.tx,
+			Sender:          sender,
+			Timeboosted:     queueItem.isTimeboosted,
+			FirstAppearance: queueItem.firstAppearance,
+		}
+	}
+	order := s.orderingPolicy.Order(candidates, lastBlock.BaseFee)
+	if err := validOrder(candidates, order); err != nil {
+		log.Error("ordering policy returned an invalid order, using queue order", "err", err)
+		return queueItems
+	}
+	ordered := make([]txQueueItem, len(queueItems))
+	for i, idx := range order {
+		ordered[i] = queueItems[idx]
+	}
+	return ordered
+}
+
 func (s *Sequencer) createBlock(ctx context.Context) (returnValue bool) {
 	var queueItems []txQueueItem
 
@@ -1222,6 +1573,7 @@ func (s *Sequencer) createBlock(ctx context.Context) (returnValue bool) {
 		}
 	}()
 	defer nonceFailureCacheSizeGauge.Update(int64(s.nonceFailures.Len()))
+	defer s.pendingTxs.prune()
 
 	config := s.config()
 	lastBlock := s.execEngine.bc.CurrentBlock()
@@ -1339,8 +1691,15 @@ func (s *Sequencer) createBlock(ctx context.Context) (returnValue bool) {
 			log.Info("Error sequencing timeboost tx", "err", err)
 			continue
 		}
-		if arbmath.BigLessThan(queueItem.tx.GasFeeCap(), lastBlock.BaseFee) {
-			queueItem.returnResult(fmt.Errorf("%w: maxFeePerGas: %s baseFee: %s", core.ErrFeeCapTooLow, queueItem.tx.GasFeeCap(), lastBlock.BaseFee))
+		feeCapTooLow := false
+		for _, tx := range queueItem.txs() {
+			if arbmath.BigLessThan(tx.GasFeeCap(), lastBlock.BaseFee) {
+				queueItem.returnResult(fmt.Errorf("%w: maxFeePerGas: %s baseFee: %s", core.ErrFeeCapTooLow, tx.GasFeeCap(), lastBlock.BaseFee))
+				feeCapTooLow = true
+				break
+			}
+		}
+		if feeCapTooLow {
 			continue
 		}
 		queueItems = append(queueItems, queueItem)
@@ -1349,15 +1708,25 @@ func (s *Sequencer) createBlock(ctx context.Context) (returnValue bool) {
 	s.nonceCache.Resize(config.NonceCacheSize) // Would probably be better in a config hook but this is basically free
 	s.nonceCache.BeginNewBlock()
 	queueItems = s.precheckNonces(queueItems)
+	queueItems = s.orderQueueItems(queueItems, lastBlock)
 	timeboostedTxs := make(map[common.Hash]struct{})
 	maxTxDataSize := s.config().MaxTxDataSize
+	hooksItems := make([]txQueueItem, 0, len(queueItems))
+	for i := range queueItems {
+		if queueItems[i].bundle != nil {
+			hooksItems = append(hooksItems, queueItems[i].expandBundle()...)
+		} else {
+			hooksItems = append(hooksItems, queueItems[i])
+		}
+	}
 	hooks := MakeSequencingHooks(
-		queueItems,
+		hooksItems,
 		maxTxDataSize,
 		s.preTxFilter,
 		s.postTxFilter,
 		nil,
 	)
+	hooks.bundleFailed = s.bundleFailed
 
 	for _, queueItem := range queueItems {
 		if queueItem.isTimeboosted {
@@ -1422,7 +1791,12 @@ func (s *Sequencer) createBlock(ctx context.Context) (returnValue bool) {
 			err = fmt.Errorf("unexpected number of error results: %v vs number of txes %v", len(hooks.txErrors), hooks.sequencedQueueItemsCount)
 		} else {
 			for i := hooks.sequencedQueueItemsCount; i < len(hooks.queueItems); i++ {
-				s.txRetryQueue.Push(hooks.queueItems[i])
+				item := &hooks.queueItems[i]
+				if item.bundle == nil {
+					s.txRetryQueue.Push(*item)
+				} else if item.startsBundle() {
+					s.txRetryQueue.Push(item.collapseBundle())
+				}
 			}
 		}
 	}
@@ -1462,8 +1836,18 @@ func (s *Sequencer) createBlock(ctx context.Context) (returnValue bool) {
 	madeBlock := false
 	var blockTxSize int64
 	blockGasLimitReached := false
-	for i, err := range hooks.txErrors {
-		queueItem := queueItems[i]
+	for i := 0; i < len(hooks.txErrors); i++ {
+		err := hooks.txErrors[i]
+		queueItem := hooks.queueItems[i]
+		if queueItem.bundle != nil {
+			// The transactions of a bundle share its result, which is the
+			// same for all of them.
+			i += len(queueItem.bundle.txs) - 1
+			queueItem = queueItem.collapseBundle()
+			if err == nil {
+				bundlesSequencedCounter.Inc(1)
+			}
+		}
 		if err == nil {
 			madeBlock = true
 			blockTxSize += int64(queueItem.txSize)
@@ -1483,10 +1867,13 @@ func (s *Sequencer) createBlock(ctx context.Context) (returnValue bool) {
 			err = core.ErrIntrinsicGas
 		}
 		var nonceError NonceError
-		if errors.As(err, &nonceError) && nonceError.txNonce > nonceError.stateNonce {
+		if queueItem.bundle == nil && errors.As(err, &nonceError) && nonceError.txNonce > nonceError.stateNonce {
 			s.nonceFailures.Add(nonceError, queueItem)
 			continue
 		}
+		if queueItem.bundle != nil && err != nil {
+			bundlesFailedCounter.Inc(1)
+		}
 		queueItem.returnResult(err)
 	}
 	if madeBlock {
@@ -1799,6 +2186,8 @@ func (s *Sequencer) StopAndWait() {
 				var err error
 				if src == TimeboostAuctionResolutionTxQueue {
 					err = forwarder.PublishAuctionResolutionTransaction(it.ctx, it.tx)
+				} else if it.bundle != nil {
+					err = forwarder.PublishBundle(it.ctx, it.bundle.txs)
 				} else {
 					err = forwarder.PublishTransaction(it.ctx, it.tx, it.options)
 				}
@@ -0,0 +1,177 @@
+// Copyright 2026, Offchain Labs, Inc.
+// For license information, see https://github.com/OffchainLabs/nitro/blob/master/LICENSE.md
+
+package gethexec
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/offchainlabs/nitro/util/containers"
+)
+
+func testQueueItems(t *testing.T, bundles ...[]uint64) []txQueueItem {
+	t.Helper()
+	var items []txQueueItem
+	for _, nonces := range bundles {
+		var txs types.Transactions
+		for _, nonce := range nonces {
+			txs = append(txs, types.NewTx(&types.LegacyTx{Nonce: nonce}))
+		}
+		if len(txs) == 1 {
+			items = append(items, txQueueItem{tx: txs[0], txSize: int(txs[0].Size())})
+			continue
+		}
+		bundle := &txBundle{txs: txs}
+		for _, tx := range txs {
+			bundle.size += int(tx.Size())
+		}
+		item := txQueueItem{tx: txs[0], txSize: bundle.size, bundle: bundle}
+		items = append(items, item.expandBundle()...)
+	}
+	return items
+}
+
+func nextNonce(t *testing.T, hooks *FullSequencingHooks) uint64 {
+	t.Helper()
+	tx, _, err := hooks.NextTxToSequence()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tx == nil {
+		t.Fatal("unexpected end of transactions")
+	}
+	return tx.Nonce()
+}
+
+func TestSequencingHooksBundleFailed(t *testing.T) {
+	items := testQueueItems(t, []uint64{0}, []uint64{1, 2, 3}, []uint64{4})
+	hooks := MakeSequencingHooks(items, 1<<20, nil, nil, nil)
+	var rolledBack bool
+	hooks.bundleFailed = func(*types.Header) { rolledBack = true }
+
+	if nonce := nextNonce(t, hooks); nonce != 0 || hooks.BundleSize() != 0 {
+		t.Fatalf("got nonce %d bundle size %d, want a single transaction with nonce 0", nonce, hooks.BundleSize())
+	}
+	hooks.TxSucceeded()
+	if nonce := nextNonce(t, hooks); nonce != 1 || hooks.BundleSize() != 3 {
+		t.Fatalf("got nonce %d bundle size %d, want a bundle of 3 starting with nonce 1", nonce, hooks.BundleSize())
+	}
+	hooks.TxSucceeded()
+	if nonce := nextNonce(t, hooks); nonce != 2 || hooks.BundleSize() != 0 {
+		t.Fatalf("got nonce %d bundle size %d, want the second transaction of the bundle", nonce, hooks.BundleSize())
+	}
+	bundleErr := errors.New("bundle transaction failed")
+	hooks.BundleFailed(nil, bundleErr)
+	if !rolledBack {
+		t.Error("bundle rollback callback not called")
+	}
+	// The last transaction of the bundle is skipped
+	if nonce := nextNonce(t, hooks); nonce != 4 {
+		t.Fatalf("got nonce %d, want 4", nonce)
+	}
+	hooks.TxSucceeded()
+	if tx, _, err := hooks.NextTxToSequence(); err != nil || tx != nil {
+		t.Fatalf("expected end of transactions, got %v, err: %v", tx, err)
+	}
+
+	errs := hooks.GetTxErrors()
+	if len(errs) != len(items) {
+		t.Fatalf("got %d results, want %d", len(errs), len(items))
+	}
+	for i, err := range errs {
+		inBundle := i >= 1 && i <= 3
+		if inBundle && !errors.Is(err, bundleErr) {
+			t.Errorf("result %d: got %v, want the bundle error", i, err)
+		}
+		if !inBundle && err != nil {
+			t.Errorf("result %d: unexpected error %v", i, err)
+		}
+	}
+	wantSize := items[0].txSize + items[4].txSize
+	if hooks.sequencedTxsSizeSoFar != wantSize {
+		t.Errorf("sequenced size %d, want %d", hooks.sequencedTxsSizeSoFar, wantSize)
+	}
+}
+
+func TestSequencingHooksBundleSizeLimit(t *testing.T) {
+	items := testQueueItems(t, []uint64{0}, []uint64{1, 2, 3}, []uint64{4})
+	// Only room for two single transactions, so the bundle doesn't fit as a whole
+	hooks := MakeSequencingHooks(items, items[0].txSize+items[4].txSize, nil, nil, nil)
+
+	if nonce := nextNonce(t, hooks); nonce != 0 {
+		t.Fatalf("got nonce %d, want 0", nonce)
+	}
+	hooks.TxSucceeded()
+	if nonce := nextNonce(t, hooks); nonce != 4 {
+		t.Fatalf("got nonce %d, want the bundle to be skipped", nonce)
+	}
+	hooks.TxSucceeded()
+	if tx, _, err := hooks.NextTxToSequence(); err != nil || tx != nil {
+		t.Fatalf("expected end of transactions, got %v, err: %v", tx, err)
+	}
+	for i, err := range hooks.GetTxErrors()[1:4] {
+		if !errors.Is(err, core.ErrGasLimitReached) {
+			t.Errorf("bundle transaction %d: got %v, want %v", i, err, core.ErrGasLimitReached)
+		}
+	}
+	if !hooks.txSizeLimitReached {
+		t.Error("expected the size limit to be reached")
+	}
+}
+
+func TestPrecheckBundleNoncesRevivesSuccessor(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sender := crypto.PubkeyToAddress(key.PublicKey)
+	signer := types.LatestSignerForChainID(big.NewInt(1))
+	signedTx := func(nonce uint64) *types.Transaction {
+		tx, err := types.SignNewTx(key, signer, &types.LegacyTx{Nonce: nonce, GasPrice: common.Big1})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return tx
+	}
+	s := &Sequencer{pendingTxs: newPendingTxTracker()}
+	s.nonceFailures = &nonceFailureCache{
+		containers.NewLruCache[addressAndNonce, *nonceFailure](8),
+		func() time.Duration { return time.Minute },
+		s.pendingTxs,
+	}
+
+	// The successor of the bundle arrived first and is waiting for its predecessor
+	successor := txQueueItem{tx: signedTx(2), ctx: context.Background(), firstAppearance: time.Now()}
+	s.nonceFailures.Add(NonceError{sender: sender, txNonce: 2, stateNonce: 0}, successor)
+
+	bundle := &txBundle{txs: types.Transactions{signedTx(0), signedTx(1)}}
+	pendingNonces := map[common.Address]uint64{sender: 0}
+	nextNonces, err := s.precheckBundleNonces(bundle, signer, nil, nil, pendingNonces)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(nextNonces) != 1 || nextNonces[0] != (addressAndNonce{sender, 2}) || pendingNonces[sender] != 2 {
+		t.Fatalf("got next nonces %v and pending nonce %d, want 2", nextNonces, pendingNonces[sender])
+	}
+	revived := s.reviveNonceFailure(sender, 2)
+	if revived == nil || revived.tx.Nonce() != 2 {
+		t.Fatalf("expected the successor of the bundle to be revived, got %v", revived)
+	}
+	if s.nonceFailures.Contains(NonceError{sender: sender, txNonce: 2}) {
+		t.Error("revived successor is still waiting")
+	}
+
+	// A bundle with a too high nonce is rejected instead of waiting
+	if _, err := s.precheckBundleNonces(&txBundle{txs: types.Transactions{signedTx(4)}}, signer, nil, nil, pendingNonces); !errors.Is(err, core.ErrNonceTooHigh) {
+		t.Fatalf("got %v, want %v", err, core.ErrNonceTooHigh)
+	}
+}
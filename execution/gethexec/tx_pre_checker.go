@@ -42,9 +42,10 @@ const TxPreCheckerStrictnessLikelyCompatible uint = 20
 const TxPreCheckerStrictnessFullValidation uint = 30
 
 type TxPreCheckerConfig struct {
-	Strictness             uint  `koanf:"strictness" reload:"hot"`
-	RequiredStateAge       int64 `koanf:"required-state-age" reload:"hot"`
-	RequiredStateMaxBlocks uint  `koanf:"required-state-max-blocks" reload:"hot"`
+	Strictness             uint              `koanf:"strictness" reload:"hot"`
+	RequiredStateAge       int64             `koanf:"required-state-age" reload:"hot"`
+	RequiredStateMaxBlocks uint              `koanf:"required-state-max-blocks" reload:"hot"`
+	RateLimit              TxRateLimitConfig `koanf:"rate-limit" reload:"hot"`
 }
 
 type TxPreCheckerConfigFetcher func() *TxPreCheckerConfig
@@ -53,6 +54,7 @@ var DefaultTxPreCheckerConfig = TxPreCheckerConfig{
 	Strictness:             TxPreCheckerStrictnessLikelyCompatible,
 	RequiredStateAge:       2,
 	RequiredStateMaxBlocks: 4,
+	RateLimit:              DefaultTxRateLimitConfig,
 }
 
 func TxPreCheckerConfigAddOptions(prefix string, f *pflag.FlagSet) {
@@ -61,6 +63,11 @@ func TxPreCheckerConfigAddOptions(prefix string, f *pflag.FlagSet) {
 		"30 = full validation which may reject txs that would succeed")
 	f.Int64(prefix+".required-state-age", DefaultTxPreCheckerConfig.RequiredStateAge, "how long ago should the storage conditions from eth_SendRawTransactionConditional be true, 0 = don't check old state")
 	f.Uint(prefix+".required-state-max-blocks", DefaultTxPreCheckerConfig.RequiredStateMaxBlocks, "maximum number of blocks to look back while looking for the <required-state-age> seconds old state, 0 = don't limit the search")
+	TxRateLimitConfigAddOptions(prefix+".rate-limit", f)
+}
+
+func (c *TxPreCheckerConfig) Validate() error {
+	return c.RateLimit.Validate()
 }
 
 type TxPreChecker struct {
@@ -69,6 +76,7 @@ type TxPreChecker struct {
 	config             TxPreCheckerConfigFetcher
 	expressLaneTracker *timeboost.ExpressLaneTracker
 	backend            core.NodeInterfaceBackendAPI
+	rateLimiter        *txRateLimiter
 }
 
 func NewTxPreChecker(
@@ -79,6 +87,7 @@ func NewTxPreChecker(
 		TransactionPublisher: publisher,
 		bc:                   bc,
 		config:               config,
+		rateLimiter:          newTxRateLimiter(func() *TxRateLimitConfig { return &config().RateLimit }),
 	}
 }
 
@@ -222,7 +231,29 @@ func PreCheckTx(bc *core.BlockChain, chainConfig *params.ChainConfig, header *ty
 	return nil
 }
 
+// checkRateLimit applies the rate limits of the senders of the transactions
+// and of the source IP of the request. The error is returned unwrapped so
+// that the RPC server reports its error code.
+func (c *TxPreChecker) checkRateLimit(ctx context.Context, txs types.Transactions) error {
+	if !c.config().RateLimit.Enable {
+		return nil
+	}
+	signer := types.LatestSigner(c.bc.Config())
+	senders := make([]common.Address, 0, len(txs))
+	for _, tx := range txs {
+		sender, err := types.Sender(signer, tx)
+		if err != nil {
+			return err
+		}
+		senders = append(senders, sender)
+	}
+	return c.rateLimiter.allow(ctx, senders)
+}
+
 func (c *TxPreChecker) PublishTransaction(ctx context.Context, tx *types.Transaction, options *arbitrum_types.ConditionalOptions) error {
+	if err := c.checkRateLimit(ctx, types.Transactions{tx}); err != nil {
+		return err
+	}
 	block := c.bc.CurrentBlock()
 	statedb, err := c.bc.StateAt(block.Root)
 	if err != nil {
@@ -242,6 +273,35 @@ func (c *TxPreChecker) PublishTransaction(ctx context.Context, tx *types.Transac
 	return c.TransactionPublisher.PublishTransaction(ctx, tx, options)
 }
 
+// PublishBundle pre-checks the transactions of the bundle individually. Since
+// they may depend on each other's state changes, only the checks which don't
+// depend on the sender's nonce and balance are applied.
+func (c *TxPreChecker) PublishBundle(ctx context.Context, txs types.Transactions) error {
+	if err := c.checkRateLimit(ctx, txs); err != nil {
+		return err
+	}
+	block := c.bc.CurrentBlock()
+	statedb, err := c.bc.StateAt(block.Root)
+	if err != nil {
+		return err
+	}
+	arbos, err := arbosState.OpenSystemArbosState(statedb, nil, true)
+	if err != nil {
+		return err
+	}
+	config := *c.config()
+	config.Strictness = min(config.Strictness, TxPreCheckerStrictnessAlwaysCompatible)
+	for _, tx := range txs {
+		if err := PreCheckTx(c.bc, c.bc.Config(), block, statedb, arbos, tx, nil, &config); err != nil {
+			return fmt.Errorf("bundle transaction %v: %w", tx.Hash(), err)
+		}
+		if err := c.checkFilteredAddresses(ctx, tx, block); err != nil {
+			return err
+		}
+	}
+	return c.TransactionPublisher.PublishBundle(ctx, txs)
+}
+
 func (c *TxPreChecker) PublishExpressLaneTransaction(ctx context.Context, msg *timeboost.ExpressLaneSubmission) error {
 	if msg == nil || msg.Transaction == nil {
 		return timeboost.ErrMalformedData
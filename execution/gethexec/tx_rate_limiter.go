@@ -0,0 +1,275 @@
+// Copyright 2026, Offchain Labs, Inc.
+// For license information, see https://github.com/OffchainLabs/nitro/blob/master/LICENSE.md
+
+package gethexec
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/netip"
+	"sync"
+	"time"
+
+	"github.com/spf13/pflag"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/offchainlabs/nitro/util/containers"
+)
+
+var (
+	senderRateLimitedCounter = metrics.NewRegisteredCounter("arb/txprechecker/ratelimited/sender", nil)
+	ipRateLimitedCounter     = metrics.NewRegisteredCounter("arb/txprechecker/ratelimited/ip", nil)
+)
+
+// TxRateLimitedErrorCode is the JSON-RPC error code of TxRateLimitedError,
+// the "limit exceeded" code of EIP-1474.
+const TxRateLimitedErrorCode = -32005
+
+// TxRateLimitedError is returned when a transaction submission exceeds the
+// rate limit of its sender or source IP.
+type TxRateLimitedError struct {
+	limit      string
+	key        string
+	retryAfter time.Duration
+}
+
+func (e *TxRateLimitedError) Error() string {
+	return fmt.Sprintf("transaction rate limit exceeded for %s %s, retry in %v", e.limit, e.key, e.retryAfter)
+}
+
+func (e *TxRateLimitedError) ErrorCode() int {
+	return TxRateLimitedErrorCode
+}
+
+func (e *TxRateLimitedError) ErrorData() interface{} {
+	return map[string]interface{}{"retryAfterMs": e.retryAfter.Milliseconds()}
+}
+
+type TxRateLimitConfig struct {
+	Enable         bool     `koanf:"enable" reload:"hot"`
+	SenderRate     float64  `koanf:"sender-rate" reload:"hot"`
+	SenderBurst    int      `koanf:"sender-burst" reload:"hot"`
+	IPRate         float64  `koanf:"ip-rate" reload:"hot"`
+	IPBurst        int      `koanf:"ip-burst" reload:"hot"`
+	ExemptSenders  []string `koanf:"exempt-senders" reload:"hot"`
+	ExemptIPs      []string `koanf:"exempt-ips" reload:"hot"`
+	MaxTrackedKeys int      `koanf:"max-tracked-keys" reload:"hot"`
+}
+
+var DefaultTxRateLimitConfig = TxRateLimitConfig{
+	Enable:         false,
+	SenderRate:     10,
+	SenderBurst:    100,
+	IPRate:         50,
+	IPBurst:        500,
+	ExemptSenders:  []string{},
+	ExemptIPs:      []string{},
+	MaxTrackedKeys: 100_000,
+}
+
+func TxRateLimitConfigAddOptions(prefix string, f *pflag.FlagSet) {
+	f.Bool(prefix+".enable", DefaultTxRateLimitConfig.Enable, "enable rate limiting of transaction submissions per sender and per source IP")
+	f.Float64(prefix+".sender-rate", DefaultTxRateLimitConfig.SenderRate, "transactions per second allowed per sender address (0 = unlimited)")
+	f.Int(prefix+".sender-burst", DefaultTxRateLimitConfig.SenderBurst, "maximum burst of transactions allowed per sender address")
+	f.Float64(prefix+".ip-rate", DefaultTxRateLimitConfig.IPRate, "transactions per second allowed per source IP (0 = unlimited)")
+	f.Int(prefix+".ip-burst", DefaultTxRateLimitConfig.IPBurst, "maximum burst of transactions allowed per source IP")
+	f.StringSlice(prefix+".exempt-senders", DefaultTxRateLimitConfig.ExemptSenders, "comma separated list of sender addresses exempt from rate limiting")
+	f.StringSlice(prefix+".exempt-ips", DefaultTxRateLimitConfig.ExemptIPs, "comma separated list of source IPs or CIDR ranges exempt from rate limiting, such as the nodes forwarding transactions to the sequencer")
+	f.Int(prefix+".max-tracked-keys", DefaultTxRateLimitConfig.MaxTrackedKeys, "maximum number of senders and of IPs whose rate limit is tracked, the least recently seen ones are forgotten")
+}
+
+func (c *TxRateLimitConfig) Validate() error {
+	if c.SenderRate < 0 || c.IPRate < 0 {
+		return fmt.Errorf("tx rate limit rates cannot be negative, got sender-rate %v ip-rate %v", c.SenderRate, c.IPRate)
+	}
+	if c.SenderRate > 0 && c.SenderBurst < 1 {
+		return fmt.Errorf("tx rate limit sender-burst must be at least 1, got %d", c.SenderBurst)
+	}
+	if c.IPRate > 0 && c.IPBurst < 1 {
+		return fmt.Errorf("tx rate limit ip-burst must be at least 1, got %d", c.IPBurst)
+	}
+	if c.Enable && c.MaxTrackedKeys < 1 {
+		return fmt.Errorf("tx rate limit max-tracked-keys must be at least 1, got %d", c.MaxTrackedKeys)
+	}
+	_, err := parseTxRateLimitExemptions(c)
+	return err
+}
+
+type txRateLimitExemptions struct {
+	senders map[common.Address]struct{}
+	ips     []netip.Prefix
+}
+
+func parseTxRateLimitExemptions(c *TxRateLimitConfig) (*txRateLimitExemptions, error) {
+	exemptions := &txRateLimitExemptions{senders: make(map[common.Address]struct{})}
+	for _, sender := range c.ExemptSenders {
+		if len(sender) == 0 {
+			continue
+		}
+		if !common.IsHexAddress(sender) {
+			return nil, fmt.Errorf("tx rate limit exempt sender \"%v\" is not a valid address", sender)
+		}
+		exemptions.senders[common.HexToAddress(sender)] = struct{}{}
+	}
+	for _, ip := range c.ExemptIPs {
+		if len(ip) == 0 {
+			continue
+		}
+		prefix, err := netip.ParsePrefix(ip)
+		if err != nil {
+			addr, addrErr := netip.ParseAddr(ip)
+			if addrErr != nil {
+				return nil, fmt.Errorf("tx rate limit exempt ip \"%v\" is not a valid IP or CIDR range", ip)
+			}
+			prefix = netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen())
+		}
+		exemptions.ips = append(exemptions.ips, prefix.Masked())
+	}
+	return exemptions, nil
+}
+
+func (e *txRateLimitExemptions) exemptIP(ip netip.Addr) bool {
+	for _, prefix := range e.ips {
+		if prefix.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// take refills the bucket at rate tokens per second up to burst, and takes n
+// tokens from it if there are enough. Otherwise it returns how long it takes
+// until there are.
+func (b *tokenBucket) take(now time.Time, rate float64, burst int, n int) (bool, time.Duration) {
+	b.tokens = min(b.tokens+now.Sub(b.last).Seconds()*rate, float64(burst))
+	b.last = now
+	if b.tokens >= float64(n) {
+		b.tokens -= float64(n)
+		return true, 0
+	}
+	missing := float64(n) - b.tokens
+	return false, time.Duration(missing / rate * float64(time.Second))
+}
+
+type txRateLimiterBuckets[K comparable] struct {
+	buckets *containers.LruCache[K, *tokenBucket]
+}
+
+func (l *txRateLimiterBuckets[K]) take(key K, now time.Time, rate float64, burst int, maxKeys int, n int) (bool, time.Duration) {
+	if l.buckets == nil {
+		l.buckets = containers.NewLruCache[K, *tokenBucket](maxKeys)
+	} else if l.buckets.Size() != maxKeys {
+		l.buckets.Resize(maxKeys)
+	}
+	bucket, ok := l.buckets.Get(key)
+	if !ok {
+		bucket = &tokenBucket{tokens: float64(burst), last: now}
+		l.buckets.Add(key, bucket)
+	}
+	return bucket.take(now, rate, burst, n)
+}
+
+// txRateLimiter applies token-bucket rate limits to transaction submissions
+// per sender address and per source IP. Its config is hot-reloadable.
+type txRateLimiter struct {
+	config func() *TxRateLimitConfig
+	now    func() time.Time
+
+	mutex            sync.Mutex
+	exemptions       *txRateLimitExemptions
+	exemptionsConfig *TxRateLimitConfig
+	senders          txRateLimiterBuckets[common.Address]
+	ips              txRateLimiterBuckets[netip.Addr]
+}
+
+func newTxRateLimiter(config func() *TxRateLimitConfig) *txRateLimiter {
+	return &txRateLimiter{
+		config: config,
+		now:    time.Now,
+	}
+}
+
+// updateExemptions parses the exemptions again if the config was reloaded.
+// The mutex must be held.
+func (l *txRateLimiter) updateExemptions(config *TxRateLimitConfig) {
+	if l.exemptionsConfig == config {
+		return
+	}
+	exemptions, err := parseTxRateLimitExemptions(config)
+	if err != nil {
+		// Unreachable as the config is validated
+		exemptions = &txRateLimitExemptions{}
+	}
+	l.exemptions = exemptions
+	l.exemptionsConfig = config
+}
+
+// sourceIP returns the IP the RPC request came from, if any.
+func sourceIP(ctx context.Context) (netip.Addr, bool) {
+	remoteAddr := rpc.PeerInfoFromContext(ctx).RemoteAddr
+	if remoteAddr == "" {
+		return netip.Addr{}, false
+	}
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		host = remoteAddr
+	}
+	ip, err := netip.ParseAddr(host)
+	if err != nil {
+		return netip.Addr{}, false
+	}
+	return ip.Unmap(), true
+}
+
+// allow takes n tokens from the buckets of the senders and of the source IP
+// of the request, returning a TxRateLimitedError if any of them is exhausted.
+// A bundle counts as one submission per transaction for each of its senders.
+func (l *txRateLimiter) allow(ctx context.Context, senders []common.Address) error {
+	config := l.config()
+	if !config.Enable {
+		return nil
+	}
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.updateExemptions(config)
+	now := l.now()
+
+	if config.IPRate > 0 {
+		if ip, ok := sourceIP(ctx); ok && !l.exemptions.exemptIP(ip) {
+			if ok, retryAfter := l.ips.take(ip, now, config.IPRate, config.IPBurst, config.MaxTrackedKeys, len(senders)); !ok {
+				ipRateLimitedCounter.Inc(1)
+				return &TxRateLimitedError{limit: "source ip", key: ip.String(), retryAfter: retryAfter}
+			}
+		}
+	}
+	if config.SenderRate > 0 {
+		counts := make(map[common.Address]int)
+		for _, sender := range senders {
+			counts[sender]++
+		}
+		for _, sender := range senders {
+			n, ok := counts[sender]
+			if !ok {
+				continue
+			}
+			delete(counts, sender)
+			if _, exempt := l.exemptions.senders[sender]; exempt {
+				continue
+			}
+			if ok, retryAfter := l.senders.take(sender, now, config.SenderRate, config.SenderBurst, config.MaxTrackedKeys, n); !ok {
+				senderRateLimitedCounter.Inc(1)
+				return &TxRateLimitedError{limit: "sender", key: sender.Hex(), retryAfter: retryAfter}
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+// Copyright 2026, Offchain Labs, Inc.
+// For license information, see https://github.com/OffchainLabs/nitro/blob/master/LICENSE.md
+
+package gethexec
+
+import (
+	"context"
+	"errors"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestTxRateLimiter(t *testing.T) {
+	config := DefaultTxRateLimitConfig
+	config.Enable = true
+	config.SenderRate = 2
+	config.SenderBurst = 3
+	config.ExemptSenders = []string{"0x0000000000000000000000000000000000000002"}
+	limiter := newTxRateLimiter(func() *TxRateLimitConfig { return &config })
+	now := time.Unix(1_700_000_000, 0)
+	limiter.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	limited := common.Address{19: 1}
+	exempt := common.Address{19: 2}
+	for i := 0; i < 3; i++ {
+		if err := limiter.allow(ctx, []common.Address{limited}); err != nil {
+			t.Fatalf("submission %d within the burst rejected: %v", i, err)
+		}
+	}
+	err := limiter.allow(ctx, []common.Address{limited})
+	var rateLimitedErr *TxRateLimitedError
+	if !errors.As(err, &rateLimitedErr) {
+		t.Fatalf("got %v, want a rate limit error", err)
+	}
+	if rateLimitedErr.ErrorCode() != TxRateLimitedErrorCode || rateLimitedErr.retryAfter != 500*time.Millisecond {
+		t.Errorf("unexpected error code %d or retry after %v", rateLimitedErr.ErrorCode(), rateLimitedErr.retryAfter)
+	}
+	for i := 0; i < 10; i++ {
+		if err := limiter.allow(ctx, []common.Address{exempt}); err != nil {
+			t.Fatalf("exempt sender rejected: %v", err)
+		}
+	}
+
+	// Refilled at 2 tokens per second
+	now = now.Add(time.Second)
+	if err := limiter.allow(ctx, []common.Address{limited, limited}); err != nil {
+		t.Fatalf("refilled submissions rejected: %v", err)
+	}
+	if err := limiter.allow(ctx, []common.Address{limited}); err == nil {
+		t.Fatal("expected submission beyond the refill to be rejected")
+	}
+
+	// A bundle needs a token per transaction of each sender
+	now = now.Add(time.Hour)
+	if err := limiter.allow(ctx, []common.Address{limited, exempt, limited, limited, limited}); err == nil {
+		t.Fatal("expected bundle larger than the burst to be rejected")
+	}
+
+	// Hot reloading the config updates the limits and the exemptions
+	reloaded := config
+	reloaded.ExemptSenders = []string{limited.Hex()}
+	limiter.config = func() *TxRateLimitConfig { return &reloaded }
+	if err := limiter.allow(ctx, []common.Address{limited, limited, limited, limited}); err != nil {
+		t.Fatalf("newly exempt sender rejected: %v", err)
+	}
+	reloaded.Enable = false
+	if err := limiter.allow(ctx, []common.Address{exempt}); err != nil {
+		t.Fatalf("submission rejected with rate limiting disabled: %v", err)
+	}
+}
+
+func TestTxRateLimitExemptions(t *testing.T) {
+	config := DefaultTxRateLimitConfig
+	config.ExemptIPs = []string{"10.0.0.0/8", "192.168.1.1", "::ffff:172.16.0.1", "2001:db8::/32"}
+	if err := config.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	exemptions, err := parseTxRateLimitExemptions(&config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for ip, want := range map[string]bool{
+		"10.1.2.3":    true,
+		"192.168.1.1": true,
+		"192.168.1.2": false,
+		"172.16.0.1":  true,
+		"2001:db8::1": true,
+		"2001:db9::1": false,
+	} {
+		if got := exemptions.exemptIP(netip.MustParseAddr(ip)); got != want {
+			t.Errorf("ip %v exempt: got %v, want %v", ip, got, want)
+		}
+	}
+
+	for _, invalid := range []TxRateLimitConfig{
+		{SenderRate: -1},
+		{SenderRate: 1, SenderBurst: 0},
+		{IPRate: 1, IPBurst: 0},
+		{Enable: true, MaxTrackedKeys: 0},
+		{ExemptSenders: []string{"0x1234"}},
+		{ExemptIPs: []string{"10.0.0.0/33"}},
+	} {
+		if err := invalid.Validate(); err == nil {
+			t.Errorf("expected config %+v to be invalid", invalid)
+		}
+	}
+}
@@ -70,6 +70,7 @@ type ExecutionRecorder interface {
 	RecordBlockCreation(
 		pos arbutil.MessageIndex,
 		msg *arbostypes.MessageWithMetadata,
+		posInBatch uint64,
 		wasmTargets []rawdb.WasmTarget,
 	) containers.PromiseInterface[*RecordResult]
 	PrepareForRecord(start, end arbutil.MessageIndex) containers.PromiseInterface[struct{}]
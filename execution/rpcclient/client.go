@@ -106,8 +106,8 @@ func (c *Client) ArbOSVersionForMessageIndex(msgIdx arbutil.MessageIndex) contai
 	return sendRequest[uint64](c, "_arbOSVersionForMessageIndex", msgIdx)
 }
 
-func (c *Client) RecordBlockCreation(pos arbutil.MessageIndex, msg *arbostypes.MessageWithMetadata, wasmTargets []rawdb.WasmTarget) containers.PromiseInterface[*execution.RecordResult] {
-	return sendRequest[*execution.RecordResult](c, "_recordBlockCreation", pos, msg, wasmTargets)
+func (c *Client) RecordBlockCreation(pos arbutil.MessageIndex, msg *arbostypes.MessageWithMetadata, posInBatch uint64, wasmTargets []rawdb.WasmTarget) containers.PromiseInterface[*execution.RecordResult] {
+	return sendRequest[*execution.RecordResult](c, "_recordBlockCreation", pos, msg, posInBatch, wasmTargets)
 }
 
 func (c *Client) PrepareForRecord(start, end arbutil.MessageIndex) containers.PromiseInterface[struct{}] {
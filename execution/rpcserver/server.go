@@ -69,8 +69,8 @@ func (c *Server) ArbOSVersionForMessageIndex(ctx context.Context, msgIdx arbutil
 	return c.executionClient.ArbOSVersionForMessageIndex(msgIdx).Await(ctx)
 }
 
-func (c *Server) RecordBlockCreation(ctx context.Context, pos arbutil.MessageIndex, msg *arbostypes.MessageWithMetadata, wasmTargets []rawdb.WasmTarget) (*execution.RecordResult, error) {
-	return c.executionRecorder.RecordBlockCreation(pos, msg, wasmTargets).Await(ctx)
+func (c *Server) RecordBlockCreation(ctx context.Context, pos arbutil.MessageIndex, msg *arbostypes.MessageWithMetadata, posInBatch uint64, wasmTargets []rawdb.WasmTarget) (*execution.RecordResult, error) {
+	return c.executionRecorder.RecordBlockCreation(pos, msg, posInBatch, wasmTargets).Await(ctx)
 }
 
 func (c *Server) PrepareForRecord(ctx context.Context, start, end arbutil.MessageIndex) error {
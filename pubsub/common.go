@@ -21,6 +21,9 @@ func ResultKeyFor(streamName, id string) string {
 func ErrorKeyFor(streamName, id string) string {
 	return fmt.Sprintf("error-key:%s.%s.error", streamName, id)
 }
+func FailureKeyFor(streamName, id string) string {
+	return fmt.Sprintf("failure-key:%s.%s", streamName, id)
+}
 
 // CreateStream tries to create stream with given name, if it already exists
 // does not return an error.
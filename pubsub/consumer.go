@@ -7,9 +7,8 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"math"
 	"math/rand"
-	"strconv"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
@@ -31,6 +30,13 @@ type ConsumerConfig struct {
 	Retry bool `koanf:"retry"`
 	// Number of message retries after which we set error response
 	MaxRetryCount int64 `koanf:"max-retry-count"`
+	// Number of deliveries after which a request is moved to the dead-letter
+	// stream and an error response is set
+	MaxDeliveryAttempts int64 `koanf:"max-delivery-attempts"`
+	// Number of priority lanes, each of which is a separate stream.
+	PriorityLanes int `koanf:"priority-lanes"`
+	// Weights of the priority lanes, from the lowest to the highest.
+	LaneWeights []int `koanf:"lane-weights"`
 }
 
 var DefaultConsumerConfig = ConsumerConfig{
@@ -38,6 +44,9 @@ var DefaultConsumerConfig = ConsumerConfig{
 	IdletimeToAutoclaim:  5 * time.Minute,
 	Retry:                true,
 	MaxRetryCount:        -1,
+	MaxDeliveryAttempts:  0,
+	PriorityLanes:        1,
+	LaneWeights:          nil,
 }
 
 var TestConsumerConfig = ConsumerConfig{
@@ -45,8 +54,49 @@ var TestConsumerConfig = ConsumerConfig{
 	IdletimeToAutoclaim:  30 * time.Millisecond,
 	Retry:                true,
 	MaxRetryCount:        -1,
+	MaxDeliveryAttempts:  0,
+	PriorityLanes:        1,
+	LaneWeights:          nil,
 }
 
+func (c *ConsumerConfig) Validate() error {
+	if c.MaxDeliveryAttempts < 0 {
+		return fmt.Errorf("max-delivery-attempts must not be negative, got %d", c.MaxDeliveryAttempts)
+	}
+	if c.PriorityLanes < 1 {
+		return fmt.Errorf("priority-lanes must be at least 1, got %d", c.PriorityLanes)
+	}
+	if len(c.LaneWeights) == 0 {
+		return nil
+	}
+	if len(c.LaneWeights) != c.PriorityLanes {
+		return fmt.Errorf("lane-weights must have one weight for each of the %d priority lanes, got %d", c.PriorityLanes, len(c.LaneWeights))
+	}
+	total := 0
+	for _, weight := range c.LaneWeights {
+		if weight < 0 {
+			return fmt.Errorf("lane-weights must not be negative, got %d", weight)
+		}
+		total += weight
+	}
+	if total == 0 {
+		return errors.New("lane-weights must not all be 0")
+	}
+	return nil
+}
+
+// laneWeight returns the configured weight of the lane, by default 2^lane.
+func (c *ConsumerConfig) laneWeight(lane int) int {
+	if len(c.LaneWeights) == 0 {
+		return 1 << lane
+	}
+	return c.LaneWeights[lane]
+}
+
+// laneRecheckInterval is how often consumers check whether the streams of
+// priority lanes which did not exist have been created.
+const laneRecheckInterval = 10 * time.Second
+
 var ErrAlreadySet = errors.New("redis key already set")
 
 func ConsumerConfigAddOptions(prefix string, f *pflag.FlagSet) {
@@ -58,22 +108,34 @@ func ConsumerConfigAddOptionsWithDefaults(prefix string, f *pflag.FlagSet, defau
 	f.Duration(prefix+".idletime-to-autoclaim", defaultConfig.IdletimeToAutoclaim, "After a message spends this amount of time in PEL (Pending Entries List i.e claimed by another consumer but not Acknowledged) it will be allowed to be autoclaimed by other consumers. This option should be set to the same value for all consumers and producers.")
 	f.Bool(prefix+".retry", defaultConfig.Retry, "enables autoclaim for this consumer, if set to false this consumer will not check messages from PEL (Pending Entries List)")
 	f.Int64(prefix+".max-retry-count", defaultConfig.MaxRetryCount, "number of message retries after which this consumer will set an error response and Acknowledge the message (-1 = no limit)")
+	f.Int64(prefix+".max-delivery-attempts", defaultConfig.MaxDeliveryAttempts, "number of deliveries after which a request is moved to the dead-letter stream and an error response is set, so that a request crashing consumers cannot stall them (0 = disabled)")
+	f.Int(prefix+".priority-lanes", defaultConfig.PriorityLanes, "number of priority lanes, requests of higher priority lanes are consumed first; should be set to the same value for all consumers and producers")
+	f.IntSlice(prefix+".lane-weights", defaultConfig.LaneWeights, "weights of the priority lanes from the lowest to the highest; each lane is read first in proportion to its weight, so that lower lanes are not starved, and lanes of weight 0 only when the others are empty (default lane i has weight 2^i)")
 }
 
-// Consumer implements a consumer for redis stream provides heartbeat to
+// Consumer implements a consumer for a pubsub stream provides heartbeat to
 // indicate it is alive.
 type Consumer[Request any, Response any] struct {
 	stopwaiter.StopWaiter
 	id          string
-	client      redis.UniversalClient
+	transport   Transport
 	redisStream string
-	redisGroup  string
 	cfg         *ConsumerConfig
 
 	// Idle messages will be reclaimed randomly from the oldest idle N messages.
 	// Note: Not exposed as a configuration option because it affects semantic
 	// correctness for for some use cases.
 	claimAmongOldestIdleN int64
+
+	lanesMutex sync.Mutex
+	lanes      []laneState
+}
+
+type laneState struct {
+	exists  bool
+	checked time.Time
+	// credit of the smooth weighted round robin choosing the lane read first
+	credit int
 }
 
 type Message[Request any] struct {
@@ -83,17 +145,24 @@ type Message[Request any] struct {
 }
 
 func NewConsumer[Request any, Response any](client redis.UniversalClient, streamName string, cfg *ConsumerConfig) (*Consumer[Request, Response], error) {
+	return NewConsumerWithTransport[Request, Response](NewRedisTransport(client), streamName, cfg)
+}
+
+func NewConsumerWithTransport[Request any, Response any](transport Transport, streamName string, cfg *ConsumerConfig) (*Consumer[Request, Response], error) {
 	if streamName == "" {
-		return nil, fmt.Errorf("redis stream name cannot be empty")
+		return nil, fmt.Errorf("stream name cannot be empty")
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
 	}
 	return &Consumer[Request, Response]{
 		id:          uuid.NewString(),
-		client:      client,
+		transport:   transport,
 		redisStream: streamName,
-		redisGroup:  streamName, // There is 1-1 mapping of redis stream and consumer group.
 		cfg:         cfg,
 
 		claimAmongOldestIdleN: 50, // Default for most use cases.
+		lanes:                 make([]laneState, cfg.PriorityLanes),
 	}, nil
 }
 
@@ -115,53 +184,120 @@ func (c *Consumer[Request, Response]) StopAndWait() {
 	c.StopWaiter.StopAndWait()
 }
 
+// RedisClient returns the redis client of the consumer, or nil if it does
+// not use redis streams.
 func (c *Consumer[Request, Response]) RedisClient() redis.UniversalClient {
-	return c.client
+	if t, ok := c.transport.(*RedisTransport); ok {
+		return t.Client()
+	}
+	return nil
 }
 
 func (c *Consumer[Request, Response]) StreamName() string {
 	return c.redisStream
 }
 
-func decrementMsgIdByOne(msgId string) string {
-	id, err := getUintParts(msgId)
-	if err != nil {
-		log.Error("Error decrementing start of XAutoClaim by one, defaulting to 0", "err", err)
-		return "0"
+// StreamExists returns whether the consumer's stream exists.
+func (c *Consumer[Request, Response]) StreamExists(ctx context.Context) bool {
+	return c.transport.StreamExists(ctx, c.redisStream)
+}
+
+// laneExists returns whether the stream of the priority lane exists, checking
+// missing streams at most every laneRecheckInterval. The lowest lane is the
+// consumer's stream, which callers wait for before consuming.
+func (c *Consumer[Request, Response]) laneExists(ctx context.Context, lane int) bool {
+	if lane == 0 {
+		return true
 	}
-	if id[1] > 0 {
-		return strconv.FormatUint(id[0], 10) + "-" + strconv.FormatUint(id[1]-1, 10)
-	} else if id[0] > 0 {
-		return strconv.FormatUint(id[0]-1, 10) + "-" + strconv.FormatUint(math.MaxUint64, 10)
+	c.lanesMutex.Lock()
+	defer c.lanesMutex.Unlock()
+	state := &c.lanes[lane]
+	if state.exists || (!state.checked.IsZero() && time.Since(state.checked) < laneRecheckInterval) {
+		return state.exists
 	}
-	return "0"
+	state.exists = c.transport.StreamExists(ctx, LaneStreamName(c.redisStream, lane))
+	state.checked = time.Now()
+	return state.exists
 }
 
-// Consumer first checks it there exists pending message that is claimed by
-// unresponsive consumer, if not then reads from the stream.
+// laneOrder returns the order in which the lanes are read: the lane chosen by
+// a smooth weighted round robin over the lane weights first, and then the
+// others from the highest to the lowest.
+func (c *Consumer[Request, Response]) laneOrder() []int {
+	first := c.cfg.PriorityLanes - 1
+	if c.cfg.PriorityLanes > 1 {
+		c.lanesMutex.Lock()
+		total := 0
+		for lane := range c.lanes {
+			weight := c.cfg.laneWeight(lane)
+			c.lanes[lane].credit += weight
+			total += weight
+		}
+		first = -1
+		for lane := len(c.lanes) - 1; lane >= 0; lane-- {
+			if c.cfg.laneWeight(lane) > 0 && (first < 0 || c.lanes[lane].credit > c.lanes[first].credit) {
+				first = lane
+			}
+		}
+		c.lanes[first].credit -= total
+		c.lanesMutex.Unlock()
+	}
+	order := make([]int, 0, c.cfg.PriorityLanes)
+	order = append(order, first)
+	for lane := c.cfg.PriorityLanes - 1; lane >= 0; lane-- {
+		if lane != first {
+			order = append(order, lane)
+		}
+	}
+	return order
+}
+
+// Consume takes a request from the lanes in the order of laneOrder, so that
+// higher priority lanes are read first more often without starving the lower
+// ones. Requests whose deadline has passed are responded to with an error and
+// skipped.
 func (c *Consumer[Request, Response]) Consume(ctx context.Context) (*Message[Request], error) {
-	var messages []redis.XMessage
-	if c.cfg.Retry {
-		// First try to XAUTOCLAIM, with start as a random messageID from PEL with MinIdle as IdletimeToAutoclaim
-		// this prioritizes processing PEL messages that have been waiting for more than IdletimeToAutoclaim duration
-		if pendingMsgs, err := c.client.XPendingExt(ctx, &redis.XPendingExtArgs{
-			Stream: c.redisStream,
-			Group:  c.redisGroup,
-			Start:  "-",
-			End:    "+",
-			Count:  c.claimAmongOldestIdleN,
-			Idle:   c.cfg.IdletimeToAutoclaim,
-		}).Result(); err != nil {
-			if !errors.Is(err, redis.Nil) {
-				log.Error("Error from XpendingExt in getting PEL for auto claim", "err", err, "pendingLen", len(pendingMsgs))
+	for _, lane := range c.laneOrder() {
+		if !c.laneExists(ctx, lane) {
+			continue
+		}
+		delivery, err := c.consumeLane(ctx, LaneStreamName(c.redisStream, lane))
+		if err != nil {
+			return nil, err
+		}
+		if delivery == nil {
+			continue
+		}
+		messageID := laneMessageID(lane, delivery.ID)
+		if !delivery.Deadline.IsZero() && time.Now().After(delivery.Deadline) {
+			log.Debug("skipping request past its deadline", "consumer_id", c.id, "message_id", messageID, "deadline", delivery.Deadline)
+			deadlineErr := &DeadlineExceededError{Deadline: delivery.Deadline}
+			if err := c.SetError(ctx, messageID, deadlineErr.Error()); err != nil && !errors.Is(err, ErrAlreadySet) {
+				log.Error("Failed to set error response for a request past its deadline", "messageID", messageID, "err", err)
 			}
+			return nil, nil
+		}
+		return c.message(delivery, LaneStreamName(c.redisStream, lane), messageID)
+	}
+	return nil, nil
+}
+
+// consumeLane first checks it there exists pending message that is claimed by
+// unresponsive consumer, if not then reads from the stream.
+func (c *Consumer[Request, Response]) consumeLane(ctx context.Context, stream string) (*Delivery, error) {
+	var delivery *Delivery
+	if c.cfg.Retry {
+		// First try to claim a random message from the oldest pending messages that have been idle for IdletimeToAutoclaim,
+		// this prioritizes processing pending messages that have been waiting for more than IdletimeToAutoclaim duration
+		if pendingMsgs, err := c.transport.Pending(ctx, stream, c.cfg.IdletimeToAutoclaim, c.claimAmongOldestIdleN); err != nil {
+			log.Error("Error getting pending messages for auto claim", "err", err)
 		} else if len(pendingMsgs) > 0 {
 			if c.cfg.MaxRetryCount != -1 {
 				// choose messages that didn't exceed MaxRetryCount
-				var exceededRetries []redis.XPendingExt
-				var filtered []redis.XPendingExt
+				var exceededRetries []PendingRequest
+				var filtered []PendingRequest
 				for _, msg := range pendingMsgs {
-					if msg.RetryCount > c.cfg.MaxRetryCount {
+					if msg.Deliveries > c.cfg.MaxRetryCount {
 						exceededRetries = append(exceededRetries, msg)
 					} else {
 						filtered = append(filtered, msg)
@@ -172,13 +308,36 @@ func (c *Consumer[Request, Response]) Consume(ctx context.Context) (*Message[Req
 					// * error set for only one message - avoid starving message processing
 					// * randomly chosen - mitigation for multiple consumers trying to set error for the same msg
 					idx := rand.Intn(len(exceededRetries))
-					if err := c.SetError(ctx, exceededRetries[idx].ID, "too many retries"); err != nil {
+					if err := c.setResponse(ctx, stream, exceededRetries[idx].ID, &Response{Error: "too many retries", IsError: true}); err != nil {
 						logger := log.Error
 						if errors.Is(err, ErrAlreadySet) {
 							// if error is already set, that's not a real error
 							logger = log.Debug
 						}
-						logger("Failed to set error response for a message that exceeded retries limit", "err", err, "retryCount", exceededRetries[idx].RetryCount)
+						logger("Failed to set error response for a message that exceeded retries limit", "err", err, "retryCount", exceededRetries[idx].Deliveries)
+					}
+				}
+				pendingMsgs = filtered
+			}
+			if c.cfg.MaxDeliveryAttempts > 0 {
+				var exhausted []PendingRequest
+				var filtered []PendingRequest
+				for _, msg := range pendingMsgs {
+					if msg.Deliveries >= c.cfg.MaxDeliveryAttempts {
+						exhausted = append(exhausted, msg)
+					} else {
+						filtered = append(filtered, msg)
+					}
+				}
+				if len(exhausted) > 0 {
+					// dead-letter one randomly chosen message, for the same reasons as above
+					idx := rand.Intn(len(exhausted))
+					if err := c.deadLetter(ctx, stream, exhausted[idx]); err != nil {
+						logger := log.Error
+						if errors.Is(err, ErrAlreadySet) {
+							logger = log.Debug
+						}
+						logger("Failed to move a message to the dead-letter stream", "err", err, "msgID", exhausted[idx].ID, "deliveries", exhausted[idx].Deliveries)
 					}
 				}
 				pendingMsgs = filtered
@@ -187,81 +346,89 @@ func (c *Consumer[Request, Response]) Consume(ctx context.Context) (*Message[Req
 				// attempt auto-claiming one randomly chosen message;
 				// random choice is a mitigation for multiple consumers trying to claim same msg
 				idx := rand.Intn(len(pendingMsgs))
-				messages, _, err = c.client.XAutoClaim(ctx, &redis.XAutoClaimArgs{
-					Group:    c.redisGroup,
-					Consumer: c.id,
-					MinIdle:  c.cfg.IdletimeToAutoclaim, // Minimum idle time for messages to claim (in milliseconds)
-					Stream:   c.redisStream,
-					Start:    decrementMsgIdByOne(pendingMsgs[idx].ID),
-					Count:    1,
-				}).Result()
+				delivery, err = c.transport.ClaimIdle(ctx, stream, c.id, pendingMsgs[idx].ID, c.cfg.IdletimeToAutoclaim)
 				if err != nil {
-					log.Info("error from xautoclaim", "err", err)
+					log.Info("error from auto claim", "err", err)
 				}
 			}
 		}
 	}
-	if len(messages) == 0 {
+	if delivery == nil {
 		// If we fail to autoclaim then we do not retry but instead fallback to reading new messages
-		res, err := c.client.XReadGroup(ctx, &redis.XReadGroupArgs{
-			Group:    c.redisGroup,
-			Consumer: c.id,
-			// Receive only messages that were never delivered to any other consumer,
-			// that is, only new messages.
-			Streams: []string{c.redisStream, ">"},
-			Count:   1,
-			Block:   time.Millisecond, // 0 seems to block the read instead of immediately returning
-		}).Result()
-		if errors.Is(err, redis.Nil) {
-			return nil, nil
-		}
+		var err error
+		delivery, err = c.transport.ReadNew(ctx, stream, c.id)
 		if err != nil {
 			return nil, fmt.Errorf("reading message for consumer: %q: %w", c.id, err)
 		}
-		if len(res) != 1 || len(res[0].Messages) != 1 {
-			return nil, fmt.Errorf("redis returned entries: %+v, for querying single message", res)
-		}
-		messages = res[0].Messages
 	}
+	return delivery, nil
+}
 
-	var (
-		value    = messages[0].Values[messageKey]
-		data, ok = (value).(string)
-	)
-	if !ok {
-		return nil, errors.New("error casting request to string")
+// deadLetter sets an error response for the pending request and moves it to the
+// dead-letter stream. Setting the response first makes sure only one consumer
+// moves the request, as it fails with ErrAlreadySet for the others.
+func (c *Consumer[Request, Response]) deadLetter(ctx context.Context, stream string, pending PendingRequest) error {
+	deliveries, err := c.transport.Range(ctx, stream, pending.ID, 1)
+	if err != nil {
+		return fmt.Errorf("reading message to dead-letter: %w", err)
+	}
+	if len(deliveries) == 0 || deliveries[0].ID != pending.ID {
+		// Already responded to
+		return nil
 	}
+	lastFailure, err := c.transport.LastFailure(ctx, stream, pending.ID)
+	if err != nil {
+		log.Warn("Failed to read the last failure of a request to dead-letter", "stream", stream, "msgID", pending.ID, "err", err)
+	}
+	errMsg := fmt.Sprintf("request failed after %d delivery attempts, moved to dead-letter stream", pending.Deliveries)
+	if lastFailure != "" {
+		errMsg += ", last failure: " + lastFailure
+	} else {
+		errMsg += ", consumers stopped while processing it"
+	}
+	if err := c.setResponse(ctx, stream, pending.ID, &Response{Error: errMsg, IsError: true}); err != nil {
+		return err
+	}
+	letter, err := json.Marshal(&DeadLetter{
+		Stream:         stream,
+		RequestID:      pending.ID,
+		Request:        deliveries[0].Data,
+		Deliveries:     pending.Deliveries,
+		Error:          errMsg,
+		LastFailure:    lastFailure,
+		DeadLetteredAt: time.Now(),
+	})
+	if err != nil {
+		return err
+	}
+	deadLetterStream := DeadLetterStreamName(c.redisStream)
+	if _, err := c.transport.Publish(ctx, deadLetterStream, letter, time.Time{}); err != nil {
+		return fmt.Errorf("publishing to dead-letter stream %s: %w", deadLetterStream, err)
+	}
+	log.Warn("Moved request to dead-letter stream", "stream", stream, "msgID", pending.ID, "deliveries", pending.Deliveries, "lastFailure", lastFailure, "deadLetterStream", deadLetterStream)
+	return nil
+}
+
+func (c *Consumer[Request, Response]) message(delivery *Delivery, stream, messageID string) (*Message[Request], error) {
 	var req Request
-	if err := json.Unmarshal([]byte(data), &req); err != nil {
-		return nil, fmt.Errorf("unmarshaling value: %v, error: %w", value, err)
+	if err := json.Unmarshal(delivery.Data, &req); err != nil {
+		return nil, fmt.Errorf("unmarshaling value: %v, error: %w", string(delivery.Data), err)
 	}
 	ackNotifier := make(chan struct{})
 	c.StopWaiter.LaunchThread(func(ctx context.Context) {
 		for {
-			// Use XClaimJustID so that we would have clear difference between invalid requests that are claimed multiple times due to xautoclaim and
-			// valid requests that are just being claimed in regular intervals to indicate heartbeat
-			if ids, err := c.client.XClaimJustID(ctx, &redis.XClaimArgs{
-				Stream:   c.redisStream,
-				Group:    c.redisGroup,
-				Consumer: c.id,
-				MinIdle:  0,
-				Messages: []string{messages[0].ID},
-			}).Result(); err != nil {
-				log.Error("Error claiming message, it might be possible that other consumers might pick this request", "msgID", messages[0].ID)
-			} else if len(ids) == 0 {
-				log.Warn("XClaimJustID returned empty response when indicating heartbeat", "msgID", messages[0].ID)
-			} else if len(ids) > 1 {
-				log.Error("XClaimJustID returned response with more than entry", "msgIDs", ids)
+			if err := c.transport.Heartbeat(ctx, stream, c.id, delivery.ID); err != nil {
+				log.Error("Error claiming message, it might be possible that other consumers might pick this request", "msgID", delivery.ID, "err", err)
 			}
 			select {
 			case <-ackNotifier:
 				return
 			case <-ctx.Done():
-				log.Info("Context done while claiming message to indicate heartbeat", "messageID", messages[0].ID, "error", ctx.Err().Error())
+				log.Info("Context done while claiming message to indicate heartbeat", "messageID", delivery.ID, "error", ctx.Err().Error())
 				if c.StopWaiter.GetParentContext().Err() == nil {
 					// Proceeding to set the Idle time of message to IdletimeToAutoclaim to allow it to be picked by other consumers
-					if err := c.client.Do(c.StopWaiter.GetParentContext(), "XCLAIM", c.redisStream, c.redisGroup, c.id, 0, messages[0].ID, "IDLE", c.cfg.IdletimeToAutoclaim.Milliseconds()).Err(); err != nil {
-						log.Error("error when trying to set the idle time of currently worked on message to IdletimeToAutoclaim", "messageID", messages[0].ID, "err", err)
+					if err := c.transport.Release(c.StopWaiter.GetParentContext(), stream, c.id, delivery.ID, c.cfg.IdletimeToAutoclaim); err != nil {
+						log.Error("error when trying to set the idle time of currently worked on message to IdletimeToAutoclaim", "messageID", delivery.ID, "err", err)
 					}
 				}
 				return
@@ -269,9 +436,9 @@ func (c *Consumer[Request, Response]) Consume(ctx context.Context) (*Message[Req
 			}
 		}
 	})
-	log.Debug("Redis stream consuming", "consumer_id", c.id, "message_id", messages[0].ID)
+	log.Debug("Stream consuming", "consumer_id", c.id, "message_id", messageID)
 	return &Message[Request]{
-		ID:    messages[0].ID,
+		ID:    messageID,
 		Value: req,
 		Ack:   func() { close(ackNotifier) },
 	}, nil
@@ -282,43 +449,51 @@ func (c *Consumer[Request, Response]) SetResult(ctx context.Context, messageID s
 	if err != nil {
 		return fmt.Errorf("marshaling result: %w", err)
 	}
-	resultKey := ResultKeyFor(c.StreamName(), messageID)
-	log.Debug("consumer: setting result", "cid", c.id, "msgIdInStream", messageID, "resultKeyInRedis", resultKey)
-	acquired, err := c.client.SetNX(ctx, resultKey, resp, c.cfg.ResponseEntryTimeout).Result()
-	if !acquired && err == nil {
-		err = ErrAlreadySet
-	}
-	if err != nil {
-		return fmt.Errorf("setting result for message with message-id in stream: %v, error: %w", messageID, err)
-	}
-	log.Debug("consumer: xack", "cid", c.id, "messageId", messageID)
-	if _, err := c.client.XAck(ctx, c.redisStream, c.redisGroup, messageID).Result(); err != nil {
-		return fmt.Errorf("acking message: %v, error: %w", messageID, err)
-	}
-	log.Debug("consumer: xdel", "cid", c.id, "messageId", messageID)
-	if _, err := c.client.XDel(ctx, c.redisStream, messageID).Result(); err != nil {
-		return fmt.Errorf("deleting message: %v, error: %w", messageID, err)
-	}
-	return nil
+	log.Debug("consumer: setting result", "cid", c.id, "msgIdInStream", messageID)
+	return c.respond(ctx, messageID, &Response{Data: resp})
 }
 
 func (c *Consumer[Request, Response]) SetError(ctx context.Context, messageID string, error string) error {
-	errorKey := ErrorKeyFor(c.StreamName(), messageID)
-	log.Debug("consumer: setting error", "cid", c.id, "msgIdInStream", messageID, "errorKeyInRedis", errorKey)
-	acquired, err := c.client.SetNX(ctx, errorKey, error, c.cfg.ResponseEntryTimeout).Result()
-	if !acquired && err == nil {
-		err = ErrAlreadySet
-	}
+	log.Debug("consumer: setting error", "cid", c.id, "msgIdInStream", messageID)
+	return c.respond(ctx, messageID, &Response{Error: error, IsError: true})
+}
+
+// RecordFailure records why processing the request with the message ID
+// returned by Consume failed, when it's left to be retried by another
+// delivery rather than responded to with an error. The last recorded failure
+// is reported if the request is moved to the dead-letter stream.
+func (c *Consumer[Request, Response]) RecordFailure(ctx context.Context, messageID string, failure error) error {
+	lane, id, err := parseLaneMessageID(messageID)
 	if err != nil {
-		return fmt.Errorf("setting error for message with message-id in stream: %v, error: %w", messageID, err)
+		return err
 	}
-	log.Debug("consumer: xack", "cid", c.id, "messageId", messageID)
-	if _, err := c.client.XAck(ctx, c.redisStream, c.redisGroup, messageID).Result(); err != nil {
-		return fmt.Errorf("acking message: %v, error: %w", messageID, err)
+	return c.recordFailure(ctx, LaneStreamName(c.redisStream, lane), id, failure.Error())
+}
+
+// recordFailure keeps the failure until the request could be moved to the
+// dead-letter stream, after all its delivery attempts timed out.
+func (c *Consumer[Request, Response]) recordFailure(ctx context.Context, stream, id, failure string) error {
+	ttl := c.cfg.ResponseEntryTimeout + c.cfg.IdletimeToAutoclaim*time.Duration(max(c.cfg.MaxDeliveryAttempts, 1))
+	return c.transport.RecordFailure(ctx, stream, id, failure, ttl)
+}
+
+// respond sets the response for a message ID returned by Consume. If it
+// fails, the error is recorded as the request's last failure.
+func (c *Consumer[Request, Response]) respond(ctx context.Context, messageID string, response *Response) error {
+	lane, id, err := parseLaneMessageID(messageID)
+	if err != nil {
+		return err
 	}
-	log.Debug("consumer: xdel", "cid", c.id, "messageId", messageID)
-	if _, err := c.client.XDel(ctx, c.redisStream, messageID).Result(); err != nil {
-		return fmt.Errorf("deleting message: %v, error: %w", messageID, err)
+	stream := LaneStreamName(c.redisStream, lane)
+	err = c.setResponse(ctx, stream, id, response)
+	if err != nil && !errors.Is(err, ErrAlreadySet) {
+		if recordErr := c.recordFailure(ctx, stream, id, "setting response: "+err.Error()); recordErr != nil {
+			log.Warn("Failed to record the failure to respond to a request", "msgID", messageID, "err", recordErr)
+		}
 	}
-	return nil
+	return err
+}
+
+func (c *Consumer[Request, Response]) setResponse(ctx context.Context, stream, id string, response *Response) error {
+	return c.transport.Respond(ctx, stream, id, response, c.cfg.ResponseEntryTimeout)
 }
@@ -0,0 +1,142 @@
+// Copyright 2026, Offchain Labs, Inc.
+// For license information, see https://github.com/OffchainLabs/nitro/blob/master/LICENSE.md
+package pubsub
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// DeadLetterNamespace is the RPC namespace of DeadLetterAPI.
+const DeadLetterNamespace = "deadletter"
+
+var ErrDeadLetterNotFound = errors.New("dead letter not found")
+
+// DeadLetter is a request which consumers gave up on after too many delivery
+// attempts.
+type DeadLetter struct {
+	// ID of the dead letter in the dead-letter stream.
+	ID string `json:"id"`
+	// Stream the request was published to, and its ID there.
+	Stream    string `json:"stream"`
+	RequestID string `json:"requestId"`
+	// Request is the JSON encoded request.
+	Request    json.RawMessage `json:"request"`
+	Deliveries int64           `json:"deliveries"`
+	// Error is the error response set for the request.
+	Error string `json:"error"`
+	// LastFailure is the failure last recorded while processing or
+	// responding to the request, if any was.
+	LastFailure    string    `json:"lastFailure,omitempty"`
+	DeadLetteredAt time.Time `json:"deadLetteredAt"`
+}
+
+// DeadLetterStreamName returns the name of the stream holding the dead
+// letters of the stream and its priority lanes.
+func DeadLetterStreamName(stream string) string {
+	return stream + "-dead-letter"
+}
+
+// DeadLetterQueue inspects and requeues the dead letters of a stream.
+type DeadLetterQueue struct {
+	transport Transport
+	stream    string
+}
+
+func NewDeadLetterQueue(transport Transport, stream string) *DeadLetterQueue {
+	return &DeadLetterQueue{
+		transport: transport,
+		stream:    DeadLetterStreamName(stream),
+	}
+}
+
+func deadLetterFromDelivery(delivery *Delivery) (*DeadLetter, error) {
+	var letter DeadLetter
+	if err := json.Unmarshal(delivery.Data, &letter); err != nil {
+		return nil, fmt.Errorf("unmarshaling dead letter %s: %w", delivery.ID, err)
+	}
+	letter.ID = delivery.ID
+	return &letter, nil
+}
+
+// List returns up to count dead letters, starting with the given ID or from the
+// oldest one if start is empty.
+func (q *DeadLetterQueue) List(ctx context.Context, start string, count int64) ([]*DeadLetter, error) {
+	deliveries, err := q.transport.Range(ctx, q.stream, start, count)
+	if err != nil {
+		return nil, err
+	}
+	letters := make([]*DeadLetter, 0, len(deliveries))
+	for i := range deliveries {
+		letter, err := deadLetterFromDelivery(&deliveries[i])
+		if err != nil {
+			return nil, err
+		}
+		letters = append(letters, letter)
+	}
+	return letters, nil
+}
+
+// Get returns the dead letter with the given ID.
+func (q *DeadLetterQueue) Get(ctx context.Context, id string) (*DeadLetter, error) {
+	deliveries, err := q.transport.Range(ctx, q.stream, id, 1)
+	if err != nil {
+		return nil, err
+	}
+	if len(deliveries) == 0 || deliveries[0].ID != id {
+		return nil, fmt.Errorf("%w: %s", ErrDeadLetterNotFound, id)
+	}
+	return deadLetterFromDelivery(&deliveries[0])
+}
+
+// Requeue publishes the request of the dead letter again to the stream it was
+// originally published to, and removes the dead letter. It returns the new
+// request ID. The producer of the original request has already received an
+// error, so the response to the requeued request is not awaited.
+func (q *DeadLetterQueue) Requeue(ctx context.Context, id string) (string, error) {
+	letter, err := q.Get(ctx, id)
+	if err != nil {
+		return "", err
+	}
+	requestID, err := q.transport.Publish(ctx, letter.Stream, letter.Request, time.Time{})
+	if err != nil {
+		return "", fmt.Errorf("requeuing dead letter %s: %w", id, err)
+	}
+	if err := q.transport.Delete(ctx, q.stream, id); err != nil {
+		return "", fmt.Errorf("deleting requeued dead letter %s: %w", id, err)
+	}
+	return requestID, nil
+}
+
+// Drop removes the dead letter.
+func (q *DeadLetterQueue) Drop(ctx context.Context, id string) error {
+	if _, err := q.Get(ctx, id); err != nil {
+		return err
+	}
+	return q.transport.Delete(ctx, q.stream, id)
+}
+
+// DeadLetterAPI exposes the dead-letter queues of the streams of a transport
+// over RPC.
+type DeadLetterAPI struct {
+	transport Transport
+}
+
+func NewDeadLetterAPI(transport Transport) *DeadLetterAPI {
+	return &DeadLetterAPI{transport: transport}
+}
+
+func (a *DeadLetterAPI) List(ctx context.Context, stream, start string, count int64) ([]*DeadLetter, error) {
+	return NewDeadLetterQueue(a.transport, stream).List(ctx, start, count)
+}
+
+func (a *DeadLetterAPI) Requeue(ctx context.Context, stream, id string) (string, error) {
+	return NewDeadLetterQueue(a.transport, stream).Requeue(ctx, id)
+}
+
+func (a *DeadLetterAPI) Drop(ctx context.Context, stream, id string) error {
+	return NewDeadLetterQueue(a.transport, stream).Drop(ctx, id)
+}
@@ -0,0 +1,103 @@
+// Copyright 2026, Offchain Labs, Inc.
+// For license information, see https://github.com/OffchainLabs/nitro/blob/master/LICENSE.md
+package pubsub
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestDeadLetter(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	for name, transport := range testTransports(ctx, t) {
+		t.Run(name, func(t *testing.T) {
+			streamName := fmt.Sprintf("stream:%s", uuid.NewString())
+			producer, err := NewProducerWithTransport[testRequest, testResponse](transport, streamName, &TestProducerConfig)
+			if err != nil {
+				t.Fatalf("Error creating new producer: %v", err)
+			}
+			producer.Start(ctx)
+			defer producer.StopAndWait()
+			consumerConfig := TestConsumerConfig
+			consumerConfig.MaxDeliveryAttempts = 2
+			newConsumer := func() *Consumer[testRequest, testResponse] {
+				consumer, err := NewConsumerWithTransport[testRequest, testResponse](transport, streamName, &consumerConfig)
+				if err != nil {
+					t.Fatalf("Error creating new consumer: %v", err)
+				}
+				consumer.Start(ctx)
+				return consumer
+			}
+
+			promise, err := producer.Produce(ctx, testRequest{Request: "poison"})
+			if err != nil {
+				t.Fatalf("Error producing request: %v", err)
+			}
+			// Consumers fail or crash while working on the request until it is
+			// dead-lettered, and the last failure is reported.
+			for i := int64(0); i < consumerConfig.MaxDeliveryAttempts; i++ {
+				consumer := newConsumer()
+				msg, err := consumer.Consume(ctx)
+				if err != nil || msg == nil || msg.Value.Request != "poison" {
+					t.Fatalf("Unexpected message %+v, err: %v", msg, err)
+				}
+				if err := consumer.RecordFailure(ctx, msg.ID, fmt.Errorf("attempt %d failed", i+1)); err != nil {
+					t.Fatalf("Error recording failure: %v", err)
+				}
+				consumer.StopAndWait()
+			}
+			lastFailure := fmt.Sprintf("attempt %d failed", consumerConfig.MaxDeliveryAttempts)
+			consumer := newConsumer()
+			defer consumer.StopAndWait()
+			if msg, err := consumer.Consume(ctx); err != nil || msg != nil {
+				t.Fatalf("Unexpected message %+v, err: %v", msg, err)
+			}
+			awaitCtx, awaitCancel := context.WithTimeout(ctx, 10*time.Second)
+			defer awaitCancel()
+			if _, err := promise.Await(awaitCtx); err == nil || !strings.Contains(err.Error(), "dead-letter") || !strings.Contains(err.Error(), lastFailure) {
+				t.Fatalf("Expected dead-letter error, got: %v", err)
+			}
+
+			queue := NewDeadLetterQueue(transport, streamName)
+			letters, err := queue.List(ctx, "", 10)
+			if err != nil {
+				t.Fatalf("Error listing dead letters: %v", err)
+			}
+			if len(letters) != 1 {
+				t.Fatalf("Got %d dead letters, want 1", len(letters))
+			}
+			letter := letters[0]
+			var req testRequest
+			if err := json.Unmarshal(letter.Request, &req); err != nil || req.Request != "poison" {
+				t.Fatalf("Unexpected dead letter request %s, err: %v", letter.Request, err)
+			}
+			if letter.Stream != streamName || letter.Deliveries != consumerConfig.MaxDeliveryAttempts || !strings.Contains(letter.Error, lastFailure) || letter.LastFailure != lastFailure {
+				t.Errorf("Unexpected dead letter: %+v", letter)
+			}
+
+			if _, err := queue.Requeue(ctx, letter.ID); err != nil {
+				t.Fatalf("Error requeuing dead letter: %v", err)
+			}
+			msg, err := consumer.Consume(ctx)
+			if err != nil || msg == nil || msg.Value.Request != "poison" {
+				t.Fatalf("Unexpected message %+v, err: %v", msg, err)
+			}
+			msg.Ack()
+			if letters, err := queue.List(ctx, "", 10); err != nil || len(letters) != 0 {
+				t.Errorf("Unexpected dead letters after requeue: %+v, err: %v", letters, err)
+			}
+			if err := queue.Drop(ctx, letter.ID); !errors.Is(err, ErrDeadLetterNotFound) {
+				t.Errorf("Expected ErrDeadLetterNotFound, got: %v", err)
+			}
+		})
+	}
+}
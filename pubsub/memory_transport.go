@@ -0,0 +1,335 @@
+// Copyright 2026, Offchain Labs, Inc.
+// For license information, see https://github.com/OffchainLabs/nitro/blob/master/LICENSE.md
+package pubsub
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// memoryReadTimeout is how long ReadNew waits for a new request, similarly to
+// a blocking read from a redis stream.
+const memoryReadTimeout = 10 * time.Millisecond
+
+type memoryRequest struct {
+	id          string
+	data        []byte
+	deadline    time.Time
+	published   time.Time
+	delivered   bool
+	consumer    string
+	lastClaimed time.Time
+	deliveries  int64
+	lastFailure string
+}
+
+type memoryResponse struct {
+	response Response
+	expiry   time.Time
+}
+
+type memoryStream struct {
+	// requests are ordered by ID
+	requests  []*memoryRequest
+	responses map[string]*memoryResponse
+	// published is closed and replaced when a request is published
+	published chan struct{}
+}
+
+// MemoryTransport implements Transport in process memory, for running
+// producers and consumers in the same process and for tests.
+type MemoryTransport struct {
+	mutex      sync.Mutex
+	streams    map[string]*memoryStream
+	lastMillis int64
+	serial     uint64
+}
+
+func NewMemoryTransport() *MemoryTransport {
+	return &MemoryTransport{streams: make(map[string]*memoryStream)}
+}
+
+var (
+	namedMemoryTransportsMutex sync.Mutex
+	namedMemoryTransports      = make(map[string]*MemoryTransport)
+)
+
+// NamedMemoryTransport returns the process wide MemoryTransport with the given
+// name, creating it if needed.
+func NamedMemoryTransport(name string) *MemoryTransport {
+	namedMemoryTransportsMutex.Lock()
+	defer namedMemoryTransportsMutex.Unlock()
+	t, ok := namedMemoryTransports[name]
+	if !ok {
+		t = NewMemoryTransport()
+		namedMemoryTransports[name] = t
+	}
+	return t
+}
+
+// stream must be called with the mutex held.
+func (t *MemoryTransport) stream(name string, create bool) *memoryStream {
+	s, ok := t.streams[name]
+	if !ok && create {
+		s = &memoryStream{
+			responses: make(map[string]*memoryResponse),
+			published: make(chan struct{}),
+		}
+		t.streams[name] = s
+	}
+	return s
+}
+
+// find must be called with the mutex held.
+func (s *memoryStream) find(id string) (int, *memoryRequest) {
+	for i, req := range s.requests {
+		if req.id == id {
+			return i, req
+		}
+	}
+	return -1, nil
+}
+
+func (t *MemoryTransport) CreateStream(_ context.Context, stream string) error {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.stream(stream, true)
+	return nil
+}
+
+func (t *MemoryTransport) StreamExists(_ context.Context, stream string) bool {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	return t.stream(stream, false) != nil
+}
+
+func (t *MemoryTransport) Publish(_ context.Context, stream string, data []byte, deadline time.Time) (string, error) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	now := time.Now()
+	if millis := now.UnixMilli(); millis > t.lastMillis {
+		t.lastMillis = millis
+		t.serial = 0
+	} else {
+		t.serial++
+	}
+	s := t.stream(stream, true)
+	req := &memoryRequest{
+		id:        formatID(time.UnixMilli(t.lastMillis), t.serial),
+		data:      data,
+		deadline:  deadline,
+		published: now,
+	}
+	s.requests = append(s.requests, req)
+	close(s.published)
+	s.published = make(chan struct{})
+	return req.id, nil
+}
+
+func (t *MemoryTransport) TakeResponse(_ context.Context, stream, id string) (*Response, error) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	s := t.stream(stream, false)
+	if s == nil {
+		return nil, nil
+	}
+	resp, ok := s.responses[id]
+	if !ok {
+		return nil, nil
+	}
+	delete(s.responses, id)
+	if time.Now().After(resp.expiry) {
+		return nil, nil
+	}
+	return &resp.response, nil
+}
+
+func (t *MemoryTransport) Trim(_ context.Context, stream, _ string, expiredBefore time.Time) (bool, error) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	s := t.stream(stream, false)
+	if s == nil {
+		return false, nil
+	}
+	now := time.Now()
+	for id, resp := range s.responses {
+		if now.After(resp.expiry) {
+			delete(s.responses, id)
+		}
+	}
+	if len(s.requests) > 0 && s.requests[0].published.Before(expiredBefore) {
+		s.requests = s.requests[1:]
+		return true, nil
+	}
+	return false, nil
+}
+
+func (t *MemoryTransport) Pending(_ context.Context, stream string, idle time.Duration, count int64) ([]PendingRequest, error) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	s := t.stream(stream, false)
+	if s == nil {
+		return nil, nil
+	}
+	var pending []PendingRequest
+	now := time.Now()
+	for _, req := range s.requests {
+		if int64(len(pending)) >= count {
+			break
+		}
+		if req.delivered && now.Sub(req.lastClaimed) >= idle {
+			pending = append(pending, PendingRequest{ID: req.id, Deliveries: req.deliveries})
+		}
+	}
+	return pending, nil
+}
+
+// claim must be called with the mutex held.
+func (req *memoryRequest) claim(consumerID string) *Delivery {
+	req.delivered = true
+	req.consumer = consumerID
+	req.lastClaimed = time.Now()
+	req.deliveries++
+	return &Delivery{ID: req.id, Data: req.data, Deadline: req.deadline}
+}
+
+func (t *MemoryTransport) ClaimIdle(_ context.Context, stream, consumerID, id string, idle time.Duration) (*Delivery, error) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	s := t.stream(stream, false)
+	if s == nil {
+		return nil, nil
+	}
+	start, _ := s.find(id)
+	if start < 0 {
+		return nil, nil
+	}
+	now := time.Now()
+	for _, req := range s.requests[start:] {
+		if req.delivered && now.Sub(req.lastClaimed) >= idle {
+			return req.claim(consumerID), nil
+		}
+	}
+	return nil, nil
+}
+
+func (t *MemoryTransport) ReadNew(ctx context.Context, stream, consumerID string) (*Delivery, error) {
+	timer := time.NewTimer(memoryReadTimeout)
+	defer timer.Stop()
+	for {
+		t.mutex.Lock()
+		s := t.stream(stream, false)
+		if s == nil {
+			t.mutex.Unlock()
+			return nil, nil
+		}
+		for _, req := range s.requests {
+			if !req.delivered {
+				delivery := req.claim(consumerID)
+				t.mutex.Unlock()
+				return delivery, nil
+			}
+		}
+		published := s.published
+		t.mutex.Unlock()
+		select {
+		case <-published:
+		case <-timer.C:
+			return nil, nil
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+func (t *MemoryTransport) Heartbeat(_ context.Context, stream, consumerID, id string) error {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	if s := t.stream(stream, false); s != nil {
+		if _, req := s.find(id); req != nil {
+			req.consumer = consumerID
+			req.lastClaimed = time.Now()
+		}
+	}
+	return nil
+}
+
+func (t *MemoryTransport) Release(_ context.Context, stream, consumerID, id string, idle time.Duration) error {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	if s := t.stream(stream, false); s != nil {
+		if _, req := s.find(id); req != nil && req.consumer == consumerID {
+			req.lastClaimed = time.Now().Add(-idle)
+		}
+	}
+	return nil
+}
+
+func (t *MemoryTransport) Respond(_ context.Context, stream, id string, response *Response, ttl time.Duration) error {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	s := t.stream(stream, true)
+	if resp, ok := s.responses[id]; ok && time.Now().Before(resp.expiry) {
+		return ErrAlreadySet
+	}
+	s.responses[id] = &memoryResponse{response: *response, expiry: time.Now().Add(ttl)}
+	if i, _ := s.find(id); i >= 0 {
+		s.requests = append(s.requests[:i], s.requests[i+1:]...)
+	}
+	return nil
+}
+
+func (t *MemoryTransport) Range(_ context.Context, stream, start string, count int64) ([]Delivery, error) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	s := t.stream(stream, false)
+	if s == nil {
+		return nil, nil
+	}
+	var deliveries []Delivery
+	for _, req := range s.requests {
+		if int64(len(deliveries)) >= count {
+			break
+		}
+		if start == "" || cmpMsgId(req.id, start) >= 0 {
+			deliveries = append(deliveries, Delivery{ID: req.id, Data: req.data, Deadline: req.deadline})
+		}
+	}
+	return deliveries, nil
+}
+
+func (t *MemoryTransport) Delete(_ context.Context, stream, id string) error {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	if s := t.stream(stream, false); s != nil {
+		if i, _ := s.find(id); i >= 0 {
+			s.requests = append(s.requests[:i], s.requests[i+1:]...)
+		}
+	}
+	return nil
+}
+
+// RecordFailure keeps the failure with the request, rather than for ttl, as
+// it's only needed while the request is in the stream.
+func (t *MemoryTransport) RecordFailure(_ context.Context, stream, id, failure string, _ time.Duration) error {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	if s := t.stream(stream, false); s != nil {
+		if _, req := s.find(id); req != nil {
+			req.lastFailure = failure
+		}
+	}
+	return nil
+}
+
+func (t *MemoryTransport) LastFailure(_ context.Context, stream, id string) (string, error) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	if s := t.stream(stream, false); s != nil {
+		if _, req := s.find(id); req != nil {
+			return req.lastFailure, nil
+		}
+	}
+	return "", nil
+}
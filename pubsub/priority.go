@@ -0,0 +1,78 @@
+// Copyright 2026, Offchain Labs, Inc.
+// For license information, see https://github.com/OffchainLabs/nitro/blob/master/LICENSE.md
+package pubsub
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrDeadlineExceeded is matched by the errors of requests which have not been
+// responded to before their deadline.
+var ErrDeadlineExceeded = errors.New("request deadline exceeded")
+
+// DeadlineExceededError is returned by the promise of a request which has not
+// been responded to before its deadline.
+type DeadlineExceededError struct {
+	Deadline time.Time
+}
+
+func (e *DeadlineExceededError) Error() string {
+	return fmt.Sprintf("%v at %v", ErrDeadlineExceeded, e.Deadline.UTC())
+}
+
+func (e *DeadlineExceededError) Unwrap() error {
+	return ErrDeadlineExceeded
+}
+
+// ProduceOptions are the per-request options of ProduceWithOptions.
+type ProduceOptions struct {
+	// Priority of the request. Consumers take requests from the highest
+	// priority lane first; priorities above the configured number of lanes
+	// use the highest lane.
+	Priority int
+	// Deadline after which the request fails with a DeadlineExceededError.
+	// Zero means no deadline other than the producer's request timeout.
+	Deadline time.Time
+}
+
+// laneSeparator separates the lane from the request ID in message IDs of
+// requests with priority, so that consumers know which lane to respond to.
+const laneSeparator = "@"
+
+// LaneStreamName returns the name of the stream of the given priority lane.
+// The lowest priority lane is the stream itself, so producers and consumers
+// with a single lane are compatible with those with more.
+func LaneStreamName(stream string, lane int) string {
+	if lane <= 0 {
+		return stream
+	}
+	return fmt.Sprintf("%s-priority-%d", stream, lane)
+}
+
+// laneOf clamps the priority to the available lanes.
+func laneOf(priority, lanes int) int {
+	return max(0, min(priority, lanes-1))
+}
+
+func laneMessageID(lane int, id string) string {
+	if lane == 0 {
+		return id
+	}
+	return id + laneSeparator + strconv.Itoa(lane)
+}
+
+func parseLaneMessageID(messageID string) (int, string, error) {
+	id, laneStr, found := strings.Cut(messageID, laneSeparator)
+	if !found {
+		return 0, messageID, nil
+	}
+	lane, err := strconv.Atoi(laneStr)
+	if err != nil || lane <= 0 {
+		return 0, "", fmt.Errorf("invalid message id: %v", messageID)
+	}
+	return lane, id, nil
+}
@@ -0,0 +1,171 @@
+// Copyright 2026, Offchain Labs, Inc.
+// For license information, see https://github.com/OffchainLabs/nitro/blob/master/LICENSE.md
+package pubsub
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/offchainlabs/nitro/util/containers"
+)
+
+func TestPriorityLanes(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	transport := NewMemoryTransport()
+	streamName := fmt.Sprintf("stream:%s", uuid.NewString())
+	producerConfig := TestProducerConfig
+	producerConfig.PriorityLanes = 3
+	producer, err := NewProducerWithTransport[testRequest, testResponse](transport, streamName, &producerConfig)
+	if err != nil {
+		t.Fatalf("Error creating new producer: %v", err)
+	}
+	if err := producer.CreateStreams(ctx); err != nil {
+		t.Fatalf("Error creating streams: %v", err)
+	}
+	producer.Start(ctx)
+	defer producer.StopAndWait()
+	consumerConfig := TestConsumerConfig
+	consumerConfig.PriorityLanes = 3
+	// Only the highest lane has weight, so the lanes are read in strict order.
+	consumerConfig.LaneWeights = []int{0, 0, 1}
+	consumer, err := NewConsumerWithTransport[testRequest, testResponse](transport, streamName, &consumerConfig)
+	if err != nil {
+		t.Fatalf("Error creating new consumer: %v", err)
+	}
+	consumer.Start(ctx)
+	defer consumer.StopAndWait()
+
+	// Priorities above the number of lanes share the highest lane.
+	priorities := []int{0, 1, 0, 5, 2, 1}
+	want := []string{"3", "4", "1", "5", "0", "2"}
+	for i, priority := range priorities {
+		if _, err := producer.ProduceWithOptions(ctx, testRequest{Request: fmt.Sprint(i)}, ProduceOptions{Priority: priority}); err != nil {
+			t.Fatalf("Error producing request %d: %v", i, err)
+		}
+	}
+	for _, w := range want {
+		msg, err := consumer.Consume(ctx)
+		if err != nil || msg == nil {
+			t.Fatalf("Error consuming message: %v", err)
+		}
+		if msg.Value.Request != w {
+			t.Errorf("Consumed request %q, want %q", msg.Value.Request, w)
+		}
+		if err := consumer.SetResult(ctx, msg.ID, testResponse{Response: msg.Value.Request}); err != nil {
+			t.Errorf("Error setting result for %q: %v", msg.ID, err)
+		}
+		msg.Ack()
+	}
+	if msg, err := consumer.Consume(ctx); err != nil || msg != nil {
+		t.Errorf("Unexpected message %+v, err: %v", msg, err)
+	}
+}
+
+func TestLaneWeights(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	transport := NewMemoryTransport()
+	streamName := fmt.Sprintf("stream:%s", uuid.NewString())
+	producerConfig := TestProducerConfig
+	producerConfig.PriorityLanes = 3
+	producer, err := NewProducerWithTransport[testRequest, testResponse](transport, streamName, &producerConfig)
+	if err != nil {
+		t.Fatalf("Error creating new producer: %v", err)
+	}
+	if err := producer.CreateStreams(ctx); err != nil {
+		t.Fatalf("Error creating streams: %v", err)
+	}
+	producer.Start(ctx)
+	defer producer.StopAndWait()
+	// With the default weights 1, 2 and 4 the lowest lane is read first once
+	// every 7 reads.
+	consumerConfig := TestConsumerConfig
+	consumerConfig.PriorityLanes = 3
+	consumer, err := NewConsumerWithTransport[testRequest, testResponse](transport, streamName, &consumerConfig)
+	if err != nil {
+		t.Fatalf("Error creating new consumer: %v", err)
+	}
+	consumer.Start(ctx)
+	defer consumer.StopAndWait()
+
+	priorities := []int{0, 1, 0, 2, 2, 1, 2, 2}
+	want := []string{"3", "1", "4", "0", "6", "5", "7", "2"}
+	for i, priority := range priorities {
+		if _, err := producer.ProduceWithOptions(ctx, testRequest{Request: fmt.Sprint(i)}, ProduceOptions{Priority: priority}); err != nil {
+			t.Fatalf("Error producing request %d: %v", i, err)
+		}
+	}
+	for _, w := range want {
+		msg, err := consumer.Consume(ctx)
+		if err != nil || msg == nil {
+			t.Fatalf("Error consuming message: %v", err)
+		}
+		if msg.Value.Request != w {
+			t.Errorf("Consumed request %q, want %q", msg.Value.Request, w)
+		}
+		if err := consumer.SetResult(ctx, msg.ID, testResponse{Response: msg.Value.Request}); err != nil {
+			t.Errorf("Error setting result for %q: %v", msg.ID, err)
+		}
+		msg.Ack()
+	}
+}
+
+func TestProduceDeadline(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	transport := NewMemoryTransport()
+	streamName := fmt.Sprintf("stream:%s", uuid.NewString())
+	producer, err := NewProducerWithTransport[testRequest, testResponse](transport, streamName, &TestProducerConfig)
+	if err != nil {
+		t.Fatalf("Error creating new producer: %v", err)
+	}
+	producer.Start(ctx)
+	defer producer.StopAndWait()
+	consumer, err := NewConsumerWithTransport[testRequest, testResponse](transport, streamName, &TestConsumerConfig)
+	if err != nil {
+		t.Fatalf("Error creating new consumer: %v", err)
+	}
+	consumer.Start(ctx)
+	defer consumer.StopAndWait()
+
+	expired, err := producer.ProduceWithOptions(ctx, testRequest{Request: "expired"}, ProduceOptions{Deadline: time.Now().Add(-time.Second)})
+	if err != nil {
+		t.Fatalf("Error producing request: %v", err)
+	}
+	unanswered, err := producer.ProduceWithOptions(ctx, testRequest{Request: "unanswered"}, ProduceOptions{Deadline: time.Now().Add(100 * time.Millisecond)})
+	if err != nil {
+		t.Fatalf("Error producing request: %v", err)
+	}
+	// The consumer skips the request past its deadline.
+	msg, err := consumer.Consume(ctx)
+	if err != nil || msg != nil {
+		t.Fatalf("Unexpected message %+v, err: %v", msg, err)
+	}
+	msg, err = consumer.Consume(ctx)
+	if err != nil || msg == nil || msg.Value.Request != "unanswered" {
+		t.Fatalf("Unexpected message %+v, err: %v", msg, err)
+	}
+	defer msg.Ack()
+
+	awaitCtx, awaitCancel := context.WithTimeout(ctx, 10*time.Second)
+	defer awaitCancel()
+	for _, promise := range []*containers.Promise[testResponse]{expired, unanswered} {
+		_, err := promise.Await(awaitCtx)
+		var deadlineErr *DeadlineExceededError
+		if !errors.Is(err, ErrDeadlineExceeded) || !errors.As(err, &deadlineErr) {
+			t.Errorf("Expected a DeadlineExceededError, got: %v", err)
+		}
+	}
+	if cnt := producer.promisesLen(); cnt != 0 {
+		t.Errorf("Producer still has %d unfullfilled promises", cnt)
+	}
+}
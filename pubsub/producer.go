@@ -32,6 +32,7 @@ import (
 
 const (
 	messageKey          = "msg"
+	deadlineKey         = "deadline"
 	defaultGroup        = "default_consumer_group"
 	TimeoutErrorMessage = "request has been waiting for too long"
 )
@@ -39,59 +40,102 @@ const (
 type Producer[Request any, Response any] struct {
 	stopwaiter.StopWaiter
 	id          string
-	client      redis.UniversalClient
+	transport   Transport
 	redisStream string
-	redisGroup  string
 	cfg         *ProducerConfig
 
 	promisesLock sync.RWMutex
-	promises     map[string]*containers.Promise[Response]
+	promises     map[requestKey]*pendingPromise[Response]
+	// Priority lanes above the lowest one which have been created.
+	createdLanes []bool
 
 	// Used for checking responses from consumers iteratively
 	// For the first time when Produce is called.
 	once sync.Once
 }
 
+type requestKey struct {
+	stream string
+	id     string
+}
+
+type pendingPromise[Response any] struct {
+	promise  *containers.Promise[Response]
+	deadline time.Time
+}
+
 // lint:require-exhaustive-initialization
 type ProducerConfig struct {
 	// Interval duration for checking the result set by consumers.
 	CheckResultInterval time.Duration `koanf:"check-result-interval"`
 	// RequestTimeout is a TTL for any message sent to the redis stream
 	RequestTimeout time.Duration `koanf:"request-timeout"`
+	// Number of priority lanes, each of which is a separate stream.
+	PriorityLanes int `koanf:"priority-lanes"`
 }
 
 var DefaultProducerConfig = ProducerConfig{
 	CheckResultInterval: 5 * time.Second,
 	RequestTimeout:      3 * time.Hour,
+	PriorityLanes:       1,
 }
 
 var TestProducerConfig = ProducerConfig{
 	CheckResultInterval: 5 * time.Millisecond,
 	RequestTimeout:      time.Minute,
+	PriorityLanes:       1,
+}
+
+func (c *ProducerConfig) Validate() error {
+	if c.PriorityLanes < 1 {
+		return fmt.Errorf("priority-lanes must be at least 1, got %d", c.PriorityLanes)
+	}
+	return nil
 }
 
 func ProducerAddConfigAddOptions(prefix string, f *pflag.FlagSet) {
 	f.Duration(prefix+".check-result-interval", DefaultProducerConfig.CheckResultInterval, "interval in which producer checks pending messages whether consumer processing them is inactive")
 	f.Duration(prefix+".request-timeout", DefaultProducerConfig.RequestTimeout, "timeout after which the message in redis stream is considered as errored, this prevents workers from working on wrong requests indefinitely")
+	f.Int(prefix+".priority-lanes", DefaultProducerConfig.PriorityLanes, "number of priority lanes, consumers take requests of higher priority lanes first; should be set to the same value for all consumers and producers")
 }
 
 func NewProducer[Request any, Response any](client redis.UniversalClient, streamName string, cfg *ProducerConfig) (*Producer[Request, Response], error) {
 	if client == nil {
 		return nil, fmt.Errorf("redis client cannot be nil")
 	}
+	return NewProducerWithTransport[Request, Response](NewRedisTransport(client), streamName, cfg)
+}
+
+func NewProducerWithTransport[Request any, Response any](transport Transport, streamName string, cfg *ProducerConfig) (*Producer[Request, Response], error) {
+	if transport == nil {
+		return nil, fmt.Errorf("transport cannot be nil")
+	}
 	if streamName == "" {
 		return nil, fmt.Errorf("stream name cannot be empty")
 	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
 	return &Producer[Request, Response]{
-		id:          uuid.NewString(),
-		client:      client,
-		redisStream: streamName,
-		redisGroup:  streamName, // There is 1-1 mapping of redis stream and consumer group.
-		cfg:         cfg,
-		promises:    make(map[string]*containers.Promise[Response]),
+		id:           uuid.NewString(),
+		transport:    transport,
+		redisStream:  streamName,
+		cfg:          cfg,
+		promises:     make(map[requestKey]*pendingPromise[Response]),
+		createdLanes: make([]bool, cfg.PriorityLanes),
 	}, nil
 }
 
+// CreateStreams creates the streams of all the priority lanes.
+func (p *Producer[Request, Response]) CreateStreams(ctx context.Context) error {
+	for lane := 0; lane < p.cfg.PriorityLanes; lane++ {
+		if err := p.transport.CreateStream(ctx, LaneStreamName(p.redisStream, lane)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func getUintParts(msgId string) ([2]uint64, error) {
 	idParts := strings.Split(msgId, "-")
 	if len(idParts) != 2 {
@@ -134,105 +178,95 @@ func cmpMsgId(msgId1, msgId2 string) int {
 
 // checkResponses checks iteratively whether response for the promise is ready.
 func (p *Producer[Request, Response]) checkResponses(ctx context.Context) time.Duration {
-	log.Debug("redis producer: check responses starting")
+	log.Debug("producer: check responses starting")
 	p.promisesLock.Lock()
 	defer p.promisesLock.Unlock()
 	responded := 0
 	errored := 0
 	checked := 0
-	allowedOldestID := fmt.Sprintf("%d-0", time.Now().Add(-p.cfg.RequestTimeout).UnixMilli())
-	for id, promise := range p.promises {
+	now := time.Now()
+	allowedOldestID := formatID(now.Add(-p.cfg.RequestTimeout), 0)
+	for key, pending := range p.promises {
 		if ctx.Err() != nil {
 			return 0
 		}
 		checked++
-		// First check if there is an error for this promise
-		errorKey := ErrorKeyFor(p.redisStream, id)
-		errorResponse, err := p.client.Get(ctx, errorKey).Result()
-		if err != nil && !errors.Is(err, redis.Nil) {
-			// If we get an error that is not redis.Nil, then log it and continue.
-			log.Error("Error reading error in redis", "key", errorKey, "error", err)
+		id, promise := key.id, pending.promise
+		response, err := p.transport.TakeResponse(ctx, key.stream, id)
+		if err != nil {
+			// If we get an error reading the response, then log it and continue.
+			log.Error("Error reading response", "msgId", id, "error", err)
 			continue
 		}
-		if err == nil {
-			// If we found the error key, then delete it and return the error to the promise and continue.
-			p.client.Del(ctx, errorKey)
-			promise.ProduceError(errors.New(errorResponse))
-			log.Debug("consumer returned error", "error", errorResponse, "msgId", id)
+		// A result which arrives after the deadline is still returned, but
+		// errors, including the consumer's own deadline error, are replaced
+		// with a DeadlineExceededError.
+		if (response == nil || response.IsError) && !pending.deadline.IsZero() && now.After(pending.deadline) {
+			promise.ProduceError(&DeadlineExceededError{Deadline: pending.deadline})
+			log.Debug("request deadline exceeded", "msgId", id, "stream", key.stream, "deadline", pending.deadline)
 			errored++
-			delete(p.promises, id)
+			delete(p.promises, key)
 			continue
 		}
-		// If we do not find the error key, then check for the result key.
-		resultKey := ResultKeyFor(p.redisStream, id)
-		res, err := p.client.Get(ctx, resultKey).Result()
-		if err != nil {
-			if !errors.Is(err, redis.Nil) {
-				log.Error("Error reading value in redis", "key", resultKey, "error", err)
-			} else if cmpMsgId(id, allowedOldestID) == -1 {
+		if response == nil {
+			if cmpMsgId(id, allowedOldestID) == -1 {
 				// The request this producer is waiting for has been past its TTL or is older than current PEL's lower,
 				// so safe to error and stop tracking this promise
 				promise.ProduceError(errors.New("error getting response, " + TimeoutErrorMessage))
 				log.Debug("request timed out waiting for response", "msgId", id, "allowedOldestId", allowedOldestID)
 				errored++
-				delete(p.promises, id)
+				delete(p.promises, key)
 			}
 			continue
 		}
+		if response.IsError {
+			promise.ProduceError(errors.New(response.Error))
+			log.Debug("consumer returned error", "error", response.Error, "msgId", id)
+			errored++
+			delete(p.promises, key)
+			continue
+		}
 		var resp Response
-		if err := json.Unmarshal([]byte(res), &resp); err != nil {
+		if err := json.Unmarshal(response.Data, &resp); err != nil {
 			promise.ProduceError(fmt.Errorf("error unmarshalling: %w", err))
-			log.Error("redis producer: Error unmarshaling", "value", res, "error", err)
+			log.Error("producer: Error unmarshaling", "value", string(response.Data), "error", err)
 			errored++
 		} else {
 			promise.Produce(resp)
 			responded++
 		}
-		p.client.Del(ctx, resultKey)
-		delete(p.promises, id)
+		delete(p.promises, key)
 	}
 	log.Debug("checkResponses", "responded", responded, "errored", errored, "checked", checked)
 	return p.cfg.CheckResultInterval
 }
 
 func (p *Producer[Request, Response]) clearMessages(ctx context.Context) time.Duration {
-	pelData, err := p.client.XPending(ctx, p.redisStream, p.redisGroup).Result()
-	if err != nil {
-		log.Error("error getting PEL data from xpending, xtrimming is disabled", "err", err)
-	}
-	// XDEL on consumer side already deletes acked messages (mark as deleted) but doesn't claim the memory back, XTRIM helps in claiming this memory in normal conditions
-	// pelData might be outdated when we do the xtrim, but that's ok as the messages are also being trimmed by other producers
-	if pelData != nil && pelData.Lower != "" {
-		trimmed, trimErr := p.client.XTrimMinID(ctx, p.redisStream, pelData.Lower).Result()
-		log.Debug("trimming", "xTrimMinID", pelData.Lower, "trimmed", trimmed, "trim-err", trimErr)
-		// Check if pelData.Lower has been past its TTL and if it is then ack it to remove from PEL and delete it, once
-		// its taken out from PEL the producer that sent this request will handle the corresponding promise accordingly (as its past TTL)
-		allowedOldestID := fmt.Sprintf("%d-0", time.Now().Add(-p.cfg.RequestTimeout).UnixMilli())
-		if cmpMsgId(pelData.Lower, allowedOldestID) == -1 {
-			if err := p.client.XClaim(ctx, &redis.XClaimArgs{
-				Stream:   p.redisStream,
-				Group:    p.redisGroup,
-				Consumer: p.id,
-				MinIdle:  0,
-				Messages: []string{pelData.Lower},
-			}).Err(); err != nil {
-				log.Error("error claiming PEL's lower message that's past its TTL", "msgID", pelData.Lower, "err", err)
-				return 5 * p.cfg.CheckResultInterval
-			}
-			if _, err := p.client.XAck(ctx, p.redisStream, p.redisGroup, pelData.Lower).Result(); err != nil {
-				log.Error("error acking PEL's lower message that's past its TTL", "msgID", pelData.Lower, "err", err)
-				return 5 * p.cfg.CheckResultInterval
-			}
-			if _, err := p.client.XDel(ctx, p.redisStream, pelData.Lower).Result(); err != nil {
-				log.Error("error deleting PEL's lower message that's past its TTL", "msgID", pelData.Lower, "err", err)
-				return 5 * p.cfg.CheckResultInterval
-			}
-			return 0
+	anyDropped := false
+	for lane := 0; lane < p.cfg.PriorityLanes; lane++ {
+		if lane > 0 && !p.laneCreated(lane) {
+			continue
 		}
+		stream := LaneStreamName(p.redisStream, lane)
+		dropped, err := p.transport.Trim(ctx, stream, p.id, time.Now().Add(-p.cfg.RequestTimeout))
+		if err != nil {
+			log.Error("error trimming stream", "stream", stream, "err", err)
+			continue
+		}
+		anyDropped = anyDropped || dropped
+	}
+	if anyDropped {
+		return 0
 	}
 	return 5 * p.cfg.CheckResultInterval
 }
 
+func (p *Producer[Request, Response]) laneCreated(lane int) bool {
+	p.promisesLock.RLock()
+	defer p.promisesLock.RUnlock()
+	return p.createdLanes[lane]
+}
+
 func (p *Producer[Request, Response]) Start(ctx context.Context) {
 	p.StopWaiter.Start(ctx, p)
 }
@@ -243,31 +277,47 @@ func (p *Producer[Request, Response]) promisesLen() int {
 	return len(p.promises)
 }
 
-func (p *Producer[Request, Response]) produce(ctx context.Context, value Request) (*containers.Promise[Response], error) {
+func (p *Producer[Request, Response]) produce(ctx context.Context, value Request, opts ProduceOptions) (*containers.Promise[Response], error) {
 	val, err := json.Marshal(value)
 	if err != nil {
 		return nil, fmt.Errorf("marshaling value: %w", err)
 	}
-	// catching the promiseLock before we sendXadd makes sure promise ids will be always ascending
+	lane := laneOf(opts.Priority, p.cfg.PriorityLanes)
+	stream := LaneStreamName(p.redisStream, lane)
+	// catching the promiseLock before we publish makes sure promise ids will be always ascending
 	p.promisesLock.Lock()
 	defer p.promisesLock.Unlock()
-	msgId, err := p.client.XAdd(ctx, &redis.XAddArgs{
-		Stream: p.redisStream,
-		Values: map[string]any{messageKey: val},
-	}).Result()
+	// Higher priority lanes are created on first use, consumers pick them up
+	// once they exist.
+	if lane > 0 && !p.createdLanes[lane] {
+		if err := p.transport.CreateStream(ctx, stream); err != nil {
+			return nil, fmt.Errorf("creating priority lane stream %s: %w", stream, err)
+		}
+		p.createdLanes[lane] = true
+	}
+	msgId, err := p.transport.Publish(ctx, stream, val, opts.Deadline)
 	if err != nil {
-		return nil, fmt.Errorf("adding values to redis: %w", err)
+		return nil, err
 	}
 	promise := containers.NewPromise[Response](nil)
-	p.promises[msgId] = &promise
+	p.promises[requestKey{stream: stream, id: msgId}] = &pendingPromise[Response]{
+		promise:  &promise,
+		deadline: opts.Deadline,
+	}
 	return &promise, nil
 }
 
 func (p *Producer[Request, Response]) Produce(ctx context.Context, value Request) (*containers.Promise[Response], error) {
-	log.Debug("Redis stream producing", "value", value)
+	return p.ProduceWithOptions(ctx, value, ProduceOptions{})
+}
+
+// ProduceWithOptions produces the request with the given priority and
+// deadline. Deadlines are checked every CheckResultInterval.
+func (p *Producer[Request, Response]) ProduceWithOptions(ctx context.Context, value Request, opts ProduceOptions) (*containers.Promise[Response], error) {
+	log.Debug("Stream producing", "value", value, "priority", opts.Priority, "deadline", opts.Deadline)
 	p.once.Do(func() {
 		p.StopWaiter.CallIteratively(p.checkResponses)
 		p.StopWaiter.CallIteratively(p.clearMessages)
 	})
-	return p.produce(ctx, value)
+	return p.produce(ctx, value, opts)
 }
@@ -108,10 +108,10 @@ func newProducersConsumersForConfigs(ctx context.Context, t *testing.T, producer
 		}
 		consumers = append(consumers, c)
 	}
-	createRedisGroup(ctx, t, streamName, producers[0].client)
+	createRedisGroup(ctx, t, streamName, redisClient)
 	t.Cleanup(func() {
 		ctx := context.Background()
-		destroyRedisGroup(ctx, t, streamName, producers[0].client)
+		destroyRedisGroup(ctx, t, streamName, redisClient)
 	})
 	return redisClient, streamName, producers, consumers
 }
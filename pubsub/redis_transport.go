@@ -0,0 +1,298 @@
+// Copyright 2024-2026, Offchain Labs, Inc.
+// For license information, see https://github.com/OffchainLabs/nitro/blob/master/LICENSE.md
+package pubsub
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// RedisTransport implements Transport with redis streams. Every stream has a
+// single consumer group with the same name, and responses are stored in
+// separate keys.
+type RedisTransport struct {
+	client redis.UniversalClient
+}
+
+func NewRedisTransport(client redis.UniversalClient) *RedisTransport {
+	return &RedisTransport{client: client}
+}
+
+func (t *RedisTransport) Client() redis.UniversalClient {
+	return t.client
+}
+
+func (t *RedisTransport) CreateStream(ctx context.Context, stream string) error {
+	return CreateStream(ctx, stream, t.client)
+}
+
+func (t *RedisTransport) StreamExists(ctx context.Context, stream string) bool {
+	return StreamExists(ctx, stream, t.client)
+}
+
+func (t *RedisTransport) Publish(ctx context.Context, stream string, data []byte, deadline time.Time) (string, error) {
+	values := map[string]any{messageKey: data}
+	if !deadline.IsZero() {
+		values[deadlineKey] = deadline.UnixMilli()
+	}
+	msgId, err := t.client.XAdd(ctx, &redis.XAddArgs{
+		Stream: stream,
+		Values: values,
+	}).Result()
+	if err != nil {
+		return "", fmt.Errorf("adding values to redis: %w", err)
+	}
+	return msgId, nil
+}
+
+func (t *RedisTransport) TakeResponse(ctx context.Context, stream, id string) (*Response, error) {
+	// First check if there is an error for this request
+	errorKey := ErrorKeyFor(stream, id)
+	errorResponse, err := t.client.Get(ctx, errorKey).Result()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return nil, fmt.Errorf("reading error %s in redis: %w", errorKey, err)
+	}
+	if err == nil {
+		t.client.Del(ctx, errorKey)
+		return &Response{Error: errorResponse, IsError: true}, nil
+	}
+	// If we do not find the error key, then check for the result key.
+	resultKey := ResultKeyFor(stream, id)
+	res, err := t.client.Get(ctx, resultKey).Result()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading value %s in redis: %w", resultKey, err)
+	}
+	t.client.Del(ctx, resultKey)
+	return &Response{Data: []byte(res)}, nil
+}
+
+func (t *RedisTransport) Trim(ctx context.Context, stream, producerID string, expiredBefore time.Time) (bool, error) {
+	pelData, err := t.client.XPending(ctx, stream, stream).Result()
+	if err != nil {
+		log.Error("error getting PEL data from xpending, xtrimming is disabled", "err", err)
+	}
+	// XDEL on consumer side already deletes acked messages (mark as deleted) but doesn't claim the memory back, XTRIM helps in claiming this memory in normal conditions
+	// pelData might be outdated when we do the xtrim, but that's ok as the messages are also being trimmed by other producers
+	if pelData == nil || pelData.Lower == "" {
+		return false, nil
+	}
+	trimmed, trimErr := t.client.XTrimMinID(ctx, stream, pelData.Lower).Result()
+	log.Debug("trimming", "xTrimMinID", pelData.Lower, "trimmed", trimmed, "trim-err", trimErr)
+	// Check if pelData.Lower has been past its TTL and if it is then ack it to remove from PEL and delete it, once
+	// its taken out from PEL the producer that sent this request will handle the corresponding promise accordingly (as its past TTL)
+	allowedOldestID := formatID(expiredBefore, 0)
+	if cmpMsgId(pelData.Lower, allowedOldestID) != -1 {
+		return false, nil
+	}
+	if err := t.client.XClaim(ctx, &redis.XClaimArgs{
+		Stream:   stream,
+		Group:    stream,
+		Consumer: producerID,
+		MinIdle:  0,
+		Messages: []string{pelData.Lower},
+	}).Err(); err != nil {
+		return false, fmt.Errorf("claiming PEL's lower message %s that's past its TTL: %w", pelData.Lower, err)
+	}
+	if _, err := t.client.XAck(ctx, stream, stream, pelData.Lower).Result(); err != nil {
+		return false, fmt.Errorf("acking PEL's lower message %s that's past its TTL: %w", pelData.Lower, err)
+	}
+	if _, err := t.client.XDel(ctx, stream, pelData.Lower).Result(); err != nil {
+		return false, fmt.Errorf("deleting PEL's lower message %s that's past its TTL: %w", pelData.Lower, err)
+	}
+	return true, nil
+}
+
+func (t *RedisTransport) Pending(ctx context.Context, stream string, idle time.Duration, count int64) ([]PendingRequest, error) {
+	pendingMsgs, err := t.client.XPendingExt(ctx, &redis.XPendingExtArgs{
+		Stream: stream,
+		Group:  stream,
+		Start:  "-",
+		End:    "+",
+		Count:  count,
+		Idle:   idle,
+	}).Result()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	pending := make([]PendingRequest, 0, len(pendingMsgs))
+	for _, msg := range pendingMsgs {
+		pending = append(pending, PendingRequest{ID: msg.ID, Deliveries: msg.RetryCount})
+	}
+	return pending, nil
+}
+
+func decrementMsgIdByOne(msgId string) string {
+	id, err := getUintParts(msgId)
+	if err != nil {
+		log.Error("Error decrementing start of XAutoClaim by one, defaulting to 0", "err", err)
+		return "0"
+	}
+	if id[1] > 0 {
+		return strconv.FormatUint(id[0], 10) + "-" + strconv.FormatUint(id[1]-1, 10)
+	} else if id[0] > 0 {
+		return strconv.FormatUint(id[0]-1, 10) + "-" + strconv.FormatUint(math.MaxUint64, 10)
+	}
+	return "0"
+}
+
+func (t *RedisTransport) ClaimIdle(ctx context.Context, stream, consumerID, id string, idle time.Duration) (*Delivery, error) {
+	messages, _, err := t.client.XAutoClaim(ctx, &redis.XAutoClaimArgs{
+		Group:    stream,
+		Consumer: consumerID,
+		MinIdle:  idle, // Minimum idle time for messages to claim (in milliseconds)
+		Stream:   stream,
+		Start:    decrementMsgIdByOne(id),
+		Count:    1,
+	}).Result()
+	if err != nil {
+		return nil, err
+	}
+	if len(messages) == 0 {
+		return nil, nil
+	}
+	return deliveryFromMessage(messages[0])
+}
+
+func (t *RedisTransport) ReadNew(ctx context.Context, stream, consumerID string) (*Delivery, error) {
+	res, err := t.client.XReadGroup(ctx, &redis.XReadGroupArgs{
+		Group:    stream,
+		Consumer: consumerID,
+		// Receive only messages that were never delivered to any other consumer,
+		// that is, only new messages.
+		Streams: []string{stream, ">"},
+		Count:   1,
+		Block:   time.Millisecond, // 0 seems to block the read instead of immediately returning
+	}).Result()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(res) != 1 || len(res[0].Messages) != 1 {
+		return nil, fmt.Errorf("redis returned entries: %+v, for querying single message", res)
+	}
+	return deliveryFromMessage(res[0].Messages[0])
+}
+
+func deliveryFromMessage(message redis.XMessage) (*Delivery, error) {
+	data, ok := message.Values[messageKey].(string)
+	if !ok {
+		return nil, errors.New("error casting request to string")
+	}
+	delivery := &Delivery{ID: message.ID, Data: []byte(data)}
+	// Requests of older producers have no deadline
+	if value, ok := message.Values[deadlineKey].(string); ok {
+		deadline, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid request deadline %q: %w", value, err)
+		}
+		delivery.Deadline = time.UnixMilli(deadline)
+	}
+	return delivery, nil
+}
+
+func (t *RedisTransport) Heartbeat(ctx context.Context, stream, consumerID, id string) error {
+	// Use XClaimJustID so that we would have clear difference between invalid requests that are claimed multiple times due to xautoclaim and
+	// valid requests that are just being claimed in regular intervals to indicate heartbeat
+	ids, err := t.client.XClaimJustID(ctx, &redis.XClaimArgs{
+		Stream:   stream,
+		Group:    stream,
+		Consumer: consumerID,
+		MinIdle:  0,
+		Messages: []string{id},
+	}).Result()
+	if err != nil {
+		return err
+	}
+	if len(ids) == 0 {
+		log.Warn("XClaimJustID returned empty response when indicating heartbeat", "msgID", id)
+	} else if len(ids) > 1 {
+		log.Error("XClaimJustID returned response with more than entry", "msgIDs", ids)
+	}
+	return nil
+}
+
+func (t *RedisTransport) Release(ctx context.Context, stream, consumerID, id string, idle time.Duration) error {
+	return t.client.Do(ctx, "XCLAIM", stream, stream, consumerID, 0, id, "IDLE", idle.Milliseconds()).Err()
+}
+
+func (t *RedisTransport) Respond(ctx context.Context, stream, id string, response *Response, ttl time.Duration) error {
+	key := ResultKeyFor(stream, id)
+	var value any = response.Data
+	if response.IsError {
+		key = ErrorKeyFor(stream, id)
+		value = response.Error
+	}
+	acquired, err := t.client.SetNX(ctx, key, value, ttl).Result()
+	if !acquired && err == nil {
+		err = ErrAlreadySet
+	}
+	if err != nil {
+		return fmt.Errorf("setting response for message with message-id in stream: %v, error: %w", id, err)
+	}
+	if _, err := t.client.XAck(ctx, stream, stream, id).Result(); err != nil {
+		return fmt.Errorf("acking message: %v, error: %w", id, err)
+	}
+	if _, err := t.client.XDel(ctx, stream, id).Result(); err != nil {
+		return fmt.Errorf("deleting message: %v, error: %w", id, err)
+	}
+	return nil
+}
+
+func (t *RedisTransport) Range(ctx context.Context, stream, start string, count int64) ([]Delivery, error) {
+	if start == "" {
+		start = "-"
+	}
+	messages, err := t.client.XRangeN(ctx, stream, start, "+", count).Result()
+	if err != nil {
+		return nil, err
+	}
+	deliveries := make([]Delivery, 0, len(messages))
+	for _, message := range messages {
+		delivery, err := deliveryFromMessage(message)
+		if err != nil {
+			return nil, fmt.Errorf("reading message %s: %w", message.ID, err)
+		}
+		deliveries = append(deliveries, *delivery)
+	}
+	return deliveries, nil
+}
+
+func (t *RedisTransport) Delete(ctx context.Context, stream, id string) error {
+	// Acking removes the request from the PEL if it was delivered, and does
+	// nothing for streams without a consumer group
+	if _, err := t.client.XAck(ctx, stream, stream, id).Result(); err != nil {
+		return fmt.Errorf("acking message: %v, error: %w", id, err)
+	}
+	if _, err := t.client.XDel(ctx, stream, id).Result(); err != nil {
+		return fmt.Errorf("deleting message: %v, error: %w", id, err)
+	}
+	return nil
+}
+
+func (t *RedisTransport) RecordFailure(ctx context.Context, stream, id, failure string, ttl time.Duration) error {
+	return t.client.Set(ctx, FailureKeyFor(stream, id), failure, ttl).Err()
+}
+
+func (t *RedisTransport) LastFailure(ctx context.Context, stream, id string) (string, error) {
+	failure, err := t.client.Get(ctx, FailureKeyFor(stream, id)).Result()
+	if errors.Is(err, redis.Nil) {
+		return "", nil
+	}
+	return failure, err
+}
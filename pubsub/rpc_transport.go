@@ -0,0 +1,287 @@
+// Copyright 2026, Offchain Labs, Inc.
+// For license information, see https://github.com/OffchainLabs/nitro/blob/master/LICENSE.md
+package pubsub
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/spf13/pflag"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/offchainlabs/nitro/cmd/genericconf"
+	"github.com/offchainlabs/nitro/util/stopwaiter"
+)
+
+// TransportNamespace is the RPC namespace served by a TransportServer.
+const TransportNamespace = "pubsub"
+
+// errAlreadySetCode is the RPC error code for ErrAlreadySet, which consumers
+// need to tell apart from other errors.
+const errAlreadySetCode = -32099
+
+type TransportServerConfig struct {
+	Enable         bool                                `koanf:"enable"`
+	Addr           string                              `koanf:"addr"`
+	Port           uint64                              `koanf:"port"`
+	ServerTimeouts genericconf.HTTPServerTimeoutConfig `koanf:"server-timeouts"`
+}
+
+var DefaultTransportServerConfig = TransportServerConfig{
+	Enable:         false,
+	Addr:           "localhost",
+	Port:           9650,
+	ServerTimeouts: genericconf.HTTPServerTimeoutConfigDefault,
+}
+
+func TransportServerConfigAddOptions(prefix string, f *pflag.FlagSet) {
+	f.Bool(prefix+".enable", DefaultTransportServerConfig.Enable, "enable the pubsub transport server")
+	f.String(prefix+".addr", DefaultTransportServerConfig.Addr, "pubsub transport server listening interface")
+	f.Uint64(prefix+".port", DefaultTransportServerConfig.Port, "pubsub transport server listening port")
+	genericconf.HTTPServerTimeoutConfigAddOptions(prefix+".server-timeouts", f)
+}
+
+// TransportServer serves a transport, by default a MemoryTransport, over
+// JSON-RPC, so that producers and consumers in different processes can use it
+// with a "pubsub://<host>:<port>" url without operating a redis server.
+type TransportServer struct {
+	stopwaiter.StopWaiter
+	config    *TransportServerConfig
+	transport Transport
+	listener  net.Listener
+	server    *http.Server
+}
+
+func NewTransportServer(config *TransportServerConfig, transport Transport) (*TransportServer, error) {
+	if transport == nil {
+		transport = NewMemoryTransport()
+	}
+	rpcServer := rpc.NewServer()
+	if err := rpcServer.RegisterName(TransportNamespace, &transportAPI{transport: transport}); err != nil {
+		return nil, err
+	}
+	return &TransportServer{
+		config:    config,
+		transport: transport,
+		server: &http.Server{
+			Handler:           rpcServer,
+			ReadTimeout:       config.ServerTimeouts.ReadTimeout,
+			ReadHeaderTimeout: config.ServerTimeouts.ReadHeaderTimeout,
+			WriteTimeout:      config.ServerTimeouts.WriteTimeout,
+			IdleTimeout:       config.ServerTimeouts.IdleTimeout,
+		},
+	}, nil
+}
+
+func (s *TransportServer) Start(ctx context.Context) error {
+	listener, err := net.Listen("tcp", fmt.Sprintf("%s:%d", s.config.Addr, s.config.Port))
+	if err != nil {
+		return err
+	}
+	s.listener = listener
+	s.StopWaiter.Start(ctx, s)
+	s.LaunchThread(func(ctx context.Context) {
+		if err := s.server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Error("pubsub transport server failed", "err", err)
+		}
+	})
+	s.LaunchThread(func(ctx context.Context) {
+		<-ctx.Done()
+		_ = s.server.Shutdown(context.Background())
+	})
+	log.Info("pubsub transport server listening", "addr", listener.Addr())
+	return nil
+}
+
+// URL returns the url for connecting to the server with TransportFromURL.
+func (s *TransportServer) URL() string {
+	return "pubsub://" + s.listener.Addr().String()
+}
+
+type alreadySetError struct{}
+
+func (alreadySetError) Error() string  { return ErrAlreadySet.Error() }
+func (alreadySetError) ErrorCode() int { return errAlreadySetCode }
+
+// transportAPI exposes a Transport over JSON-RPC.
+type transportAPI struct {
+	transport Transport
+}
+
+func (a *transportAPI) CreateStream(ctx context.Context, stream string) error {
+	return a.transport.CreateStream(ctx, stream)
+}
+
+func (a *transportAPI) StreamExists(ctx context.Context, stream string) bool {
+	return a.transport.StreamExists(ctx, stream)
+}
+
+func (a *transportAPI) Publish(ctx context.Context, stream string, data []byte, deadline time.Time) (string, error) {
+	return a.transport.Publish(ctx, stream, data, deadline)
+}
+
+func (a *transportAPI) TakeResponse(ctx context.Context, stream, id string) (*Response, error) {
+	return a.transport.TakeResponse(ctx, stream, id)
+}
+
+func (a *transportAPI) Trim(ctx context.Context, stream, producerID string, expiredBefore time.Time) (bool, error) {
+	return a.transport.Trim(ctx, stream, producerID, expiredBefore)
+}
+
+func (a *transportAPI) Pending(ctx context.Context, stream string, idle time.Duration, count int64) ([]PendingRequest, error) {
+	return a.transport.Pending(ctx, stream, idle, count)
+}
+
+func (a *transportAPI) ClaimIdle(ctx context.Context, stream, consumerID, id string, idle time.Duration) (*Delivery, error) {
+	return a.transport.ClaimIdle(ctx, stream, consumerID, id, idle)
+}
+
+func (a *transportAPI) ReadNew(ctx context.Context, stream, consumerID string) (*Delivery, error) {
+	return a.transport.ReadNew(ctx, stream, consumerID)
+}
+
+func (a *transportAPI) Heartbeat(ctx context.Context, stream, consumerID, id string) error {
+	return a.transport.Heartbeat(ctx, stream, consumerID, id)
+}
+
+func (a *transportAPI) Release(ctx context.Context, stream, consumerID, id string, idle time.Duration) error {
+	return a.transport.Release(ctx, stream, consumerID, id, idle)
+}
+
+func (a *transportAPI) Respond(ctx context.Context, stream, id string, response *Response, ttl time.Duration) error {
+	err := a.transport.Respond(ctx, stream, id, response, ttl)
+	if errors.Is(err, ErrAlreadySet) {
+		return alreadySetError{}
+	}
+	return err
+}
+
+func (a *transportAPI) Range(ctx context.Context, stream, start string, count int64) ([]Delivery, error) {
+	return a.transport.Range(ctx, stream, start, count)
+}
+
+func (a *transportAPI) Delete(ctx context.Context, stream, id string) error {
+	return a.transport.Delete(ctx, stream, id)
+}
+
+func (a *transportAPI) RecordFailure(ctx context.Context, stream, id, failure string, ttl time.Duration) error {
+	return a.transport.RecordFailure(ctx, stream, id, failure, ttl)
+}
+
+func (a *transportAPI) LastFailure(ctx context.Context, stream, id string) (string, error) {
+	return a.transport.LastFailure(ctx, stream, id)
+}
+
+// RPCTransport implements Transport by calling a TransportServer.
+type RPCTransport struct {
+	client *rpc.Client
+}
+
+// DialTransport connects to the TransportServer at the given http url.
+func DialTransport(ctx context.Context, url string) (*RPCTransport, error) {
+	client, err := rpc.DialContext(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+	return &RPCTransport{client: client}, nil
+}
+
+func (t *RPCTransport) call(ctx context.Context, result any, method string, args ...any) error {
+	return t.client.CallContext(ctx, result, TransportNamespace+"_"+method, args...)
+}
+
+func (t *RPCTransport) CreateStream(ctx context.Context, stream string) error {
+	return t.call(ctx, nil, "createStream", stream)
+}
+
+func (t *RPCTransport) StreamExists(ctx context.Context, stream string) bool {
+	var exists bool
+	if err := t.call(ctx, &exists, "streamExists", stream); err != nil {
+		log.Error("pubsub transport error", "err", err, "searching stream", stream)
+		return false
+	}
+	return exists
+}
+
+func (t *RPCTransport) Publish(ctx context.Context, stream string, data []byte, deadline time.Time) (string, error) {
+	var id string
+	err := t.call(ctx, &id, "publish", stream, data, deadline)
+	return id, err
+}
+
+func (t *RPCTransport) TakeResponse(ctx context.Context, stream, id string) (*Response, error) {
+	var response *Response
+	err := t.call(ctx, &response, "takeResponse", stream, id)
+	return response, err
+}
+
+func (t *RPCTransport) Trim(ctx context.Context, stream, producerID string, expiredBefore time.Time) (bool, error) {
+	var dropped bool
+	err := t.call(ctx, &dropped, "trim", stream, producerID, expiredBefore)
+	return dropped, err
+}
+
+func (t *RPCTransport) Pending(ctx context.Context, stream string, idle time.Duration, count int64) ([]PendingRequest, error) {
+	var pending []PendingRequest
+	err := t.call(ctx, &pending, "pending", stream, idle, count)
+	return pending, err
+}
+
+func (t *RPCTransport) ClaimIdle(ctx context.Context, stream, consumerID, id string, idle time.Duration) (*Delivery, error) {
+	var delivery *Delivery
+	err := t.call(ctx, &delivery, "claimIdle", stream, consumerID, id, idle)
+	return delivery, err
+}
+
+func (t *RPCTransport) ReadNew(ctx context.Context, stream, consumerID string) (*Delivery, error) {
+	var delivery *Delivery
+	err := t.call(ctx, &delivery, "readNew", stream, consumerID)
+	return delivery, err
+}
+
+func (t *RPCTransport) Heartbeat(ctx context.Context, stream, consumerID, id string) error {
+	return t.call(ctx, nil, "heartbeat", stream, consumerID, id)
+}
+
+func (t *RPCTransport) Release(ctx context.Context, stream, consumerID, id string, idle time.Duration) error {
+	return t.call(ctx, nil, "release", stream, consumerID, id, idle)
+}
+
+func (t *RPCTransport) Respond(ctx context.Context, stream, id string, response *Response, ttl time.Duration) error {
+	err := t.call(ctx, nil, "respond", stream, id, response, ttl)
+	var rpcErr rpc.Error
+	if errors.As(err, &rpcErr) && rpcErr.ErrorCode() == errAlreadySetCode {
+		return ErrAlreadySet
+	}
+	return err
+}
+
+func (t *RPCTransport) Range(ctx context.Context, stream, start string, count int64) ([]Delivery, error) {
+	var deliveries []Delivery
+	err := t.call(ctx, &deliveries, "range", stream, start, count)
+	return deliveries, err
+}
+
+func (t *RPCTransport) Delete(ctx context.Context, stream, id string) error {
+	return t.call(ctx, nil, "delete", stream, id)
+}
+
+func (t *RPCTransport) RecordFailure(ctx context.Context, stream, id, failure string, ttl time.Duration) error {
+	return t.call(ctx, nil, "recordFailure", stream, id, failure, ttl)
+}
+
+func (t *RPCTransport) LastFailure(ctx context.Context, stream, id string) (string, error) {
+	var failure string
+	err := t.call(ctx, &failure, "lastFailure", stream, id)
+	return failure, err
+}
+
+func (t *RPCTransport) Close() {
+	t.client.Close()
+}
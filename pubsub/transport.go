@@ -0,0 +1,129 @@
+// Copyright 2026, Offchain Labs, Inc.
+// For license information, see https://github.com/OffchainLabs/nitro/blob/master/LICENSE.md
+package pubsub
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/offchainlabs/nitro/util/redisutil"
+)
+
+// Delivery is a request read from a stream by a consumer.
+type Delivery struct {
+	ID   string `json:"id"`
+	Data []byte `json:"data"`
+	// Deadline after which the producer no longer waits for the response,
+	// zero if there is none.
+	Deadline time.Time `json:"deadline"`
+}
+
+// PendingRequest is a request which has been delivered to a consumer but not
+// responded to yet.
+type PendingRequest struct {
+	ID string `json:"id"`
+	// Number of times the request has been delivered to consumers.
+	Deliveries int64 `json:"deliveries"`
+}
+
+// Response is the result or the error set by a consumer for a request.
+type Response struct {
+	Data    []byte `json:"data,omitempty"`
+	Error   string `json:"error,omitempty"`
+	IsError bool   `json:"isError,omitempty"`
+}
+
+// Transport carries the requests from producers to consumers and their
+// responses back. Producer and Consumer implement the request lifecycle on top
+// of it: claiming, heartbeats, retries and timeouts.
+//
+// Request IDs must be formatted as "<unix milliseconds>-<serial>" and be
+// ascending within a stream, as producers derive request timeouts from them.
+type Transport interface {
+	// CreateStream creates the stream and its consumer group, and does not
+	// return an error if it already exists.
+	CreateStream(ctx context.Context, stream string) error
+	// StreamExists returns whether consumers can read from the stream.
+	StreamExists(ctx context.Context, stream string) bool
+
+	// Publish appends the request to the stream and returns its ID. A zero
+	// deadline means the request does not expire before the producer's
+	// request timeout.
+	Publish(ctx context.Context, stream string, data []byte, deadline time.Time) (string, error)
+	// TakeResponse returns and deletes the response to the request, or nil
+	// if there is no response yet.
+	TakeResponse(ctx context.Context, stream, id string) (*Response, error)
+	// Trim releases the memory of responded requests and drops the oldest
+	// pending request if it was published before expiredBefore, claiming it
+	// for the producer. It returns whether a request was dropped.
+	Trim(ctx context.Context, stream, producerID string, expiredBefore time.Time) (bool, error)
+
+	// Pending returns up to count of the oldest pending requests which no
+	// consumer has claimed for at least idle.
+	Pending(ctx context.Context, stream string, idle time.Duration, count int64) ([]PendingRequest, error)
+	// ClaimIdle claims a pending request which no consumer has claimed for at
+	// least idle, starting with the given one. It returns nil if there is
+	// none.
+	ClaimIdle(ctx context.Context, stream, consumerID, id string, idle time.Duration) (*Delivery, error)
+	// ReadNew returns a request which was never delivered to any consumer,
+	// or nil if there is none.
+	ReadNew(ctx context.Context, stream, consumerID string) (*Delivery, error)
+	// Heartbeat renews the consumer's claim on the request.
+	Heartbeat(ctx context.Context, stream, consumerID, id string) error
+	// Release makes the request claimable by other consumers, as if it had
+	// not been claimed for idle.
+	Release(ctx context.Context, stream, consumerID, id string, idle time.Duration) error
+	// Respond sets the response to the request and removes the request from
+	// the stream. It returns ErrAlreadySet if a response has already been
+	// set. The response is kept for ttl.
+	Respond(ctx context.Context, stream, id string, response *Response, ttl time.Duration) error
+
+	// Range returns up to count requests of the stream, delivered or not,
+	// starting with the given ID or from the oldest one if start is empty.
+	Range(ctx context.Context, stream, start string, count int64) ([]Delivery, error)
+	// Delete removes the request from the stream without responding to it.
+	Delete(ctx context.Context, stream, id string) error
+
+	// RecordFailure records why processing the request failed, replacing
+	// the failure recorded before. It is kept for ttl, so that it can be
+	// reported when the request is moved to the dead-letter stream.
+	RecordFailure(ctx context.Context, stream, id, failure string, ttl time.Duration) error
+	// LastFailure returns the failure last recorded for the request, or ""
+	// if there is none.
+	LastFailure(ctx context.Context, stream, id string) (string, error)
+}
+
+// TransportFromURL creates the transport for the given url:
+//   - redis://, rediss://, unix:// and redis+sentinel:// urls use redis streams
+//   - memory://<name> uses the in-process transport with that name, shared by
+//     all producers and consumers of the process using the same name
+//   - pubsub://<host>:<port> connects to a TransportServer
+func TransportFromURL(transportURL string) (Transport, error) {
+	u, err := url.Parse(transportURL)
+	if err != nil {
+		return nil, err
+	}
+	switch u.Scheme {
+	case "memory":
+		return NamedMemoryTransport(u.Host + u.Path), nil
+	case "pubsub":
+		return DialTransport(context.Background(), "http://"+u.Host+u.Path)
+	case "pubsubs":
+		return DialTransport(context.Background(), "https://"+u.Host+u.Path)
+	}
+	client, err := redisutil.RedisClientFromURL(transportURL)
+	if err != nil {
+		return nil, err
+	}
+	if client == nil {
+		return nil, fmt.Errorf("unsupported pubsub transport url %q", transportURL)
+	}
+	return NewRedisTransport(client), nil
+}
+
+// formatID formats a request ID as expected by producers.
+func formatID(publishTime time.Time, serial uint64) string {
+	return fmt.Sprintf("%d-%d", publishTime.UnixMilli(), serial)
+}
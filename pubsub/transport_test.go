@@ -0,0 +1,168 @@
+// Copyright 2026, Offchain Labs, Inc.
+// For license information, see https://github.com/OffchainLabs/nitro/blob/master/LICENSE.md
+package pubsub
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func testTransports(ctx context.Context, t *testing.T) map[string]Transport {
+	t.Helper()
+	serverConfig := DefaultTransportServerConfig
+	serverConfig.Port = 0
+	server, err := NewTransportServer(&serverConfig, nil)
+	if err != nil {
+		t.Fatalf("NewTransportServer() unexpected error: %v", err)
+	}
+	if err := server.Start(ctx); err != nil {
+		t.Fatalf("Error starting transport server: %v", err)
+	}
+	t.Cleanup(server.StopAndWait)
+	rpcTransport, err := TransportFromURL(server.URL())
+	if err != nil {
+		t.Fatalf("TransportFromURL(%q) unexpected error: %v", server.URL(), err)
+	}
+	memoryTransport, err := TransportFromURL("memory://" + uuid.NewString())
+	if err != nil {
+		t.Fatalf("TransportFromURL() unexpected error: %v", err)
+	}
+	return map[string]Transport{
+		"memory": memoryTransport,
+		"rpc":    rpcTransport,
+	}
+}
+
+func TestTransportProduceConsume(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	for name, transport := range testTransports(ctx, t) {
+		t.Run(name, func(t *testing.T) {
+			streamName := fmt.Sprintf("stream:%s", uuid.NewString())
+			if transport.StreamExists(ctx, streamName) {
+				t.Fatal("stream exists before it is created")
+			}
+			if err := transport.CreateStream(ctx, streamName); err != nil {
+				t.Fatalf("Error creating stream: %v", err)
+			}
+			if !transport.StreamExists(ctx, streamName) {
+				t.Fatal("stream does not exist after it is created")
+			}
+			producer, err := NewProducerWithTransport[testRequest, testResponse](transport, streamName, &TestProducerConfig)
+			if err != nil {
+				t.Fatalf("Error creating new producer: %v", err)
+			}
+			producer.Start(ctx)
+			defer producer.StopAndWait()
+			var consumers []*Consumer[testRequest, testResponse]
+			for i := 0; i < 3; i++ {
+				consumer, err := NewConsumerWithTransport[testRequest, testResponse](transport, streamName, &TestConsumerConfig)
+				if err != nil {
+					t.Fatalf("Error creating new consumer: %v", err)
+				}
+				consumers = append(consumers, consumer)
+			}
+
+			entries := wantMessages([]int{20})[0]
+			promises, requests, err := produceMessages(ctx, entries, producer, true)
+			if err != nil {
+				t.Fatalf("Error producing messages: %v", err)
+			}
+
+			// The first consumer claims a request but stops without responding,
+			// so that the others take over its work.
+			consumers[0].Start(ctx)
+			killed, err := consumers[0].Consume(ctx)
+			if err != nil || killed == nil {
+				t.Fatalf("Error consuming message: %v", err)
+			}
+			consumers[0].StopAndWait()
+
+			for _, c := range consumers[1:] {
+				c.Start(ctx)
+				c.StopWaiter.LaunchThread(func(ctx context.Context) {
+					for {
+						msg, err := c.Consume(ctx)
+						if err != nil {
+							if !errors.Is(err, context.Canceled) {
+								t.Errorf("Consume() unexpected error: %v", err)
+							}
+							return
+						}
+						if msg == nil {
+							continue
+						}
+						if msg.Value.IsInvalid {
+							err = c.SetError(ctx, msg.ID, "invalid request: "+msg.Value.Request)
+						} else {
+							err = c.SetResult(ctx, msg.ID, testResponse{Response: "result for: " + msg.Value.Request})
+						}
+						if err != nil && !errors.Is(err, ErrAlreadySet) {
+							t.Errorf("Error setting a response: %v", err)
+						}
+						msg.Ack()
+					}
+				})
+				defer c.StopAndWait()
+			}
+
+			awaitCtx, awaitCancel := context.WithTimeout(ctx, 30*time.Second)
+			defer awaitCancel()
+			responses, errs := awaitResponses(awaitCtx, promises)
+			for i, request := range requests {
+				if request.IsInvalid {
+					if errs[i] == nil || !strings.Contains(errs[i].Error(), "invalid request: "+request.Request) {
+						t.Errorf("Expected error for request %q, got: %v", request.Request, errs[i])
+					}
+				} else if errs[i] != nil {
+					t.Errorf("Unexpected error for request %q: %v", request.Request, errs[i])
+				} else if responses[i] != "result for: "+request.Request {
+					t.Errorf("Unexpected response for request %q: %q", request.Request, responses[i])
+				}
+			}
+			if cnt := producer.promisesLen(); cnt != 0 {
+				t.Errorf("Producer still has %d unfullfilled promises", cnt)
+			}
+		})
+	}
+}
+
+func TestMemoryTransportRespondOnce(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	transport := NewMemoryTransport()
+	id, err := transport.Publish(ctx, "stream", []byte("request"), time.Time{})
+	if err != nil {
+		t.Fatalf("Error publishing: %v", err)
+	}
+	if _, err := getUintParts(id); err != nil {
+		t.Fatalf("Invalid request id %q: %v", id, err)
+	}
+	delivery, err := transport.ReadNew(ctx, "stream", "consumer")
+	if err != nil || delivery == nil || delivery.ID != id {
+		t.Fatalf("Unexpected delivery %+v, err: %v", delivery, err)
+	}
+	if delivery, err := transport.ReadNew(ctx, "stream", "consumer"); err != nil || delivery != nil {
+		t.Fatalf("Request delivered twice: %+v, err: %v", delivery, err)
+	}
+	if err := transport.Respond(ctx, "stream", id, &Response{Data: []byte("1")}, time.Minute); err != nil {
+		t.Fatalf("Error responding: %v", err)
+	}
+	if err := transport.Respond(ctx, "stream", id, &Response{Data: []byte("2")}, time.Minute); !errors.Is(err, ErrAlreadySet) {
+		t.Fatalf("Expected ErrAlreadySet, got: %v", err)
+	}
+	response, err := transport.TakeResponse(ctx, "stream", id)
+	if err != nil || response == nil || string(response.Data) != "1" {
+		t.Fatalf("Unexpected response %+v, err: %v", response, err)
+	}
+	if response, err := transport.TakeResponse(ctx, "stream", id); err != nil || response != nil {
+		t.Fatalf("Response taken twice: %+v, err: %v", response, err)
+	}
+}
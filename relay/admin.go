@@ -0,0 +1,85 @@
+// Copyright 2026, Offchain Labs, Inc.
+// For license information, see https://github.com/OffchainLabs/nitro/blob/master/LICENSE.md
+
+package relay
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+
+	"github.com/spf13/pflag"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/offchainlabs/nitro/broadcaster"
+	"github.com/offchainlabs/nitro/cmd/genericconf"
+)
+
+// AdminRPCConfig configures the RPC server exposing the feed admin API, which
+// lists and disconnects the relay's clients. It should not be exposed
+// publicly.
+type AdminRPCConfig struct {
+	Enable         bool                                `koanf:"enable"`
+	Addr           string                              `koanf:"addr"`
+	Port           uint64                              `koanf:"port"`
+	ServerTimeouts genericconf.HTTPServerTimeoutConfig `koanf:"server-timeouts"`
+}
+
+var AdminRPCConfigDefault = AdminRPCConfig{
+	Enable:         false,
+	Addr:           "127.0.0.1",
+	Port:           9643,
+	ServerTimeouts: genericconf.HTTPServerTimeoutConfigDefault,
+}
+
+func AdminRPCConfigAddOptions(prefix string, f *pflag.FlagSet) {
+	f.Bool(prefix+".enable", AdminRPCConfigDefault.Enable, "enable the feed admin RPC server, serving the "+broadcaster.FeedAdminNamespace+" namespace")
+	f.String(prefix+".addr", AdminRPCConfigDefault.Addr, "feed admin RPC server listening interface")
+	f.Uint64(prefix+".port", AdminRPCConfigDefault.Port, "feed admin RPC server listening port")
+	genericconf.HTTPServerTimeoutConfigAddOptions(prefix+".server-timeouts", f)
+}
+
+func (r *Relay) startAdminRPC(config *AdminRPCConfig) error {
+	listener, err := net.Listen("tcp", fmt.Sprintf("%s:%d", config.Addr, config.Port))
+	if err != nil {
+		return fmt.Errorf("error listening for feed admin RPC: %w", err)
+	}
+	rpcServer := rpc.NewServer()
+	if err := rpcServer.RegisterName(broadcaster.FeedAdminNamespace, broadcaster.NewFeedAdminAPI(r.broadcaster)); err != nil {
+		_ = listener.Close()
+		return err
+	}
+	srv := &http.Server{
+		Handler:           rpcServer,
+		ReadTimeout:       config.ServerTimeouts.ReadTimeout,
+		ReadHeaderTimeout: config.ServerTimeouts.ReadHeaderTimeout,
+		WriteTimeout:      config.ServerTimeouts.WriteTimeout,
+		IdleTimeout:       config.ServerTimeouts.IdleTimeout,
+	}
+	r.adminListener = listener
+	log.Info("feed admin RPC server listening", "addr", listener.Addr())
+	r.LaunchThread(func(ctx context.Context) {
+		if err := srv.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Error("feed admin RPC server failed", "err", err)
+		}
+	})
+	r.LaunchThread(func(ctx context.Context) {
+		<-ctx.Done()
+		_ = srv.Shutdown(context.Background())
+		rpcServer.Stop()
+	})
+	return nil
+}
+
+// GetAdminRPCAddr returns the address of the feed admin RPC server, or nil if
+// it is not enabled.
+func (r *Relay) GetAdminRPCAddr() net.Addr {
+	if r.adminListener == nil {
+		return nil
+	}
+	return r.adminListener.Addr()
+}
@@ -0,0 +1,349 @@
+// Copyright 2026, Offchain Labs, Inc.
+// For license information, see https://github.com/OffchainLabs/nitro/blob/master/LICENSE.md
+
+package relay
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/spf13/pflag"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+
+	"github.com/offchainlabs/nitro/arbutil"
+	"github.com/offchainlabs/nitro/broadcastclient"
+	"github.com/offchainlabs/nitro/broadcaster/message"
+	"github.com/offchainlabs/nitro/util/stopwaiter"
+)
+
+var (
+	upstreamForwardedCounter = metrics.NewRegisteredCounter("arb/relay/upstream/forwarded", nil)
+	upstreamDuplicateCounter = metrics.NewRegisteredCounter("arb/relay/upstream/duplicate", nil)
+	upstreamConflictCounter  = metrics.NewRegisteredCounter("arb/relay/upstream/conflict", nil)
+	upstreamStaleCounter     = metrics.NewRegisteredCounter("arb/relay/upstream/stale", nil)
+	upstreamFlaggedGauge     = metrics.NewRegisteredGauge("arb/relay/upstream/flagged", nil)
+	upstreamConnectedGauge   = metrics.NewRegisteredGauge("arb/relay/upstream/connected", nil)
+)
+
+const upstreamQueueSize = 1024
+
+type MultiUpstreamConfig struct {
+	Enable                bool `koanf:"enable"`
+	HistorySize           int  `koanf:"history-size"`
+	DisconnectConflicting bool `koanf:"disconnect-conflicting"`
+}
+
+func (c *MultiUpstreamConfig) Validate() error {
+	if c.Enable && c.HistorySize <= 0 {
+		return errors.New("multi-upstream history-size must be positive")
+	}
+	return nil
+}
+
+var MultiUpstreamConfigDefault = MultiUpstreamConfig{
+	Enable:                false,
+	HistorySize:           64 * 1024,
+	DisconnectConflicting: false,
+}
+
+func MultiUpstreamConfigAddOptions(prefix string, f *pflag.FlagSet) {
+	f.Bool(prefix+".enable", MultiUpstreamConfigDefault.Enable, "connect to all feed URLs at once, forwarding the first valid copy of each message and flagging feeds which deliver conflicting messages")
+	f.Int(prefix+".history-size", MultiUpstreamConfigDefault.HistorySize, "number of recent sequence numbers remembered to detect duplicate and conflicting messages")
+	f.Bool(prefix+".disconnect-conflicting", MultiUpstreamConfigDefault.DisconnectConflicting, "disconnect from a feed once it delivered a message conflicting with the one already forwarded")
+}
+
+// upstreamMessage is a feed message together with the index of the upstream
+// which delivered it.
+type upstreamMessage struct {
+	upstream int
+	msg      *message.BroadcastFeedMessage
+}
+
+// upstreamQueue receives the verified messages of a single upstream.
+type upstreamQueue struct {
+	index     int
+	messages  chan upstreamMessage
+	rotations chan *message.KeyRotationMessage
+}
+
+func (q *upstreamQueue) AddBroadcastMessages(feedMessages []*message.BroadcastFeedMessage) error {
+	for _, feedMessage := range feedMessages {
+		q.messages <- upstreamMessage{upstream: q.index, msg: feedMessage}
+	}
+	return nil
+}
+
+func (q *upstreamQueue) AddKeyRotation(rotation *message.KeyRotationMessage) error {
+	q.rotations <- rotation
+	return nil
+}
+
+type upstream struct {
+	url    string
+	client *broadcastclient.BroadcastClient
+
+	// Only accessed by the merging thread, apart from flagged which is
+	// protected by MultiUpstream.flaggedMutex
+	flagged   bool
+	conflicts uint64
+}
+
+// forwardedMessage is what is remembered about a forwarded message to
+// detect conflicting copies.
+type forwardedMessage struct {
+	hash     common.Hash
+	upstream int
+}
+
+// MultiUpstream connects to several upstream feeds simultaneously and merges
+// them into a single stream. Every BroadcastClient verifies the signatures of
+// its messages, and the first copy of each sequence number is forwarded.
+// Upstreams delivering a different message for an already forwarded sequence
+// number are flagged, as this means that either the upstream or the
+// sequencer is equivocating.
+type MultiUpstream struct {
+	stopwaiter.StopWaiter
+
+	config       func() *MultiUpstreamConfig
+	chainId      uint64
+	upstreams    []*upstream
+	flaggedMutex sync.Mutex
+
+	messages             chan upstreamMessage
+	rotations            chan *message.KeyRotationMessage
+	confirmations        chan arbutil.MessageIndex
+	forwardTxStreamer    broadcastclient.TransactionStreamerInterface
+	forwardConfirmations chan arbutil.MessageIndex
+
+	// forwarded holds the hashes of the recently forwarded messages, and
+	// forwardedOrder their sequence numbers in a ring buffer to evict the
+	// oldest one once the history is full.
+	forwarded          map[arbutil.MessageIndex]forwardedMessage
+	forwardedOrder     []arbutil.MessageIndex
+	forwardedOrderNext int
+	evictedUpTo        arbutil.MessageIndex
+	hasEvicted         bool
+
+	lastConfirmed arbutil.MessageIndex
+	hasConfirmed  bool
+}
+
+func NewMultiUpstream(
+	config func() *MultiUpstreamConfig,
+	feedConfig broadcastclient.ConfigFetcher,
+	chainId uint64,
+	txStreamer broadcastclient.TransactionStreamerInterface,
+	confirmedSequenceNumberListener chan arbutil.MessageIndex,
+	fatalErrChan chan error,
+) (*MultiUpstream, error) {
+	feed := feedConfig()
+	if !feed.Enable() {
+		return nil, errors.New("no upstream feeds configured")
+	}
+	if len(feed.SecondaryURL) > 0 {
+		log.Warn("secondary feed URLs are not used in multi-upstream mode", "secondaryURL", feed.SecondaryURL)
+	}
+	if feed.Verify.Dangerous.AcceptMissing {
+		log.Warn("multi-upstream relay is not verifying feed signatures, conflicts are only detected between upstreams")
+	}
+	historySize := config().HistorySize
+	mu := &MultiUpstream{
+		config:               config,
+		chainId:              chainId,
+		messages:             make(chan upstreamMessage, upstreamQueueSize),
+		rotations:            make(chan *message.KeyRotationMessage, upstreamQueueSize),
+		confirmations:        make(chan arbutil.MessageIndex, upstreamQueueSize),
+		forwardTxStreamer:    txStreamer,
+		forwardConfirmations: confirmedSequenceNumberListener,
+		forwarded:            make(map[arbutil.MessageIndex]forwardedMessage, historySize),
+		forwardedOrder:       make([]arbutil.MessageIndex, 0, historySize),
+	}
+	for i, url := range feed.URL {
+		queue := &upstreamQueue{index: i, messages: mu.messages, rotations: mu.rotations}
+		client, err := broadcastclient.NewBroadcastClient(
+			feedConfig,
+			url,
+			chainId,
+			0,
+			queue,
+			mu.confirmations,
+			fatalErrChan,
+			nil,
+			mu.adjustConnected,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("error creating broadcast client for %s: %w", url, err)
+		}
+		mu.upstreams = append(mu.upstreams, &upstream{url: url, client: client})
+	}
+	return mu, nil
+}
+
+func (mu *MultiUpstream) adjustConnected(delta int32) {
+	upstreamConnectedGauge.Inc(int64(delta))
+}
+
+func (mu *MultiUpstream) Start(ctx context.Context) {
+	mu.StopWaiter.Start(ctx, mu)
+	for _, u := range mu.upstreams {
+		u.client.Start(mu.GetContext())
+	}
+	mu.LaunchThread(func(ctx context.Context) {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case m := <-mu.messages:
+				if err := mu.handleMessage(m); err != nil {
+					if errors.Is(err, broadcastclient.TransactionStreamerBlockCreationStopped) {
+						log.Info("stopping multi-upstream relay because transaction streamer has stopped")
+						return
+					}
+					log.Error("error forwarding message from upstream feed", "url", mu.upstreams[m.upstream].url, "err", err)
+				}
+			case cs := <-mu.confirmations:
+				mu.handleConfirmation(ctx, cs)
+			case rotation := <-mu.rotations:
+				if listener, ok := mu.forwardTxStreamer.(broadcastclient.KeyRotationListener); ok {
+					if err := listener.AddKeyRotation(rotation); err != nil {
+						log.Error("error forwarding feed key rotation", "err", err)
+					}
+				}
+			}
+		}
+	})
+}
+
+// handleMessage forwards the first copy of every sequence number and
+// compares later copies with it.
+func (mu *MultiUpstream) handleMessage(m upstreamMessage) error {
+	if mu.upstreams[m.upstream].flagged && mu.config().DisconnectConflicting {
+		return nil
+	}
+	seqNum := m.msg.SequenceNumber
+	hash := m.msg.SignatureHash(mu.chainId)
+	if previous, ok := mu.forwarded[seqNum]; ok {
+		if previous.hash == hash {
+			upstreamDuplicateCounter.Inc(1)
+			return nil
+		}
+		mu.flagConflict(m.upstream, previous, seqNum, hash)
+		return nil
+	}
+	if mu.hasEvicted && seqNum <= mu.evictedUpTo {
+		// Too old to detect conflicts, it has been forwarded long ago
+		upstreamStaleCounter.Inc(1)
+		return nil
+	}
+	mu.remember(seqNum, forwardedMessage{hash: hash, upstream: m.upstream})
+	upstreamForwardedCounter.Inc(1)
+	return mu.forwardTxStreamer.AddBroadcastMessages([]*message.BroadcastFeedMessage{m.msg})
+}
+
+// remember records a forwarded message, evicting the oldest one once the
+// history is full.
+func (mu *MultiUpstream) remember(seqNum arbutil.MessageIndex, fm forwardedMessage) {
+	historySize := mu.config().HistorySize
+	if len(mu.forwardedOrder) < historySize {
+		mu.forwardedOrder = append(mu.forwardedOrder, seqNum)
+	} else {
+		if mu.forwardedOrderNext >= len(mu.forwardedOrder) {
+			mu.forwardedOrderNext = 0
+		}
+		evicted := mu.forwardedOrder[mu.forwardedOrderNext]
+		delete(mu.forwarded, evicted)
+		if !mu.hasEvicted || evicted > mu.evictedUpTo {
+			mu.evictedUpTo = evicted
+			mu.hasEvicted = true
+		}
+		mu.forwardedOrder[mu.forwardedOrderNext] = seqNum
+		mu.forwardedOrderNext++
+	}
+	mu.forwarded[seqNum] = fm
+}
+
+func (mu *MultiUpstream) flagConflict(index int, previous forwardedMessage, seqNum arbutil.MessageIndex, hash common.Hash) {
+	upstreamConflictCounter.Inc(1)
+	source := mu.upstreams[index]
+	source.conflicts++
+	log.Error(
+		"upstream feed delivered a message conflicting with the forwarded one",
+		"sequenceNumber", seqNum,
+		"url", source.url,
+		"hash", hash,
+		"forwardedFrom", mu.upstreams[previous.upstream].url,
+		"forwardedHash", previous.hash,
+		"conflicts", source.conflicts,
+	)
+	if source.flagged {
+		return
+	}
+	mu.flaggedMutex.Lock()
+	source.flagged = true
+	mu.flaggedMutex.Unlock()
+	upstreamFlaggedGauge.Inc(1)
+	if mu.config().DisconnectConflicting {
+		log.Warn("disconnecting from conflicting upstream feed", "url", source.url)
+		// StopAndWait blocks until the client's reader exits, which might be
+		// waiting to deliver to this thread, so stop it in the background.
+		client := source.client
+		go client.StopAndWait()
+	}
+}
+
+// handleConfirmation forwards confirmations which advance the confirmed
+// sequence number.
+func (mu *MultiUpstream) handleConfirmation(ctx context.Context, cs arbutil.MessageIndex) {
+	if mu.hasConfirmed && cs <= mu.lastConfirmed {
+		return
+	}
+	mu.hasConfirmed = true
+	mu.lastConfirmed = cs
+	if mu.forwardConfirmations != nil {
+		select {
+		case mu.forwardConfirmations <- cs:
+		case <-ctx.Done():
+		}
+	}
+}
+
+// FlaggedUpstreams returns the URLs of the upstreams which delivered
+// conflicting messages.
+func (mu *MultiUpstream) FlaggedUpstreams() []string {
+	mu.flaggedMutex.Lock()
+	defer mu.flaggedMutex.Unlock()
+	var flagged []string
+	for _, u := range mu.upstreams {
+		if u.flagged {
+			flagged = append(flagged, u.url)
+		}
+	}
+	return flagged
+}
+
+func (mu *MultiUpstream) StopAndWait() {
+	// The clients may be blocked delivering to the merging thread which
+	// might have exited already, so drain their queues until they stopped.
+	clientsStopped := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-mu.messages:
+			case <-mu.rotations:
+			case <-mu.confirmations:
+			case <-clientsStopped:
+				return
+			}
+		}
+	}()
+	for _, u := range mu.upstreams {
+		u.client.StopAndWait()
+	}
+	close(clientsStopped)
+	mu.StopWaiter.StopAndWait()
+}
@@ -0,0 +1,103 @@
+// Copyright 2026, Offchain Labs, Inc.
+// For license information, see https://github.com/OffchainLabs/nitro/blob/master/LICENSE.md
+package relay
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/offchainlabs/nitro/arbos/arbostypes"
+	"github.com/offchainlabs/nitro/arbutil"
+	"github.com/offchainlabs/nitro/broadcastclient"
+	"github.com/offchainlabs/nitro/broadcaster/message"
+)
+
+type recordingStreamer struct {
+	messages []*message.BroadcastFeedMessage
+}
+
+func (s *recordingStreamer) AddBroadcastMessages(feedMessages []*message.BroadcastFeedMessage) error {
+	s.messages = append(s.messages, feedMessages...)
+	return nil
+}
+
+func newTestFeedMessage(seqNum arbutil.MessageIndex, l2msg byte) *message.BroadcastFeedMessage {
+	return &message.BroadcastFeedMessage{
+		SequenceNumber: seqNum,
+		Message: arbostypes.MessageWithMetadata{
+			Message: &arbostypes.L1IncomingMessage{
+				Header: &arbostypes.L1IncomingMessageHeader{},
+				L2msg:  []byte{l2msg},
+			},
+		},
+	}
+}
+
+func newTestMultiUpstream(t *testing.T, config *MultiUpstreamConfig, streamer *recordingStreamer) *MultiUpstream {
+	t.Helper()
+	feedConfig := broadcastclient.DefaultTestConfig
+	feedConfig.URL = []string{"ws://127.0.0.1:1", "ws://127.0.0.1:2"}
+	mu, err := NewMultiUpstream(
+		func() *MultiUpstreamConfig { return config },
+		func() *broadcastclient.Config { return &feedConfig },
+		412346,
+		streamer,
+		nil,
+		make(chan error, 1),
+	)
+	require.NoError(t, err)
+	return mu
+}
+
+func TestMultiUpstreamDeduplicates(t *testing.T) {
+	config := MultiUpstreamConfigDefault
+	config.Enable = true
+	streamer := &recordingStreamer{}
+	mu := newTestMultiUpstream(t, &config, streamer)
+
+	require.NoError(t, mu.handleMessage(upstreamMessage{upstream: 1, msg: newTestFeedMessage(0, 1)}))
+	require.NoError(t, mu.handleMessage(upstreamMessage{upstream: 0, msg: newTestFeedMessage(0, 1)}))
+	require.NoError(t, mu.handleMessage(upstreamMessage{upstream: 0, msg: newTestFeedMessage(1, 2)}))
+	require.NoError(t, mu.handleMessage(upstreamMessage{upstream: 1, msg: newTestFeedMessage(1, 2)}))
+
+	require.Len(t, streamer.messages, 2)
+	require.Equal(t, arbutil.MessageIndex(0), streamer.messages[0].SequenceNumber)
+	require.Equal(t, arbutil.MessageIndex(1), streamer.messages[1].SequenceNumber)
+	require.Empty(t, mu.FlaggedUpstreams())
+}
+
+func TestMultiUpstreamFlagsConflicts(t *testing.T) {
+	config := MultiUpstreamConfigDefault
+	config.Enable = true
+	config.DisconnectConflicting = true
+	streamer := &recordingStreamer{}
+	mu := newTestMultiUpstream(t, &config, streamer)
+
+	require.NoError(t, mu.handleMessage(upstreamMessage{upstream: 0, msg: newTestFeedMessage(0, 1)}))
+	require.NoError(t, mu.handleMessage(upstreamMessage{upstream: 1, msg: newTestFeedMessage(0, 2)}))
+	require.Equal(t, []string{"ws://127.0.0.1:2"}, mu.FlaggedUpstreams())
+
+	// Messages of a disconnected upstream are ignored
+	require.NoError(t, mu.handleMessage(upstreamMessage{upstream: 1, msg: newTestFeedMessage(1, 3)}))
+	require.NoError(t, mu.handleMessage(upstreamMessage{upstream: 0, msg: newTestFeedMessage(1, 4)}))
+	require.Len(t, streamer.messages, 2)
+	require.Equal(t, []byte{4}, streamer.messages[1].Message.Message.L2msg)
+}
+
+func TestMultiUpstreamHistory(t *testing.T) {
+	config := MultiUpstreamConfigDefault
+	config.Enable = true
+	config.HistorySize = 2
+	streamer := &recordingStreamer{}
+	mu := newTestMultiUpstream(t, &config, streamer)
+
+	for i := arbutil.MessageIndex(0); i < 4; i++ {
+		require.NoError(t, mu.handleMessage(upstreamMessage{upstream: 0, msg: newTestFeedMessage(i, byte(i))}))
+	}
+	require.Len(t, mu.forwarded, 2)
+
+	// Late copies of evicted messages are not forwarded again
+	require.NoError(t, mu.handleMessage(upstreamMessage{upstream: 1, msg: newTestFeedMessage(1, 1)}))
+	require.Len(t, streamer.messages, 4)
+}
@@ -22,16 +22,26 @@ import (
 	"github.com/offchainlabs/nitro/wsbroadcastserver"
 )
 
+// feedSource is the source of the messages forwarded by the relay.
+type feedSource interface {
+	Start(ctx context.Context)
+	StopAndWait()
+}
+
 type Relay struct {
 	stopwaiter.StopWaiter
-	broadcastClients            *broadcastclients.BroadcastClients
+	broadcastClients            feedSource
 	broadcaster                 *broadcaster.Broadcaster
 	confirmedSequenceNumberChan chan arbutil.MessageIndex
 	messageChan                 chan message.BroadcastFeedMessage
+	keyRotationChan             chan *message.KeyRotationMessage
+	adminRPCConfig              AdminRPCConfig
+	adminListener               net.Listener
 }
 
 type MessageQueue struct {
-	queue chan message.BroadcastFeedMessage
+	queue        chan message.BroadcastFeedMessage
+	keyRotations chan *message.KeyRotationMessage
 }
 
 func (q *MessageQueue) AddBroadcastMessages(feedMessages []*message.BroadcastFeedMessage) error {
@@ -42,26 +52,51 @@ func (q *MessageQueue) AddBroadcastMessages(feedMessages []*message.BroadcastFee
 	return nil
 }
 
+func (q *MessageQueue) AddKeyRotation(rotation *message.KeyRotationMessage) error {
+	q.keyRotations <- rotation
+	return nil
+}
+
 func NewRelay(config *Config, feedErrChan chan error) (*Relay, error) {
 
-	q := MessageQueue{make(chan message.BroadcastFeedMessage, config.Queue)}
+	q := MessageQueue{
+		queue:        make(chan message.BroadcastFeedMessage, config.Queue),
+		keyRotations: make(chan *message.KeyRotationMessage, config.Queue),
+	}
 
 	confirmedSequenceNumberListener := make(chan arbutil.MessageIndex, config.Queue)
 
-	clients, err := broadcastclients.NewBroadcastClients(
-		func() *broadcastclient.Config { return &config.Node.Feed.Input },
-		config.Chain.ID,
-		0,
-		&q,
-		confirmedSequenceNumberListener,
-		feedErrChan,
-		nil,
-	)
-	if err != nil {
-		return nil, err
-	}
-	if clients == nil {
-		return nil, errors.New("no feed servers found")
+	var source feedSource
+	if config.MultiUpstream.Enable {
+		multiUpstream, err := NewMultiUpstream(
+			func() *MultiUpstreamConfig { return &config.MultiUpstream },
+			func() *broadcastclient.Config { return &config.Node.Feed.Input },
+			config.Chain.ID,
+			&q,
+			confirmedSequenceNumberListener,
+			feedErrChan,
+		)
+		if err != nil {
+			return nil, err
+		}
+		source = multiUpstream
+	} else {
+		clients, err := broadcastclients.NewBroadcastClients(
+			func() *broadcastclient.Config { return &config.Node.Feed.Input },
+			config.Chain.ID,
+			0,
+			&q,
+			confirmedSequenceNumberListener,
+			feedErrChan,
+			nil,
+		)
+		if err != nil {
+			return nil, err
+		}
+		if clients == nil {
+			return nil, errors.New("no feed servers found")
+		}
+		source = clients
 	}
 
 	dataSignerErr := func([]byte) ([]byte, error) {
@@ -69,9 +104,11 @@ func NewRelay(config *Config, feedErrChan chan error) (*Relay, error) {
 	}
 	return &Relay{
 		broadcaster:                 broadcaster.NewBroadcaster(func() *wsbroadcastserver.BroadcasterConfig { return &config.Node.Feed.Output }, config.Chain.ID, feedErrChan, dataSignerErr),
-		broadcastClients:            clients,
+		broadcastClients:            source,
 		confirmedSequenceNumberChan: confirmedSequenceNumberListener,
 		messageChan:                 q.queue,
+		keyRotationChan:             q.keyRotations,
+		adminRPCConfig:              config.AdminRPC,
 	}, nil
 }
 
@@ -86,6 +123,12 @@ func (r *Relay) Start(ctx context.Context) error {
 		return errors.New("broadcast unable to start")
 	}
 
+	if r.adminRPCConfig.Enable {
+		if err := r.startAdminRPC(&r.adminRPCConfig); err != nil {
+			return err
+		}
+	}
+
 	r.broadcastClients.Start(r.GetContext())
 
 	r.LaunchThread(func(ctx context.Context) {
@@ -100,6 +143,8 @@ func (r *Relay) Start(ctx context.Context) error {
 				}
 			case cs := <-r.confirmedSequenceNumberChan:
 				r.broadcaster.Confirm(cs)
+			case rotation := <-r.keyRotationChan:
+				r.broadcaster.BroadcastKeyRotation(rotation)
 			}
 		}
 	})
@@ -128,6 +173,8 @@ type Config struct {
 	PprofCfg      genericconf.PProf               `koanf:"pprof-cfg"`
 	Node          NodeConfig                      `koanf:"node"`
 	Queue         int                             `koanf:"queue"`
+	MultiUpstream MultiUpstreamConfig             `koanf:"multi-upstream"`
+	AdminRPC      AdminRPCConfig                  `koanf:"admin-rpc"`
 }
 
 var ConfigDefault = Config{
@@ -141,6 +188,8 @@ var ConfigDefault = Config{
 	PprofCfg:      genericconf.PProfDefault,
 	Node:          NodeConfigDefault,
 	Queue:         1024,
+	MultiUpstream: MultiUpstreamConfigDefault,
+	AdminRPC:      AdminRPCConfigDefault,
 }
 
 func ConfigAddOptions(f *pflag.FlagSet) {
@@ -154,6 +203,8 @@ func ConfigAddOptions(f *pflag.FlagSet) {
 	genericconf.PProfAddOptions("pprof-cfg", f)
 	NodeConfigAddOptions("node", f)
 	f.Int("queue", ConfigDefault.Queue, "queue for incoming messages from sequencer")
+	MultiUpstreamConfigAddOptions("multi-upstream", f)
+	AdminRPCConfigAddOptions("admin-rpc", f)
 }
 
 type NodeConfig struct {
@@ -194,6 +245,9 @@ func ParseRelay(_ context.Context, args []string) (*Config, error) {
 	if err := confighelpers.EndCommonParse(k, &relayConfig); err != nil {
 		return nil, err
 	}
+	if err := relayConfig.MultiUpstream.Validate(); err != nil {
+		return nil, err
+	}
 
 	if relayConfig.Conf.Dump {
 		err = confighelpers.DumpConfig(k, map[string]interface{}{})
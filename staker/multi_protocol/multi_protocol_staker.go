@@ -13,6 +13,7 @@ import (
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/node"
 
+	"github.com/offchainlabs/nitro/arbnode/dataposter"
 	"github.com/offchainlabs/nitro/daprovider"
 	"github.com/offchainlabs/nitro/solgen/go/bridgegen"
 	"github.com/offchainlabs/nitro/solgen/go/rollupgen"
@@ -252,6 +253,11 @@ func (m *MultiProtocolStaker) StopAndWait() {
 	m.wallet.StopAndWait()
 }
 
+// DataPoster returns the DataPoster of the staker's wallet, which may be nil.
+func (m *MultiProtocolStaker) DataPoster() *dataposter.DataPoster {
+	return m.wallet.DataPoster()
+}
+
 func (m *MultiProtocolStaker) getCallOpts(ctx context.Context) *bind.CallOpts {
 	opts := m.callOpts
 	opts.Context = ctx
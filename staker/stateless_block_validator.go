@@ -373,7 +373,7 @@ func (v *StatelessBlockValidator) ValidationEntryRecord(ctx context.Context, e *
 		if len(wasmTargets) == 0 {
 			wasmTargets = v.wasmTargets
 		}
-		recording, err := v.recorder.RecordBlockCreation(e.Pos, e.msg, wasmTargets).Await(ctx)
+		recording, err := v.recorder.RecordBlockCreation(e.Pos, e.msg, e.Start.PosInBatch, wasmTargets).Await(ctx)
 		if err != nil {
 			return err
 		}
@@ -24,6 +24,8 @@ type EOA struct {
 	client      *ethclient.Client
 	dataPoster  *dataposter.DataPoster
 	getExtraGas func() uint64
+	// pool posts the transactions which anyone can send, if set
+	pool *dataposter.DataPosterPool
 }
 
 func NewEOA(dataPoster *dataposter.DataPoster, l1Client *ethclient.Client, getExtraGas func() uint64) (*EOA, error) {
@@ -35,6 +37,13 @@ func NewEOA(dataPoster *dataposter.DataPoster, l1Client *ethclient.Client, getEx
 	}, nil
 }
 
+// SetDataPosterPool makes the wallet post the transactions which don't need
+// to come from its address, like challenge timeouts, through the pool. It
+// must be called before the wallet is started.
+func (w *EOA) SetDataPosterPool(pool *dataposter.DataPosterPool) {
+	w.pool = pool
+}
+
 func (w *EOA) Initialize(ctx context.Context) error {
 	return nil
 }
@@ -96,6 +105,15 @@ func (w *EOA) TimeoutChallenges(ctx context.Context, timeouts []uint64, challeng
 	if err != nil {
 		return nil, err
 	}
+	if w.pool != nil {
+		// Anyone can time out a challenge
+		gas := tx.Gas() + w.getExtraGas()
+		newTx, err := w.pool.PostSimpleTransaction(ctx, *tx.To(), tx.Data(), gas, tx.Value())
+		if err != nil {
+			return nil, fmt.Errorf("post transaction through data poster pool: %w", err)
+		}
+		return newTx, nil
+	}
 	return w.postTransaction(ctx, tx)
 }
 
@@ -109,14 +127,23 @@ func (w *EOA) AuthIfEoa() *bind.TransactOpts {
 
 func (w *EOA) Start(ctx context.Context) {
 	w.dataPoster.Start(ctx)
+	if w.pool != nil {
+		w.pool.Start(ctx)
+	}
 }
 
 func (b *EOA) StopAndWait() {
 	b.dataPoster.StopAndWait()
+	if b.pool != nil {
+		b.pool.StopAndWait()
+	}
 }
 
 func (b *EOA) StopOnly() {
 	b.dataPoster.StopOnly()
+	if b.pool != nil {
+		b.pool.StopOnly()
+	}
 }
 
 func (b *EOA) DataPoster() *dataposter.DataPoster {
@@ -260,12 +260,14 @@ var TestSequencerConfig = gethexec.SequencerConfig{
 	QueueTimeout:                 time.Second * 5,
 	NonceCacheSize:               4,
 	MaxTxDataSize:                95000,
+	MaxBundleSize:                16,
 	NonceFailureCacheSize:        1024,
 	NonceFailureCacheExpiry:      time.Second,
 	ExpectedSurplusGasPriceMode:  "CalldataPrice",
 	ExpectedSurplusSoftThreshold: "default",
 	ExpectedSurplusHardThreshold: "default",
 	EnableProfiling:              false,
+	OrderingPolicy:               gethexec.OrderingPolicyFIFO,
 }
 
 func ExecConfigDefaultNonSequencerTest(t *testing.T, stateScheme string) *gethexec.Config {
@@ -2735,8 +2737,8 @@ func createReferenceDAProviderServerWithControl(t *testing.T, ctx context.Contex
 	// Create ReferenceDA components
 	storage := referenceda.GetInMemoryStorage()
 	reader := referenceda.NewReader(storage, l1Client, validatorAddr)
-	writer := referenceda.NewWriter(dataSigner, maxMessageSize)
-	validator := referenceda.NewValidator(l1Client, validatorAddr)
+	writer := referenceda.NewWriter(storage, dataSigner, maxMessageSize)
+	validator := referenceda.NewValidator(storage, l1Client, validatorAddr)
 
 	// Create controllable wrapper
 	wrappedWriter := &controllableWriter{
@@ -0,0 +1,77 @@
+// Copyright 2026, Offchain Labs, Inc.
+// For license information, see https://github.com/OffchainLabs/nitro/blob/master/LICENSE.md
+
+package arbtest
+
+import (
+	"context"
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+
+	"github.com/offchainlabs/nitro/arbnode"
+	"github.com/offchainlabs/nitro/arbnode/dataposter/storage"
+	"github.com/offchainlabs/nitro/arbnode/parent"
+)
+
+func TestDataPosterPoolPosts(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	builder := NewNodeBuilder(ctx).DefaultConfig(t, true)
+	cleanup := builder.Build(t)
+	defer cleanup()
+
+	senders := []string{"PoolSignerA", "PoolSignerB"}
+	config := arbnode.ConfigDefaultL1NonSequencerTest()
+	for _, name := range senders {
+		builder.L1Info.GenerateAccount(name)
+		builder.L1.TransferBalance(t, "Faucet", name, big.NewInt(params.Ether), builder.L1Info)
+		key := builder.L1Info.GetInfoWithPrivKey(name).PrivateKey
+		config.Staker.DataPoster.PoolSigners.PrivateKeys = append(config.Staker.DataPoster.PoolSigners.PrivateKeys, hex.EncodeToString(crypto.FromECDSA(key)))
+	}
+
+	parentChainID, err := builder.L1.Client.ChainID(ctx)
+	Require(t, err)
+	l2node := builder.L2.ConsensusNode
+	pool, err := arbnode.StakerDataposterPool(
+		ctx,
+		rawdb.NewTable(l2node.ConsensusDB, storage.StakerPoolPrefix),
+		l2node.L1Reader,
+		NewCommonConfigFetcher(config),
+		parent.NewParentChain(ctx, parentChainID, l2node.L1Reader),
+	)
+	Require(t, err)
+	if pool == nil {
+		Fatal(t, "no data poster pool created for the configured pool signers")
+	}
+	pool.Start(ctx)
+	defer pool.StopAndWait()
+
+	to := common.HexToAddress("0x1234")
+	posted := make(map[common.Address]int)
+	for i := 0; i < 4; i++ {
+		tx, err := pool.PostSimpleTransaction(ctx, to, nil, builder.L1Info.TransferGas, common.Big1)
+		Require(t, err)
+		_, err = builder.L1.EnsureTxSucceeded(tx)
+		Require(t, err)
+		sender, err := builder.L1Info.Signer.Sender(tx)
+		Require(t, err)
+		posted[sender]++
+	}
+	for _, name := range senders {
+		if got := posted[builder.L1Info.GetAddress(name)]; got != 2 {
+			Fatal(t, "pool signer", name, "posted", got, "transactions, expected 2")
+		}
+	}
+	balance, err := builder.L1.Client.BalanceAt(ctx, to, nil)
+	Require(t, err)
+	if balance.Cmp(big.NewInt(4)) != 0 {
+		Fatal(t, "unexpected balance", balance, "of the pool transactions' recipient")
+	}
+}
@@ -59,7 +59,7 @@ func NewEvilDAProvider(l1Client *ethclient.Client, validatorAddr common.Address)
 	storage := referenceda.GetInMemoryStorage()
 	return &EvilDAProvider{
 		reader:            referenceda.NewReader(storage, l1Client, validatorAddr),
-		validator:         referenceda.NewValidator(l1Client, validatorAddr),
+		validator:         referenceda.NewValidator(storage, l1Client, validatorAddr),
 		evilData:          make(map[common.Hash][]byte),
 		invalidClaimCerts: make(map[common.Hash]bool),
 	}
@@ -49,6 +49,7 @@ func BuildBlock(
 		delayedMessagesRead,
 		nil,
 		daprovider.KeysetValidate,
+		arbstate.FixedArbOSVersion(daprovider.ZstdMessageArbOSVersion),
 		getChainConfig(),
 	)
 
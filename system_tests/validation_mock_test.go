@@ -549,6 +549,7 @@ type mockBlockRecorder struct {
 func (m *mockBlockRecorder) RecordBlockCreation(
 	pos arbutil.MessageIndex,
 	msg *arbostypes.MessageWithMetadata,
+	posInBatch uint64,
 	wasmTargets []rawdb.WasmTarget,
 ) containers.PromiseInterface[*execution.RecordResult] {
 	_, globalpos, err := m.validator.GlobalStatePositionsAtCount(pos + 1)
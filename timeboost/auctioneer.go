@@ -109,6 +109,9 @@ var DefaultAuctioneerConsumerConfig = pubsub.ConsumerConfig{
 	IdletimeToAutoclaim: time.Second,
 	Retry:               true,
 	MaxRetryCount:       -1,
+	MaxDeliveryAttempts: 0,
+	PriorityLanes:       1,
+	LaneWeights:         nil,
 }
 
 var DefaultAuctioneerServerConfig = AuctioneerServerConfig{
@@ -25,6 +25,9 @@ var testCoordinationConfig = pubsub.ConsumerConfig{
 	ResponseEntryTimeout: time.Minute,
 	Retry:                true,
 	MaxRetryCount:        -1,
+	MaxDeliveryAttempts:  0,
+	PriorityLanes:        1,
+	LaneWeights:          nil,
 }
 
 // Helper function to create and start an auctioneer for testing
@@ -361,6 +361,9 @@ func TestAuctioneerFailoverMessageReprocessing(t *testing.T) {
 		ResponseEntryTimeout: time.Minute,
 		Retry:                true,
 		MaxRetryCount:        -1,
+		MaxDeliveryAttempts:  0,
+		PriorityLanes:        1,
+		LaneWeights:          nil,
 	}
 
 	// Create primary auctioneer instance
@@ -6,8 +6,8 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"time"
 
-	"github.com/redis/go-redis/v9"
 	"github.com/spf13/pflag"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -16,7 +16,6 @@ import (
 
 	"github.com/offchainlabs/nitro/pubsub"
 	"github.com/offchainlabs/nitro/util/containers"
-	"github.com/offchainlabs/nitro/util/redisutil"
 	"github.com/offchainlabs/nitro/util/stopwaiter"
 	"github.com/offchainlabs/nitro/validator"
 	"github.com/offchainlabs/nitro/validator/server_api"
@@ -24,20 +23,44 @@ import (
 )
 
 type ValidationClientConfig struct {
-	Name           string                `koanf:"name"`
-	StreamPrefix   string                `koanf:"stream-prefix"`
-	Room           int32                 `koanf:"room"`
-	RedisURL       string                `koanf:"redis-url"`
-	StylusArchs    []string              `koanf:"stylus-archs"`
-	ProducerConfig pubsub.ProducerConfig `koanf:"producer-config"`
-	CreateStreams  bool                  `koanf:"create-streams"`
+	Name            string                `koanf:"name"`
+	StreamPrefix    string                `koanf:"stream-prefix"`
+	Room            int32                 `koanf:"room"`
+	RedisURL        string                `koanf:"redis-url"`
+	URL             string                `koanf:"url"`
+	StylusArchs     []string              `koanf:"stylus-archs"`
+	ProducerConfig  pubsub.ProducerConfig `koanf:"producer-config"`
+	CreateStreams   bool                  `koanf:"create-streams"`
+	Priority        int                   `koanf:"priority"`
+	RequestDeadline time.Duration         `koanf:"request-deadline"`
 }
 
 func (c ValidationClientConfig) Enabled() bool {
-	return c.RedisURL != ""
+	return c.TransportURL() != ""
+}
+
+// TransportURL returns the url of the pubsub transport, which is url if set
+// and otherwise redis-url.
+func (c ValidationClientConfig) TransportURL() string {
+	if c.URL != "" {
+		return c.URL
+	}
+	return c.RedisURL
+}
+
+// produceOptions returns the options of a request produced now.
+func (c ValidationClientConfig) produceOptions() pubsub.ProduceOptions {
+	opts := pubsub.ProduceOptions{Priority: c.Priority}
+	if c.RequestDeadline > 0 {
+		opts.Deadline = time.Now().Add(c.RequestDeadline)
+	}
+	return opts
 }
 
 func (c ValidationClientConfig) Validate() error {
+	if c.Priority < 0 {
+		return fmt.Errorf("invalid priority: %d", c.Priority)
+	}
 	for _, arch := range c.StylusArchs {
 		if !rawdb.IsSupportedWasmTarget(rawdb.WasmTarget(arch)) {
 			return fmt.Errorf("Invalid stylus arch: %v", arch)
@@ -47,32 +70,41 @@ func (c ValidationClientConfig) Validate() error {
 }
 
 var DefaultValidationClientConfig = ValidationClientConfig{
-	Name:           "redis validation client",
-	Room:           2,
-	RedisURL:       "",
-	StylusArchs:    []string{string(rawdb.TargetWavm)},
-	ProducerConfig: pubsub.DefaultProducerConfig,
-	CreateStreams:  true,
+	Name:            "redis validation client",
+	Room:            2,
+	RedisURL:        "",
+	URL:             "",
+	StylusArchs:     []string{string(rawdb.TargetWavm)},
+	ProducerConfig:  pubsub.DefaultProducerConfig,
+	CreateStreams:   true,
+	Priority:        0,
+	RequestDeadline: 0,
 }
 
 var TestValidationClientConfig = ValidationClientConfig{
-	Name:           "test redis validation client",
-	Room:           2,
-	RedisURL:       "",
-	StreamPrefix:   "test-",
-	StylusArchs:    []string{string(rawdb.TargetWavm)},
-	ProducerConfig: pubsub.TestProducerConfig,
-	CreateStreams:  false,
+	Name:            "test redis validation client",
+	Room:            2,
+	RedisURL:        "",
+	URL:             "",
+	StreamPrefix:    "test-",
+	StylusArchs:     []string{string(rawdb.TargetWavm)},
+	ProducerConfig:  pubsub.TestProducerConfig,
+	CreateStreams:   false,
+	Priority:        0,
+	RequestDeadline: 0,
 }
 
 func ValidationClientConfigAddOptions(prefix string, f *pflag.FlagSet) {
 	f.String(prefix+".name", DefaultValidationClientConfig.Name, "validation client name")
 	f.Int32(prefix+".room", DefaultValidationClientConfig.Room, "validation client room")
 	f.String(prefix+".redis-url", DefaultValidationClientConfig.RedisURL, "redis url")
+	f.String(prefix+".url", DefaultValidationClientConfig.URL, "url of the pubsub transport: a redis url, memory://<name> or pubsub://<host>:<port> (takes precedence over redis-url)")
 	f.String(prefix+".stream-prefix", DefaultValidationClientConfig.StreamPrefix, "prefix for stream name")
 	f.StringSlice(prefix+".stylus-archs", DefaultValidationClientConfig.StylusArchs, "archs required for stylus workers")
 	pubsub.ProducerAddConfigAddOptions(prefix+".producer-config", f)
 	f.Bool(prefix+".create-streams", DefaultValidationClientConfig.CreateStreams, "create redis streams if it does not exist")
+	f.Int(prefix+".priority", DefaultValidationClientConfig.Priority, "priority lane of the validation requests, e.g. to serve a validator before other nodes sharing the validation servers; producer-config.priority-lanes of all clients and consumer-config.priority-lanes of the servers have to be above it")
+	f.Duration(prefix+".request-deadline", DefaultValidationClientConfig.RequestDeadline, "time after which validation requests which were not responded to fail, and are skipped by validation servers (0 = no deadline)")
 }
 
 // ValidationClient implements validation client through redis streams.
@@ -82,44 +114,44 @@ type ValidationClient struct {
 	capacity int
 	// producers stores moduleRoot to producer mapping.
 	producers   map[common.Hash]*pubsub.Producer[*validator.ValidationInput, validator.GoGlobalState]
-	redisClient redis.UniversalClient
+	transport   pubsub.Transport
 	moduleRoots []common.Hash
 }
 
 func NewValidationClient(cfg *ValidationClientConfig) (*ValidationClient, error) {
-	if cfg.RedisURL == "" {
-		return nil, fmt.Errorf("redis url cannot be empty")
+	if cfg.TransportURL() == "" {
+		return nil, fmt.Errorf("pubsub transport url cannot be empty")
 	}
-	redisClient, err := redisutil.RedisClientFromURL(cfg.RedisURL)
+	transport, err := pubsub.TransportFromURL(cfg.TransportURL())
 	if err != nil {
 		return nil, err
 	}
 	validationClient := &ValidationClient{
-		config:      cfg,
-		producers:   make(map[common.Hash]*pubsub.Producer[*validator.ValidationInput, validator.GoGlobalState]),
-		redisClient: redisClient,
-		capacity:    int(cfg.Room),
+		config:    cfg,
+		producers: make(map[common.Hash]*pubsub.Producer[*validator.ValidationInput, validator.GoGlobalState]),
+		transport: transport,
+		capacity:  int(cfg.Room),
 	}
 	return validationClient, nil
 }
 
 func (c *ValidationClient) Initialize(ctx context.Context, moduleRoots []common.Hash) error {
 	for _, mr := range moduleRoots {
-		if c.config.CreateStreams {
-			if err := pubsub.CreateStream(ctx, server_api.RedisStreamForRoot(c.config.StreamPrefix, mr), c.redisClient); err != nil {
-				return fmt.Errorf("creating redis stream: %w", err)
-			}
-		}
 		if _, exists := c.producers[mr]; exists {
 			log.Warn("Producer already exists for module root", "hash", mr)
 			continue
 		}
-		p, err := pubsub.NewProducer[*validator.ValidationInput, validator.GoGlobalState](
-			c.redisClient, server_api.RedisStreamForRoot(c.config.StreamPrefix, mr), &c.config.ProducerConfig)
+		p, err := pubsub.NewProducerWithTransport[*validator.ValidationInput, validator.GoGlobalState](
+			c.transport, server_api.RedisStreamForRoot(c.config.StreamPrefix, mr), &c.config.ProducerConfig)
 		if err != nil {
 			log.Warn("failed init redis for %v: %w", mr, err)
 			continue
 		}
+		if c.config.CreateStreams {
+			if err := p.CreateStreams(ctx); err != nil {
+				return fmt.Errorf("creating redis stream: %w", err)
+			}
+		}
 		c.producers[mr] = p
 		c.moduleRoots = append(c.moduleRoots, mr)
 	}
@@ -136,7 +168,7 @@ func (c *ValidationClient) Launch(entry *validator.ValidationInput, moduleRoot c
 		errPromise := containers.NewReadyPromise(validator.GoGlobalState{}, fmt.Errorf("no validation is configured for wasm root %v", moduleRoot))
 		return server_common.NewValRun(errPromise, moduleRoot)
 	}
-	promise, err := producer.Produce(c.GetContext(), entry)
+	promise, err := producer.ProduceWithOptions(c.GetContext(), entry, c.config.produceOptions())
 	if err != nil {
 		errPromise := containers.NewReadyPromise(validator.GoGlobalState{}, fmt.Errorf("error producing input: %w", err))
 		return server_common.NewValRun(errPromise, moduleRoot)
@@ -189,29 +221,23 @@ func NewBOLDRedisExecutionClient(redisValClient *ValidationClient) *BOLDRedisExe
 }
 
 func (br *BOLDRedisExecutionClient) Initialize(ctx context.Context, moduleRoots []common.Hash) error {
-	if br.redisValidationClient.config.RedisURL == "" {
-		return fmt.Errorf("redis url cannot be empty")
-	}
-	redisClient, err := redisutil.RedisClientFromURL(br.redisValidationClient.config.RedisURL)
-	if err != nil {
-		return err
-	}
+	transport := br.redisValidationClient.transport
 	for _, mr := range moduleRoots {
-		if br.redisValidationClient.config.CreateStreams {
-			if err := pubsub.CreateStream(ctx, server_api.RedisBoldStreamForRoot(br.redisValidationClient.config.StreamPrefix, mr), redisClient); err != nil {
-				return fmt.Errorf("creating redis stream: %w", err)
-			}
-		}
 		if _, exists := br.producers[mr]; exists {
 			log.Warn("Producer already exists for module root", "hash", mr)
 			continue
 		}
-		p, err := pubsub.NewProducer[*server_api.BoldValidationInput, []byte](
-			redisClient, server_api.RedisBoldStreamForRoot(br.redisValidationClient.config.StreamPrefix, mr), &br.redisValidationClient.config.ProducerConfig)
+		p, err := pubsub.NewProducerWithTransport[*server_api.BoldValidationInput, []byte](
+			transport, server_api.RedisBoldStreamForRoot(br.redisValidationClient.config.StreamPrefix, mr), &br.redisValidationClient.config.ProducerConfig)
 		if err != nil {
 			log.Warn("failed init redis for %v: %w", mr, err)
 			continue
 		}
+		if br.redisValidationClient.config.CreateStreams {
+			if err := p.CreateStreams(ctx); err != nil {
+				return fmt.Errorf("creating redis stream: %w", err)
+			}
+		}
 		br.producers[mr] = p
 	}
 	return nil
@@ -222,7 +248,7 @@ func (br *BOLDRedisExecutionClient) produce(req *server_api.BoldValidationInput)
 	if !found {
 		return containers.NewReadyPromise([]byte{}, fmt.Errorf("no validation is configured for wasm root %v", req.ModuleRoot))
 	}
-	promise, err := producer.Produce(br.GetContext(), req)
+	promise, err := producer.ProduceWithOptions(br.GetContext(), req, br.redisValidationClient.config.produceOptions())
 	if err != nil {
 		return containers.NewReadyPromise([]byte{}, fmt.Errorf("error producing input: %w", err))
 	}
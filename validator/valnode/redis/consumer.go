@@ -15,7 +15,6 @@ import (
 
 	"github.com/offchainlabs/nitro/pubsub"
 	"github.com/offchainlabs/nitro/util"
-	"github.com/offchainlabs/nitro/util/redisutil"
 	"github.com/offchainlabs/nitro/util/stopwaiter"
 	"github.com/offchainlabs/nitro/validator"
 	"github.com/offchainlabs/nitro/validator/server_api"
@@ -30,22 +29,29 @@ type ValidationServer struct {
 
 	// consumers stores moduleRoot to consumer mapping.
 	consumers map[common.Hash]*pubsub.Consumer[*validator.ValidationInput, validator.GoGlobalState]
+	transport pubsub.Transport
 
 	config *ValidationServerConfig
 }
 
 func NewValidationServer(cfg *ValidationServerConfig, spawner validator.ExecutionSpawner) (*ValidationServer, error) {
-	if cfg.RedisURL == "" {
-		return nil, fmt.Errorf("redis url cannot be empty")
+	if cfg.TransportURL() == "" {
+		return nil, fmt.Errorf("pubsub transport url cannot be empty")
 	}
-	redisClient, err := redisutil.RedisClientFromURL(cfg.RedisURL)
+	transport, err := pubsub.TransportFromURL(cfg.TransportURL())
 	if err != nil {
 		return nil, err
 	}
+	return NewValidationServerWithTransport(cfg, transport, spawner)
+}
+
+// NewValidationServerWithTransport creates a validation server consuming from
+// the given transport instead of the one in the config's url.
+func NewValidationServerWithTransport(cfg *ValidationServerConfig, transport pubsub.Transport, spawner validator.ExecutionSpawner) (*ValidationServer, error) {
 	consumers := make(map[common.Hash]*pubsub.Consumer[*validator.ValidationInput, validator.GoGlobalState])
 	for _, hash := range cfg.ModuleRoots {
 		mr := common.HexToHash(hash)
-		c, err := pubsub.NewConsumer[*validator.ValidationInput, validator.GoGlobalState](redisClient, server_api.RedisStreamForRoot(cfg.StreamPrefix, mr), &cfg.ConsumerConfig)
+		c, err := pubsub.NewConsumerWithTransport[*validator.ValidationInput, validator.GoGlobalState](transport, server_api.RedisStreamForRoot(cfg.StreamPrefix, mr), &cfg.ConsumerConfig)
 		if err != nil {
 			return nil, fmt.Errorf("creating consumer for validation: %w", err)
 		}
@@ -53,11 +59,17 @@ func NewValidationServer(cfg *ValidationServerConfig, spawner validator.Executio
 	}
 	return &ValidationServer{
 		consumers: consumers,
+		transport: transport,
 		spawner:   spawner,
 		config:    cfg,
 	}, nil
 }
 
+// Transport returns the transport the validation requests are consumed from.
+func (s *ValidationServer) Transport() pubsub.Transport {
+	return s.transport
+}
+
 func (s *ValidationServer) Start(ctx_in context.Context) {
 	s.StopWaiter.Start(ctx_in, s)
 	s.startBoldSpawner()
@@ -89,7 +101,7 @@ func (s *ValidationServer) Start(ctx_in context.Context) {
 		ready := make(chan struct{}, 1)
 		s.StopWaiter.LaunchThread(func(ctx context.Context) {
 			for {
-				if pubsub.StreamExists(ctx, c.StreamName(), c.RedisClient()) {
+				if c.StreamExists(ctx) {
 					ready <- struct{}{}
 					readyStreams <- struct{}{}
 					return
@@ -195,7 +207,7 @@ func (s *ValidationServer) Start(ctx_in context.Context) {
 
 func (s *ValidationServer) startBoldSpawner() {
 	var err error
-	s.boldSpawner, err = NewExecutionSpawner(s.config, s.spawner)
+	s.boldSpawner, err = NewExecutionSpawnerWithTransport(s.config, s.transport, s.spawner)
 	if err != nil {
 		log.Error("creating redis execution spawner", "error", err)
 		return
@@ -213,17 +225,23 @@ type ExecutionSpawner struct {
 }
 
 func NewExecutionSpawner(cfg *ValidationServerConfig, spawner validator.ExecutionSpawner) (*ExecutionSpawner, error) {
-	if cfg.RedisURL == "" {
-		return nil, fmt.Errorf("redis url cannot be empty")
+	if cfg.TransportURL() == "" {
+		return nil, fmt.Errorf("pubsub transport url cannot be empty")
 	}
-	redisClient, err := redisutil.RedisClientFromURL(cfg.RedisURL)
+	transport, err := pubsub.TransportFromURL(cfg.TransportURL())
 	if err != nil {
 		return nil, err
 	}
+	return NewExecutionSpawnerWithTransport(cfg, transport, spawner)
+}
+
+// NewExecutionSpawnerWithTransport creates an execution spawner consuming
+// from the given transport instead of the one in the config's url.
+func NewExecutionSpawnerWithTransport(cfg *ValidationServerConfig, transport pubsub.Transport, spawner validator.ExecutionSpawner) (*ExecutionSpawner, error) {
 	consumers := make(map[common.Hash]*pubsub.Consumer[*server_api.BoldValidationInput, []byte])
 	for _, hash := range cfg.ModuleRoots {
 		mr := common.HexToHash(hash)
-		c, err := pubsub.NewConsumer[*server_api.BoldValidationInput, []byte](redisClient, server_api.RedisBoldStreamForRoot(cfg.StreamPrefix, mr), &cfg.ConsumerConfig)
+		c, err := pubsub.NewConsumerWithTransport[*server_api.BoldValidationInput, []byte](transport, server_api.RedisBoldStreamForRoot(cfg.StreamPrefix, mr), &cfg.ConsumerConfig)
 		if err != nil {
 			return nil, fmt.Errorf("creating consumer for validation: %w", err)
 		}
@@ -249,7 +267,7 @@ func (s *ExecutionSpawner) Start(ctx_in context.Context) {
 		ready := make(chan struct{}, 1)
 		s.StopWaiter.LaunchThread(func(ctx context.Context) {
 			for {
-				if pubsub.StreamExists(ctx, c.StreamName(), c.RedisClient()) {
+				if c.StreamExists(ctx) {
 					ready <- struct{}{}
 					readyStreams <- struct{}{}
 					return
@@ -279,10 +297,18 @@ func (s *ExecutionSpawner) Start(ctx_in context.Context) {
 					// There's nothing in the queue.
 					return time.Second
 				}
+				// The request is left to be retried, with the failure recorded
+				// in case it's moved to the dead-letter stream
+				recordFailure := func(failure error) {
+					if err := c.RecordFailure(ctx, req.ID, failure); err != nil {
+						log.Warn("Error recording failure of request", "id", req.ID, "error", err)
+					}
+				}
 				run, err := s.spawner.CreateExecutionRun(moduleRoot,
 					req.Value.ValidationInput, true).Await(ctx)
 				if err != nil {
 					log.Error("Creating BOLD execution", "error", err)
+					recordFailure(err)
 					return 0
 				}
 				var res interface{}
@@ -300,11 +326,13 @@ func (s *ExecutionSpawner) Start(ctx_in context.Context) {
 				}
 				if err != nil {
 					log.Error("Getting machine hashes", "error", err)
+					recordFailure(err)
 					return 0
 				}
 				jsonRes, err := json.Marshal(res)
 				if err != nil {
 					log.Error("Marshaling result", "error", err)
+					recordFailure(err)
 					return 0
 				}
 				if err := c.SetResult(ctx, req.ID, jsonRes); err != nil {
@@ -333,6 +361,7 @@ func (s *ExecutionSpawner) Start(ctx_in context.Context) {
 
 type ValidationServerConfig struct {
 	RedisURL       string                `koanf:"redis-url"`
+	URL            string                `koanf:"url"`
 	ConsumerConfig pubsub.ConsumerConfig `koanf:"consumer-config"`
 	// Supported wasm module roots.
 	ModuleRoots []string `koanf:"module-roots"`
@@ -345,6 +374,7 @@ type ValidationServerConfig struct {
 
 var DefaultValidationServerConfig = ValidationServerConfig{
 	RedisURL:       "",
+	URL:            "",
 	StreamPrefix:   "",
 	ConsumerConfig: pubsub.DefaultConsumerConfig,
 	ModuleRoots:    []string{},
@@ -355,6 +385,7 @@ var DefaultValidationServerConfig = ValidationServerConfig{
 
 var TestValidationServerConfig = ValidationServerConfig{
 	RedisURL:       "",
+	URL:            "",
 	StreamPrefix:   "test-",
 	ConsumerConfig: pubsub.TestConsumerConfig,
 	ModuleRoots:    []string{},
@@ -367,6 +398,7 @@ func ValidationServerConfigAddOptions(prefix string, f *pflag.FlagSet) {
 	pubsub.ConsumerConfigAddOptions(prefix+".consumer-config", f)
 	f.StringSlice(prefix+".module-roots", nil, "Supported module root hashes")
 	f.String(prefix+".redis-url", DefaultValidationServerConfig.RedisURL, "url of redis server")
+	f.String(prefix+".url", DefaultValidationServerConfig.URL, "url of the pubsub transport: a redis url, memory://<name> or pubsub://<host>:<port> (takes precedence over redis-url)")
 	f.String(prefix+".stream-prefix", DefaultValidationServerConfig.StreamPrefix, "prefix for stream name")
 	f.Duration(prefix+".stream-timeout", DefaultValidationServerConfig.StreamTimeout, "Timeout on polling for existence of redis streams")
 	f.Int(prefix+".workers", DefaultValidationServerConfig.Workers, "number of validation threads (0 to use number of CPUs)")
@@ -374,5 +406,14 @@ func ValidationServerConfigAddOptions(prefix string, f *pflag.FlagSet) {
 }
 
 func (cfg *ValidationServerConfig) Enabled() bool {
-	return cfg.RedisURL != ""
+	return cfg.TransportURL() != ""
+}
+
+// TransportURL returns the url of the pubsub transport, which is url if set
+// and otherwise redis-url.
+func (cfg *ValidationServerConfig) TransportURL() string {
+	if cfg.URL != "" {
+		return cfg.URL
+	}
+	return cfg.RedisURL
 }
@@ -9,6 +9,7 @@ import (
 
 	"github.com/ethereum/go-ethereum/log"
 
+	"github.com/offchainlabs/nitro/pubsub"
 	"github.com/offchainlabs/nitro/util/redisutil"
 	"github.com/offchainlabs/nitro/util/testhelpers"
 )
@@ -31,3 +32,42 @@ func TestTimeout(t *testing.T) {
 	}
 	cancel()
 }
+
+func TestTransportURL(t *testing.T) {
+	config := TestValidationServerConfig
+	config.RedisURL = "redis://localhost:6379"
+	config.URL = "memory://validation-test"
+	config.ModuleRoots = []string{"0x123"}
+	if config.TransportURL() != config.URL {
+		t.Fatalf("expected url %v to take precedence over redis-url, got %v", config.URL, config.TransportURL())
+	}
+	vs, err := NewValidationServer(&config, nil)
+	if err != nil {
+		t.Fatalf("NewValidationServer() unexpected error: %v", err)
+	}
+	if vs.Transport() != pubsub.NamedMemoryTransport("validation-test") {
+		t.Error("expected the validation server to use the memory transport of its url")
+	}
+}
+
+func TestExecutionSpawnerSharesTransport(t *testing.T) {
+	config := TestValidationServerConfig
+	config.ModuleRoots = []string{"0x123"}
+	transport := pubsub.NewMemoryTransport()
+	vs, err := NewValidationServerWithTransport(&config, transport, nil)
+	if err != nil {
+		t.Fatalf("NewValidationServerWithTransport() unexpected error: %v", err)
+	}
+	// The hosted transport has no url, the BoLD consumers use the one of the
+	// validation server
+	spawner, err := NewExecutionSpawnerWithTransport(vs.config, vs.Transport(), nil)
+	if err != nil {
+		t.Fatalf("NewExecutionSpawnerWithTransport() unexpected error: %v", err)
+	}
+	if len(spawner.consumers) != len(config.ModuleRoots) {
+		t.Errorf("expected a consumer for each of the %d module roots, got %d", len(config.ModuleRoots), len(spawner.consumers))
+	}
+	if _, err := NewExecutionSpawner(vs.config, nil); err == nil {
+		t.Error("expected an error creating an execution spawner without a transport url")
+	}
+}
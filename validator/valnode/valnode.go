@@ -11,6 +11,7 @@ import (
 	"github.com/ethereum/go-ethereum/node"
 	"github.com/ethereum/go-ethereum/rpc"
 
+	"github.com/offchainlabs/nitro/pubsub"
 	"github.com/offchainlabs/nitro/validator"
 	"github.com/offchainlabs/nitro/validator/server_api"
 	"github.com/offchainlabs/nitro/validator/server_arb"
@@ -38,32 +39,35 @@ var DefaultWasmConfig = WasmConfig{
 }
 
 type Config struct {
-	UseJit     bool                               `koanf:"use-jit"`
-	ApiAuth    bool                               `koanf:"api-auth"`
-	ApiPublic  bool                               `koanf:"api-public"`
-	Arbitrator server_arb.ArbitratorSpawnerConfig `koanf:"arbitrator" reload:"hot"`
-	Jit        server_jit.JitSpawnerConfig        `koanf:"jit" reload:"hot"`
-	Wasm       WasmConfig                         `koanf:"wasm"`
+	UseJit       bool                               `koanf:"use-jit"`
+	ApiAuth      bool                               `koanf:"api-auth"`
+	ApiPublic    bool                               `koanf:"api-public"`
+	Arbitrator   server_arb.ArbitratorSpawnerConfig `koanf:"arbitrator" reload:"hot"`
+	Jit          server_jit.JitSpawnerConfig        `koanf:"jit" reload:"hot"`
+	Wasm         WasmConfig                         `koanf:"wasm"`
+	PubsubServer pubsub.TransportServerConfig       `koanf:"pubsub-server"`
 }
 
 type ValidationConfigFetcher func() *Config
 
 var DefaultValidationConfig = Config{
-	UseJit:     true,
-	Jit:        server_jit.DefaultJitSpawnerConfig,
-	ApiAuth:    true,
-	ApiPublic:  false,
-	Arbitrator: server_arb.DefaultArbitratorSpawnerConfig,
-	Wasm:       DefaultWasmConfig,
+	UseJit:       true,
+	Jit:          server_jit.DefaultJitSpawnerConfig,
+	ApiAuth:      true,
+	ApiPublic:    false,
+	Arbitrator:   server_arb.DefaultArbitratorSpawnerConfig,
+	Wasm:         DefaultWasmConfig,
+	PubsubServer: pubsub.DefaultTransportServerConfig,
 }
 
 var TestValidationConfig = Config{
-	UseJit:     true,
-	Jit:        server_jit.DefaultJitSpawnerConfig,
-	ApiAuth:    false,
-	ApiPublic:  true,
-	Arbitrator: server_arb.DefaultArbitratorSpawnerConfig,
-	Wasm:       DefaultWasmConfig,
+	UseJit:       true,
+	Jit:          server_jit.DefaultJitSpawnerConfig,
+	ApiAuth:      false,
+	ApiPublic:    true,
+	Arbitrator:   server_arb.DefaultArbitratorSpawnerConfig,
+	Wasm:         DefaultWasmConfig,
+	PubsubServer: pubsub.DefaultTransportServerConfig,
 }
 
 func ValidationConfigAddOptions(prefix string, f *pflag.FlagSet) {
@@ -73,6 +77,7 @@ func ValidationConfigAddOptions(prefix string, f *pflag.FlagSet) {
 	server_arb.ArbitratorSpawnerConfigAddOptions(prefix+".arbitrator", f)
 	server_jit.JitSpawnerConfigAddOptions(prefix+".jit", f)
 	WasmConfigAddOptions(prefix+".wasm", f)
+	pubsub.TransportServerConfigAddOptions(prefix+".pubsub-server", f)
 }
 
 type ValidationNode struct {
@@ -81,7 +86,8 @@ type ValidationNode struct {
 	jitSpawner *server_jit.JitSpawner
 	serverAPI  *ExecServerAPI
 
-	redisConsumer *redis.ValidationServer
+	redisConsumer   *redis.ValidationServer
+	transportServer *pubsub.TransportServer
 }
 
 func EnsureValidationExposedViaAuthRPC(stackConf *node.Config) {
@@ -124,7 +130,31 @@ func CreateValidationNode(configFetcher ValidationConfigFetcher, stack *node.Nod
 		serverAPI = NewExecutionServerAPI(arbSpawner, arbSpawner, arbConfigFetcher)
 	}
 	var redisConsumer *redis.ValidationServer
+	var transportServer *pubsub.TransportServer
 	redisValidationConfig := arbConfigFetcher().RedisValidationServerConfig
+	if config.PubsubServer.Enable {
+		// Validation clients connect to the hosted transport with a
+		// pubsub://<host>:<port> url, and this node consumes from it directly
+		// unless another transport is configured.
+		transport := pubsub.NewMemoryTransport()
+		transportServer, err = pubsub.NewTransportServer(&config.PubsubServer, transport)
+		if err != nil {
+			return nil, err
+		}
+		if !redisValidationConfig.Enabled() {
+			// Consume the requests for the module roots this node can
+			// validate, unless others are configured.
+			if len(redisValidationConfig.ModuleRoots) == 0 {
+				for _, moduleRoot := range locator.ModuleRoots() {
+					redisValidationConfig.ModuleRoots = append(redisValidationConfig.ModuleRoots, moduleRoot.Hex())
+				}
+			}
+			redisConsumer, err = redis.NewValidationServerWithTransport(&redisValidationConfig, transport, arbSpawner)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
 	if redisValidationConfig.Enabled() {
 		redisConsumer, err = redis.NewValidationServer(&redisValidationConfig, arbSpawner)
 		if err != nil {
@@ -138,9 +168,18 @@ func CreateValidationNode(configFetcher ValidationConfigFetcher, stack *node.Nod
 		Public:        config.ApiPublic,
 		Authenticated: config.ApiAuth,
 	}}
+	if redisConsumer != nil {
+		valAPIs = append(valAPIs, rpc.API{
+			Namespace:     pubsub.DeadLetterNamespace,
+			Version:       "1.0",
+			Service:       pubsub.NewDeadLetterAPI(redisConsumer.Transport()),
+			Public:        false,
+			Authenticated: config.ApiAuth,
+		})
+	}
 	stack.RegisterAPIs(valAPIs)
 
-	return &ValidationNode{configFetcher, arbSpawner, jitSpawner, serverAPI, redisConsumer}, nil
+	return &ValidationNode{configFetcher, arbSpawner, jitSpawner, serverAPI, redisConsumer, transportServer}, nil
 }
 
 func (v *ValidationNode) Start(ctx context.Context) error {
@@ -152,6 +191,11 @@ func (v *ValidationNode) Start(ctx context.Context) error {
 			return err
 		}
 	}
+	if v.transportServer != nil {
+		if err := v.transportServer.Start(ctx); err != nil {
+			return err
+		}
+	}
 	if v.redisConsumer != nil {
 		v.redisConsumer.Start(ctx)
 	}
@@ -163,6 +207,9 @@ func (v *ValidationNode) Stop() {
 	if v.redisConsumer != nil {
 		v.redisConsumer.StopOnly()
 	}
+	if v.transportServer != nil {
+		v.transportServer.StopOnly()
+	}
 	v.arbSpawner.Stop()
 	if v.jitSpawner != nil {
 		v.jitSpawner.Stop()
@@ -28,8 +28,12 @@ import (
 var errContextDone = errors.New("context done")
 
 type message struct {
-	data           []byte
-	sequenceNumber *arbutil.MessageIndex
+	data []byte
+	// firstSequenceNumber and sequenceNumber are the first and last sequence
+	// numbers of the broadcast, whether or not they matched the client's filter.
+	firstSequenceNumber *arbutil.MessageIndex
+	sequenceNumber      *arbutil.MessageIndex
+	queued              time.Time
 }
 
 type ClientConnectionAction struct {
@@ -52,6 +56,12 @@ type ClientConnection struct {
 	requestedSeqNum arbutil.MessageIndex
 	LastSentSeqNum  atomic.Uint64
 
+	// deliveredSeqNum is the sequence number of the last message written to
+	// the client, including messages skipped by the client's filter. Unlike
+	// LastSentSeqNum it also tracks live messages and goes back on reorgs.
+	deliveredSeqNum atomic.Uint64
+	hasDelivered    atomic.Bool
+
 	lastHeardUnix atomic.Int64
 	out           chan message
 	backlog       backlog.Backlog
@@ -59,9 +69,13 @@ type ClientConnection struct {
 	backlogSent   bool
 
 	compression bool
+	encoding    m.Encoding
+	filter      *m.Filter
 	flateReader *wsflate.Reader
 
 	delay time.Duration
+
+	keyRotations func() []*m.KeyRotationMessage
 }
 
 func NewClientConnection(
@@ -71,9 +85,12 @@ func NewClientConnection(
 	requestedSeqNum arbutil.MessageIndex,
 	connectingIP net.IP,
 	compression bool,
+	encoding m.Encoding,
+	filter *m.Filter,
 	maxSendQueue int,
 	delay time.Duration,
 	bklg backlog.Backlog,
+	keyRotations func() []*m.KeyRotationMessage,
 ) *ClientConnection {
 	clientConnection := &ClientConnection{
 		conn:            conn,
@@ -85,9 +102,12 @@ func NewClientConnection(
 		requestedSeqNum: requestedSeqNum,
 		out:             make(chan message, maxSendQueue),
 		compression:     compression,
+		encoding:        encoding,
+		filter:          filter,
 		flateReader:     NewFlateReader(),
 		delay:           delay,
 		backlog:         bklg,
+		keyRotations:    keyRotations,
 		registered:      make(chan bool, 1),
 		backlogSent:     false,
 	}
@@ -103,6 +123,16 @@ func (cc *ClientConnection) Compression() bool {
 	return cc.compression
 }
 
+func (cc *ClientConnection) Encoding() m.Encoding {
+	return cc.encoding
+}
+
+// Filter returns the filter the client subscribed with, or nil if the client
+// receives all messages.
+func (cc *ClientConnection) Filter() *m.Filter {
+	return cc.filter
+}
+
 // Register sends the ClientConnection to be registered with the ClientManager.
 func (cc *ClientConnection) Register() {
 	cc.clientAction <- ClientConnectionAction{
@@ -146,13 +176,17 @@ func (cc *ClientConnection) writeBacklog(ctx context.Context, segment backlog.Ba
 			break
 		}
 		isFirstSegment = false
-		bm := &m.BroadcastMessage{
-			Version:  m.V1,
-			Messages: msgs,
-		}
-		err := cc.writeBroadcastMessage(bm)
-		if err != nil {
-			return err
+		// Messages not matching the client's filter are skipped, but still
+		// count as sent so they are not sent again when catching up.
+		if filtered := cc.filter.Apply(msgs); len(filtered) > 0 {
+			bm := &m.BroadcastMessage{
+				Version:  m.V1,
+				Messages: filtered,
+			}
+			err := cc.writeBroadcastMessage(bm)
+			if err != nil {
+				return err
+			}
 		}
 
 		// do not use prevSegment.End() method, must figure out the last
@@ -160,13 +194,30 @@ func (cc *ClientConnection) writeBacklog(ctx context.Context, segment backlog.Ba
 		// more messages are added.
 		end := uint64(msgs[len(msgs)-1].SequenceNumber)
 		cc.LastSentSeqNum.Store(end)
-		log.Debug("segment sent to client", "client", cc.Name, "sentCount", len(bm.Messages), "lastSentSeqNum", end)
+		cc.setDelivered(msgs[len(msgs)-1].SequenceNumber)
+		log.Debug("segment sent to client", "client", cc.Name, "sentCount", len(msgs), "lastSentSeqNum", end)
 	}
 	return nil
 }
 
+// writeKeyRotations writes the key rotations following the given number of
+// already sent ones, and returns the number of key rotations sent in total.
+func (cc *ClientConnection) writeKeyRotations(sent int) (int, error) {
+	if cc.keyRotations == nil {
+		return sent, nil
+	}
+	rotations := cc.keyRotations()
+	for ; sent < len(rotations); sent++ {
+		err := cc.writeBroadcastMessage(&m.BroadcastMessage{Version: m.V1, KeyRotationMessage: rotations[sent]})
+		if err != nil {
+			return sent, err
+		}
+	}
+	return sent, nil
+}
+
 func (cc *ClientConnection) writeBroadcastMessage(bm *m.BroadcastMessage) error {
-	notCompressed, compressed, err := serializeMessage(bm, !cc.compression, cc.compression)
+	notCompressed, compressed, err := serializeMessage(bm, cc.encoding, !cc.compression, cc.compression)
 	if err != nil {
 		return err
 	}
@@ -200,6 +251,15 @@ func (cc *ClientConnection) Start(parentCtx context.Context) {
 			}
 		}
 
+		// Key rotations are sent before any messages so that the client can
+		// verify the signatures of messages after the rotation
+		keyRotationsSent, err := cc.writeKeyRotations(0)
+		if err != nil {
+			logWarn(err, "error writing key rotations")
+			cc.Remove()
+			return
+		}
+
 		// Send the current backlog before registering the ClientConnection in
 		// case the backlog is very large
 		segment := cc.backlog.Head()
@@ -211,7 +271,7 @@ func (cc *ClientConnection) Start(parentCtx context.Context) {
 				segment = s
 			}
 		}
-		err := cc.writeBacklog(ctx, segment)
+		err = cc.writeBacklog(ctx, segment)
 		if errors.Is(err, errContextDone) {
 			return
 		} else if err != nil {
@@ -233,6 +293,13 @@ func (cc *ClientConnection) Start(parentCtx context.Context) {
 			log.Error("timed out waiting for ClientConnection to register with ClientManager", "client", cc.Name)
 		}
 
+		// Key rotations broadcast while the backlog was sent were missed
+		if _, err := cc.writeKeyRotations(keyRotationsSent); err != nil {
+			logWarn(err, "error writing key rotations")
+			cc.Remove()
+			return
+		}
+
 		// broadcast any new messages sent to the out channel
 		for {
 			select {
@@ -245,28 +312,42 @@ func (cc *ClientConnection) Start(parentCtx context.Context) {
 				}
 
 				expSeqNum := cc.LastSentSeqNum.Load() + 1
-				if !cc.backlogSent && msg.sequenceNumber != nil && uint64(*msg.sequenceNumber) > expSeqNum {
-					catchupSeqNum := uint64(*msg.sequenceNumber) - 1
+				if !cc.backlogSent && msg.firstSequenceNumber != nil && uint64(*msg.firstSequenceNumber) > expSeqNum {
+					catchupSeqNum := uint64(*msg.firstSequenceNumber) - 1
 					bm, err := cc.backlog.Get(expSeqNum, catchupSeqNum)
 					if err != nil {
 						logWarn(err, fmt.Sprintf("error reading messages %d to %d from backlog", expSeqNum, catchupSeqNum))
 						return
 					}
 
-					err = cc.writeBroadcastMessage(bm)
+					bm.Messages = cc.filter.Apply(bm.Messages)
+					if len(bm.Messages) > 0 {
+						err = cc.writeBroadcastMessage(bm)
+						if err != nil {
+							logWarn(err, fmt.Sprintf("error writing messages %d to %d from backlog", expSeqNum, catchupSeqNum))
+							cc.Remove()
+							return
+						}
+					}
+					cc.setDelivered(arbutil.MessageIndex(catchupSeqNum))
+				}
+				cc.backlogSent = true
+
+				// Messages not matching the client's filter have no data
+				// unless they carry a confirmation.
+				if len(msg.data) > 0 {
+					err := cc.writeRaw(msg.data)
 					if err != nil {
-						logWarn(err, fmt.Sprintf("error writing messages %d to %d from backlog", expSeqNum, catchupSeqNum))
+						logWarn(err, "error writing data to client")
 						cc.Remove()
 						return
 					}
+					if !msg.queued.IsZero() {
+						clientsDeliverHistogram.Update(time.Since(msg.queued).Microseconds())
+					}
 				}
-				cc.backlogSent = true
-
-				err := cc.writeRaw(msg.data)
-				if err != nil {
-					logWarn(err, "error writing data to client")
-					cc.Remove()
-					return
+				if msg.sequenceNumber != nil {
+					cc.setDelivered(*msg.sequenceNumber)
 				}
 			}
 		}
@@ -291,6 +372,42 @@ func (cc *ClientConnection) RequestedSeqNum() arbutil.MessageIndex {
 	return cc.requestedSeqNum
 }
 
+func (cc *ClientConnection) setDelivered(seqNum arbutil.MessageIndex) {
+	cc.deliveredSeqNum.Store(uint64(seqNum))
+	cc.hasDelivered.Store(true)
+}
+
+// DeliveredSeqNum returns the sequence number of the last message written to
+// the client, or false if nothing has been written yet.
+func (cc *ClientConnection) DeliveredSeqNum() (arbutil.MessageIndex, bool) {
+	if !cc.hasDelivered.Load() {
+		return 0, false
+	}
+	return arbutil.MessageIndex(cc.deliveredSeqNum.Load()), true
+}
+
+// QueueDepth returns the number of messages waiting to be written to the
+// client.
+func (cc *ClientConnection) QueueDepth() int {
+	return len(cc.out)
+}
+
+// Lag returns the number of messages the client is behind the given latest
+// broadcast sequence number.
+func (cc *ClientConnection) Lag(latest arbutil.MessageIndex) uint64 {
+	delivered, ok := cc.DeliveredSeqNum()
+	if !ok {
+		if latest < cc.requestedSeqNum {
+			return 0
+		}
+		return uint64(latest-cc.requestedSeqNum) + 1
+	}
+	if latest <= delivered {
+		return 0
+	}
+	return uint64(latest - delivered)
+}
+
 func (cc *ClientConnection) GetLastHeard() time.Time {
 	return time.Unix(cc.lastHeardUnix.Load(), 0)
 }
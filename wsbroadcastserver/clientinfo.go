@@ -0,0 +1,111 @@
+// Copyright 2026, Offchain Labs, Inc.
+// For license information, see https://github.com/OffchainLabs/nitro/blob/master/LICENSE.md
+
+package wsbroadcastserver
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/offchainlabs/nitro/arbutil"
+)
+
+var ErrClientNotFound = errors.New("feed client not found")
+
+// ClientInfo describes a connected feed client, to debug slow consumers.
+type ClientInfo struct {
+	Name            string                `json:"name"`
+	IP              string                `json:"ip"`
+	Compression     bool                  `json:"compression"`
+	Encoding        string                `json:"encoding"`
+	Filtered        bool                  `json:"filtered"`
+	RequestedSeqNum arbutil.MessageIndex  `json:"requestedSeqNum"`
+	DeliveredSeqNum *arbutil.MessageIndex `json:"deliveredSeqNum,omitempty"`
+	Lag             uint64                `json:"lag"`
+	QueueDepth      int                   `json:"queueDepth"`
+	Age             string                `json:"age"`
+	LastHeard       time.Time             `json:"lastHeard"`
+}
+
+// clientInfos must only be called by the main ClientManager thread.
+func (cm *ClientManager) clientInfos() []ClientInfo {
+	infos := make([]ClientInfo, 0, len(cm.clientPtrMap))
+	for client := range cm.clientPtrMap {
+		info := ClientInfo{
+			Name:            client.Name,
+			IP:              client.clientIp.String(),
+			Compression:     client.Compression(),
+			Encoding:        client.Encoding().String(),
+			Filtered:        !client.Filter().IsEmpty(),
+			RequestedSeqNum: client.RequestedSeqNum(),
+			QueueDepth:      client.QueueDepth(),
+			Age:             client.Age().Round(time.Second).String(),
+			LastHeard:       client.GetLastHeard(),
+		}
+		if delivered, ok := client.DeliveredSeqNum(); ok {
+			info.DeliveredSeqNum = &delivered
+		}
+		if cm.hasLatest {
+			info.Lag = client.Lag(cm.latestSeqNum)
+		}
+		infos = append(infos, info)
+	}
+	return infos
+}
+
+// runOnManagerThread runs the function on the main ClientManager thread and
+// waits for it to finish.
+func (cm *ClientManager) runOnManagerThread(ctx context.Context, f func()) error {
+	if !cm.Started() || cm.Stopped() {
+		return errors.New("feed client manager is not running")
+	}
+	done := make(chan struct{})
+	request := func() {
+		f()
+		close(done)
+	}
+	select {
+	case cm.requests <- request:
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-cm.GetContext().Done():
+		return errors.New("feed client manager stopped")
+	}
+	<-done
+	return nil
+}
+
+// Clients returns information about the clients registered with the
+// ClientManager.
+func (cm *ClientManager) Clients(ctx context.Context) ([]ClientInfo, error) {
+	var infos []ClientInfo
+	err := cm.runOnManagerThread(ctx, func() {
+		infos = cm.clientInfos()
+	})
+	return infos, err
+}
+
+// DisconnectClient disconnects the clients with the given name, and returns
+// ErrClientNotFound if there is none.
+func (cm *ClientManager) DisconnectClient(ctx context.Context, name string) error {
+	disconnected := 0
+	err := cm.runOnManagerThread(ctx, func() {
+		for client := range cm.clientPtrMap {
+			if client.Name == name {
+				log.Info("disconnecting feed client on request", "client", client.Name, "age", client.Age())
+				cm.removeClient(client)
+				disconnected++
+			}
+		}
+	})
+	if err != nil {
+		return err
+	}
+	if disconnected == 0 {
+		return ErrClientNotFound
+	}
+	return nil
+}
@@ -7,7 +7,6 @@ import (
 	"bytes"
 	"compress/flate"
 	"context"
-	"encoding/json"
 	"fmt"
 	"io"
 	"strings"
@@ -37,6 +36,11 @@ var (
 	clientsTotalFailedUpgradeCounter = metrics.NewRegisteredCounter("arb/feed/clients/failed/upgrade", nil)
 	clientsTotalFailedWorkerCounter  = metrics.NewRegisteredCounter("arb/feed/clients/failed/worker", nil)
 	clientsDurationHistogram         = metrics.NewRegisteredHistogram("arb/feed/clients/duration", nil, metrics.NewBoundedHistogramSample())
+	clientsDeliverHistogram          = metrics.NewRegisteredHistogram("arb/feed/clients/deliver", nil, metrics.NewBoundedHistogramSample())
+	clientsLagHistogram              = metrics.NewRegisteredHistogram("arb/feed/clients/lag", nil, metrics.NewBoundedHistogramSample())
+	clientsMaxLagGauge               = metrics.NewRegisteredGauge("arb/feed/clients/lag/max", nil)
+	clientsQueueDepthHistogram       = metrics.NewRegisteredHistogram("arb/feed/clients/queue", nil, metrics.NewBoundedHistogramSample())
+	clientsMaxQueueDepthGauge        = metrics.NewRegisteredGauge("arb/feed/clients/queue/max", nil)
 )
 
 // ClientManager manages client connections
@@ -52,6 +56,14 @@ type ClientManager struct {
 	config        BroadcasterConfigFetcher
 	backlog       backlog.Backlog
 
+	// Only accessed by the main ClientManager thread
+	latestSeqNum arbutil.MessageIndex
+	hasLatest    bool
+
+	// requests are run by the main ClientManager thread, to access the
+	// clients from other threads
+	requests chan func()
+
 	connectionLimiter *ConnectionLimiter
 }
 
@@ -65,6 +77,7 @@ func NewClientManager(poller netpoll.Poller, configFetcher BroadcasterConfigFetc
 		clientAction:      make(chan ClientConnectionAction, 128),
 		config:            configFetcher,
 		backlog:           bklg,
+		requests:          make(chan func()),
 		connectionLimiter: NewConnectionLimiter(func() *ConnectionLimiterConfig { return &configFetcher().ConnectionLimits }),
 	}
 }
@@ -180,51 +193,109 @@ func (cm *ClientManager) doBroadcast(bm *m.BroadcastMessage) ([]*ClientConnectio
 	if err := cm.backlog.Append(bm); err != nil {
 		return nil, err
 	}
+	if n := len(bm.Messages); n > 0 {
+		cm.latestSeqNum = bm.Messages[n-1].SequenceNumber
+		cm.hasLatest = true
+	}
 	config := cm.config()
-	//                                        /-> wsutil.Writer -> not compressed msg buffer
-	// bm -> json.Encoder -> io.MultiWriter -|
-	//                                        \-> flateWriter -> wsutil.Writer -> compressed msg buffer
+	//                                                  /-> wsutil.Writer -> not compressed msg buffer
+	// bm -> BroadcastMessage.Encode -> io.MultiWriter -|
+	//                                                  \-> flateWriter -> wsutil.Writer -> compressed msg buffer
+	//
+	// Messages are serialized at most once per encoding and subset of
+	// messages matching a client's filter, and only if a client needing that
+	// serialization is connected. Clients whose filter matches none of the
+	// messages only receive the confirmation, if there is one.
+	type serializedKey struct {
+		encoding m.Encoding
+		matching string
+	}
+	serialized := make(map[serializedKey]*serializedMessage)
+	getSerialized := func(key serializedKey, messages []*m.BroadcastFeedMessage) (*serializedMessage, error) {
+		if s, ok := serialized[key]; ok {
+			return s, nil
+		}
+		toSerialize := bm
+		if len(messages) != len(bm.Messages) {
+			toSerialize = &m.BroadcastMessage{
+				Version:                        bm.Version,
+				Messages:                       messages,
+				ConfirmedSequenceNumberMessage: bm.ConfirmedSequenceNumberMessage,
+			}
+		}
+		notCompressed, compressed, err := serializeMessage(toSerialize, key.encoding, !config.RequireCompression, config.EnableCompression)
+		if err != nil {
+			return nil, err
+		}
+		s := &serializedMessage{notCompressed: notCompressed, compressed: compressed}
+		serialized[key] = s
+		return s, nil
+	}
 
-	notCompressed, compressed, err := serializeMessage(bm, !config.RequireCompression, config.EnableCompression)
-	if err != nil {
-		return nil, err
+	// All clients are queued the full range of sequence numbers, so that they
+	// know which messages they have skipped when catching up from the backlog.
+	var firstSeqNum, lastSeqNum *arbutil.MessageIndex
+	if n := len(bm.Messages); n > 0 {
+		firstSeqNum = &bm.Messages[0].SequenceNumber
+		lastSeqNum = &bm.Messages[n-1].SequenceNumber
 	}
 
 	sendQueueTooLargeCount := 0
 	clientDeleteList := make([]*ClientConnection, 0, len(cm.clientPtrMap))
 	for client := range cm.clientPtrMap {
+		if client.Encoding().IsBinary() && !config.EnableBinaryEncoding {
+			log.Warn("disconnecting because client has negotiated binary encoding, but binary encoding support is disabled", "client", client.Name)
+			clientDeleteList = append(clientDeleteList, client)
+			continue
+		}
+		messages := bm.Messages
+		matching := make([]byte, len(bm.Messages))
+		for i := range matching {
+			matching[i] = '1'
+		}
+		if filter := client.Filter(); !filter.IsEmpty() {
+			messages = make([]*m.BroadcastFeedMessage, 0, len(bm.Messages))
+			for i, msg := range bm.Messages {
+				if filter.Matches(msg) {
+					messages = append(messages, msg)
+				} else {
+					matching[i] = '0'
+				}
+			}
+		}
+		key := serializedKey{encoding: client.Encoding(), matching: string(matching)}
+		// When the client is not sent anything, data is left empty but the
+		// sequence numbers are still queued.
 		var data []byte
-		if client.Compression() {
-			if config.EnableCompression {
-				data = compressed.Bytes()
-			} else {
-				log.Warn("disconnecting because client has enabled compression, but compression support is disabled", "client", client.Name)
-				clientDeleteList = append(clientDeleteList, client)
-				continue
+		if len(messages) > 0 || bm.ConfirmedSequenceNumberMessage != nil {
+			s, err := getSerialized(key, messages)
+			if err != nil {
+				return nil, err
 			}
-		} else {
-			if !config.RequireCompression {
-				data = notCompressed.Bytes()
+			if client.Compression() {
+				if config.EnableCompression {
+					data = s.compressed.Bytes()
+				} else {
+					log.Warn("disconnecting because client has enabled compression, but compression support is disabled", "client", client.Name)
+					clientDeleteList = append(clientDeleteList, client)
+					continue
+				}
 			} else {
-				log.Warn("disconnecting because client has disabled compression, but compression support is required", "client", client.Name)
-				clientDeleteList = append(clientDeleteList, client)
-				continue
+				if !config.RequireCompression {
+					data = s.notCompressed.Bytes()
+				} else {
+					log.Warn("disconnecting because client has disabled compression, but compression support is required", "client", client.Name)
+					clientDeleteList = append(clientDeleteList, client)
+					continue
+				}
 			}
 		}
 
-		var seqNum *arbutil.MessageIndex
-		n := len(bm.Messages)
-		if n == 0 {
-			seqNum = nil
-		} else if n == 1 {
-			seqNum = &bm.Messages[0].SequenceNumber
-		} else {
-			return nil, fmt.Errorf("doBroadcast was sent %d BroadcastFeedMessages, it can only parse 1 BroadcastFeedMessage at a time", n)
-		}
-
 		m := message{
-			sequenceNumber: seqNum,
-			data:           data,
+			firstSequenceNumber: firstSeqNum,
+			sequenceNumber:      lastSeqNum,
+			data:                data,
+			queued:              time.Now(),
 		}
 		select {
 		case client.out <- m:
@@ -246,23 +317,33 @@ func (cm *ClientManager) doBroadcast(bm *m.BroadcastMessage) ([]*ClientConnectio
 	return clientDeleteList, nil
 }
 
-func serializeMessage(bm *m.BroadcastMessage, enableNonCompressedOutput, enableCompressedOutput bool) (bytes.Buffer, bytes.Buffer, error) {
+// serializedMessage holds a BroadcastMessage serialized into websocket frames.
+type serializedMessage struct {
+	notCompressed bytes.Buffer
+	compressed    bytes.Buffer
+}
+
+func serializeMessage(bm *m.BroadcastMessage, encoding m.Encoding, enableNonCompressedOutput, enableCompressedOutput bool) (bytes.Buffer, bytes.Buffer, error) {
 	flateWriter, err := flate.NewWriterDict(nil, DeflateCompressionLevel, GetStaticCompressorDictionary())
 	if err != nil {
 		return bytes.Buffer{}, bytes.Buffer{}, fmt.Errorf("unable to create flate writer: %w", err)
 	}
 
+	opCode := ws.OpText
+	if encoding.IsBinary() {
+		opCode = ws.OpBinary
+	}
 	var notCompressed bytes.Buffer
 	var compressed bytes.Buffer
 	writers := []io.Writer{}
 	var notCompressedWriter *wsutil.Writer
 	var compressedWriter *wsutil.Writer
 	if enableNonCompressedOutput {
-		notCompressedWriter = wsutil.NewWriter(&notCompressed, ws.StateServerSide, ws.OpText)
+		notCompressedWriter = wsutil.NewWriter(&notCompressed, ws.StateServerSide, opCode)
 		writers = append(writers, notCompressedWriter)
 	}
 	if enableCompressedOutput {
-		compressedWriter = wsutil.NewWriter(&compressed, ws.StateServerSide|ws.StateExtended, ws.OpText)
+		compressedWriter = wsutil.NewWriter(&compressed, ws.StateServerSide|ws.StateExtended, opCode)
 		var msg wsflate.MessageState
 		msg.SetCompressed(true)
 		compressedWriter.SetExtensions(&msg)
@@ -271,10 +352,13 @@ func serializeMessage(bm *m.BroadcastMessage, enableNonCompressedOutput, enableC
 	}
 
 	multiWriter := io.MultiWriter(writers...)
-	encoder := json.NewEncoder(multiWriter)
-	if err := encoder.Encode(bm); err != nil {
+	data, err := bm.Encode(encoding)
+	if err != nil {
 		return bytes.Buffer{}, bytes.Buffer{}, fmt.Errorf("unable to encode message: %w", err)
 	}
+	if _, err := multiWriter.Write(data); err != nil {
+		return bytes.Buffer{}, bytes.Buffer{}, fmt.Errorf("unable to write message: %w", err)
+	}
 	if notCompressedWriter != nil {
 		if err := notCompressedWriter.Flush(); err != nil {
 			return bytes.Buffer{}, bytes.Buffer{}, fmt.Errorf("unable to flush message: %w", err)
@@ -300,7 +384,19 @@ func (cm *ClientManager) verifyClients() []*ClientConnection {
 
 	// Send ping to all connected clients
 	log.Debug("pinging clients", "count", len(cm.clientPtrMap))
+	var maxLag uint64
+	var maxQueueDepth int
 	for client := range cm.clientPtrMap {
+		if cm.hasLatest {
+			lag := client.Lag(cm.latestSeqNum)
+			// #nosec G115
+			clientsLagHistogram.Update(int64(lag))
+			maxLag = max(maxLag, lag)
+		}
+		queueDepth := client.QueueDepth()
+		clientsQueueDepthHistogram.Update(int64(queueDepth))
+		maxQueueDepth = max(maxQueueDepth, queueDepth)
+
 		diff := time.Since(client.GetLastHeard())
 		if diff > cm.config().ClientTimeout {
 			log.Debug("disconnecting because connection timed out", "client", client.Name)
@@ -313,6 +409,9 @@ func (cm *ClientManager) verifyClients() []*ClientConnection {
 			}
 		}
 	}
+	// #nosec G115
+	clientsMaxLagGauge.Update(int64(maxLag))
+	clientsMaxQueueDepthGauge.Update(int64(maxQueueDepth))
 
 	return clientDeleteList
 }
@@ -344,25 +443,10 @@ func (cm *ClientManager) Start(parentCtx context.Context) {
 				}
 			case bm := <-cm.broadcastChan:
 				var err error
-				for i, msg := range bm.Messages {
-					m := &m.BroadcastMessage{
-						Version:  bm.Version,
-						Messages: []*m.BroadcastFeedMessage{msg},
-					}
-					// This ensures that only one message is sent with the confirmed sequence number
-					if i == 0 {
-						m.ConfirmedSequenceNumberMessage = bm.ConfirmedSequenceNumberMessage
-					}
-					clientDeleteList, err = cm.doBroadcast(m)
-					logError(err, "failed to do broadcast")
-				}
-
-				// A message with ConfirmedSequenceNumberMessage could be sent without any messages
-				// this section ensures that message is still sent.
-				if len(bm.Messages) == 0 {
-					clientDeleteList, err = cm.doBroadcast(bm)
-					logError(err, "failed to do broadcast")
-				}
+				clientDeleteList, err = cm.doBroadcast(bm)
+				logError(err, "failed to do broadcast")
+			case request := <-cm.requests:
+				request()
 			case <-pingTimer.C:
 				clientDeleteList = cm.verifyClients()
 				pingTimer.Reset(cm.config().Ping)
@@ -0,0 +1,122 @@
+// Copyright 2026, Offchain Labs, Inc.
+// For license information, see https://github.com/OffchainLabs/nitro/blob/master/LICENSE.md
+
+package wsbroadcastserver
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/gobwas/ws"
+
+	"github.com/offchainlabs/nitro/arbos/arbostypes"
+	"github.com/offchainlabs/nitro/arbutil"
+	"github.com/offchainlabs/nitro/broadcaster/backlog"
+	m "github.com/offchainlabs/nitro/broadcaster/message"
+)
+
+func testFeedMessage(seqNum arbutil.MessageIndex, kind uint8) *m.BroadcastFeedMessage {
+	return &m.BroadcastFeedMessage{
+		SequenceNumber: seqNum,
+		Message: arbostypes.MessageWithMetadata{
+			Message: &arbostypes.L1IncomingMessage{
+				Header: &arbostypes.L1IncomingMessageHeader{Kind: kind},
+			},
+		},
+	}
+}
+
+func TestBroadcastFiltersEachMessage(t *testing.T) {
+	config := DefaultTestBroadcasterConfig
+	config.EnableCompression = false
+	bklg := backlog.NewBacklog(func() *backlog.Config { return &config.Backlog })
+	cm := NewClientManager(nil, func() *BroadcasterConfig { return &config }, bklg)
+
+	newClient := func(filter *m.Filter) *ClientConnection {
+		client := &ClientConnection{
+			Name:     "test",
+			out:      make(chan message, 1),
+			encoding: m.EncodingJSON,
+			filter:   filter,
+		}
+		cm.clientPtrMap[client] = true
+		return client
+	}
+	unfiltered := newClient(nil)
+	deposits := newClient(&m.Filter{Kinds: []uint8{arbostypes.L1MessageType_EthDeposit}})
+	reports := newClient(&m.Filter{Kinds: []uint8{arbostypes.L1MessageType_BatchPostingReport}})
+	retryables := newClient(&m.Filter{Kinds: []uint8{arbostypes.L1MessageType_SubmitRetryable}})
+
+	received := func(client *ClientConnection, first, last arbutil.MessageIndex, confirmed bool) []arbutil.MessageIndex {
+		t.Helper()
+		msg := <-client.out
+		if msg.firstSequenceNumber == nil || *msg.firstSequenceNumber != first || msg.sequenceNumber == nil || *msg.sequenceNumber != last {
+			Fail(t, "unexpected sequence numbers queued", msg.firstSequenceNumber, msg.sequenceNumber)
+		}
+		if len(msg.data) == 0 {
+			if confirmed {
+				Fail(t, "confirmation not sent")
+			}
+			return nil
+		}
+		frame, err := ws.ReadFrame(bytes.NewReader(msg.data))
+		Require(t, err)
+		bm, err := m.DecodeBroadcastMessage(frame.Payload, m.EncodingJSON)
+		Require(t, err)
+		if confirmed != (bm.ConfirmedSequenceNumberMessage != nil) {
+			Fail(t, "unexpected confirmation", bm.ConfirmedSequenceNumberMessage)
+		}
+		var seqNums []arbutil.MessageIndex
+		for _, feedMsg := range bm.Messages {
+			seqNums = append(seqNums, feedMsg.SequenceNumber)
+		}
+		return seqNums
+	}
+	expectSeqNums := func(name string, got []arbutil.MessageIndex, want ...arbutil.MessageIndex) {
+		t.Helper()
+		if len(got) != len(want) {
+			Fail(t, name, "received", got, "expected", want)
+		}
+		for i := range want {
+			if got[i] != want[i] {
+				Fail(t, name, "received", got, "expected", want)
+			}
+		}
+	}
+
+	bm := &m.BroadcastMessage{
+		Version: m.V1,
+		Messages: []*m.BroadcastFeedMessage{
+			testFeedMessage(0, arbostypes.L1MessageType_L2Message),
+			testFeedMessage(1, arbostypes.L1MessageType_EthDeposit),
+			testFeedMessage(2, arbostypes.L1MessageType_L2Message),
+			testFeedMessage(3, arbostypes.L1MessageType_EthDeposit),
+		},
+	}
+	deleted, err := cm.doBroadcast(bm)
+	Require(t, err)
+	if len(deleted) > 0 {
+		Fail(t, "clients unexpectedly removed", len(deleted))
+	}
+	expectSeqNums("unfiltered", received(unfiltered, 0, 3, false), 0, 1, 2, 3)
+	expectSeqNums("deposits", received(deposits, 0, 3, false), 1, 3)
+	expectSeqNums("reports", received(reports, 0, 3, false))
+	expectSeqNums("retryables", received(retryables, 0, 3, false))
+
+	// Clients whose filter matches nothing still get the confirmation
+	bm = &m.BroadcastMessage{
+		Version: m.V1,
+		Messages: []*m.BroadcastFeedMessage{
+			testFeedMessage(4, arbostypes.L1MessageType_BatchPostingReport),
+			testFeedMessage(5, arbostypes.L1MessageType_BatchPostingReport),
+			testFeedMessage(6, arbostypes.L1MessageType_L2Message),
+		},
+		ConfirmedSequenceNumberMessage: &m.ConfirmedSequenceNumberMessage{SequenceNumber: 1},
+	}
+	_, err = cm.doBroadcast(bm)
+	Require(t, err)
+	expectSeqNums("unfiltered", received(unfiltered, 4, 6, true), 4, 5, 6)
+	expectSeqNums("deposits", received(deposits, 4, 6, true))
+	expectSeqNums("reports", received(reports, 4, 6, true), 4, 5)
+	expectSeqNums("retryables", received(retryables, 4, 6, true))
+}
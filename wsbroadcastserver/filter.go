@@ -0,0 +1,70 @@
+// Copyright 2026, Offchain Labs, Inc.
+// For license information, see https://github.com/OffchainLabs/nitro/blob/master/LICENSE.md
+
+package wsbroadcastserver
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	m "github.com/offchainlabs/nitro/broadcaster/message"
+)
+
+// AddFilterHTTPHeaders sets the handshake headers subscribing to the messages
+// matching the filter.
+func AddFilterHTTPHeaders(header http.Header, filter *m.Filter) {
+	if filter.IsEmpty() {
+		return
+	}
+	if len(filter.Kinds) > 0 {
+		kinds := make([]string, 0, len(filter.Kinds))
+		for _, kind := range filter.Kinds {
+			kinds = append(kinds, strconv.FormatUint(uint64(kind), 10))
+		}
+		header[HTTPHeaderFilterKinds] = []string{strings.Join(kinds, ",")}
+	}
+	if len(filter.Posters) > 0 {
+		posters := make([]string, 0, len(filter.Posters))
+		for _, poster := range filter.Posters {
+			posters = append(posters, poster.Hex())
+		}
+		header[HTTPHeaderFilterPosters] = []string{strings.Join(posters, ",")}
+	}
+	if filter.TimeboostedOnly {
+		header[HTTPHeaderFilterTimeboosted] = []string{"true"}
+	}
+}
+
+// parseFilterHeader adds the criterion of a filter handshake header to the
+// filter.
+func parseFilterHeader(filter *m.Filter, headerName string, value string) error {
+	switch headerName {
+	case HTTPHeaderFilterKinds:
+		for _, item := range strings.Split(value, ",") {
+			kind, err := strconv.ParseUint(strings.TrimSpace(item), 0, 8)
+			if err != nil {
+				return fmt.Errorf("invalid message kind %q", item)
+			}
+			filter.Kinds = append(filter.Kinds, uint8(kind))
+		}
+	case HTTPHeaderFilterPosters:
+		for _, item := range strings.Split(value, ",") {
+			item = strings.TrimSpace(item)
+			if !common.IsHexAddress(item) {
+				return fmt.Errorf("invalid poster address %q", item)
+			}
+			filter.Posters = append(filter.Posters, common.HexToAddress(item))
+		}
+	case HTTPHeaderFilterTimeboosted:
+		timeboostedOnly, err := strconv.ParseBool(strings.TrimSpace(value))
+		if err != nil {
+			return fmt.Errorf("invalid timeboosted filter %q", value)
+		}
+		filter.TimeboostedOnly = timeboostedOnly
+	}
+	return nil
+}
@@ -0,0 +1,142 @@
+// Copyright 2026, Offchain Labs, Inc.
+// For license information, see https://github.com/OffchainLabs/nitro/blob/master/LICENSE.md
+
+package wsbroadcastserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+
+	"github.com/gobwas/ws"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+
+	"github.com/offchainlabs/nitro/arbutil"
+	m "github.com/offchainlabs/nitro/broadcaster/message"
+)
+
+var (
+	replayRequestsCounter = metrics.NewRegisteredCounter("arb/feed/replay/requests", nil)
+	replayFailedCounter   = metrics.NewRegisteredCounter("arb/feed/replay/failed", nil)
+	replayMessagesCounter = metrics.NewRegisteredCounter("arb/feed/replay/messages", nil)
+)
+
+// replayChunkSize is the maximum number of messages sent in a single
+// BroadcastMessage when replaying a range.
+const replayChunkSize = 64
+
+var ErrReplayRangeNotAvailable = errors.New("requested replay range not available")
+
+// FeedHistory provides the feed messages of a sequence number range, which
+// may no longer be available in the backlog.
+type FeedHistory interface {
+	GetFeedMessages(start, end arbutil.MessageIndex) ([]*m.BroadcastFeedMessage, error)
+}
+
+// replayRequest is a request for the messages [start, end], parsed from the
+// replay handshake headers.
+type replayRequest struct {
+	start    *arbutil.MessageIndex
+	end      *arbutil.MessageIndex
+	encoding m.Encoding
+	filter   *m.Filter
+}
+
+func (r *replayRequest) requested() bool {
+	return r.start != nil || r.end != nil
+}
+
+func (r *replayRequest) validate(maxRange uint64) error {
+	if maxRange == 0 {
+		return errors.New("feed replay is disabled")
+	}
+	if r.start == nil || r.end == nil {
+		return fmt.Errorf("both %s and %s must be provided", HTTPHeaderReplayStart, HTTPHeaderReplayEnd)
+	}
+	if *r.start > *r.end {
+		return fmt.Errorf("replay start %d is after replay end %d", *r.start, *r.end)
+	}
+	if uint64(*r.end-*r.start) >= maxRange {
+		return fmt.Errorf("replay range of %d messages exceeds the limit of %d", uint64(*r.end-*r.start)+1, maxRange)
+	}
+	return nil
+}
+
+// replay writes the requested messages matching the client's filter to the
+// connection in chunks, using the negotiated encoding and compression, and
+// then closes the connection. The key rotations are written first, as to
+// clients of the live feed, so that the messages signed by a previous feed
+// signer can be verified as well as the ones signed by the current one.
+// Replay connections are never registered with the ClientManager, so they do
+// not receive live messages.
+func (s *WSBroadcastServer) replay(ctx context.Context, conn net.Conn, request *replayRequest, compression bool) {
+	defer func() {
+		_ = conn.Close()
+	}()
+	replayRequestsCounter.Inc(1)
+	closeStatus := ws.StatusNormalClosure
+	closeReason := ""
+	write := func(bm *m.BroadcastMessage) error {
+		notCompressed, compressed, err := serializeMessage(bm, request.encoding, !compression, compression)
+		if err != nil {
+			return err
+		}
+		data := notCompressed.Bytes()
+		if compression {
+			data = compressed.Bytes()
+		}
+		_, err = conn.Write(data)
+		return err
+	}
+	for _, rotation := range s.KeyRotations() {
+		if err := write(&m.BroadcastMessage{Version: m.V1, KeyRotationMessage: rotation}); err != nil {
+			logWarn(err, "error writing key rotations for feed replay")
+			replayFailedCounter.Inc(1)
+			return
+		}
+	}
+	for start := *request.start; start <= *request.end; start += replayChunkSize {
+		if ctx.Err() != nil {
+			return
+		}
+		end := min(start+replayChunkSize-1, *request.end)
+		var msgs []*m.BroadcastFeedMessage
+		var err error
+		if s.history != nil {
+			msgs, err = s.history.GetFeedMessages(start, end)
+		} else {
+			err = ErrReplayRangeNotAvailable
+		}
+		if err != nil {
+			log.Debug("error reading messages for feed replay", "start", start, "end", end, "err", err)
+			replayFailedCounter.Inc(1)
+			closeStatus = ws.StatusUnsupportedData
+			closeReason = ErrReplayRangeNotAvailable.Error()
+			break
+		}
+		msgs = request.filter.Apply(msgs)
+		if len(msgs) > 0 {
+			bm := &m.BroadcastMessage{
+				Version:  m.V1,
+				Messages: msgs,
+			}
+			if err := write(bm); err != nil {
+				logWarn(err, "error writing messages for feed replay")
+				replayFailedCounter.Inc(1)
+				return
+			}
+			// #nosec G115
+			replayMessagesCounter.Inc(int64(len(msgs)))
+		}
+		if end == *request.end {
+			break
+		}
+	}
+	closeFrame := ws.NewCloseFrame(ws.NewCloseFrameBody(closeStatus, closeReason))
+	if err := ws.WriteFrame(conn, closeFrame); err != nil {
+		logWarn(err, "error writing close frame for feed replay")
+	}
+}
@@ -7,9 +7,11 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"net"
 	"net/http"
 	"net/textproto"
+	"slices"
 	"strconv"
 	"strings"
 	"sync"
@@ -36,6 +38,12 @@ var (
 	HTTPHeaderFeedClientVersion       = textproto.CanonicalMIMEHeaderKey("Arbitrum-Feed-Client-Version")
 	HTTPHeaderRequestedSequenceNumber = textproto.CanonicalMIMEHeaderKey("Arbitrum-Requested-Sequence-Number")
 	HTTPHeaderChainId                 = textproto.CanonicalMIMEHeaderKey("Arbitrum-Chain-Id")
+	HTTPHeaderFeedEncoding            = textproto.CanonicalMIMEHeaderKey("Arbitrum-Feed-Encoding")
+	HTTPHeaderReplayStart             = textproto.CanonicalMIMEHeaderKey("Arbitrum-Replay-Start-Sequence-Number")
+	HTTPHeaderReplayEnd               = textproto.CanonicalMIMEHeaderKey("Arbitrum-Replay-End-Sequence-Number")
+	HTTPHeaderFilterKinds             = textproto.CanonicalMIMEHeaderKey("Arbitrum-Feed-Filter-Kinds")
+	HTTPHeaderFilterPosters           = textproto.CanonicalMIMEHeaderKey("Arbitrum-Feed-Filter-Posters")
+	HTTPHeaderFilterTimeboosted       = textproto.CanonicalMIMEHeaderKey("Arbitrum-Feed-Filter-Timeboosted")
 	upgradeToWSTimer                  = metrics.NewRegisteredHistogram("arb/feed/clients/upgrade/duration", nil, metrics.NewBoundedHistogramSample())
 	startWithHeaderTimer              = metrics.NewRegisteredHistogram("arb/feed/clients/start/duration", nil, metrics.NewBoundedHistogramSample())
 )
@@ -47,35 +55,56 @@ const (
 )
 
 type BroadcasterConfig struct {
-	Enable             bool                    `koanf:"enable"`
-	Signed             bool                    `koanf:"signed"`
-	Addr               string                  `koanf:"addr"`
-	ReadTimeout        time.Duration           `koanf:"read-timeout" reload:"hot"`      // reloaded value will affect all clients (next time the timeout is checked)
-	WriteTimeout       time.Duration           `koanf:"write-timeout" reload:"hot"`     // reloading will affect only new connections
-	HandshakeTimeout   time.Duration           `koanf:"handshake-timeout" reload:"hot"` // reloading will affect only new connections
-	Port               string                  `koanf:"port"`
-	Ping               time.Duration           `koanf:"ping" reload:"hot"`           // reloaded value will change future ping intervals
-	ClientTimeout      time.Duration           `koanf:"client-timeout" reload:"hot"` // reloaded value will affect all clients (next time the timeout is checked)
-	Queue              int                     `koanf:"queue"`
-	Workers            int                     `koanf:"workers"`
-	MaxSendQueue       int                     `koanf:"max-send-queue" reload:"hot"`  // reloaded value will affect only new connections
-	RequireVersion     bool                    `koanf:"require-version" reload:"hot"` // reloaded value will affect only future upgrades to websocket
-	LogConnect         bool                    `koanf:"log-connect"`
-	LogDisconnect      bool                    `koanf:"log-disconnect"`
-	EnableCompression  bool                    `koanf:"enable-compression" reload:"hot"`  // if reloaded to false will cause disconnection of clients with enabled compression on next broadcast
-	RequireCompression bool                    `koanf:"require-compression" reload:"hot"` // if reloaded to true will cause disconnection of clients with disabled compression on next broadcast
-	LimitCatchup       bool                    `koanf:"limit-catchup" reload:"hot"`
-	MaxCatchup         int                     `koanf:"max-catchup" reload:"hot"`
-	ConnectionLimits   ConnectionLimiterConfig `koanf:"connection-limits" reload:"hot"`
-	ClientDelay        time.Duration           `koanf:"client-delay" reload:"hot"`
-	Backlog            backlog.Config          `koanf:"backlog" reload:"hot"`
+	Enable               bool                    `koanf:"enable"`
+	Signed               bool                    `koanf:"signed"`
+	Addr                 string                  `koanf:"addr"`
+	ReadTimeout          time.Duration           `koanf:"read-timeout" reload:"hot"`      // reloaded value will affect all clients (next time the timeout is checked)
+	WriteTimeout         time.Duration           `koanf:"write-timeout" reload:"hot"`     // reloading will affect only new connections
+	HandshakeTimeout     time.Duration           `koanf:"handshake-timeout" reload:"hot"` // reloading will affect only new connections
+	Port                 string                  `koanf:"port"`
+	Ping                 time.Duration           `koanf:"ping" reload:"hot"`           // reloaded value will change future ping intervals
+	ClientTimeout        time.Duration           `koanf:"client-timeout" reload:"hot"` // reloaded value will affect all clients (next time the timeout is checked)
+	Queue                int                     `koanf:"queue"`
+	Workers              int                     `koanf:"workers"`
+	MaxSendQueue         int                     `koanf:"max-send-queue" reload:"hot"`  // reloaded value will affect only new connections
+	RequireVersion       bool                    `koanf:"require-version" reload:"hot"` // reloaded value will affect only future upgrades to websocket
+	LogConnect           bool                    `koanf:"log-connect"`
+	LogDisconnect        bool                    `koanf:"log-disconnect"`
+	EnableCompression    bool                    `koanf:"enable-compression" reload:"hot"`     // if reloaded to false will cause disconnection of clients with enabled compression on next broadcast
+	RequireCompression   bool                    `koanf:"require-compression" reload:"hot"`    // if reloaded to true will cause disconnection of clients with disabled compression on next broadcast
+	EnableBinaryEncoding bool                    `koanf:"enable-binary-encoding" reload:"hot"` // if reloaded to false will cause disconnection of clients with binary encoding on next broadcast
+	LimitCatchup         bool                    `koanf:"limit-catchup" reload:"hot"`
+	MaxCatchup           int                     `koanf:"max-catchup" reload:"hot"`
+	ConnectionLimits     ConnectionLimiterConfig `koanf:"connection-limits" reload:"hot"`
+	ClientDelay          time.Duration           `koanf:"client-delay" reload:"hot"`
+	MaxReplayRange       uint64                  `koanf:"max-replay-range" reload:"hot"`
+	SignerRotation       SignerRotationConfig    `koanf:"signer-rotation"`
+	Backlog              backlog.Config          `koanf:"backlog" reload:"hot"`
+}
+
+type SignerRotationConfig struct {
+	NewSignerKey             string `koanf:"new-signer-key"`
+	ActivationSequenceNumber uint64 `koanf:"activation-sequence-number"`
+}
+
+var DefaultSignerRotationConfig = SignerRotationConfig{
+	NewSignerKey:             "",
+	ActivationSequenceNumber: 0,
+}
+
+func SignerRotationConfigAddOptions(prefix string, f *pflag.FlagSet) {
+	f.String(prefix+".new-signer-key", DefaultSignerRotationConfig.NewSignerKey, "ecdsa private key to rotate the feed signer to, treated as a hex string if prefixed with 0x otherwise treated as a file; it must stay configured after the rotation")
+	f.Uint64(prefix+".activation-sequence-number", DefaultSignerRotationConfig.ActivationSequenceNumber, "sequence number from which the feed is signed with new-signer-key; the rotation can also be scheduled with the arbfeed_rotateSigner admin RPC (0 means not scheduled)")
 }
 
 func (bc *BroadcasterConfig) Validate() error {
 	if !bc.EnableCompression && bc.RequireCompression {
 		return errors.New("require-compression cannot be true while enable-compression is false")
 	}
-	return nil
+	if bc.SignerRotation.ActivationSequenceNumber != 0 && bc.SignerRotation.NewSignerKey == "" {
+		return errors.New("signer-rotation.activation-sequence-number cannot be set without signer-rotation.new-signer-key")
+	}
+	return bc.Backlog.Validate()
 }
 
 type BroadcasterConfigFetcher func() *BroadcasterConfig
@@ -98,61 +127,70 @@ func BroadcasterConfigAddOptions(prefix string, f *pflag.FlagSet) {
 	f.Bool(prefix+".log-disconnect", DefaultBroadcasterConfig.LogDisconnect, "log every client disconnect")
 	f.Bool(prefix+".enable-compression", DefaultBroadcasterConfig.EnableCompression, "enable per message deflate compression support")
 	f.Bool(prefix+".require-compression", DefaultBroadcasterConfig.RequireCompression, "require clients to use compression")
+	f.Bool(prefix+".enable-binary-encoding", DefaultBroadcasterConfig.EnableBinaryEncoding, "allow clients to negotiate the compact binary encoding of feed messages")
 	f.Bool(prefix+".limit-catchup", DefaultBroadcasterConfig.LimitCatchup, "only supply catchup buffer if requested sequence number is reasonable")
 	f.Int(prefix+".max-catchup", DefaultBroadcasterConfig.MaxCatchup, "the maximum size of the catchup buffer (-1 means unlimited)")
 	ConnectionLimiterConfigAddOptions(prefix+".connection-limits", f)
 	f.Duration(prefix+".client-delay", DefaultBroadcasterConfig.ClientDelay, "delay the first messages sent to each client by this amount")
+	f.Uint64(prefix+".max-replay-range", DefaultBroadcasterConfig.MaxReplayRange, "maximum number of messages a client can request in a single replay of a sequence number range; replay connections count against the connection limits (0 disables replay)")
+	SignerRotationConfigAddOptions(prefix+".signer-rotation", f)
 	backlog.AddOptions(prefix+".backlog", f)
 }
 
 var DefaultBroadcasterConfig = BroadcasterConfig{
-	Enable:             false,
-	Signed:             false,
-	Addr:               "",
-	ReadTimeout:        time.Second,
-	WriteTimeout:       2 * time.Second,
-	HandshakeTimeout:   time.Second,
-	Port:               "9642",
-	Ping:               5 * time.Second,
-	ClientTimeout:      15 * time.Second,
-	Queue:              100,
-	Workers:            100,
-	MaxSendQueue:       4096,
-	RequireVersion:     false,
-	LogConnect:         false,
-	LogDisconnect:      false,
-	EnableCompression:  false,
-	RequireCompression: false,
-	LimitCatchup:       false,
-	MaxCatchup:         -1,
-	ConnectionLimits:   DefaultConnectionLimiterConfig,
-	ClientDelay:        0,
-	Backlog:            backlog.DefaultConfig,
+	Enable:               false,
+	Signed:               false,
+	Addr:                 "",
+	ReadTimeout:          time.Second,
+	WriteTimeout:         2 * time.Second,
+	HandshakeTimeout:     time.Second,
+	Port:                 "9642",
+	Ping:                 5 * time.Second,
+	ClientTimeout:        15 * time.Second,
+	Queue:                100,
+	Workers:              100,
+	MaxSendQueue:         4096,
+	RequireVersion:       false,
+	LogConnect:           false,
+	LogDisconnect:        false,
+	EnableCompression:    false,
+	RequireCompression:   false,
+	EnableBinaryEncoding: true,
+	LimitCatchup:         false,
+	MaxCatchup:           -1,
+	ConnectionLimits:     DefaultConnectionLimiterConfig,
+	ClientDelay:          0,
+	MaxReplayRange:       0,
+	SignerRotation:       DefaultSignerRotationConfig,
+	Backlog:              backlog.DefaultConfig,
 }
 
 var DefaultTestBroadcasterConfig = BroadcasterConfig{
-	Enable:             false,
-	Signed:             false,
-	Addr:               "0.0.0.0",
-	ReadTimeout:        2 * time.Second,
-	WriteTimeout:       2 * time.Second,
-	HandshakeTimeout:   2 * time.Second,
-	Port:               "0",
-	Ping:               5 * time.Second,
-	ClientTimeout:      15 * time.Second,
-	Queue:              1,
-	Workers:            100,
-	MaxSendQueue:       4096,
-	RequireVersion:     false,
-	LogConnect:         false,
-	LogDisconnect:      false,
-	EnableCompression:  true,
-	RequireCompression: false,
-	LimitCatchup:       false,
-	MaxCatchup:         -1,
-	ConnectionLimits:   DefaultConnectionLimiterConfig,
-	ClientDelay:        0,
-	Backlog:            backlog.DefaultTestConfig,
+	Enable:               false,
+	Signed:               false,
+	Addr:                 "0.0.0.0",
+	ReadTimeout:          2 * time.Second,
+	WriteTimeout:         2 * time.Second,
+	HandshakeTimeout:     2 * time.Second,
+	Port:                 "0",
+	Ping:                 5 * time.Second,
+	ClientTimeout:        15 * time.Second,
+	Queue:                1,
+	Workers:              100,
+	MaxSendQueue:         4096,
+	RequireVersion:       false,
+	LogConnect:           false,
+	LogDisconnect:        false,
+	EnableCompression:    true,
+	RequireCompression:   false,
+	EnableBinaryEncoding: true,
+	LimitCatchup:         false,
+	MaxCatchup:           -1,
+	ConnectionLimits:     DefaultConnectionLimiterConfig,
+	ClientDelay:          0,
+	MaxReplayRange:       10000,
+	SignerRotation:       DefaultSignerRotationConfig,
+	Backlog:              backlog.DefaultTestConfig,
 }
 
 type WSBroadcastServer struct {
@@ -167,15 +205,20 @@ type WSBroadcastServer struct {
 	started       bool
 	clientManager *ClientManager
 	backlog       backlog.Backlog
+	history       FeedHistory
 	chainId       uint64
 	fatalErrChan  chan error
+
+	keyRotationsMutex sync.Mutex
+	keyRotations      []*m.KeyRotationMessage
 }
 
-func NewWSBroadcastServer(config BroadcasterConfigFetcher, bklg backlog.Backlog, chainId uint64, fatalErrChan chan error) *WSBroadcastServer {
+func NewWSBroadcastServer(config BroadcasterConfigFetcher, bklg backlog.Backlog, history FeedHistory, chainId uint64, fatalErrChan chan error) *WSBroadcastServer {
 	return &WSBroadcastServer{
 		config:       config,
 		started:      false,
 		backlog:      bklg,
+		history:      history,
 		chainId:      chainId,
 		fatalErrChan: fatalErrChan,
 	}
@@ -255,6 +298,10 @@ func (s *WSBroadcastServer) StartWithHeader(ctx context.Context, header ws.Hands
 		var feedClientVersionSeen bool
 		var connectingIP net.IP
 		var requestedSeqNum arbutil.MessageIndex
+		var encodingRequested bool
+		encoding := m.EncodingJSON
+		var replay replayRequest
+		var filter m.Filter
 		upgrader := ws.Upgrader{
 			OnRequest: func(uri []byte) error {
 				if strings.Contains(string(uri), LivenessProbeURI) {
@@ -290,6 +337,38 @@ func (s *WSBroadcastServer) StartWithHeader(ctx context.Context, header ws.Hands
 						)
 					}
 					requestedSeqNum = arbutil.MessageIndex(num)
+				} else if headerName == HTTPHeaderReplayStart || headerName == HTTPHeaderReplayEnd {
+					num, err := strconv.ParseUint(string(value), 0, 64)
+					if err != nil {
+						return ws.RejectConnectionError(
+							ws.RejectionStatus(http.StatusBadRequest),
+							ws.RejectionReason(fmt.Sprintf("Malformed HTTP header %s", headerName)),
+						)
+					}
+					seqNum := arbutil.MessageIndex(num)
+					if headerName == HTTPHeaderReplayStart {
+						replay.start = &seqNum
+					} else {
+						replay.end = &seqNum
+					}
+				} else if headerName == HTTPHeaderFeedEncoding {
+					// The client lists the encodings it accepts in order of preference,
+					// pick the first one this server is willing to use.
+					encodingRequested = true
+					for _, requested := range m.ParseEncodings(string(value)) {
+						if requested.IsBinary() && !config.EnableBinaryEncoding {
+							continue
+						}
+						encoding = requested
+						break
+					}
+				} else if headerName == HTTPHeaderFilterKinds || headerName == HTTPHeaderFilterPosters || headerName == HTTPHeaderFilterTimeboosted {
+					if err := parseFilterHeader(&filter, headerName, string(value)); err != nil {
+						return ws.RejectConnectionError(
+							ws.RejectionStatus(http.StatusBadRequest),
+							ws.RejectionReason(fmt.Sprintf("Malformed HTTP header %s: %v", headerName, err)),
+						)
+					}
 				} else if headerName == HTTPHeaderCloudflareConnectingIP {
 					connectingIP = net.ParseIP(string(value))
 					log.Trace("Client IP parsed from header", "ip", connectingIP, "header", headerName, "value", string(value))
@@ -313,6 +392,15 @@ func (s *WSBroadcastServer) StartWithHeader(ctx context.Context, header ws.Hands
 					}
 				}
 
+				if replay.requested() {
+					if err := replay.validate(config.MaxReplayRange); err != nil {
+						return nil, ws.RejectConnectionError(
+							ws.RejectionStatus(http.StatusBadRequest),
+							ws.RejectionReason(err.Error()),
+						)
+					}
+				}
+
 				if config.ConnectionLimits.Enable && !s.clientManager.connectionLimiter.IsAllowed(connectingIP) {
 					return nil, ws.RejectConnectionError(
 						ws.RejectionStatus(http.StatusTooManyRequests),
@@ -320,6 +408,14 @@ func (s *WSBroadcastServer) StartWithHeader(ctx context.Context, header ws.Hands
 					)
 				}
 
+				if encodingRequested {
+					return handshakeHeaders{
+						header,
+						ws.HandshakeHeaderHTTP(http.Header{
+							HTTPHeaderFeedEncoding: []string{encoding.String()},
+						}),
+					}, nil
+				}
 				return header, nil
 			},
 			Negotiate: negotiate,
@@ -364,6 +460,40 @@ func (s *WSBroadcastServer) StartWithHeader(ctx context.Context, header ws.Hands
 			return
 		}
 
+		safeConn := writeDeadliner{conn, config.WriteTimeout}
+
+		var clientFilter *m.Filter
+		if !filter.IsEmpty() {
+			clientFilter = &filter
+		}
+
+		if replay.requested() {
+			replay.encoding = encoding
+			replay.filter = clientFilter
+			// Replay connections count against the connection limits like the
+			// clients registered with the ClientManager
+			limited := config.ConnectionLimits.Enable
+			if limited && !s.clientManager.connectionLimiter.Register(connectingIP) {
+				log.Debug("feed replay connection limited", "connectingIP", connectingIP)
+				_ = conn.Close()
+				return
+			}
+			err = s.clientManager.LaunchThreadSafe(func(ctx context.Context) {
+				if limited {
+					defer s.clientManager.connectionLimiter.Release(connectingIP)
+				}
+				s.replay(ctx, safeConn, &replay, compressionAccepted)
+			})
+			if err != nil {
+				log.Warn("error starting feed replay", "connectingIP", connectingIP, "err", err)
+				if limited {
+					s.clientManager.connectionLimiter.Release(connectingIP)
+				}
+				_ = conn.Close()
+			}
+			return
+		}
+
 		// Create netpoll event descriptor to handle only read events.
 		desc, err := netpoll.HandleRead(conn)
 		if err != nil {
@@ -373,9 +503,7 @@ func (s *WSBroadcastServer) StartWithHeader(ctx context.Context, header ws.Hands
 		}
 
 		// Register incoming client in clientManager.
-		safeConn := writeDeadliner{conn, config.WriteTimeout}
-
-		client := NewClientConnection(safeConn, desc, s.clientManager.clientAction, requestedSeqNum, connectingIP, compressionAccepted, s.config().MaxSendQueue, s.config().ClientDelay, s.backlog)
+		client := NewClientConnection(safeConn, desc, s.clientManager.clientAction, requestedSeqNum, connectingIP, compressionAccepted, encoding, clientFilter, s.config().MaxSendQueue, s.config().ClientDelay, s.backlog, s.KeyRotations)
 		client.Start(ctx)
 
 		// Subscribe to events about conn.
@@ -536,6 +664,44 @@ func (s *WSBroadcastServer) Broadcast(bm *m.BroadcastMessage) {
 	s.clientManager.Broadcast(bm)
 }
 
+// BroadcastKeyRotation sends the key rotation to all clients. Every key
+// rotation is also sent to clients when they connect, so that they can
+// verify the signatures of the messages following the rotation. Rotations
+// which were already broadcast, e.g. by a relay receiving them from several
+// feeds, are skipped.
+func (s *WSBroadcastServer) BroadcastKeyRotation(rotation *m.KeyRotationMessage) {
+	if !s.AddKeyRotation(rotation) {
+		return
+	}
+	s.Broadcast(&m.BroadcastMessage{
+		Version:            m.V1,
+		KeyRotationMessage: rotation,
+	})
+}
+
+// AddKeyRotation records a key rotation to be sent to clients when they
+// connect, without sending it to the clients already connected. It can be
+// called before the server is started. It returns false if the rotation was
+// already recorded.
+func (s *WSBroadcastServer) AddKeyRotation(rotation *m.KeyRotationMessage) bool {
+	s.keyRotationsMutex.Lock()
+	defer s.keyRotationsMutex.Unlock()
+	for _, previous := range s.keyRotations {
+		if previous.NewSigner == rotation.NewSigner && previous.ActivationSequenceNumber == rotation.ActivationSequenceNumber {
+			return false
+		}
+	}
+	s.keyRotations = append(s.keyRotations, rotation)
+	return true
+}
+
+// KeyRotations returns all key rotations broadcast so far.
+func (s *WSBroadcastServer) KeyRotations() []*m.KeyRotationMessage {
+	s.keyRotationsMutex.Lock()
+	defer s.keyRotationsMutex.Unlock()
+	return slices.Clone(s.keyRotations)
+}
+
 func (s *WSBroadcastServer) PopulateFeedBacklog(bm *m.BroadcastMessage) error {
 	return s.clientManager.populateFeedBacklog(bm)
 }
@@ -544,6 +710,30 @@ func (s *WSBroadcastServer) ClientCount() int32 {
 	return s.clientManager.ClientCount()
 }
 
+func (s *WSBroadcastServer) Clients(ctx context.Context) ([]ClientInfo, error) {
+	return s.clientManager.Clients(ctx)
+}
+
+func (s *WSBroadcastServer) DisconnectClient(ctx context.Context, name string) error {
+	return s.clientManager.DisconnectClient(ctx, name)
+}
+
+// handshakeHeaders writes multiple handshake headers one after another, so
+// that per connection headers can be appended to the static server headers.
+type handshakeHeaders []ws.HandshakeHeader
+
+func (h handshakeHeaders) WriteTo(w io.Writer) (int64, error) {
+	var total int64
+	for _, header := range h {
+		n, err := header.WriteTo(w)
+		total += n
+		if err != nil {
+			return total, err
+		}
+	}
+	return total, nil
+}
+
 // writeDeadliner is a wrapper around net.Conn that sets write deadlines before
 // every Write() call.
 type writeDeadliner struct {